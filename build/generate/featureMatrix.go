@@ -36,6 +36,10 @@ func generateFeatureMatrix() error {
 			{"TLSA", "Provider can manage TLSA records"},
 			{"TXTMulti", "Provider can manage TXT records with multiple strings"},
 			{"R53_ALIAS", "Provider supports Route 53 limited ALIAS"},
+			{"AUTO TTL", "Provider has an automatic TTL that TTL('auto') maps to"},
+			{"GEO", "Provider can serve records by the location of the client (GEO())"},
+			{"URL forwarding", "Provider can manage the web forwarding records URL, URL301 and FRAME"},
+			{"SOA", "Provider takes the SOA record from SOA(), instead of managing it itself"},
 
 			{"dual host", "This provider is recommended for use in 'dual hosting' scenarios. Usually this means the provider allows full control over the apex NS records"},
 			{"create-domains", "This means the provider can automatically create domains that do not currently exist on your account. The 'dnscontrol create-domains' command will initialize any missing domains"},
@@ -77,6 +81,10 @@ func generateFeatureMatrix() error {
 		setCap("TLSA", providers.CanUseTLSA)
 		setCap("TXTMulti", providers.CanUseTXTMulti)
 		setCap("R53_ALIAS", providers.CanUseRoute53Alias)
+		setCap("AUTO TTL", providers.CanUseAutoTTL)
+		setCap("GEO", providers.CanUseGeoRecords)
+		setCap("URL forwarding", providers.CanUseURLForward)
+		setCap("SOA", providers.CanUseSOA)
 		setDoc("dual host", providers.DocDualHost, false)
 		setDoc("create-domains", providers.DocCreateDomains, true)
 
@@ -15,6 +15,11 @@ convertzone: Read and write DNS zone files.
     -out=tsv      TAB-separated values
     -out=pretty   pretty-printed (BIND-style zonefiles)
 
+    -cloudflare=NAME  Read which records are proxied from the Cloudflare
+                      provider NAME in the -creds file (default creds.json)
+                      and add CF_PROXY_ON to them in the DSL output.
+                      Zone exports don't include the proxy state.
+
     zonename    The FQDN of the zone name.
     filename    File to read (optional. Defaults to stdin)
 
@@ -43,7 +48,10 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/StackExchange/dnscontrol/providers"
 	"github.com/StackExchange/dnscontrol/providers/bind"
+	"github.com/StackExchange/dnscontrol/providers/cloudflare"
+	"github.com/StackExchange/dnscontrol/providers/config"
 	"github.com/StackExchange/dnscontrol/providers/octodns/octoyaml"
 	"github.com/miekg/dns"
 	"github.com/miekg/dns/dnsutil"
@@ -55,6 +63,8 @@ var flagOutfmt = flag.String("out", "dsl", "dsl|tsv|pretty")
 var flagDefaultTTL = flag.Uint("ttl", 300, "Default TTL")
 var flagRegText = flag.String("registrar", "REG_FILL_IN", "registrar text")
 var flagProviderText = flag.String("provider", "DNS_FILL_IN", "provider text")
+var flagCloudflare = flag.String("cloudflare", "", "Cloudflare provider (name in the creds file) to read the proxy state of records from")
+var flagCreds = flag.String("creds", "creds.json", "Provider credentials JSON file")
 
 func main() {
 	flag.Parse()
@@ -79,6 +89,14 @@ func main() {
 		recs = readOctodns(zonename, reader, filename)
 	}
 
+	var proxied map[string]bool
+	if *flagCloudflare != "" {
+		proxied, err = readCloudflareProxied(zonename, *flagCreds, *flagCloudflare)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	// Write it out:
 
 	switch *flagOutfmt {
@@ -86,10 +104,10 @@ func main() {
 		bind.WriteZoneFile(os.Stdout, recs, zonename)
 	case "dsl":
 		fmt.Printf(`D("%s", %s, DnsProvider(%s)`, zonename, *flagRegText, *flagProviderText)
-		rrFormat(zonename, filename, recs, defTTL, true)
+		rrFormat(os.Stdout, zonename, filename, recs, defTTL, true, proxied)
 		fmt.Println("\n)")
 	case "tsv":
-		rrFormat(zonename, filename, recs, defTTL, false)
+		rrFormat(os.Stdout, zonename, filename, recs, defTTL, false, nil)
 	default:
 		fmt.Println("convertzone [-flags] ZONENAME FILENAME")
 		flag.Usage()
@@ -151,14 +169,61 @@ func readOctodns(zonename string, r io.Reader, filename string) []dns.RR {
 	return l
 }
 
+// readCloudflareProxied reads which records of zonename are proxied from the
+// Cloudflare provider called name in credsFile.
+func readCloudflareProxied(zonename, credsFile, name string) (map[string]bool, error) {
+	configs, err := config.LoadProviderConfigs(credsFile)
+	if err != nil {
+		return nil, err
+	}
+	if configs[name] == nil {
+		return nil, errors.Errorf("-cloudflare: %s is not in %s", name, credsFile)
+	}
+	p, err := providers.CreateDNSProvider("CLOUDFLAREAPI", configs[name], nil)
+	if err != nil {
+		return nil, err
+	}
+	return p.(*cloudflare.CloudflareApi).ProxiedRecords(zonename)
+}
+
+// proxyTarget returns the target of records that Cloudflare can proxy, or
+// "" for other types.
+func proxyTarget(x dns.RR) string {
+	switch v := x.(type) {
+	case *dns.A:
+		return v.A.String()
+	case *dns.AAAA:
+		return v.AAAA.String()
+	case *dns.CNAME:
+		return v.Target
+	}
+	return ""
+}
+
 // pretty outputs the zonefile using the prettyprinter.
 func writePretty(zonename string, recs []dns.RR, defaultTTL uint32) {
 	bind.WriteZoneFile(os.Stdout, recs, zonename)
 }
 
+// dslRecord is a call of a record helper in DSL output. It has more than one
+// target when the values of a name and type were grouped.
+type dslRecord struct {
+	typeStr, name string
+	targets       []string
+	ttl, proxy    string
+}
+
+// groupedTypes are the record helpers that accept an array of targets.
+var groupedTypes = map[uint16]bool{dns.TypeA: true, dns.TypeAAAA: true, dns.TypeNS: true}
+
 // rrFormat outputs the zonefile in either DSL or TSV format.
-func rrFormat(zonename string, filename string, recs []dns.RR, defaultTTL uint32, dsl bool) {
+// In DSL format, records found in proxied (see cloudflare.ProxyKey) get CF_PROXY_ON,
+// and the A, AAAA and NS records of a name with the same TTL are grouped
+// into one call, such as A('www', ['1.2.3.4', '1.2.3.5']).
+func rrFormat(w io.Writer, zonename string, filename string, recs []dns.RR, defaultTTL uint32, dsl bool, proxied map[string]bool) {
 	zonenamedot := zonename + "."
+	var calls []*dslRecord
+	groups := map[string]*dslRecord{}
 
 	for _, x := range recs {
 
@@ -192,7 +257,7 @@ func rrFormat(zonename string, filename string, recs []dns.RR, defaultTTL uint32
 		}
 
 		if !dsl { // TSV format:
-			fmt.Printf("%s\t%s\t%s\t%s\t%s\n", name, ttl, classStr, typeStr, target)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", name, ttl, classStr, typeStr, target)
 		} else { // DSL format:
 			switch hdr.Rrtype { // #rtype_variations
 			case dns.TypeMX:
@@ -214,8 +279,29 @@ func rrFormat(zonename string, filename string, recs []dns.RR, defaultTTL uint32
 			} else {
 				ttl = fmt.Sprintf(", TTL(%d)", hdr.Ttl)
 			}
-			fmt.Printf(",\n\t%s('%s', %s%s)", typeStr, name, target, ttl)
+			proxy := ""
+			if t := proxyTarget(x); t != "" && proxied[cloudflare.ProxyKey(nameFqdn, typeStr, t)] {
+				proxy = ", CF_PROXY_ON"
+			}
+			// Values with different TTLs or proxy states stay apart.
+			key := strings.Join([]string{typeStr, name, ttl, proxy}, "\x00")
+			if g := groups[key]; g != nil {
+				g.targets = append(g.targets, target)
+				continue
+			}
+			call := &dslRecord{typeStr, name, []string{target}, ttl, proxy}
+			if groupedTypes[hdr.Rrtype] && typeStr != "NAMESERVER" {
+				groups[key] = call
+			}
+			calls = append(calls, call)
 		}
 	}
 
+	for _, c := range calls {
+		target := c.targets[0]
+		if len(c.targets) > 1 {
+			target = "[" + strings.Join(c.targets, ", ") + "]"
+		}
+		fmt.Fprintf(w, ",\n\t%s('%s', %s%s%s)", c.typeStr, c.name, target, c.ttl, c.proxy)
+	}
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/providers/cloudflare"
+)
+
+const cloudflareExport = `$ORIGIN example.com.
+www	1	IN	A	1.2.3.4
+mail	300	IN	A	1.2.3.5
+blog	1	IN	CNAME	blog.example.net.
+docs	300	IN	CNAME	docs.example.net.
+@	300	IN	MX	10 mail.example.com.
+`
+
+func TestProxiedDSL(t *testing.T) {
+	recs := readZone("example.com", strings.NewReader(cloudflareExport), "test")
+	proxied := map[string]bool{
+		cloudflare.ProxyKey("www.example.com", "A", "1.2.3.4"):               true,
+		cloudflare.ProxyKey("blog.example.com", "CNAME", "blog.example.net"): true,
+	}
+	buf := &bytes.Buffer{}
+	rrFormat(buf, "example.com", "test", recs, 300, true, proxied)
+	expected := `,
+	A('www', '1.2.3.4', TTL(1), CF_PROXY_ON),
+	A('mail', '1.2.3.5'),
+	CNAME('blog', 'blog.example.net.', TTL(1), CF_PROXY_ON),
+	CNAME('docs', 'docs.example.net.'),
+	MX('@', 10, 'mail.example.com.')`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestGroupedDSL(t *testing.T) {
+	dir := filepath.Join("testdata", "grouped")
+	f, err := os.Open(filepath.Join(dir, "example.com.zone"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	recs := readZone("example.com", f, "example.com.zone")
+	buf := &bytes.Buffer{}
+	rrFormat(buf, "example.com", "example.com.zone", recs, 300, true, nil)
+	expected, err := ioutil.ReadFile(filepath.Join(dir, "example.com.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != string(expected) {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	// TSV has a line per value.
+	buf.Reset()
+	rrFormat(buf, "example.com", "example.com.zone", recs, 300, false, nil)
+	if n := strings.Count(buf.String(), "\n"); n != len(recs) {
+		t.Errorf("expected %d TSV lines, got:\n%s", len(recs), buf.String())
+	}
+}
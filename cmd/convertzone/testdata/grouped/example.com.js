@@ -0,0 +1,10 @@
+,
+	NAMESERVER('@', 'ns1.example.net.'),
+	NAMESERVER('@', 'ns2.example.net.'),
+	A('@', '1.2.3.4'),
+	A('www', ['1.2.3.4', '1.2.3.5', '1.2.3.7']),
+	A('www', '1.2.3.6', TTL(600)),
+	AAAA('www', ['2001:db8::1', '2001:db8::2']),
+	MX('@', 10, 'mx1.example.net.'),
+	MX('@', 20, 'mx2.example.net.'),
+	NS('sub', ['ns1.example.org.', 'ns2.example.org.'])
\ No newline at end of file
@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/StackExchange/dnscontrol/pkg/normalize"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/urfave/cli"
+)
+
+var _ = cmd(catUtils, &cli.Command{
+	Name:  "capabilities",
+	Usage: "print, as JSON, the record types, features and limits of every provider type",
+	Action: func(ctx *cli.Context) error {
+		return exit(printCapabilities(os.Stdout))
+	},
+})
+
+// manifestVersion is the version of the schema of the capabilities output.
+// Fields may be added to it; it changes when one is renamed or removed.
+const manifestVersion = 1
+
+type capabilityManifest struct {
+	Version   int                `json:"version"`
+	Providers []providerManifest `json:"providers"`
+}
+
+type providerManifest struct {
+	Name      string `json:"name"`
+	DNS       bool   `json:"dns"`
+	Registrar bool   `json:"registrar"`
+	// RecordTypes is empty for registrars that aren't DNS providers.
+	RecordTypes []string `json:"record_types"`
+	// Features has every feature of manifestFeatures, true if the provider has it.
+	Features     map[string]bool         `json:"features"`
+	Notes        map[string]manifestNote `json:"notes,omitempty"`
+	Limits       manifestLimits          `json:"limits"`
+	ZoneSettings []string                `json:"zone_settings"`
+}
+
+type manifestNote struct {
+	Comment       string `json:"comment,omitempty"`
+	Link          string `json:"link,omitempty"`
+	Unimplemented bool   `json:"unimplemented,omitempty"`
+}
+
+// manifestLimits are 0 or empty when there is no limit.
+type manifestLimits struct {
+	MinTTL      uint32   `json:"min_ttl"`
+	ApexTTL     uint32   `json:"apex_ttl"`
+	NoWildcards []string `json:"no_wildcards"`
+}
+
+// manifestFeatures are the names of the capabilities in the manifest. They
+// are the features of the matrix in the documentation.
+var manifestFeatures = []struct {
+	name string
+	cap  providers.Capability
+}{
+	{"alias", providers.CanUseAlias},
+	{"caa", providers.CanUseCAA},
+	{"ptr", providers.CanUsePTR},
+	{"srv", providers.CanUseSRV},
+	{"tlsa", providers.CanUseTLSA},
+	{"txt_multi", providers.CanUseTXTMulti},
+	{"r53_alias", providers.CanUseRoute53Alias},
+	{"auto_ttl", providers.CanUseAutoTTL},
+	{"geo", providers.CanUseGeoRecords},
+	{"url_forward", providers.CanUseURLForward},
+	{"soa", providers.CanUseSOA},
+	{"official_support", providers.DocOfficiallySupported},
+	{"dual_host", providers.DocDualHost},
+	{"create_domains", providers.DocCreateDomains},
+	// Inverted below: true if NO_PURGE works.
+	{"no_purge", providers.CantUseNOPURGE},
+}
+
+// capabilities returns the manifest of every registered provider type, sorted by name.
+func capabilities() capabilityManifest {
+	names := map[string]bool{}
+	for name := range providers.DNSProviderTypes {
+		names[name] = true
+	}
+	for name := range providers.RegistrarTypes {
+		names[name] = true
+	}
+	m := capabilityManifest{Version: manifestVersion, Providers: []providerManifest{}}
+	for name := range names {
+		m.Providers = append(m.Providers, providerCapabilities(name))
+	}
+	sort.Slice(m.Providers, func(i, j int) bool { return m.Providers[i].Name < m.Providers[j].Name })
+	return m
+}
+
+func providerCapabilities(name string) providerManifest {
+	p := providerManifest{
+		Name:         name,
+		DNS:          providers.DNSProviderTypes[name] != nil,
+		Registrar:    providers.RegistrarTypes[name] != nil,
+		RecordTypes:  []string{},
+		Features:     map[string]bool{},
+		Notes:        map[string]manifestNote{},
+		ZoneSettings: append([]string{}, providers.ProviderZoneSettings(name)...),
+		Limits: manifestLimits{
+			MinTTL:      providers.ProviderMinTTL(name),
+			ApexTTL:     providers.ProviderApexTTL(name),
+			NoWildcards: []string{},
+		},
+	}
+	if p.DNS {
+		p.RecordTypes = normalize.RecordTypes(name)
+	}
+	for _, rType := range p.RecordTypes {
+		if !providers.ProviderAllowsWildcard(name, rType) {
+			p.Limits.NoWildcards = append(p.Limits.NoWildcards, rType)
+		}
+	}
+	for _, f := range manifestFeatures {
+		note := providers.Notes[name][f.cap]
+		switch {
+		case note != nil:
+			p.Features[f.name] = note.HasFeature
+			if note.Comment != "" || note.Link != "" || note.Unimplemented {
+				p.Notes[f.name] = manifestNote{note.Comment, note.Link, note.Unimplemented}
+			}
+		case f.cap == providers.CantUseNOPURGE:
+			p.Features[f.name] = !providers.ProviderHasCabability(name, f.cap)
+		default:
+			p.Features[f.name] = providers.ProviderHasCabability(name, f.cap)
+		}
+	}
+	return p
+}
+
+func printCapabilities(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(capabilities())
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/providers"
+	_ "github.com/StackExchange/dnscontrol/providers/bind"
+	_ "github.com/StackExchange/dnscontrol/providers/octodns"
+	_ "github.com/StackExchange/dnscontrol/providers/route53"
+)
+
+func TestCapabilities(t *testing.T) {
+	var out bytes.Buffer
+	if err := printCapabilities(&out); err != nil {
+		t.Fatal(err)
+	}
+	// Tooling reads the JSON, so the test does too, without the Go types.
+	var m map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["version"] != float64(1) {
+		t.Errorf("Expected version 1, got %v", m["version"])
+	}
+	byName := map[string]map[string]interface{}{}
+	for _, p := range m["providers"].([]interface{}) {
+		p := p.(map[string]interface{})
+		var keys []string
+		for k := range p {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		want := []string{"dns", "features", "limits", "name", "record_types", "registrar", "zone_settings"}
+		if _, ok := p["notes"]; ok {
+			want = []string{"dns", "features", "limits", "name", "notes", "record_types", "registrar", "zone_settings"}
+		}
+		if !reflect.DeepEqual(keys, want) {
+			t.Errorf("%v: expected the fields %v, got %v", p["name"], want, keys)
+		}
+		if len(p["features"].(map[string]interface{})) != len(manifestFeatures) {
+			t.Errorf("%v: expected all %d features, got %v", p["name"], len(manifestFeatures), p["features"])
+		}
+		byName[p["name"].(string)] = p
+	}
+
+	bind, r53 := byName["BIND"], byName["ROUTE53"]
+	if bind == nil || r53 == nil {
+		t.Fatalf("Expected BIND and ROUTE53 in %s", out.String())
+	}
+	if bind["dns"] != true || bind["registrar"] != false || r53["dns"] != true || r53["registrar"] != true {
+		t.Errorf("Expected BIND to be a DNS provider and ROUTE53 both, got %v %v", bind, r53)
+	}
+	types := func(p map[string]interface{}) []interface{} { return p["record_types"].([]interface{}) }
+	wantBind := []interface{}{"A", "AAAA", "CAA", "CNAME", "MX", "NS", "PTR", "SOA", "SRV", "TLSA", "TXT"}
+	if !reflect.DeepEqual(types(bind), wantBind) {
+		t.Errorf("Expected BIND to manage %v, got %v", wantBind, types(bind))
+	}
+	wantR53 := []interface{}{"A", "AAAA", "CAA", "CNAME", "MX", "NS", "PTR", "R53_ALIAS", "SRV", "TXT"}
+	if !reflect.DeepEqual(types(r53), wantR53) {
+		t.Errorf("Expected ROUTE53 to manage %v, got %v", wantR53, types(r53))
+	}
+	features := func(p map[string]interface{}) map[string]interface{} { return p["features"].(map[string]interface{}) }
+	if f := features(r53); f["geo"] != true || f["alias"] != false || f["r53_alias"] != true || f["no_purge"] != true {
+		t.Errorf("Unexpected ROUTE53 features %v", f)
+	}
+	if f := features(bind); f["geo"] != false || f["no_purge"] != false || f["official_support"] != true {
+		t.Errorf("Unexpected BIND features %v", f)
+	}
+	// Both write whole files, so they can't leave records alone.
+	for _, name := range []string{"BIND", "OCTODNS"} {
+		if f := features(byName[name]); f["no_purge"] != false || !providers.ProviderWritesWholeZone(name) {
+			t.Errorf("Expected %s to write whole zones, got %v", name, f)
+		}
+	}
+	notes := r53["notes"].(map[string]interface{})
+	if alias := notes["alias"].(map[string]interface{}); alias["comment"] != "R53 does not provide a generic ALIAS functionality. Use R53_ALIAS instead." {
+		t.Errorf("Expected the note about ALIAS, got %v", notes)
+	}
+	limits := bind["limits"].(map[string]interface{})
+	if len(limits) != 3 || limits["min_ttl"] != float64(0) || len(limits["no_wildcards"].([]interface{})) != 0 {
+		t.Errorf("Expected BIND to have no limits, got %v", limits)
+	}
+}
@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/StackExchange/dnscontrol/providers/config"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args CheckCredsArgs
+	return &cli.Command{
+		Name:      "check-creds",
+		Usage:     "check that the credentials of the providers work, without changing anything",
+		ArgsUsage: "[PROVIDER...]",
+		Action: func(ctx *cli.Context) error {
+			args.Names = ctx.Args()
+			return exit(CheckCreds(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// CheckCredsArgs contains all data/flags needed to run check-creds, independently of CLI.
+type CheckCredsArgs struct {
+	GetDNSConfigArgs
+	GetCredentialsArgs
+	RotateCheck string
+	Names       []string
+}
+
+func (args *CheckCredsArgs) flags() []cli.Flag {
+	flags := args.GetDNSConfigArgs.flags()
+	flags = append(flags, args.GetCredentialsArgs.flags()...)
+	flags = append(flags, cli.StringFlag{
+		Name:        "rotate-check",
+		Destination: &args.RotateCheck,
+		Usage:       "Check the new credentials in this file, for the providers listed in it, before they replace the ones in -creds",
+	})
+	return flags
+}
+
+// checkedProvider is a provider of the configuration, as check-creds sees it.
+type checkedProvider struct {
+	name, pType string
+	registrar   bool
+	meta        json.RawMessage
+}
+
+// CheckCreds creates the providers of the configuration with their
+// credentials and, for the providers that can, reads account information
+// with them. No zone is read or changed.
+//
+// With -rotate-check, the credentials of the providers listed in that file
+// are checked instead, as well as the current ones, so that the old
+// credentials are only revoked once the new ones are known to work. Only
+// providers that can check their credentials can be rotate-checked.
+func CheckCreds(args CheckCredsArgs) error {
+	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
+	if err != nil {
+		return err
+	}
+	if err := args.loadEnv(); err != nil {
+		return err
+	}
+	current, err := config.LoadProviderConfigs(args.CredsFile)
+	if err != nil {
+		return err
+	}
+	var rotated map[string]map[string]string
+	if args.RotateCheck != "" {
+		if rotated, err = config.LoadProviderConfigs(args.RotateCheck); err != nil {
+			return err
+		}
+	}
+
+	// A provider that is both registrar and DNS provider is checked once.
+	all := map[string]checkedProvider{}
+	for _, r := range cfg.Registrars {
+		all[r.Name] = checkedProvider{r.Name, r.Type, true, nil}
+	}
+	for _, p := range cfg.DNSProviders {
+		all[p.Name] = checkedProvider{p.Name, p.Type, false, p.Metadata}
+	}
+	names := args.Names
+	if len(names) == 0 {
+		for name := range all {
+			if rotated == nil || rotated[name] != nil {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	}
+
+	failed := 0
+	for _, name := range names {
+		p, ok := all[name]
+		if !ok {
+			fmt.Printf("FAIL %s: not a provider of the configuration\n", name)
+			failed++
+			continue
+		}
+		if rotated == nil {
+			checked, err := checkCreds(p, current[name])
+			if err != nil {
+				fmt.Printf("FAIL %s (%s): %s\n", name, p.pType, err)
+				failed++
+			} else if checked {
+				fmt.Printf("OK   %s (%s)\n", name, p.pType)
+			} else {
+				fmt.Printf("OK   %s (%s): created, but %s can't check credentials any further\n", name, p.pType, p.pType)
+			}
+			continue
+		}
+		if rotated[name] == nil {
+			fmt.Printf("FAIL %s: no new credentials in %s\n", name, args.RotateCheck)
+			failed++
+			continue
+		}
+		checked, err := checkCreds(p, rotated[name])
+		if err == nil && !checked {
+			err = errors.Errorf("%s can't check credentials", p.pType)
+		}
+		if err != nil {
+			fmt.Printf("FAIL %s (%s): the new credentials don't work: %s\n", name, p.pType, err)
+			failed++
+			continue
+		}
+		if _, err := checkCreds(p, current[name]); err != nil {
+			fmt.Printf("OK   %s (%s): the new credentials work (the current ones don't: %s)\n", name, p.pType, err)
+		} else {
+			fmt.Printf("OK   %s (%s): the new credentials work. Replace the current ones, then revoke them\n", name, p.pType)
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d providers failed", failed, len(names))
+	}
+	return nil
+}
+
+// checkCreds creates the provider with creds and, if it is a
+// CredentialChecker, checks them. checked is false if it isn't one.
+func checkCreds(p checkedProvider, creds map[string]string) (checked bool, err error) {
+	var driver interface{}
+	if p.registrar {
+		driver, err = providers.CreateRegistrar(p.pType, creds)
+	} else {
+		driver, err = providers.CreateDNSProvider(p.pType, creds, p.meta)
+	}
+	if err != nil {
+		return false, err
+	}
+	checker, ok := driver.(providers.CredentialChecker)
+	if !ok {
+		return false, nil
+	}
+	return true, checker.CheckCredentials()
+}
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/pkg/errors"
+)
+
+// credsProvider accepts the tokens in validTokens.
+type credsProvider struct {
+	fakeProvider
+	token string
+}
+
+var validTokens map[string]bool
+
+func (p credsProvider) CheckCredentials() error {
+	if !validTokens[p.token] {
+		return errors.Errorf("token %s is not valid", p.token)
+	}
+	return nil
+}
+
+func init() {
+	providers.RegisterDomainServiceProviderType("FAKE-CREDS", func(m map[string]string, _ json.RawMessage) (providers.DNSServiceProvider, error) {
+		if m["token"] == "" {
+			return nil, errors.Errorf("token is required")
+		}
+		return credsProvider{fakeProvider{&fakeApplied}, m["token"]}, nil
+	})
+}
+
+const checkCredsConfig = `
+var REG = NewRegistrar("none", "NONE");
+var CREDS = NewDnsProvider("creds", "FAKE-CREDS");
+var PUSH = NewDnsProvider("push", "FAKE-PUSH");
+D("example.com", REG, DnsProvider(CREDS), DnsProvider(PUSH));
+`
+
+func TestCheckCreds(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkcreds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, content string) string {
+		f := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(f, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	jsFile := write("dnsconfig.js", checkCredsConfig)
+	credsFile := write("creds.json", `{"creds": {"token": "old"}}`)
+
+	for _, tst := range []struct {
+		desc   string
+		valid  []string
+		rotate string
+		names  []string
+		fail   bool
+	}{
+		{"current work", []string{"old"}, "", nil, false},
+		{"current fail", nil, "", nil, true},
+		{"only providers that can't check", nil, "", []string{"push", "none"}, false},
+		{"unknown provider", []string{"old"}, "", []string{"bogus"}, true},
+		{"new work", []string{"old", "new"}, `{"creds": {"token": "new"}}`, nil, false},
+		// The old ones may have been revoked already.
+		{"new work, old revoked", []string{"new"}, `{"creds": {"token": "new"}}`, nil, false},
+		{"new fail", []string{"old"}, `{"creds": {"token": "new"}}`, nil, true},
+		{"new missing", []string{"old"}, `{"creds": {}}`, []string{"creds"}, true},
+		{"new incomplete", []string{"old"}, `{"creds": {"token": ""}}`, nil, true},
+		// Without a checker, new credentials can't be verified.
+		{"can't check", []string{"old"}, `{"push": {"token": "new"}}`, nil, true},
+	} {
+		validTokens = map[string]bool{}
+		for _, v := range tst.valid {
+			validTokens[v] = true
+		}
+		args := CheckCredsArgs{Names: tst.names}
+		args.JSFile = jsFile
+		args.CredsFile = credsFile
+		if tst.rotate != "" {
+			args.RotateCheck = write("creds.new.json", tst.rotate)
+		}
+		err := CheckCreds(args)
+		if (err != nil) != tst.fail {
+			t.Errorf("%s: expected failure %v, got %v", tst.desc, tst.fail, err)
+		}
+	}
+}
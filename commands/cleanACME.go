@@ -0,0 +1,246 @@
+package commands
+
+import (
+	"strings"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args CleanACMEArgs
+	return &cli.Command{
+		Name:  "clean-acme",
+		Usage: "remove the _acme-challenge TXT records that interrupted certificate requests left behind",
+		Action: func(ctx *cli.Context) error {
+			return exit(CleanACME(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// CleanACMEArgs contains all data/flags needed to run clean-acme, independently of CLI.
+type CleanACMEArgs struct {
+	GetDNSConfigArgs
+	GetCredentialsArgs
+	ValidateArgs
+	DryRun bool
+	Wait   time.Duration
+}
+
+func (args *CleanACMEArgs) flags() []cli.Flag {
+	flags := args.GetDNSConfigArgs.flags()
+	flags = append(flags, args.GetCredentialsArgs.flags()...)
+	flags = append(flags, args.ValidateArgs.flags()...)
+	flags = append(flags, cli.BoolFlag{
+		Name:        "dry-run",
+		Destination: &args.DryRun,
+		Usage:       "Print the stale challenges without removing them",
+	})
+	flags = append(flags, cli.DurationFlag{
+		Name:        "wait",
+		Destination: &args.Wait,
+		Value:       10 * time.Minute,
+		Usage:       "How long the challenges of a zone must stay unchanged before they are stale (not with -dry-run)",
+	})
+	return flags
+}
+
+// cleanACMESleep waits between the two reads of the zones. Tests replace it.
+var cleanACMESleep = time.Sleep
+
+// challengeZone is a zone of a provider with stale challenges.
+type challengeZone struct {
+	domain   *models.DomainConfig
+	provider *models.DNSProviderInstance
+	txt      []*models.RecordConfig
+	stale    []*models.RecordConfig
+}
+
+// CleanACME implements the clean-acme subcommand.
+func CleanACME(args CleanACMEArgs) error {
+	return cleanACME(args, printer.ConsolePrinter{})
+}
+
+// cleanACME removes the _acme-challenge TXT records of the managed domains
+// that the configuration doesn't declare. A challenge is only stale if its
+// zone has the same TXT records when it is read again after args.Wait: a
+// certificate request that is still running adds or removes challenges in
+// the meantime, and the zone is then left alone. A dry run doesn't wait, and
+// reports the challenges that are not declared. Domains with NO_PURGE and
+// IGNOREd labels are left alone too, as something else manages their records,
+// and so are the zones of providers that write whole zones, which would
+// write the other records of the configuration too.
+func cleanACME(args CleanACMEArgs, out printer.CLI) error {
+	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
+	if err != nil {
+		return err
+	}
+	res := args.validate(cfg)
+	if PrintValidationErrors(res, args.Strict) {
+		return errors.Errorf("Exiting due to validation errors")
+	}
+	notifier, err := InitializeProviders(args.GetCredentialsArgs, cfg, false)
+	if err != nil {
+		return err
+	}
+
+	var zones []*challengeZone
+	for _, domain := range cfg.Domains {
+		if domain.KeepUnknown {
+			continue
+		}
+		for _, provider := range domain.DNSProviderInstances {
+			if providers.ProviderWritesWholeZone(provider.ProviderType) {
+				out.Warnf("%s at %s: the provider writes the whole zone, it can't remove challenges alone\n", domain.Name, provider.Name)
+				continue
+			}
+			txt, err := readTXT(domain, provider)
+			if err != nil {
+				return errors.Errorf("reading %s at %s: %s", domain.Name, provider.Name, err)
+			}
+			if txt == nil {
+				out.Warnf("%s at %s: the provider can't be searched for challenges\n", domain.Name, provider.Name)
+				continue
+			}
+			if stale := staleChallenges(domain, txt); len(stale) != 0 {
+				zones = append(zones, &challengeZone{domain, provider, txt, stale})
+			}
+		}
+	}
+	if len(zones) == 0 {
+		return nil
+	}
+	if args.Wait > 0 && !args.DryRun {
+		out.Debugf("Waiting %s for certificate requests in progress to finish\n", args.Wait)
+		cleanACMESleep(args.Wait)
+	}
+
+	anyErrors := false
+	for _, z := range zones {
+		var keep []*models.RecordConfig
+		for _, r := range z.txt {
+			if !isStale(r, z.stale) && !z.domain.IgnoresLabel(r.GetLabel()) {
+				keep = append(keep, r)
+			}
+		}
+		dc, err := z.domain.Copy()
+		if err != nil {
+			return err
+		}
+		// Only the TXT records are compared, but the other records are
+		// there all the same for providers that look at all of them.
+		var records []*models.RecordConfig
+		for _, r := range dc.Records {
+			if r.Type != "TXT" {
+				records = append(records, r)
+			}
+		}
+		dc.Records = append(records, keep...)
+		dc.OnlyTypes = []string{"TXT"}
+		corrections, collector, err := diffCorrections(0, z.provider.Driver, dc)
+		current := onlyTXT(collector.Existing)
+		if err != nil {
+			return errors.Errorf("reading %s at %s: %s", z.domain.Name, z.provider.Name, err)
+		}
+		out.StartDomain(z.domain.Name)
+		if !sameRecords(current, z.txt) {
+			out.Warnf("%s at %s: the TXT records changed in the last %s, a certificate request may be running. Run clean-acme again later\n", z.domain.Name, z.provider.Name, args.Wait)
+			continue
+		}
+		if printOrRunCorrections(z.domain.Name, z.provider.Name, corrections, out, !args.DryRun, false, false, notifier) {
+			anyErrors = true
+		}
+	}
+	notifier.Done()
+	if anyErrors {
+		return errors.Errorf("Completed with errors")
+	}
+	return nil
+}
+
+// readTXT returns the TXT records of the zone of domain at the provider, or
+// nil if the provider doesn't use the diff package. The corrections to
+// records are discarded.
+func readTXT(domain *models.DomainConfig, provider *models.DNSProviderInstance) ([]*models.RecordConfig, error) {
+	dc, err := domain.Copy()
+	if err != nil {
+		return nil, err
+	}
+	dc.OnlyTypes = []string{"TXT"}
+	_, collector, err := diffCorrections(0, provider.Driver, dc)
+	if err != nil {
+		return nil, err
+	}
+	if collector.Existing == nil {
+		return nil, nil
+	}
+	return onlyTXT(collector.Existing), nil
+}
+
+func onlyTXT(records []*models.RecordConfig) []*models.RecordConfig {
+	txt := []*models.RecordConfig{}
+	for _, r := range records {
+		if r.Type == "TXT" {
+			txt = append(txt, r)
+		}
+	}
+	return txt
+}
+
+// staleChallenges returns the records of txt at an _acme-challenge label that
+// domain neither declares nor IGNOREs.
+func staleChallenges(domain *models.DomainConfig, txt []*models.RecordConfig) []*models.RecordConfig {
+	declared := map[string]bool{}
+	for _, r := range domain.Records {
+		if r.Type == "TXT" {
+			declared[recordID(r)] = true
+		}
+	}
+	var stale []*models.RecordConfig
+	for _, r := range txt {
+		label := r.GetLabel()
+		if label != "_acme-challenge" && !strings.HasPrefix(label, "_acme-challenge.") {
+			continue
+		}
+		if !declared[recordID(r)] && !domain.IgnoresLabel(label) {
+			stale = append(stale, r)
+		}
+	}
+	return stale
+}
+
+func recordID(r *models.RecordConfig) string {
+	return r.GetLabelFQDN() + " " + r.GetTargetCombined()
+}
+
+func isStale(r *models.RecordConfig, stale []*models.RecordConfig) bool {
+	for _, s := range stale {
+		if s == r {
+			return true
+		}
+	}
+	return false
+}
+
+// sameRecords returns true if a and b have the same records, in any order.
+func sameRecords(a, b []*models.RecordConfig) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := map[string]int{}
+	for _, r := range a {
+		count[recordID(r)]++
+	}
+	for _, r := range b {
+		if count[recordID(r)] == 0 {
+			return false
+		}
+		count[recordID(r)]--
+	}
+	return true
+}
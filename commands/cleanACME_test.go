@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+)
+
+// txtProvider serves a zone of TXT records, by "label value", and applies the
+// corrections it is asked to make to it.
+type txtProvider struct {
+	zone map[string]bool
+}
+
+func (txtProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	return nil, nil
+}
+
+func (p txtProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	var existing []*models.RecordConfig
+	for rec := range p.zone {
+		parts := strings.SplitN(rec, " ", 2)
+		rc := &models.RecordConfig{Type: "TXT", TTL: 300}
+		rc.SetLabel(parts[0], dc.Name)
+		rc.SetTargetTXT(parts[1])
+		existing = append(existing, rc)
+	}
+	_, create, del, mod := diff.New(dc).IncrementalDiff(existing)
+	var corrections []*models.Correction
+	for _, c := range create {
+		rc := c.Desired
+		corrections = append(corrections, &models.Correction{Msg: c.String(), F: func() error {
+			p.zone[rc.GetLabel()+" "+rc.GetTargetField()] = true
+			return nil
+		}})
+	}
+	for _, c := range append(del, mod...) {
+		rc := c.Existing
+		corrections = append(corrections, &models.Correction{Msg: c.String(), F: func() error {
+			delete(p.zone, rc.GetLabel()+" "+rc.GetTargetField())
+			return nil
+		}})
+	}
+	return corrections, nil
+}
+
+var txtZone = map[string]bool{}
+
+func init() {
+	providers.RegisterDomainServiceProviderType("FAKE-TXT", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return txtProvider{txtZone}, nil
+	})
+}
+
+func TestCleanACME(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("txt", "FAKE-TXT")),
+	TXT("@", "v=spf1 -all"),
+	TXT("_acme-challenge.static", "declared"),
+	IGNORE("_acme-challenge.other")
+);`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reset := func() {
+		for k := range txtZone {
+			delete(txtZone, k)
+		}
+		for _, rec := range []string{
+			"@ v=spf1 -all",
+			"_acme-challenge stale1",
+			"_acme-challenge.www stale2",
+			"_acme-challenge.static declared",
+			"_acme-challenge.other ignored",
+			"_acme-challenges.www not-a-challenge",
+		} {
+			txtZone[rec] = true
+		}
+	}
+	zone := func() []string {
+		var recs []string
+		for rec := range txtZone {
+			recs = append(recs, rec)
+		}
+		sort.Strings(recs)
+		return recs
+	}
+	var slept time.Duration
+	cleanACMESleep = func(d time.Duration) { slept = d }
+	defer func() { cleanACMESleep = time.Sleep }()
+	args := CleanACMEArgs{Wait: time.Minute}
+	args.JSFile = jsFile
+	args.CredsFile = filepath.Join(dir, "creds.json")
+
+	// A dry run reports the stale challenges, and changes nothing.
+	reset()
+	args.DryRun = true
+	var msgs []string
+	if err := cleanACME(args, msgPrinter{msgs: &msgs}); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(msgs)
+	want := []string{
+		`DELETE TXT _acme-challenge.example.com "stale1" ttl=300`,
+		`DELETE TXT _acme-challenge.www.example.com "stale2" ttl=300`,
+	}
+	if !reflect.DeepEqual(msgs, want) {
+		t.Errorf("Expected %q, got %q", want, msgs)
+	}
+	if len(txtZone) != 6 {
+		t.Errorf("Expected a dry run to leave the zone alone, got %q", zone())
+	}
+	if slept != 0 {
+		t.Errorf("Expected a dry run not to wait, waited %s", slept)
+	}
+
+	args.DryRun = false
+	if err := cleanACME(args, msgPrinter{msgs: &msgs}); err != nil {
+		t.Fatal(err)
+	}
+	if slept != time.Minute {
+		t.Errorf("Expected to wait for -wait, waited %s", slept)
+	}
+	wantZone := []string{
+		"@ v=spf1 -all",
+		"_acme-challenge.other ignored",
+		"_acme-challenge.static declared",
+		"_acme-challenges.www not-a-challenge",
+	}
+	if !reflect.DeepEqual(zone(), wantZone) {
+		t.Errorf("Expected %q, got %q", wantZone, zone())
+	}
+
+	// A certificate request adds a challenge while clean-acme waits: the zone
+	// is left alone.
+	reset()
+	cleanACMESleep = func(time.Duration) { txtZone["_acme-challenge.mail running"] = true }
+	var warnings []string
+	msgs = nil
+	if err := cleanACME(args, warnPrinter{msgPrinter{msgs: &msgs}, &warnings}); err != nil {
+		t.Fatal(err)
+	}
+	if len(txtZone) != 7 || len(msgs) != 0 {
+		t.Errorf("Expected the zone to be left alone, got %q", zone())
+	}
+	if len(warnings) != 1 {
+		t.Errorf("Expected a warning, got %q", warnings)
+	}
+}
+
+func TestCleanACMEWholeZone(t *testing.T) {
+	dir, err := ioutil.TempDir("", "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("bind", "BIND")),
+	A("www", "1.1.1.1"),
+	MX("@", 10, "mail.example.com.")
+);`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credsFile := filepath.Join(dir, "creds.json")
+	if err := ioutil.WriteFile(credsFile, []byte(`{"bind": {"directory": "`+dir+`"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	zonefile := filepath.Join(dir, "example.com.zone")
+	zone := []byte(`$TTL 300
+@ IN SOA ns1.example.net. hostmaster.example.net. 2020010101 3600 600 604800 1440
+@ IN MX 10 mail.example.com.
+_acme-challenge IN TXT "stale"
+mail IN A 1.1.1.2
+www IN A 1.1.1.1
+`)
+	if err := ioutil.WriteFile(zonefile, zone, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cleanACMESleep = func(time.Duration) {}
+	defer func() { cleanACMESleep = time.Sleep }()
+	args := CleanACMEArgs{}
+	args.JSFile = jsFile
+	args.CredsFile = credsFile
+	var msgs, warnings []string
+	if err := cleanACME(args, warnPrinter{msgPrinter{msgs: &msgs}, &warnings}); err != nil {
+		t.Fatal(err)
+	}
+	// BIND would write the zone of the configuration, without mail.
+	if got, err := ioutil.ReadFile(zonefile); err != nil || string(got) != string(zone) {
+		t.Errorf("Expected the zonefile to be left alone, got %v:\n%s", err, got)
+	}
+	if len(msgs) != 0 || len(warnings) != 1 || !strings.Contains(warnings[0], "writes the whole zone") {
+		t.Errorf("Expected a warning and no changes, got %q and %q", warnings, msgs)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/normalize"
+	"github.com/StackExchange/dnscontrol/providers/config"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli"
 )
@@ -148,9 +150,10 @@ func preloadProviders(cfg *models.DNSConfig, err error) (*models.DNSConfig, erro
 
 // ExecuteDSLArgs are used anytime we need to read and execute dnscontrol DSL
 type ExecuteDSLArgs struct {
-	JSFile   string
-	JSONFile string
-	DevMode  bool
+	JSFile    string
+	JSONFile  string
+	DevMode   bool
+	Inventory string
 }
 
 func (args *ExecuteDSLArgs) flags() []cli.Flag {
@@ -173,6 +176,11 @@ func (args *ExecuteDSLArgs) flags() []cli.Flag {
 			Destination: &args.DevMode,
 			Usage:       "Use helpers.js from disk instead of embedded copy",
 		},
+		cli.StringFlag{
+			Name:        "inventory",
+			Destination: &args.Inventory,
+			Usage:       "JSON or YAML file of host addresses, for HOST()",
+		},
 	}
 }
 
@@ -200,6 +208,8 @@ func (args *PrintJSONArgs) flags() []cli.Flag {
 // GetCredentialsArgs encapsulates the flags/args for sub-commands that use the creds.json file.
 type GetCredentialsArgs struct {
 	CredsFile string
+	EnvFile   string
+	CredsKey  string
 }
 
 func (args *GetCredentialsArgs) flags() []cli.Flag {
@@ -210,7 +220,108 @@ func (args *GetCredentialsArgs) flags() []cli.Flag {
 			Usage:       "Provider credentials JSON file",
 			Value:       "creds.json",
 		},
+		cli.StringFlag{
+			Name:        "env-file",
+			Destination: &args.EnvFile,
+			Usage:       "File of NAME=VALUE lines to set environment variables from, for $VAR values in the credentials file. Variables that are already set are not changed",
+			Value:       ".env",
+		},
+		cli.StringFlag{
+			Name:        "creds-key",
+			Destination: &args.CredsKey,
+			Usage:       "age identity file to decrypt a credentials file encrypted with sops or age. The default is $SOPS_AGE_KEY_FILE, or the key in $SOPS_AGE_KEY",
+		},
+	}
+}
+
+// loadEnv sets the environment up for reading the credentials file: the
+// variables of -env-file, and the key of -creds-key, which sops and age
+// find in $SOPS_AGE_KEY_FILE.
+func (args GetCredentialsArgs) loadEnv() error {
+	if err := config.LoadEnvFile(args.EnvFile); err != nil {
+		return err
+	}
+	if args.CredsKey != "" {
+		return os.Setenv("SOPS_AGE_KEY_FILE", args.CredsKey)
+	}
+	return nil
+}
+
+// withoutFlag returns flags without the flag called name.
+func withoutFlag(flags []cli.Flag, name string) []cli.Flag {
+	var kept []cli.Flag
+	for _, f := range flags {
+		if f.GetName() != name {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// ValidateArgs encapsulates the flags/args for sub-commands that validate the configuration.
+type ValidateArgs struct {
+	Strict            bool
+	CheckCNAMETargets bool
+	LintEmail         bool
+	CheckPTRForward   bool
+	CheckDMARCReports bool
+	CheckDKIMRotation bool
+}
+
+func (args *ValidateArgs) flags() []cli.Flag {
+	return []cli.Flag{
+		cli.BoolFlag{
+			Name:        "strict",
+			Destination: &args.Strict,
+			Usage:       "Treat validation warnings as errors",
+		},
+		cli.BoolFlag{
+			Name:        "check-cname-targets",
+			Destination: &args.CheckCNAMETargets,
+			Usage:       "Warn about CNAMEs pointing to names that don't exist. Targets outside the configuration are looked up in the DNS",
+		},
+		cli.BoolFlag{
+			Name:        "lint-email",
+			Destination: &args.LintEmail,
+			Usage:       "Warn about syntax errors in SPF and DMARC records",
+		},
+		cli.BoolFlag{
+			Name:        "check-ptr-forward",
+			Destination: &args.CheckPTRForward,
+			Usage:       "Warn about PTRs pointing to names without an A or AAAA record of the address. Names outside the configuration are looked up in the DNS",
+		},
+		cli.BoolFlag{
+			Name:        "check-dmarc-reports",
+			Destination: &args.CheckDMARCReports,
+			Usage:       "Warn about DMARC reports sent to domains that don't allow it. Domains outside the configuration are looked up in the DNS",
+		},
+		cli.BoolFlag{
+			Name:        "check-dkim-rotation",
+			Destination: &args.CheckDKIMRotation,
+			Usage:       "Warn about DKIM keys without a second selector to rotate to",
+		},
+	}
+}
+
+// validate normalizes and validates cfg, with the optional checks of args.
+func (args *ValidateArgs) validate(cfg *models.DNSConfig) normalize.Result {
+	res := normalize.NormalizeAndValidateConfig(cfg)
+	if args.CheckCNAMETargets && len(res.Errors) == 0 {
+		res.Add(normalize.CheckCNAMETargets(cfg)...)
+	}
+	if args.LintEmail && len(res.Errors) == 0 {
+		res.Add(normalize.LintEmail(cfg)...)
+	}
+	if args.CheckPTRForward && len(res.Errors) == 0 {
+		res.Add(normalize.CheckPTRForward(cfg)...)
+	}
+	if args.CheckDMARCReports && len(res.Errors) == 0 {
+		res.Add(normalize.CheckDMARCReports(cfg)...)
+	}
+	if args.CheckDKIMRotation && len(res.Errors) == 0 {
+		res.Add(normalize.CheckDKIMRotation(cfg)...)
 	}
+	return res
 }
 
 // FilterArgs encapsulates the flags/args for sub-commands that can filter by provider or domain.
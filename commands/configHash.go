@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args ConfigHashArgs
+	return &cli.Command{
+		Name:  "config-hash",
+		Usage: "print a hash of the normalized configuration, that changes only when the configuration does",
+		Action: func(ctx *cli.Context) error {
+			return exit(ConfigHash(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// ConfigHashArgs contains all data/flags needed to run config-hash, independently of CLI.
+type ConfigHashArgs struct {
+	GetDNSConfigArgs
+	ValidateArgs
+	PerDomain bool
+}
+
+func (args *ConfigHashArgs) flags() []cli.Flag {
+	flags := args.GetDNSConfigArgs.flags()
+	flags = append(flags, args.ValidateArgs.flags()...)
+	flags = append(flags, cli.BoolFlag{
+		Name:        "per-domain",
+		Destination: &args.PerDomain,
+		Usage:       "Print the hash of each domain, followed by its name",
+	})
+	return flags
+}
+
+// ConfigHash implements the config-hash subcommand. The configuration is
+// normalized first, so that it hashes the same however it is written: in
+// another order, with default TTLs spelled out or names fully qualified. No
+// provider is accessed.
+func ConfigHash(args ConfigHashArgs) error {
+	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
+	if err != nil {
+		return err
+	}
+	res := args.validate(cfg)
+	if PrintValidationErrors(res, args.Strict) {
+		return errors.Errorf("Exiting due to validation errors")
+	}
+	return printConfigHash(os.Stdout, cfg, args.PerDomain)
+}
+
+func printConfigHash(w io.Writer, cfg *models.DNSConfig, perDomain bool) error {
+	hashes := domainHashes(cfg)
+	var names []string
+	for name := range hashes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sum := sha256.New()
+	for _, name := range names {
+		if perDomain {
+			if _, err := fmt.Fprintf(w, "%s  %s\n", hashes[name], name); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(sum, "%s %s\n", hashes[name], name)
+	}
+	if perDomain {
+		return nil
+	}
+	_, err := fmt.Fprintln(w, hex.EncodeToString(sum.Sum(nil)))
+	return err
+}
+
+// domainHashes returns the SHA-256 of each domain of cfg, in hex, by its name
+// in lowercase.
+func domainHashes(cfg *models.DNSConfig) map[string]string {
+	meta := map[string]string{}
+	for _, r := range cfg.Registrars {
+		meta["registrar "+r.Name] = r.Type + " " + canonicalJSON(r.Metadata)
+	}
+	for _, p := range cfg.DNSProviders {
+		meta["dns "+p.Name] = p.Type + " " + canonicalJSON(p.Metadata)
+	}
+	hashes := map[string]string{}
+	for _, domain := range cfg.Domains {
+		sum := sha256.Sum256([]byte(strings.Join(domainLines(domain, meta), "\n")))
+		hashes[strings.ToLower(domain.Name)] = hex.EncodeToString(sum[:])
+	}
+	return hashes
+}
+
+// domainLines describes everything about domain that a push acts on, one
+// fact per line, sorted. providers has the type and metadata of each
+// provider by "registrar NAME" or "dns NAME".
+func domainLines(domain *models.DomainConfig, providers map[string]string) []string {
+	lines := []string{
+		"domain " + strings.ToLower(domain.Name),
+		fmt.Sprintf("registrar %s %s", domain.RegistrarName, providers["registrar "+domain.RegistrarName]),
+		fmt.Sprintf("keepunknown %v", domain.KeepUnknown),
+	}
+	for name, n := range domain.DNSProviderNames {
+		lines = append(lines, fmt.Sprintf("dns %s %d %s", name, n, providers["dns "+name]))
+	}
+	for k, v := range domain.Metadata {
+		lines = append(lines, fmt.Sprintf("meta %q=%q", k, v))
+	}
+	for k, v := range domain.ProviderMeta {
+		lines = append(lines, fmt.Sprintf("provider_meta %q=%q", k, v))
+	}
+	for _, ns := range domain.Nameservers {
+		lines = append(lines, "nameserver "+strings.ToLower(ns.Name))
+	}
+	for _, l := range domain.IgnoredLabels {
+		lines = append(lines, fmt.Sprintf("ignore %q", l))
+	}
+	for _, r := range domain.Records {
+		line := fmt.Sprintf("record %s %s %d %q", r.GetLabelFQDN(), r.Type, r.TTL, r.GetTargetCombined())
+		var meta []string
+		for k, v := range r.Metadata {
+			meta = append(meta, fmt.Sprintf(" %q=%q", k, v))
+		}
+		for k, v := range r.R53Alias {
+			meta = append(meta, fmt.Sprintf(" r53_alias %q=%q", k, v))
+		}
+		sort.Strings(meta)
+		lines = append(lines, line+strings.Join(meta, ""))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// canonicalJSON returns raw with its keys sorted and no spaces, or raw
+// itself if it is not JSON.
+func canonicalJSON(raw json.RawMessage) string {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return string(raw)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return string(raw)
+	}
+	return string(b)
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const hashConfig = `
+var REG = NewRegistrar("none", "NONE");
+var BIND = NewDnsProvider("bind", "BIND", {default_ns: ["ns1.example.com."], default_soa: {master: "ns1.example.com.", mbox: "hostmaster.example.com."}});
+D("example.com", REG, DnsProvider(BIND),
+	A("www", "192.0.2.1"),
+	A("www", "192.0.2.2"),
+	CNAME("ftp", "www"),
+	MX("@", 10, "mail.example.net.")
+);
+D("example.net", REG, DnsProvider(BIND),
+	A("@", "192.0.2.3")
+);
+`
+
+// The same, written another way.
+const hashConfigReordered = `
+var REG = NewRegistrar("none", "NONE");
+var BIND = NewDnsProvider("bind", "BIND", {default_soa: {mbox: "hostmaster.example.com.", master: "ns1.example.com."}, default_ns: ["ns1.example.com."]});
+D("example.net", REG, DnsProvider(BIND),
+	A("@", "192.0.2.3", TTL(300))
+);
+D("Example.com", REG, DnsProvider(BIND),
+	MX("@", 10, "mail.example.net."),
+	CNAME("ftp", "www.example.com."),
+	A("www", ["192.0.2.2", "192.0.2.1"])
+);
+`
+
+func TestConfigHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "confighash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	hash := func(js string, perDomain bool) string {
+		jsFile := filepath.Join(dir, "dnsconfig.js")
+		if err := ioutil.WriteFile(jsFile, []byte(js), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := GetDNSConfig(GetDNSConfigArgs{JSFile: jsFile})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res := (&ValidateArgs{}).validate(cfg); len(res.Errors) != 0 {
+			t.Fatal(res.Errors)
+		}
+		var out bytes.Buffer
+		if err := printConfigHash(&out, cfg, perDomain); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	h := hash(hashConfig, false)
+	if len(strings.TrimSpace(h)) != 64 {
+		t.Fatalf("Expected a SHA-256, got %q", h)
+	}
+	if again := hash(hashConfig, false); again != h {
+		t.Errorf("Expected the same hash twice, got %s and %s", h, again)
+	}
+	if other := hash(hashConfigReordered, false); other != h {
+		t.Errorf("Expected the same hash for the same configuration written another way, got %s and %s", h, other)
+	}
+
+	perDomain := strings.Split(strings.TrimSpace(hash(hashConfig, true)), "\n")
+	if len(perDomain) != 2 || !strings.HasSuffix(perDomain[0], "  example.com") || !strings.HasSuffix(perDomain[1], "  example.net") {
+		t.Fatalf("Expected a hash for each domain, got %q", perDomain)
+	}
+
+	// A single change changes the hash, and only that of its domain.
+	for desc, js := range map[string]string{
+		"address": strings.Replace(hashConfig, "192.0.2.2", "192.0.2.4", 1),
+		"TTL":     strings.Replace(hashConfig, `A("www", "192.0.2.1")`, `A("www", "192.0.2.1", TTL(600))`, 1),
+		"meta":    strings.Replace(hashConfig, `CNAME("ftp", "www")`, `CNAME("ftp", "www", {cloudflare_proxy: "on"})`, 1),
+		"purge":   strings.Replace(hashConfig, `D("example.com", REG,`, `D("example.com", REG, NO_PURGE,`, 1),
+	} {
+		if other := hash(js, false); other == h {
+			t.Errorf("%s: expected another hash", desc)
+		}
+		changed := strings.Split(strings.TrimSpace(hash(js, true)), "\n")
+		if changed[0] == perDomain[0] || changed[1] != perDomain[1] {
+			t.Errorf("%s: expected only the hash of example.com to change, got %q and %q", desc, perDomain, changed)
+		}
+	}
+	// So does the configuration of a provider.
+	if other := hash(strings.Replace(hashConfig, "hostmaster.example.com.", "dns.example.com.", 1), true); other == strings.Join(perDomain, "\n")+"\n" {
+		t.Errorf("Expected the default_soa of BIND to change the hashes")
+	}
+}
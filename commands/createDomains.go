@@ -37,7 +37,7 @@ func CreateDomains(args CreateDomainsArgs) error {
 	if err != nil {
 		return err
 	}
-	_, err = InitializeProviders(args.CredsFile, cfg, false)
+	_, err = InitializeProviders(args.GetCredentialsArgs, cfg, false)
 	if err != nil {
 		return err
 	}
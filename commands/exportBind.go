@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/pkg/nameservers"
+	"github.com/StackExchange/dnscontrol/providers/bind"
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args ExportBindArgs
+	return &cli.Command{
+		Name:      "export-bind",
+		Usage:     "write the records of every domain as a BIND zonefile, whatever its provider",
+		ArgsUsage: "DIR",
+		Action: func(ctx *cli.Context) error {
+			if ctx.NArg() != 1 {
+				return cli.NewExitError("export-bind needs exactly one DIR", 1)
+			}
+			args.Dir = ctx.Args().First()
+			return exit(ExportBind(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// ExportBindArgs contains all data/flags needed to run export-bind, independently of CLI.
+type ExportBindArgs struct {
+	GetDNSConfigArgs
+	ValidateArgs
+	Dir string
+}
+
+func (args *ExportBindArgs) flags() []cli.Flag {
+	return append(args.GetDNSConfigArgs.flags(), args.ValidateArgs.flags()...)
+}
+
+// exportNow is replaced in tests.
+var exportNow = time.Now
+
+// ExportBind writes the desired records of each domain to DIR/DOMAIN.zone.
+// Only the configuration is used, no provider is accessed: the NS records
+// are the ones declared with NAMESERVER(), which every domain must have, and
+// an SOA record is made up if the domain has no SOA(). Records of types that only exist in dnscontrol or at
+// one provider, like ALIAS or R53_ALIAS, are left out.
+func ExportBind(args ExportBindArgs) error {
+	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
+	if err != nil {
+		return err
+	}
+	res := args.validate(cfg)
+	if PrintValidationErrors(res, args.Strict) {
+		return errors.Errorf("Exiting due to validation errors")
+	}
+	if err := os.MkdirAll(args.Dir, 0755); err != nil {
+		return err
+	}
+	// yyyymmdd01, as the BIND provider numbers new zones.
+	today := exportNow().UTC()
+	serial := uint32(today.Year()*1000000 + int(today.Month())*10000 + today.Day()*100 + 1)
+	for _, domain := range cfg.Domains {
+		dc, err := domain.Copy()
+		if err != nil {
+			return err
+		}
+		if err := dc.Punycode(); err != nil {
+			return err
+		}
+		if !dc.HasRecordTypeName("NS", "@") {
+			nameservers.AddNSRecords(dc)
+		}
+		if !dc.HasRecordTypeName("NS", "@") {
+			return errors.Errorf("%s has no nameservers, BIND won't load its zone: declare them with NAMESERVER()", dc.Name)
+		}
+		if !dc.HasRecordTypeName("SOA", "@") {
+			dc.Records = append(dc.Records, bind.ExportSOA(dc, serial))
+		} else {
+			bind.SetSOASerial(dc, serial)
+		}
+		var rrs []dns.RR
+		for _, r := range dc.Records {
+			if _, ok := dns.StringToType[r.Type]; !ok {
+				fmt.Printf("WARNING: %s %s can't be written to a zonefile, leaving it out\n", r.Type, r.GetLabelFQDN())
+				continue
+			}
+			rrs = append(rrs, r.ToRR())
+		}
+		filename := filepath.Join(args.Dir, strings.Replace(strings.ToLower(dc.Name), "/", "_", -1)+".zone")
+		f, err := os.Create(filename)
+		if err != nil {
+			return err
+		}
+		err = bind.WriteZoneFile(f, rrs, dc.Name)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return errors.Wrapf(err, "writing %s", filename)
+		}
+		fmt.Printf("Wrote %s (%d records)\n", filename, len(rrs))
+	}
+	return nil
+}
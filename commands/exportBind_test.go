@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportBind(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export-bind")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	exportNow = func() time.Time { return time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { exportNow = time.Now }()
+
+	args := ExportBindArgs{Dir: dir}
+	args.JSFile = filepath.Join("testdata", "export-bind", "dnsconfig.js")
+	if err := ExportBind(args); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"example.com.zone", "example.org.zone"} {
+		expected, err := ioutil.ReadFile(filepath.Join("testdata", "export-bind", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		found, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(found) != string(expected) {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", name, expected, found)
+		}
+	}
+}
+
+func TestExportBindNoNameservers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exportbind")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	args := ExportBindArgs{Dir: dir}
+	args.JSONFile = writeIR(t, dir, "example.com")
+	err = ExportBind(args)
+	if err == nil || !strings.Contains(err.Error(), "example.com has no nameservers") {
+		t.Errorf("Expected an error about the nameservers, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "example.com.zone")); !os.IsNotExist(err) {
+		t.Errorf("Expected no zonefile, got %v", err)
+	}
+}
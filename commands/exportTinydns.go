@@ -0,0 +1,205 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/nameservers"
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args ExportTinydnsArgs
+	return &cli.Command{
+		Name:      "export-tinydns",
+		Usage:     "write the records of every domain to a tinydns data file, whatever its provider",
+		ArgsUsage: "FILE",
+		Action: func(ctx *cli.Context) error {
+			if ctx.NArg() != 1 {
+				return cli.NewExitError("export-tinydns needs exactly one FILE", 1)
+			}
+			args.File = ctx.Args().First()
+			return exit(ExportTinydns(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// ExportTinydnsArgs contains all data/flags needed to run export-tinydns, independently of CLI.
+type ExportTinydnsArgs struct {
+	GetDNSConfigArgs
+	ValidateArgs
+	File string
+}
+
+func (args *ExportTinydnsArgs) flags() []cli.Flag {
+	return append(args.GetDNSConfigArgs.flags(), args.ValidateArgs.flags()...)
+}
+
+// ExportTinydns writes the desired records of all the domains to FILE, in
+// the data format of tinydns-data. As with export-bind, only the
+// configuration is used: the NS records are the ones declared with
+// NAMESERVER(). Without SOA() the SOA is left to tinydns-data, which makes
+// one up with the first nameserver. Records of types that only exist in
+// dnscontrol or at one provider, like ALIAS or R53_ALIAS, are left out.
+func ExportTinydns(args ExportTinydnsArgs) error {
+	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
+	if err != nil {
+		return err
+	}
+	res := args.validate(cfg)
+	if PrintValidationErrors(res, args.Strict) {
+		return errors.Errorf("Exiting due to validation errors")
+	}
+	if err := os.MkdirAll(filepath.Dir(args.File), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(args.File)
+	if err != nil {
+		return err
+	}
+	n, err := writeTinydns(f, cfg.Domains)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return errors.Wrapf(err, "writing %s", args.File)
+	}
+	fmt.Printf("Wrote %s (%d records)\n", args.File, n)
+	return nil
+}
+
+// writeTinydns writes the records of the domains to w, and returns how many
+// it wrote.
+func writeTinydns(w io.Writer, domains []*models.DomainConfig) (int, error) {
+	bw := bufio.NewWriter(w)
+	n := 0
+	for _, domain := range domains {
+		dc, err := domain.Copy()
+		if err != nil {
+			return n, err
+		}
+		if err := dc.Punycode(); err != nil {
+			return n, err
+		}
+		if !dc.HasRecordTypeName("NS", "@") {
+			nameservers.AddNSRecords(dc)
+		}
+		fmt.Fprintf(bw, "# %s\n", dc.Name)
+		// The SOA and the apex NS records come first, as in a zonefile.
+		var first, rest []*models.RecordConfig
+		for _, r := range dc.Records {
+			if r.GetLabel() == "@" && (r.Type == "SOA" || r.Type == "NS") {
+				first = append(first, r)
+			} else {
+				rest = append(rest, r)
+			}
+		}
+		soa := dc.HasRecordTypeName("SOA", "@")
+		for _, r := range append(first, rest...) {
+			if _, ok := dns.StringToType[r.Type]; !ok {
+				fmt.Printf("WARNING: %s %s can't be written to a tinydns data file, leaving it out\n", r.Type, r.GetLabelFQDN())
+				continue
+			}
+			// The first apex NS record makes the SOA, if there is no SOA().
+			makeSOA := !soa && r.Type == "NS" && r.GetLabel() == "@"
+			soa = soa || makeSOA
+			line, err := tinydnsLine(r, makeSOA)
+			if err != nil {
+				return n, errors.Wrapf(err, "%s %s", r.Type, r.GetLabelFQDN())
+			}
+			fmt.Fprintln(bw, line)
+			n++
+		}
+		if !soa {
+			fmt.Printf("WARNING: %s has no nameservers and no SOA(), tinydns won't answer for it\n", dc.Name)
+		}
+	}
+	return n, bw.Flush()
+}
+
+// tinydnsLine returns the line of tinydns-data for r. The types tinydns-data
+// has a line for use it, the others (AAAA among them, which only patched
+// versions have a line for) are written as generic records of their wire
+// format. makeSOA writes an NS record as the "." line, which also makes the
+// SOA of the zone.
+func tinydnsLine(r *models.RecordConfig, makeSOA bool) (string, error) {
+	rr := r.ToRR()
+	h := rr.Header()
+	name := tinydnsName(h.Name)
+	ttl := strconv.FormatUint(uint64(h.Ttl), 10)
+	switch v := rr.(type) {
+	case *dns.SOA:
+		serial := ""
+		if v.Serial != 0 {
+			serial = strconv.FormatUint(uint64(v.Serial), 10)
+		}
+		return tinydnsFields("Z"+name, tinydnsName(v.Ns), tinydnsName(v.Mbox), serial,
+			fmt.Sprint(v.Refresh), fmt.Sprint(v.Retry), fmt.Sprint(v.Expire), fmt.Sprint(v.Minttl), ttl), nil
+	case *dns.NS:
+		if makeSOA {
+			return tinydnsFields("."+name, "", tinydnsName(v.Ns), ttl), nil
+		}
+		return tinydnsFields("&"+name, "", tinydnsName(v.Ns), ttl), nil
+	case *dns.A:
+		return tinydnsFields("+"+name, v.A.String(), ttl), nil
+	case *dns.CNAME:
+		return tinydnsFields("C"+name, tinydnsName(v.Target), ttl), nil
+	case *dns.MX:
+		return tinydnsFields("@"+name, "", tinydnsName(v.Mx), fmt.Sprint(v.Preference), ttl), nil
+	case *dns.PTR:
+		return tinydnsFields("^"+name, tinydnsName(v.Ptr), ttl), nil
+	case *dns.TXT:
+		// v.Txt is escaped as in a zonefile, r.TxtStrings isn't.
+		if len(r.TxtStrings) == 1 {
+			return tinydnsFields("'"+name, tinydnsEscape(r.TxtStrings[0]), ttl), nil
+		}
+	}
+	// Packed with "." as the name, the header is 11 bytes.
+	cp := dns.Copy(rr)
+	cp.Header().Name = "."
+	buf := make([]byte, dns.Len(cp)+11)
+	off, err := dns.PackRR(cp, buf, 0, nil, false)
+	if err != nil {
+		return "", err
+	}
+	var rdata bytes.Buffer
+	for _, c := range buf[11:off] {
+		fmt.Fprintf(&rdata, "\\%03o", c)
+	}
+	return tinydnsFields(":"+name, fmt.Sprint(h.Rrtype), rdata.String(), ttl), nil
+}
+
+func tinydnsFields(fields ...string) string {
+	return strings.Join(fields, ":")
+}
+
+// tinydnsName returns a domain name as tinydns-data takes it, without the
+// final dot.
+func tinydnsName(fqdn string) string {
+	return tinydnsEscape(strings.TrimSuffix(fqdn, "."))
+}
+
+// tinydnsEscape escapes the bytes of s that tinydns-data doesn't take as they
+// are: ":", "\" and anything that isn't printable ASCII, as \ and their 3
+// octal digits.
+func tinydnsEscape(s string) string {
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < ' ' || c > '~' || c == ':' || c == '\\' {
+			fmt.Fprintf(&b, "\\%03o", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
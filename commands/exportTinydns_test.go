@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+func TestExportTinydns(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tinydns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	args := ExportTinydnsArgs{File: filepath.Join(dir, "tinydns", "data")}
+	args.JSFile = filepath.Join("testdata", "export-tinydns", "dnsconfig.js")
+	if err := ExportTinydns(args); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := ioutil.ReadFile(filepath.Join("testdata", "export-tinydns", "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, err := ioutil.ReadFile(args.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(found) != string(expected) {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, found)
+	}
+}
+
+func TestExportTinydnsUnknownType(t *testing.T) {
+	rec := func(rType, target string) *models.RecordConfig {
+		r := &models.RecordConfig{Type: rType, TTL: 300, Metadata: map[string]string{}}
+		r.SetLabel("www", "example.com")
+		r.SetTarget(target)
+		return r
+	}
+	dc := &models.DomainConfig{Name: "example.com", Records: []*models.RecordConfig{
+		rec("URL", "https://example.net/"),
+		rec("A", "1.2.3.4"),
+	}}
+	var buf bytes.Buffer
+	n, err := writeTinydns(&buf, []*models.DomainConfig{dc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# example.com\n+www.example.com:1.2.3.4:300\n"; n != 1 || buf.String() != want {
+		t.Errorf("Expected the URL record to be left out, got %d records:\n%s", n, buf.String())
+	}
+}
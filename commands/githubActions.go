@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+)
+
+// actionsChange is a change for the job summary of preview -github-actions.
+type actionsChange struct {
+	domain, provider, change string
+}
+
+// actionsPrinter implements preview -github-actions. It prints as usual, and
+// also prints each correction and warning as a workflow command, which the
+// GitHub Actions UI shows as an annotation. Corrections that delete records
+// are warnings, the others notices.
+type actionsPrinter struct {
+	printer.CLI
+	w                io.Writer
+	domain, provider string
+	changes          []actionsChange
+	index            changeIndex
+}
+
+func newActionsPrinter(out printer.CLI, w io.Writer) *actionsPrinter {
+	return &actionsPrinter{CLI: out, w: w}
+}
+
+// StartDomain implements printer.CLI.
+func (p *actionsPrinter) StartDomain(domain string) {
+	p.domain = domain
+	p.CLI.StartDomain(domain)
+}
+
+// StartDNSProvider implements printer.CLI.
+func (p *actionsPrinter) StartDNSProvider(name string, skip bool) {
+	p.provider, p.index = name, nil
+	p.CLI.StartDNSProvider(name, skip)
+}
+
+// StartRegistrar implements printer.CLI.
+func (p *actionsPrinter) StartRegistrar(name string, skip bool) {
+	p.provider, p.index = name, nil
+	p.CLI.StartRegistrar(name, skip)
+}
+
+// PrintChanges implements changesPrinter. The changes tell the corrections
+// that delete records.
+func (p *actionsPrinter) PrintChanges(changes []diff.Correlation, deletesOnly bool) {
+	p.index = newChangeIndex(changes)
+}
+
+// PrintCorrection implements printer.CLI.
+func (p *actionsPrinter) PrintCorrection(n int, c *models.Correction) {
+	p.CLI.PrintCorrection(n, c)
+	level := "notice"
+	for _, k := range p.index.kinds(c) {
+		if k == "DELETE" {
+			level = "warning"
+		}
+	}
+	msg := strings.TrimSpace(c.Msg)
+	fmt.Fprintf(p.w, "::%s title=%s::%s\n", level, escapeProperty(p.domain+" at "+p.provider), escapeData(msg))
+	for _, line := range strings.Split(msg, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			p.changes = append(p.changes, actionsChange{p.domain, p.provider, line})
+		}
+	}
+}
+
+// Warnf implements printer.Printer.
+func (p *actionsPrinter) Warnf(format string, args ...interface{}) {
+	p.CLI.Warnf(format, args...)
+	fmt.Fprintf(p.w, "::warning::%s\n", escapeData(strings.TrimSpace(fmt.Sprintf(format, args...))))
+}
+
+// writeSummary appends a table of the changes, in markdown, to the job
+// summary file, as named by $GITHUB_STEP_SUMMARY. Nothing is written if the
+// name is empty.
+func (p *actionsPrinter) writeSummary(filename string) error {
+	if filename == "" {
+		return nil
+	}
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, p.summary()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (p *actionsPrinter) summary() string {
+	var b bytes.Buffer
+	b.WriteString("### DNS changes\n\n")
+	if len(p.changes) == 0 {
+		b.WriteString("No changes.\n")
+		return b.String()
+	}
+	b.WriteString("| Domain | Provider | Change |\n| --- | --- | --- |\n")
+	for _, c := range p.changes {
+		fmt.Fprintf(&b, "| %s | %s | `%s` |\n", escapeCell(c.domain), escapeCell(c.provider), escapeCell(c.change))
+	}
+	return b.String()
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property, such as the title, of a workflow command.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// escapeCell escapes a cell of a markdown table. Backquotes would end the
+// code span the change is in, and are replaced by quotes.
+func escapeCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "`", "'").Replace(s)
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+func TestActionsPrinter(t *testing.T) {
+	var msgs []string
+	var buf bytes.Buffer
+	p := newActionsPrinter(msgPrinter{msgs: &msgs}, &buf)
+	p.StartDomain("example.com")
+	p.StartDNSProvider("bind", false)
+	p.PrintChanges(testChanges(t, []string{`@ TXT 100%|ok`}, []string{"www A 1.1.1.1"}), false)
+	p.PrintCorrection(0, &models.Correction{Msg: "CREATE A www.example.com 1.1.1.1 ttl=300"})
+	p.PrintCorrection(1, &models.Correction{Msg: "GENERATE_ZONEFILE: example.com\nDELETE TXT example.com \"100%|ok\" ttl=300\n"})
+	p.Warnf("%s: is slow\n", "bind")
+
+	want := "::notice title=example.com at bind::CREATE A www.example.com 1.1.1.1 ttl=300\n" +
+		"::warning title=example.com at bind::GENERATE_ZONEFILE: example.com%0ADELETE TXT example.com \"100%25|ok\" ttl=300\n" +
+		"::warning::bind: is slow\n"
+	if buf.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+	if len(msgs) != 2 {
+		t.Errorf("Expected the corrections to be printed as usual too, got %q", msgs)
+	}
+	if got := escapeProperty("a:b,c%"); got != "a%3Ab%2Cc%25" {
+		t.Errorf("Expected the property to be escaped, got %q", got)
+	}
+
+	dir, err := ioutil.TempDir("", "actions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "summary.md")
+	if err := ioutil.WriteFile(filename, []byte("Earlier step\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.writeSummary(filename); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSummary := "Earlier step\n### DNS changes\n\n" +
+		"| Domain | Provider | Change |\n| --- | --- | --- |\n" +
+		"| example.com | bind | `CREATE A www.example.com 1.1.1.1 ttl=300` |\n" +
+		"| example.com | bind | `GENERATE_ZONEFILE: example.com` |\n" +
+		"| example.com | bind | `DELETE TXT example.com \"100%\\|ok\" ttl=300` |\n"
+	if string(b) != wantSummary {
+		t.Errorf("Expected:\n%s\ngot:\n%s", wantSummary, b)
+	}
+
+	empty := newActionsPrinter(msgPrinter{msgs: &msgs}, &buf)
+	if s := empty.summary(); s != "### DNS changes\n\nNo changes.\n" {
+		t.Errorf("Expected no changes, got %q", s)
+	}
+}
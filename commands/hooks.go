@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/notifications"
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/pkg/errors"
+)
+
+// defaultHookTimeout is how long a -pre-hook or -post-hook may run before it
+// is killed, unless -hook-timeout says otherwise.
+const defaultHookTimeout = time.Minute
+
+// hookEnv is what a hook is told about the changes, in DNSCONTROL_*
+// environment variables.
+type hookEnv struct {
+	hook        string // "pre" or "post"
+	domain      string
+	provider    string
+	corrections int
+	result      string // "ok" or "failed", for post hooks only
+}
+
+func (e hookEnv) environ() []string {
+	env := append(os.Environ(),
+		"DNSCONTROL_HOOK="+e.hook,
+		"DNSCONTROL_DOMAIN="+e.domain,
+		"DNSCONTROL_PROVIDER="+e.provider,
+		"DNSCONTROL_CORRECTIONS="+strconv.Itoa(e.corrections),
+	)
+	if e.result != "" {
+		env = append(env, "DNSCONTROL_RESULT="+e.result)
+	}
+	return env
+}
+
+// runHook runs a hook command with sh -c. Its output goes to ours. It fails
+// if the command exits with an error or runs longer than timeout.
+func runHook(command string, timeout time.Duration, env hookEnv) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = env.environ()
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Errorf("-%s-hook timed out after %s", env.hook, timeout)
+	}
+	if err != nil {
+		return errors.Errorf("-%s-hook: %s", env.hook, err)
+	}
+	return nil
+}
+
+// applyCorrections prints or runs the corrections of a domain at a provider,
+// like printOrRunCorrections. When pushing corrections, -pre-hook runs
+// first and the corrections are not made if it fails; -post-hook runs after
+// them and is told whether they all worked. It returns true if anything
+// failed.
+func (args *PushArgs) applyCorrections(domain, provider string, corrections []*models.Correction, out printer.CLI, push bool, notifier notifications.Notifier) bool {
+	hooks := push && len(corrections) > 0
+	timeout := args.HookTimeout
+	if timeout <= 0 {
+		timeout = defaultHookTimeout
+	}
+	env := hookEnv{hook: "pre", domain: domain, provider: provider, corrections: len(corrections)}
+	if hooks && args.PreHook != "" {
+		if err := runHook(args.PreHook, timeout, env); err != nil {
+			out.Warnf("Not changing %s at %s: %s\n", domain, provider, err)
+			return true
+		}
+	}
+	failed := printOrRunCorrections(domain, provider, corrections, out, push, args.Interactive, args.ShowIDs, notifier)
+	if hooks && args.PostHook != "" {
+		env.hook, env.result = "post", "ok"
+		if failed {
+			env.result = "failed"
+		}
+		if err := runHook(args.PostHook, timeout, env); err != nil {
+			out.Warnf("%s at %s: %s\n", domain, provider, err)
+			return true
+		}
+	}
+	return failed
+}
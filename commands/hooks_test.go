@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+)
+
+// writeHook writes a hook script that logs its environment to log, one line
+// per run, and fails for the domain fail.
+func writeHook(t *testing.T, dir, fail string) (hook, log string) {
+	hook, log = filepath.Join(dir, "hook.sh"), filepath.Join(dir, "hook.log")
+	script := `#!/bin/sh
+echo "$DNSCONTROL_HOOK $DNSCONTROL_DOMAIN $DNSCONTROL_PROVIDER $DNSCONTROL_CORRECTIONS $DNSCONTROL_RESULT" >> "` + log + `"
+[ "$DNSCONTROL_DOMAIN" != "` + fail + `" ]
+`
+	if err := ioutil.WriteFile(hook, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return hook, log
+}
+
+func readLines(t *testing.T, filename string) []string {
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return lines
+}
+
+func TestRunHook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	hook, log := writeHook(t, dir, "fail.example.com")
+
+	if err := runHook(hook, time.Minute, hookEnv{"post", "example.com", "r53", 3, "ok"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readLines(t, log), []string{"post example.com r53 3 ok"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the hook to get %v, got %v", want, got)
+	}
+	if err := runHook(hook, time.Minute, hookEnv{hook: "pre", domain: "fail.example.com"}); err == nil || !strings.Contains(err.Error(), "-pre-hook: exit status 1") {
+		t.Errorf("Expected the hook to fail, got %v", err)
+	}
+	start := time.Now()
+	err = runHook("exec sleep 5", 100*time.Millisecond, hookEnv{hook: "post"})
+	if err == nil || err.Error() != "-post-hook timed out after 100ms" {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("Expected the hook to be killed at the timeout, it ran for %s", time.Since(start))
+	}
+}
+
+func TestPushHooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	hook, log := writeHook(t, dir, "z.example.com")
+
+	args := PushArgs{PreHook: hook, PostHook: hook}
+	args.JSONFile = writeIR(t, dir, "a.example.com", "fail.example.com", "z.example.com")
+	args.CredsFile = filepath.Join(dir, "creds.json")
+
+	// preview doesn't run them.
+	if err := run(args, false, printer.ConsolePrinter{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readLines(t, log); got != nil {
+		t.Errorf("Expected preview not to run hooks, got %v", got)
+	}
+
+	fakeApplied = nil
+	if err := run(args, true, printer.ConsolePrinter{}); err == nil {
+		t.Error("Expected an error")
+	}
+	want := []string{
+		"pre a.example.com fake 1",
+		"post a.example.com fake 1 ok",
+		"pre fail.example.com fake 1",
+		"post fail.example.com fake 1 failed",
+		// The pre hook fails, so z is left alone.
+		"pre z.example.com fake 1",
+	}
+	if got := readLines(t, log); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected hooks:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+	if want := []string{"a.example.com"}; !reflect.DeepEqual(fakeApplied, want) {
+		t.Errorf("Expected only %v to be pushed, got %v", want, fakeApplied)
+	}
+}
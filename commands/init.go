@@ -0,0 +1,237 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args InitArgs
+	return &cli.Command{
+		Name:  "init",
+		Usage: "write a dnsconfig.js and a creds.json to start from",
+		Action: func(ctx *cli.Context) error {
+			return exit(Init(args, os.Stdin))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// InitArgs contains all data/flags needed to run init, independently of CLI.
+type InitArgs struct {
+	Provider  string
+	Domain    string
+	JSFile    string
+	CredsFile string
+	Force     bool
+}
+
+func (args *InitArgs) flags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:        "provider",
+			Destination: &args.Provider,
+			Usage:       "Type of the DNS provider, like BIND or ROUTE53. Asked for if not given",
+		},
+		cli.StringFlag{
+			Name:        "domain",
+			Destination: &args.Domain,
+			Usage:       "Name of the example domain. Asked for if not given",
+		},
+		cli.StringFlag{
+			Name:        "config",
+			Destination: &args.JSFile,
+			Value:       "dnsconfig.js",
+			Usage:       "File to write the configuration to",
+		},
+		cli.StringFlag{
+			Name:        "creds",
+			Destination: &args.CredsFile,
+			Value:       "creds.json",
+			Usage:       "File to write the credentials template to",
+		},
+		cli.BoolFlag{
+			Name:        "force",
+			Destination: &args.Force,
+			Usage:       "Overwrite the files if they exist",
+		},
+	}
+}
+
+// credsField is a field of a provider's entry in creds.json.
+type credsField struct {
+	key, value, comment string
+}
+
+// credsTemplate is what init writes to creds.json for a provider type.
+type credsTemplate struct {
+	name   string // Of the provider in creds.json and dnsconfig.js.
+	fields []credsField
+}
+
+var credsTemplates = map[string]credsTemplate{
+	"ACTIVEDIRECTORY_PS": {"activedir", []credsField{
+		{"ADServer", "", "Required: the DNS server to manage"},
+	}},
+	"BIND": {"bind", []credsField{
+		{"directory", "zones", "Where the zonefiles are written"},
+	}},
+	"CLOUDFLAREAPI": {"cloudflare", []credsField{
+		{"apikey", "", "Required: the Global API Key of your account"},
+		{"apiuser", "", "Required: the email address of your account"},
+	}},
+	"DIGITALOCEAN": {"digitalocean", []credsField{
+		{"token", "", "Required: a personal access token with write scope"},
+	}},
+	"DNSIMPLE": {"dnsimple", []credsField{
+		{"token", "", "Required: an account API token"},
+	}},
+	"GANDI": {"gandi", []credsField{
+		{"apikey", "", "Required: your API key"},
+	}},
+	"GCLOUD": {"gcloud", []credsField{
+		{"project_id", "", "Required: the fields of a JSON service account key"},
+		{"private_key", "", "Required, with newlines replaced by \\n"},
+		{"client_email", "", "Required"},
+	}},
+	"LINODE": {"linode", []credsField{
+		{"token", "", "Required: a personal access token with read/write access to domains"},
+	}},
+	"NAMECHEAP": {"namecheap", []credsField{
+		{"apikey", "", "Required: your API key"},
+		{"apiuser", "", "Required: your user name"},
+	}},
+	"NAMEDOTCOM": {"namedotcom", []credsField{
+		{"apikey", "", "Required: your API token"},
+		{"apiuser", "", "Required: your user name"},
+	}},
+	"NS1": {"ns1", []credsField{
+		{"api_token", "", "Required: an API key"},
+	}},
+	"OCTODNS": {"octodns", []credsField{
+		{"directory", "config", "Where the octoDNS YAML files are written"},
+	}},
+	"ORACLE": {"oracle", []credsField{
+		{"user_ocid", "", "Required"},
+		{"tenancy_ocid", "", "Required"},
+		{"fingerprint", "", "Required: the fingerprint of the API signing key"},
+		{"private_key", "", "Required: the API signing key (PEM), with newlines replaced by \\n"},
+		{"region", "", "Required, like us-ashburn-1"},
+		{"compartment", "", "Optional: defaults to the tenancy"},
+	}},
+	"OVH": {"ovh", []credsField{
+		{"app-key", "", "Required"},
+		{"app-secret-key", "", "Required"},
+		{"consumer-key", "", "Required"},
+	}},
+	"RFC2136": {"rfc2136", []credsField{
+		{"server", "", "Required: host or host:port of the primary server"},
+		{"keyname", "", "The TSIG key. Leave both empty for no key"},
+		{"keysecret", "", "The key's secret, in base64"},
+		{"keyalgorithm", "hmac-sha256", "hmac-sha256, hmac-sha512, hmac-sha1 or hmac-md5"},
+	}},
+	"ROUTE53": {"route53", []credsField{
+		{"KeyId", "", "The access key of an IAM user. Leave both empty to use the usual AWS credentials"},
+		{"SecretKey", "", ""},
+	}},
+	"SOFTLAYER": {"softlayer", []credsField{
+		{"username", "", "Required"},
+		{"api_key", "", "Required"},
+	}},
+	"VULTR": {"vultr", []credsField{
+		{"token", "", "Required: your API key"},
+	}},
+}
+
+func credsTemplateTypes() []string {
+	var types []string
+	for t := range credsTemplates {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Init writes a dnsconfig.js with one example domain at the provider, and a
+// creds.json with the fields the provider needs. The provider and domain are
+// read from in if they were not given as flags.
+func Init(args InitArgs, in io.Reader) error {
+	if !args.Force {
+		for _, f := range []string{args.JSFile, args.CredsFile} {
+			if _, err := os.Stat(f); err == nil {
+				return errors.Errorf("%s exists. Use -force to overwrite it", f)
+			}
+		}
+	}
+	r := bufio.NewReader(in)
+	if args.Provider == "" {
+		fmt.Printf("DNS provider type (%s): ", strings.Join(credsTemplateTypes(), ", "))
+		args.Provider = readLine(r)
+	}
+	if args.Domain == "" {
+		fmt.Printf("Domain: ")
+		args.Domain = readLine(r)
+	}
+	tmpl, ok := credsTemplates[strings.ToUpper(args.Provider)]
+	if !ok {
+		return errors.Errorf("Unknown DNS provider type %q (must be one of %s)", args.Provider, strings.Join(credsTemplateTypes(), ", "))
+	}
+	domain := strings.ToLower(strings.TrimSuffix(args.Domain, "."))
+	if domain == "" || !strings.Contains(domain, ".") {
+		return errors.Errorf("Invalid domain %q", args.Domain)
+	}
+
+	js := fmt.Sprintf(`// The DNS records of your domains. See https://stackexchange.github.io/dnscontrol/
+// for the functions that can be used here.
+
+// The registrar "none" leaves the nameservers at the registrar alone.
+var REG_NONE = NewRegistrar("none", "NONE");
+var DNS_%[1]s = NewDnsProvider(%[2]q, %[3]q);
+
+D(%[4]q, REG_NONE, DnsProvider(DNS_%[1]s),
+    // Replace these example records with your own.
+    A("@", "192.0.2.1"),
+    CNAME("www", "@")
+);
+`, strings.ToUpper(tmpl.name), tmpl.name, strings.ToUpper(args.Provider), domain)
+
+	creds := &bytes.Buffer{}
+	fmt.Fprintf(creds, "// The credentials of the providers in %s, by name.\n", args.JSFile)
+	fmt.Fprintf(creds, "// A value of \"$NAME\" is read from the environment variable NAME.\n")
+	fmt.Fprintf(creds, "{\n  %q: {\n", tmpl.name)
+	for i, f := range tmpl.fields {
+		if f.comment != "" {
+			fmt.Fprintf(creds, "    // %s\n", f.comment)
+		}
+		fmt.Fprintf(creds, "    %q: %q", f.key, f.value)
+		if i < len(tmpl.fields)-1 {
+			fmt.Fprintf(creds, ",")
+		}
+		fmt.Fprintf(creds, "\n")
+	}
+	fmt.Fprintf(creds, "  }\n}\n")
+
+	if err := ioutil.WriteFile(args.JSFile, []byte(js), 0644); err != nil {
+		return err
+	}
+	// The credentials are secret once filled in.
+	if err := ioutil.WriteFile(args.CredsFile, creds.Bytes(), 0600); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s and %s. Fill in the credentials, then run dnscontrol preview\n", args.JSFile, args.CredsFile)
+	return nil
+}
+
+func readLine(r *bufio.Reader) string {
+	line, _ := r.ReadString('\n')
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/providers/config"
+)
+
+func TestInit(t *testing.T) {
+	for _, typ := range credsTemplateTypes() {
+		dir, err := ioutil.TempDir("", "init")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		args := InitArgs{
+			Provider:  typ,
+			Domain:    "Example.com.",
+			JSFile:    filepath.Join(dir, "dnsconfig.js"),
+			CredsFile: filepath.Join(dir, "creds.json"),
+		}
+		if err := Init(args, strings.NewReader("")); err != nil {
+			t.Fatalf("%s: %s", typ, err)
+		}
+		cfg, err := GetDNSConfig(GetDNSConfigArgs{ExecuteDSLArgs: ExecuteDSLArgs{JSFile: args.JSFile}})
+		if err != nil {
+			t.Fatalf("%s: %s", typ, err)
+		}
+		if len(cfg.Domains) != 1 || cfg.Domains[0].Name != "example.com" {
+			t.Errorf("%s: unexpected config %+v", typ, cfg.Domains)
+		}
+		if len(cfg.DNSProviders) != 1 || cfg.DNSProviders[0].Type != typ {
+			t.Errorf("%s: unexpected DNS providers %+v", typ, cfg.DNSProviders)
+		}
+		creds, err := config.LoadProviderConfigs(args.CredsFile)
+		if err != nil {
+			t.Fatalf("%s: %s", typ, err)
+		}
+		c, ok := creds[credsTemplates[typ].name]
+		if !ok || len(c) != len(credsTemplates[typ].fields) {
+			t.Errorf("%s: unexpected creds %v", typ, creds)
+		}
+		for _, f := range credsTemplates[typ].fields {
+			if _, ok := c[f.key]; !ok {
+				t.Errorf("%s: %s missing from creds", typ, f.key)
+			}
+		}
+	}
+}
+
+func TestInitInteractive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "init")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	args := InitArgs{JSFile: filepath.Join(dir, "dnsconfig.js"), CredsFile: filepath.Join(dir, "creds.json")}
+	if err := Init(args, strings.NewReader("bind\nexample.org\n")); err != nil {
+		t.Fatal(err)
+	}
+	js, err := ioutil.ReadFile(args.JSFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(js), `NewDnsProvider("bind", "BIND")`) || !strings.Contains(string(js), `D("example.org"`) {
+		t.Errorf("unexpected dnsconfig.js:\n%s", js)
+	}
+}
+
+func TestInitErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "init")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	args := InitArgs{
+		Provider:  "BIND",
+		Domain:    "example.com",
+		JSFile:    filepath.Join(dir, "dnsconfig.js"),
+		CredsFile: filepath.Join(dir, "creds.json"),
+	}
+	if err := ioutil.WriteFile(args.JSFile, []byte("// mine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Init(args, strings.NewReader("")); err == nil {
+		t.Error("expected an existing dnsconfig.js not to be overwritten")
+	}
+	if b, _ := ioutil.ReadFile(args.JSFile); string(b) != "// mine\n" {
+		t.Errorf("dnsconfig.js was changed to %s", b)
+	}
+	args.Force = true
+	if err := Init(args, strings.NewReader("")); err != nil {
+		t.Errorf("expected -force to overwrite: %s", err)
+	}
+
+	args.Provider = "NOSUCH"
+	if err := Init(args, strings.NewReader("")); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+	args.Provider, args.Domain = "BIND", "example"
+	if err := Init(args, strings.NewReader("")); err == nil {
+		t.Error("expected an error for an invalid domain")
+	}
+}
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/lint"
+	"github.com/StackExchange/dnscontrol/pkg/normalize"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args LintArgs
+	return &cli.Command{
+		Name:  "lint",
+		Usage: "check the configuration against best practices, such as having CAA and DMARC records",
+		Action: func(ctx *cli.Context) error {
+			return exit(Lint(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// LintArgs contains all data/flags needed to run lint, independently of CLI.
+type LintArgs struct {
+	GetDNSConfigArgs
+	ValidateArgs
+	Disable    string
+	Severities string
+	List       bool
+}
+
+func (args *LintArgs) flags() []cli.Flag {
+	flags := args.GetDNSConfigArgs.flags()
+	flags = append(flags, args.ValidateArgs.flags()...)
+	flags = append(flags, cli.StringFlag{
+		Name:        "disable",
+		Destination: &args.Disable,
+		Usage:       "Comma separated list of the rules not to check",
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "severity",
+		Destination: &args.Severities,
+		Usage:       "Comma separated list of RULE=SEVERITY, to change the severity of rules to info, warning or error",
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "list",
+		Destination: &args.List,
+		Usage:       "List the rules, with their default severity, and exit",
+	})
+	return flags
+}
+
+// config returns the lint configuration of the flags.
+func (args *LintArgs) config() (lint.Config, error) {
+	conf := lint.Config{Disabled: map[string]bool{}, Severities: map[string]lint.Severity{}}
+	for _, id := range strings.Split(args.Disable, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			conf.Disabled[id] = true
+		}
+	}
+	for _, s := range strings.Split(args.Severities, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		parts := strings.SplitN(s, "=", 2)
+		if len(parts) != 2 {
+			return conf, errors.Errorf("-severity %q is not RULE=SEVERITY", s)
+		}
+		severity, err := lint.ParseSeverity(parts[1])
+		if err != nil {
+			return conf, errors.Errorf("-severity %s: %s", parts[0], err)
+		}
+		conf.Severities[parts[0]] = severity
+	}
+	return conf, conf.Validate()
+}
+
+// Lint implements the lint subcommand. It fails if a rule of severity error
+// finds a problem.
+func Lint(args LintArgs) error {
+	return runLint(args, os.Stdout)
+}
+
+func runLint(args LintArgs, w io.Writer) error {
+	conf, err := args.config()
+	if err != nil {
+		return err
+	}
+	if args.List {
+		for _, r := range lint.Rules() {
+			fmt.Fprintf(w, "%-14s %-8s %s\n", r.ID, r.Severity, r.Description)
+		}
+		return nil
+	}
+	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
+	if err != nil {
+		return err
+	}
+	res := args.validate(cfg)
+	var findings []lint.Finding
+	if len(res.Errors) == 0 {
+		findings = lint.Run(cfg, conf)
+		res.Warnings = notFound(res.Warnings, findings)
+	}
+	if PrintValidationErrors(res, args.Strict) {
+		return errors.Errorf("Exiting due to validation errors")
+	}
+	count := map[lint.Severity]int{}
+	for _, f := range findings {
+		fmt.Fprintf(w, "%s: [%s] %s: %s\n", f.Severity, f.Rule, f.Domain, f.Message)
+		count[f.Severity]++
+	}
+	fmt.Fprintf(w, "%d errors, %d warnings, %d infos\n", count[lint.Error], count[lint.Warning], count[lint.Info])
+	if count[lint.Error] > 0 {
+		return errors.Errorf("lint found %d errors", count[lint.Error])
+	}
+	return nil
+}
+
+// notFound returns the validation warnings but those that a rule of their ID
+// found again for the same record, such as mx-cname: they are printed once,
+// as findings with the severity of the rule.
+func notFound(warnings []error, findings []lint.Finding) []error {
+	type key struct {
+		id string
+		r  *models.RecordConfig
+	}
+	found := map[key]bool{}
+	for _, f := range findings {
+		found[key{f.Rule, f.Record}] = true
+	}
+	var left []error
+	for _, err := range warnings {
+		if w, ok := err.(normalize.Warning); ok && w.Record != nil && found[key{w.ID, w.Record}] {
+			continue
+		}
+		left = append(left, err)
+	}
+	return left
+}
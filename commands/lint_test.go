@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/lint"
+	"github.com/StackExchange/dnscontrol/pkg/normalize"
+	"github.com/pkg/errors"
+)
+
+func TestLint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"),
+	MX("@", 10, "mail"),
+	CNAME("mail", "mail.example.net."),
+	TXT("@", "v=spf1 a mx include:a.example.net include:b.example.net include:c.example.net a:x mx:y include:d.example.net include:e.example.net a:z mx:w -all"),
+	CAA("@", "issue", "letsencrypt.org")
+);`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := LintArgs{}
+	args.JSFile = jsFile
+	var out bytes.Buffer
+	if err := runLint(args, &out); err == nil || !strings.Contains(err.Error(), "2 errors") {
+		t.Errorf("Expected the MX to a CNAME and the SPF record to be errors, got %v", err)
+	}
+	want := "warning: [dmarc-missing] example.com: mail is sent or received for example.com, but there is no DMARC record at _dmarc\n" +
+		"error: [mx-cname] example.com: MX example.com points to mail.example.com, which is a CNAME\n" +
+		"error: [spf-lookups] example.com: SPF record example.com requires 11 lookups (limit is 10). Consider flattening more includes\n" +
+		"2 errors, 1 warnings, 0 infos\n"
+	if out.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, out.String())
+	}
+
+	args.Severities = "spf-lookups=warning,mx-cname=warning"
+	args.Disable = "dmarc-missing"
+	out.Reset()
+	if err := runLint(args, &out); err != nil {
+		t.Errorf("Expected only warnings, got %v", err)
+	}
+	if !strings.HasSuffix(out.String(), "0 errors, 2 warnings, 0 infos\n") {
+		t.Errorf("Expected two warnings, got:\n%s", out.String())
+	}
+
+	for _, bad := range []LintArgs{{Disable: "no-such-rule"}, {Severities: "spf-lookups"}, {Severities: "spf-lookups=fatal"}} {
+		if err := runLint(bad, &out); err == nil {
+			t.Errorf("Expected -disable %q -severity %q to be an error", bad.Disable, bad.Severities)
+		}
+	}
+}
+
+func TestLintNotFound(t *testing.T) {
+	dc := &models.DomainConfig{Name: "example.com"}
+	for _, r := range []struct{ label, rType, target string }{{"@", "MX", "alias.example.com."}, {"www", "MX", "alias.example.com."}, {"alias", "CNAME", "mail.example.net."}} {
+		rc := &models.RecordConfig{Type: r.rType}
+		rc.SetLabel(r.label, dc.Name)
+		rc.SetTarget(r.target)
+		dc.Records = append(dc.Records, rc)
+	}
+	warnings := append(normalize.MXCNAMEs(dc), errors.New("domain"))
+	findings := []lint.Finding{{Rule: "mx-cname", Problem: lint.Problem{Record: dc.Records[0]}}, {Rule: "wildcard", Problem: lint.Problem{Record: dc.Records[1]}}}
+	var got []string
+	for _, err := range notFound(warnings, findings) {
+		got = append(got, err.Error())
+	}
+	if want := []string{"MX www.example.com points to alias.example.com, which is a CNAME", "domain"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the validation warnings %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/StackExchange/dnscontrol/providers/config"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+	"golang.org/x/net/idna"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args ListZonesArgs
+	return &cli.Command{
+		Name:  "list-zones",
+		Usage: "list the zones a DNS provider has, and whether the configuration manages them",
+		Action: func(ctx *cli.Context) error {
+			return exit(ListZones(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// ListZonesArgs contains all data/flags needed to run list-zones, independently of CLI.
+type ListZonesArgs struct {
+	GetDNSConfigArgs
+	GetCredentialsArgs
+	Provider string
+	JSON     bool
+}
+
+func (args *ListZonesArgs) flags() []cli.Flag {
+	flags := withoutFlag(args.GetDNSConfigArgs.flags(), "json")
+	flags = append(flags, args.GetCredentialsArgs.flags()...)
+	flags = append(flags, cli.StringFlag{
+		Name:        "provider",
+		Destination: &args.Provider,
+		Usage:       "The name of the DNS provider, as given to NewDnsProvider()",
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "json",
+		Destination: &args.JSON,
+		Usage:       "Print the zones as JSON",
+	})
+	return flags
+}
+
+// The status of a zone.
+const (
+	zoneManaged   = "managed"   // at the provider, and D() uses the provider for it
+	zoneUnmanaged = "unmanaged" // at the provider only
+	zoneMissing   = "missing"   // D() uses the provider for it, but the provider doesn't have it
+)
+
+// listedZone is a zone, as list-zones prints it.
+type listedZone struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// ListZones implements the list-zones subcommand. Only the given provider is
+// created, so the credentials of the others aren't needed.
+func ListZones(args ListZonesArgs) error {
+	if args.Provider == "" {
+		return errors.Errorf("-provider is required")
+	}
+	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
+	if err != nil {
+		return err
+	}
+	var pcfg *models.DNSProviderConfig
+	for _, p := range cfg.DNSProviders {
+		if p.Name == args.Provider {
+			pcfg = p
+		}
+	}
+	if pcfg == nil {
+		return errors.Errorf("%s is not a DNS provider of the configuration", args.Provider)
+	}
+	if err := args.loadEnv(); err != nil {
+		return err
+	}
+	creds, err := config.LoadProviderConfigs(args.CredsFile)
+	if err != nil {
+		return err
+	}
+	driver, err := providers.CreateDNSProvider(pcfg.Type, creds[pcfg.Name], pcfg.Metadata)
+	if err != nil {
+		return err
+	}
+	lister, ok := driver.(providers.ZoneLister)
+	if !ok {
+		return errors.Errorf("%s (%s) can't list zones", pcfg.Name, pcfg.Type)
+	}
+	zones, err := lister.ListZones()
+	if err != nil {
+		return errors.Errorf("listing the zones of %s: %s", pcfg.Name, err)
+	}
+	return printZones(os.Stdout, compareZones(cfg, pcfg.Name, zones), args.JSON)
+}
+
+// compareZones gives each zone of the provider, and each domain of cfg that
+// uses it, its status. Names are compared in their punycode form, as
+// providers return them.
+func compareZones(cfg *models.DNSConfig, provider string, zones []string) []listedZone {
+	status := map[string]string{}
+	for _, z := range zones {
+		status[canonicalZone(z)] = zoneUnmanaged
+	}
+	for _, domain := range cfg.Domains {
+		if _, ok := domain.DNSProviderNames[provider]; !ok {
+			continue
+		}
+		name := canonicalZone(domain.Name)
+		if status[name] == zoneUnmanaged {
+			status[name] = zoneManaged
+		} else {
+			status[name] = zoneMissing
+		}
+	}
+	list := []listedZone{}
+	for name, s := range status {
+		list = append(list, listedZone{name, s})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+func canonicalZone(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if ascii, err := idna.ToASCII(name); err == nil {
+		return ascii
+	}
+	return name
+}
+
+func printZones(w io.Writer, zones []listedZone, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(zones)
+	}
+	for _, z := range zones {
+		fmt.Fprintf(w, "%-40s %s\n", z.Name, z.Status)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/providers"
+)
+
+// zonesProvider has the zones in fakeZones.
+type zonesProvider struct {
+	fakeProvider
+}
+
+var fakeZones = []string{"a.example.com", "Unmanaged.Example.", "xn--bcher-kva.example", "other.example"}
+
+func (zonesProvider) ListZones() ([]string, error) {
+	return fakeZones, nil
+}
+
+func init() {
+	providers.RegisterDomainServiceProviderType("FAKE-ZONES", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return zonesProvider{fakeProvider{&fakeApplied}}, nil
+	})
+}
+
+const listZonesConfig = `
+var REG = NewRegistrar("none", "NONE");
+var ZONES = NewDnsProvider("zones", "FAKE-ZONES");
+var PUSH = NewDnsProvider("push", "FAKE-PUSH");
+D("a.example.com", REG, DnsProvider(ZONES));
+D("bücher.example", REG, DnsProvider(ZONES), DnsProvider(PUSH));
+D("missing.example", REG, DnsProvider(ZONES));
+D("other.example", REG, DnsProvider(PUSH));
+`
+
+func TestListZones(t *testing.T) {
+	dir, err := ioutil.TempDir("", "listzones")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	if err := ioutil.WriteFile(jsFile, []byte(listZonesConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	args := GetDNSConfigArgs{JSFile: jsFile}
+	cfg, err := GetDNSConfig(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zones := compareZones(cfg, "zones", fakeZones)
+	want := []listedZone{
+		{"a.example.com", zoneManaged},
+		{"missing.example", zoneMissing},
+		// In the configuration, but at another provider.
+		{"other.example", zoneUnmanaged},
+		{"unmanaged.example", zoneUnmanaged},
+		{"xn--bcher-kva.example", zoneManaged},
+	}
+	if !reflect.DeepEqual(zones, want) {
+		t.Errorf("Expected %v, got %v", want, zones)
+	}
+
+	var text bytes.Buffer
+	if err := printZones(&text, zones, false); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(text.String()), "\n"); len(lines) != 5 || strings.Join(strings.Fields(lines[1]), " ") != "missing.example missing" {
+		t.Errorf("Unexpected output:\n%s", text.String())
+	}
+	var out bytes.Buffer
+	if err := printZones(&out, zones, true); err != nil {
+		t.Fatal(err)
+	}
+	var back []listedZone
+	if err := json.Unmarshal(out.Bytes(), &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, want) {
+		t.Errorf("Expected the JSON to hold the same zones, got %s", out.String())
+	}
+
+	for _, tst := range []struct {
+		provider, err string
+	}{
+		{"", "-provider is required"},
+		{"bogus", "bogus is not a DNS provider of the configuration"},
+		{"push", "push (FAKE-PUSH) can't list zones"},
+	} {
+		err := ListZones(ListZonesArgs{GetDNSConfigArgs: args, GetCredentialsArgs: GetCredentialsArgs{CredsFile: filepath.Join(dir, "creds.json")}, Provider: tst.provider})
+		if err == nil || err.Error() != tst.err {
+			t.Errorf("-provider %q: expected %q, got %v", tst.provider, tst.err, err)
+		}
+	}
+}
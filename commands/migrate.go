@@ -0,0 +1,339 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/nameservers"
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var _ = cmd(catMain, func() *cli.Command {
+	var args MigrateArgs
+	return &cli.Command{
+		Name:  "migrate",
+		Usage: "move a domain to another DNS provider in phases: lower the TTLs, fill the new provider, then change the delegation",
+		Action: func(ctx *cli.Context) error {
+			return exit(Migrate(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// MigrateArgs contains all data/flags needed to run migrate, independently of CLI.
+type MigrateArgs struct {
+	GetDNSConfigArgs
+	GetCredentialsArgs
+	ValidateArgs
+	Domain    string
+	From      string
+	To        string
+	StateFile string
+	TTL       uint
+	Preview   bool
+}
+
+func (args *MigrateArgs) flags() []cli.Flag {
+	flags := args.GetDNSConfigArgs.flags()
+	flags = append(flags, args.GetCredentialsArgs.flags()...)
+	flags = append(flags, args.ValidateArgs.flags()...)
+	flags = append(flags, cli.StringFlag{
+		Name:        "domain",
+		Destination: &args.Domain,
+		Usage:       "The domain to migrate",
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "from",
+		Destination: &args.From,
+		Usage:       "The DNS provider that serves the domain now, as given to NewDnsProvider()",
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "to",
+		Destination: &args.To,
+		Usage:       "The DNS provider to move the domain to, as given to NewDnsProvider()",
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "state",
+		Destination: &args.StateFile,
+		Usage:       "The file that keeps the phase the migration is at (default migrate-DOMAIN.json)",
+	})
+	flags = append(flags, cli.UintFlag{
+		Name:        "ttl",
+		Destination: &args.TTL,
+		Value:       300,
+		Usage:       "The TTL records are lowered to during the migration",
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "preview",
+		Destination: &args.Preview,
+		Usage:       "Print the changes of the next phase without making them",
+	})
+	return flags
+}
+
+// The phases of a migration, in order. Each run of migrate does the next one.
+const (
+	phaseLowerTTLs = 1 // lower the TTLs at the old provider
+	phasePopulate  = 2 // create the zone at the new provider, with the low TTLs
+	phaseDelegate  = 3 // point the delegation at the nameservers of the new provider
+)
+
+// migrationState is the state file of a migration.
+type migrationState struct {
+	Domain string `json:"domain"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	// Phase is the last phase done, 0 before the first.
+	Phase int    `json:"phase"`
+	TTL   uint32 `json:"ttl"`
+	// WaitUntil is when the records cached with their TTLs from before
+	// phaseLowerTTLs have expired everywhere.
+	WaitUntil time.Time `json:"wait_until,omitempty"`
+}
+
+// migrateNow is the current time for migrate. Tests replace it.
+var migrateNow = time.Now
+
+// Migrate implements the migrate subcommand.
+func Migrate(args MigrateArgs) error {
+	return migrate(args, printer.ConsolePrinter{})
+}
+
+func migrate(args MigrateArgs, out printer.CLI) error {
+	if args.Domain == "" || args.From == "" || args.To == "" {
+		return errors.Errorf("-domain, -from and -to are required")
+	}
+	if args.From == args.To {
+		return errors.Errorf("-from and -to are the same provider")
+	}
+	if args.StateFile == "" {
+		args.StateFile = "migrate-" + args.Domain + ".json"
+	}
+	state, err := loadMigration(args)
+	if err != nil {
+		return err
+	}
+	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
+	if err != nil {
+		return err
+	}
+	res := args.validate(cfg)
+	if PrintValidationErrors(res, args.Strict) {
+		return errors.Errorf("Exiting due to validation errors")
+	}
+	notifier, err := InitializeProviders(args.GetCredentialsArgs, cfg, false)
+	if err != nil {
+		return err
+	}
+	domain := cfg.FindDomain(args.Domain)
+	if domain == nil {
+		return errors.Errorf("%s is not in the configuration", args.Domain)
+	}
+	from, to := providerInstance(domain, args.From), providerInstance(domain, args.To)
+	if from == nil || to == nil {
+		return errors.Errorf("%s must use both %s and %s during the migration", domain.Name, args.From, args.To)
+	}
+
+	out.StartDomain(domain.Name)
+	var corrections []*models.Correction
+	name := to.Name
+	switch state.Phase {
+	case 0:
+		name = from.Name
+		var maxTTL uint32
+		corrections, maxTTL, err = lowerTTLCorrections(domain, from, state.TTL)
+		state.WaitUntil = migrateNow().Add(time.Duration(maxTTL) * time.Second).UTC()
+	case phaseLowerTTLs:
+		if now := migrateNow(); now.Before(state.WaitUntil) {
+			return errors.Errorf("the TTLs of %s at %s were lowered less than their old TTL ago. Run migrate again after %s", domain.Name, from.Name, state.WaitUntil.Format(time.RFC3339))
+		}
+		if err := checkLowered(domain, from, state.TTL, args.StateFile); err != nil {
+			return err
+		}
+		corrections, err = migrationCorrections(domain, to, state.TTL)
+	case phasePopulate:
+		name = domain.RegistrarName
+		corrections, err = delegationCorrections(domain, to)
+	default:
+		out.Debugf("The migration of %s from %s to %s is done. Remove %s from its D(), then push to restore the TTLs\n", domain.Name, from.Name, to.Name, from.Name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	out.Debugf("Phase %d of 3 (%s):\n", state.Phase+1, phaseNames[state.Phase+1])
+	if printOrRunCorrections(domain.Name, name, corrections, out, !args.Preview, false, false, notifier) {
+		notifier.Done()
+		return errors.Errorf("Completed with errors, phase %d was not done", state.Phase+1)
+	}
+	notifier.Done()
+	if args.Preview {
+		return nil
+	}
+	state.Phase++
+	if state.Phase == phaseLowerTTLs {
+		out.Debugf("Run migrate again after %s, when the old TTLs have expired\n", state.WaitUntil.Format(time.RFC3339))
+	}
+	return saveMigration(args.StateFile, state)
+}
+
+var phaseNames = map[int]string{
+	phaseLowerTTLs: "lower the TTLs",
+	phasePopulate:  "fill the new provider",
+	phaseDelegate:  "change the delegation",
+}
+
+// loadMigration reads the state file, or starts a new migration if there is
+// none. The state must be of the same migration as args.
+func loadMigration(args MigrateArgs) (*migrationState, error) {
+	state := &migrationState{Domain: args.Domain, From: args.From, To: args.To, TTL: uint32(args.TTL)}
+	b, err := ioutil.ReadFile(args.StateFile)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	saved := &migrationState{}
+	if err := json.Unmarshal(b, saved); err != nil {
+		return nil, errors.Errorf("%s: %s", args.StateFile, err)
+	}
+	if saved.Domain != state.Domain || saved.From != state.From || saved.To != state.To {
+		return nil, errors.Errorf("%s is the state of the migration of %s from %s to %s", args.StateFile, saved.Domain, saved.From, saved.To)
+	}
+	return saved, nil
+}
+
+func saveMigration(filename string, state *migrationState) error {
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(b, '\n'), 0644)
+}
+
+func providerInstance(domain *models.DomainConfig, name string) *models.DNSProviderInstance {
+	for _, p := range domain.DNSProviderInstances {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// onlyProvider returns a copy of domain served by provider alone, with the
+// apex NS records of its nameservers.
+func onlyProvider(domain *models.DomainConfig, provider *models.DNSProviderInstance) (*models.DomainConfig, error) {
+	dc, err := domain.Copy()
+	if err != nil {
+		return nil, err
+	}
+	dc.DNSProviderInstances = []*models.DNSProviderInstance{provider}
+	if dc.Nameservers, err = nameservers.DetermineNameservers(dc); err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+// lowerTTLCorrections returns the corrections that lower the TTLs of the
+// records provider serves for domain to at most ttl, and change nothing
+// else, and the highest TTL they had.
+func lowerTTLCorrections(domain *models.DomainConfig, provider *models.DNSProviderInstance, ttl uint32) ([]*models.Correction, uint32, error) {
+	dc, existing, err := servedRecords(domain, provider)
+	if err != nil {
+		return nil, 0, err
+	}
+	var maxTTL uint32
+	dc.Records = nil
+	for _, r := range existing {
+		if r.TTL > maxTTL {
+			maxTTL = r.TTL
+		}
+		lowered, err := r.Copy()
+		if err != nil {
+			return nil, 0, err
+		}
+		if lowered.TTL > ttl {
+			lowered.TTL = ttl
+		}
+		dc.Records = append(dc.Records, lowered)
+	}
+	corrections, err := provider.Driver.GetDomainCorrections(dc)
+	return corrections, maxTTL, err
+}
+
+// checkLowered returns an error if provider serves a record of domain with a
+// TTL above ttl, as when the records were changed after phaseLowerTTLs.
+func checkLowered(domain *models.DomainConfig, provider *models.DNSProviderInstance, ttl uint32, stateFile string) error {
+	_, existing, err := servedRecords(domain, provider)
+	if err != nil {
+		return err
+	}
+	for _, r := range existing {
+		if r.TTL > ttl {
+			return errors.Errorf("%s %s at %s has a TTL of %d, not the lowered %d. Remove %s to start the migration again", r.Type, r.GetLabelFQDN(), provider.Name, r.TTL, ttl, stateFile)
+		}
+	}
+	return nil
+}
+
+// servedRecords returns domain as provider alone would serve it, and the
+// records provider serves for it now.
+func servedRecords(domain *models.DomainConfig, provider *models.DNSProviderInstance) (*models.DomainConfig, []*models.RecordConfig, error) {
+	dc, err := providerDomain(domain, provider)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, collector, err := diffCorrections(0, provider.Driver, dc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dc, collector.Existing, nil
+}
+
+// migrationCorrections returns the corrections that make provider serve
+// domain with TTLs of at most ttl.
+func migrationCorrections(domain *models.DomainConfig, provider *models.DNSProviderInstance, ttl uint32) ([]*models.Correction, error) {
+	dc, err := providerDomain(domain, provider)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range dc.Records {
+		if r.TTL > ttl {
+			r.TTL = ttl
+		}
+	}
+	return provider.Driver.GetDomainCorrections(dc)
+}
+
+// providerDomain returns domain as provider alone serves it.
+func providerDomain(domain *models.DomainConfig, provider *models.DNSProviderInstance) (*models.DomainConfig, error) {
+	dc, err := onlyProvider(domain, provider)
+	if err != nil {
+		return nil, err
+	}
+	nameservers.AddNSRecords(dc)
+	if err := providers.PrepareDomain(provider.ProviderType, dc); err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+// delegationCorrections returns the corrections that make the registrar of
+// domain delegate it to the nameservers of provider alone.
+func delegationCorrections(domain *models.DomainConfig, provider *models.DNSProviderInstance) ([]*models.Correction, error) {
+	dc, err := onlyProvider(domain, provider)
+	if err != nil {
+		return nil, err
+	}
+	if len(dc.Nameservers) == 0 {
+		return nil, errors.Errorf("%s has no nameservers for %s to delegate it to", provider.Name, domain.Name)
+	}
+	return domain.RegistrarInstance.Driver.GetRegistrarCorrections(dc)
+}
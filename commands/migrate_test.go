@@ -0,0 +1,218 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+)
+
+// zoneProvider serves a zone of records, by "label type target", with their
+// TTL, and applies the corrections it is asked to make to it.
+type zoneProvider struct {
+	zone        map[string]uint32
+	nameservers []string
+}
+
+func (p *zoneProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	return models.StringsToNameservers(p.nameservers), nil
+}
+
+func (p *zoneProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	key := func(r *models.RecordConfig) string { return r.GetLabel() + " " + r.Type + " " + r.GetTargetField() }
+	var existing []*models.RecordConfig
+	for k, ttl := range p.zone {
+		parts := strings.SplitN(k, " ", 3)
+		rc := &models.RecordConfig{Type: parts[1], TTL: ttl, Metadata: map[string]string{}}
+		rc.SetLabel(parts[0], dc.Name)
+		rc.SetTarget(parts[2])
+		existing = append(existing, rc)
+	}
+	_, create, del, mod := diff.New(dc).IncrementalDiff(existing)
+	var corrections []*models.Correction
+	for _, changes := range []diff.Changeset{create, del, mod} {
+		for _, c := range changes {
+			c := c
+			corrections = append(corrections, &models.Correction{Msg: c.String(), F: func() error {
+				if c.Existing != nil {
+					delete(p.zone, key(c.Existing))
+				}
+				if c.Desired != nil {
+					p.zone[key(c.Desired)] = c.Desired.TTL
+				}
+				return nil
+			}})
+		}
+	}
+	return corrections, nil
+}
+
+// nsRegistrar records the nameservers it delegates to.
+type nsRegistrar struct {
+	nameservers *[]string
+}
+
+func (r nsRegistrar) GetRegistrarCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	var names []string
+	for _, ns := range dc.Nameservers {
+		names = append(names, ns.Name)
+	}
+	if reflect.DeepEqual(names, *r.nameservers) {
+		return nil, nil
+	}
+	return []*models.Correction{{Msg: "Update nameservers to " + strings.Join(names, ","), F: func() error {
+		*r.nameservers = names
+		return nil
+	}}}, nil
+}
+
+var (
+	migrateZones       = map[string]*zoneProvider{}
+	migrateNameservers []string
+)
+
+func init() {
+	providers.RegisterDomainServiceProviderType("FAKE-ZONE", func(conf map[string]string, _ json.RawMessage) (providers.DNSServiceProvider, error) {
+		return migrateZones[conf["zone"]], nil
+	})
+	providers.RegisterRegistrarType("FAKE-REGISTRAR", func(map[string]string) (providers.Registrar, error) {
+		return nsRegistrar{&migrateNameservers}, nil
+	})
+}
+
+func TestMigrate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("reg", "FAKE-REGISTRAR"),
+	DnsProvider(NewDnsProvider("old", "FAKE-ZONE")), DnsProvider(NewDnsProvider("new", "FAKE-ZONE")),
+	A("@", "1.1.1.1"),
+	A("www", "1.1.1.2", TTL(3600))
+);`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credsFile := filepath.Join(dir, "creds.json")
+	err = ioutil.WriteFile(credsFile, []byte(`{"old": {"zone": "old"}, "new": {"zone": "new"}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := &zoneProvider{map[string]uint32{
+		"@ A 1.1.1.1":               300,
+		"www A 1.1.1.2":             7200,
+		"@ NS ns1.old.example.net.": 3600,
+		"old A 1.1.1.9":             600,
+	}, []string{"ns1.old.example.net"}}
+	new := &zoneProvider{map[string]uint32{}, []string{"ns1.new.example.net"}}
+	migrateZones["old"], migrateZones["new"] = old, new
+	migrateNameservers = []string{"ns1.old.example.net."}
+	start := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	migrateNow = func() time.Time { return now }
+	defer func() { migrateNow = time.Now }()
+
+	args := MigrateArgs{Domain: "example.com", From: "old", To: "new", TTL: 300}
+	args.JSFile = jsFile
+	args.CredsFile = credsFile
+	args.StateFile = filepath.Join(dir, "state.json")
+	out := printer.ConsolePrinter{}
+	state := func() *migrationState {
+		s, err := loadMigration(args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	// -preview changes nothing.
+	args.Preview = true
+	if err := migrate(args, out); err != nil {
+		t.Fatal(err)
+	}
+	if old.zone["www A 1.1.1.2"] != 7200 {
+		t.Errorf("Expected -preview to leave the zone alone, got %v", old.zone)
+	}
+	if _, err := os.Stat(args.StateFile); !os.IsNotExist(err) {
+		t.Errorf("Expected -preview not to write the state, got %v", err)
+	}
+	args.Preview = false
+
+	// Phase 1 lowers the TTLs at the old provider, which keeps serving the
+	// domain, and changes nothing else.
+	if err := migrate(args, out); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]uint32{"@ A 1.1.1.1": 300, "www A 1.1.1.2": 300, "@ NS ns1.old.example.net.": 300, "old A 1.1.1.9": 300}
+	if !reflect.DeepEqual(old.zone, want) {
+		t.Errorf("Phase 1: expected %v, got %v", want, old.zone)
+	}
+	if len(new.zone) != 0 {
+		t.Errorf("Phase 1: expected the new provider to be left alone, got %v", new.zone)
+	}
+	if s := state(); s.Phase != phaseLowerTTLs || !s.WaitUntil.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("Phase 1: expected to wait for the old TTL of www, got %+v", s)
+	}
+
+	// Phase 2 waits until the old TTLs have expired.
+	now = start.Add(time.Hour)
+	if err := migrate(args, out); err == nil || !strings.Contains(err.Error(), "2020-03-01T14:00:00Z") {
+		t.Errorf("Expected phase 2 to wait, got %v", err)
+	}
+	if len(new.zone) != 0 || state().Phase != phaseLowerTTLs {
+		t.Errorf("Expected phase 2 not to start, got %v", new.zone)
+	}
+	now = start.Add(2 * time.Hour)
+
+	// It checks that the old provider still serves the lowered TTLs.
+	old.zone["www A 1.1.1.2"] = 7200
+	if err := migrate(args, out); err == nil || !strings.Contains(err.Error(), "A www.example.com at old has a TTL of 7200") {
+		t.Errorf("Expected phase 2 to refuse a TTL that is high again, got %v", err)
+	}
+	if len(new.zone) != 0 || state().Phase != phaseLowerTTLs {
+		t.Errorf("Expected phase 2 not to start, got %v", new.zone)
+	}
+	old.zone["www A 1.1.1.2"] = 300
+	if err := migrate(args, out); err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]uint32{"@ A 1.1.1.1": 300, "www A 1.1.1.2": 300, "@ NS ns1.new.example.net.": 300}
+	if !reflect.DeepEqual(new.zone, want) {
+		t.Errorf("Phase 2: expected %v, got %v", want, new.zone)
+	}
+	if !reflect.DeepEqual(migrateNameservers, []string{"ns1.old.example.net."}) {
+		t.Errorf("Phase 2: expected the delegation to be left alone, got %v", migrateNameservers)
+	}
+
+	// Phase 3 delegates to the new provider alone.
+	if err := migrate(args, out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(migrateNameservers, []string{"ns1.new.example.net"}) {
+		t.Errorf("Phase 3: expected the new nameservers, got %v", migrateNameservers)
+	}
+	if s := state(); s.Phase != phaseDelegate {
+		t.Errorf("Phase 3: expected to be done, got %+v", s)
+	}
+	if err := migrate(args, out); err != nil {
+		t.Fatal(err)
+	}
+
+	// The state belongs to this migration.
+	args.To = "other"
+	if err := migrate(args, out); err == nil || !strings.Contains(err.Error(), "from old to new") {
+		t.Errorf("Expected the state of another migration to be an error, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+)
+
+// changeIndex finds the changes of a diff that a correction makes. Most
+// providers describe each change on its own line using
+// diff.Correlation.String(). A correction that doesn't, like one of a
+// registrar, makes none of them.
+type changeIndex map[string]diff.Correlation
+
+// newChangeIndex indexes the changes the diff of a provider reported.
+func newChangeIndex(changes diff.Changeset) changeIndex {
+	ix := changeIndex{}
+	for _, c := range changes {
+		ix[c.String()] = c
+	}
+	return ix
+}
+
+// of returns the changes that c makes.
+func (ix changeIndex) of(c *models.Correction) diff.Changeset {
+	var changes diff.Changeset
+	for _, line := range strings.Split(c.Msg, "\n") {
+		if change, ok := ix[strings.TrimSpace(line)]; ok {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// kinds returns the kind of each change c makes: CREATE, MODIFY or DELETE.
+func (ix changeIndex) kinds(c *models.Correction) []string {
+	var kinds []string
+	for _, change := range ix.of(c) {
+		action, _, _, _ := change.Fields()
+		kinds = append(kinds, action)
+	}
+	return kinds
+}
+
+// isDeletion tells if c only deletes records.
+func (ix changeIndex) isDeletion(c *models.Correction) bool {
+	kinds := ix.kinds(c)
+	for _, k := range kinds {
+		if k != "DELETE" {
+			return false
+		}
+	}
+	return len(kinds) > 0
+}
+
+// orderCorrections moves the corrections that only delete records after the
+// others if the domain's correction_order is creates-first, or before them if
+// it is deletes-first. Without correction_order the order of the provider is
+// kept: some, like Route53, delete first on purpose. A correction that makes
+// several kinds of changes at once stays with the others.
+func orderCorrections(corrections []*models.Correction, ix changeIndex, order string) []*models.Correction {
+	if order == "" {
+		return corrections
+	}
+	var deletes, others []*models.Correction
+	for _, c := range corrections {
+		if ix.isDeletion(c) {
+			deletes = append(deletes, c)
+		} else {
+			others = append(others, c)
+		}
+	}
+	if order == models.DeletesFirst {
+		return append(deletes, others...)
+	}
+	return append(others, deletes...)
+}
+
+// onlyDeletions returns the corrections that delete records, for preview
+// -deletes-only. A correction that makes other changes too is shown with its
+// deletions only. It is only for printing: running it would make them all.
+func onlyDeletions(corrections []*models.Correction, ix changeIndex) []*models.Correction {
+	var deletes []*models.Correction
+	for _, c := range corrections {
+		if ix.isDeletion(c) {
+			deletes = append(deletes, c)
+			continue
+		}
+		var lines []string
+		for _, change := range ix.of(c) {
+			if action, _, _, _ := change.Fields(); action == "DELETE" {
+				lines = append(lines, change.String())
+			}
+		}
+		if len(lines) > 0 {
+			deletes = append(deletes, &models.Correction{Msg: strings.Join(lines, "\n"), F: c.F})
+		}
+	}
+	return deletes
+}
+
+// countDeletions returns how many records the corrections delete.
+func countDeletions(corrections []*models.Correction, ix changeIndex) int {
+	counts := &changeCounts{}
+	for _, c := range corrections {
+		counts.add(ix.kinds(c))
+	}
+	return counts.deletes
+}
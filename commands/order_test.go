@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+)
+
+// testChanges returns the changes of the diff of example.com from the
+// existing records to the desired ones, each given as "label type target"
+// with a TTL of 300.
+func testChanges(t *testing.T, existing, desired []string) diff.Changeset {
+	records := func(specs []string) []*models.RecordConfig {
+		var recs []*models.RecordConfig
+		for _, spec := range specs {
+			f := strings.SplitN(spec, " ", 3)
+			r := &models.RecordConfig{Type: f[1], TTL: 300}
+			r.SetLabel(f[0], "example.com")
+			if f[1] == "TXT" {
+				r.SetTargetTXT(f[2])
+			} else {
+				r.SetTarget(f[2])
+			}
+			recs = append(recs, r)
+		}
+		return recs
+	}
+	dc := &models.DomainConfig{Name: "example.com", Records: records(desired)}
+	_, create, del, mod := diff.New(dc).IncrementalDiff(records(existing))
+	changes := append(append(create, del...), mod...)
+	if len(changes) == 0 {
+		t.Fatal("expected changes")
+	}
+	return changes
+}
+
+func TestOrderCorrections(t *testing.T) {
+	ix := newChangeIndex(testChanges(t,
+		[]string{"www CNAME web.example.net.", "old A 1.1.1.2", "a A 1.1.1.3"},
+		[]string{"www A 1.1.1.1", "b A 1.1.1.3"}))
+	var corrections []*models.Correction
+	for _, msg := range []string{
+		"DELETE CNAME www.example.com web.example.net. ttl=300",
+		"CREATE A www.example.com 1.1.1.1 ttl=300",
+		"Update nameservers ns1.example.net -> ns2.example.net",
+		"DELETE A old.example.com 1.1.1.2 ttl=300",
+		"BATCH:\nDELETE A a.example.com 1.1.1.3 ttl=300\nCREATE A b.example.com 1.1.1.3 ttl=300\n",
+	} {
+		corrections = append(corrections, &models.Correction{Msg: msg})
+	}
+	for _, tst := range []struct {
+		order string
+		want  []int
+	}{
+		{"", []int{0, 1, 2, 3, 4}},
+		{models.CreatesFirst, []int{1, 2, 4, 0, 3}},
+		{models.DeletesFirst, []int{0, 3, 1, 2, 4}},
+	} {
+		var got []int
+		for _, c := range orderCorrections(corrections, ix, tst.order) {
+			for i := range corrections {
+				if c == corrections[i] {
+					got = append(got, i)
+				}
+			}
+		}
+		if !reflect.DeepEqual(got, tst.want) {
+			t.Errorf("%q: expected %v, got %v", tst.order, tst.want, got)
+		}
+	}
+}
+
+func TestOnlyDeletions(t *testing.T) {
+	ix := newChangeIndex(testChanges(t,
+		[]string{"old A 1.1.1.2", "a A 1.1.1.3", "c A 1.1.1.4"},
+		[]string{"www A 1.1.1.1", "b A 1.1.1.3"}))
+	var corrections []*models.Correction
+	for _, msg := range []string{
+		"CREATE A www.example.com 1.1.1.1 ttl=300",
+		"DELETE A old.example.com 1.1.1.2 ttl=300",
+		"Update nameservers ns1.example.net -> ns2.example.net",
+		"BATCH:\nDELETE A a.example.com 1.1.1.3 ttl=300\nCREATE A b.example.com 1.1.1.3 ttl=300\nDELETE A c.example.com 1.1.1.4 ttl=300\n",
+	} {
+		corrections = append(corrections, &models.Correction{Msg: msg})
+	}
+	var got []string
+	for _, c := range onlyDeletions(corrections, ix) {
+		got = append(got, c.Msg)
+	}
+	want := []string{
+		"DELETE A old.example.com 1.1.1.2 ttl=300",
+		"DELETE A a.example.com 1.1.1.3 ttl=300\nDELETE A c.example.com 1.1.1.4 ttl=300",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if n := countDeletions(corrections, ix); n != 3 {
+		t.Errorf("expected 3 deletions, got %d", n)
+	}
+	// A message that only looks like a change is not one.
+	if n := countDeletions([]*models.Correction{{Msg: "DELETE record: www A 300 1.1.1.1 (id=1)"}}, ix); n != 0 {
+		t.Errorf("expected no deletions, got %d", n)
+	}
+}
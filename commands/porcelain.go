@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+)
+
+// porcelainPrinter implements preview -porcelain, a format for scripts that
+// stays the same from one version to the next. Each change is a line of five
+// fields separated by tabs:
+//
+//	ACTION	DOMAIN	FQDN	TYPE	VALUE
+//
+// ACTION is CREATE, MODIFY, DELETE or OTHER. VALUE is the rest of the
+// description of the change: the target and TTL of the record, or for MODIFY
+// "(old) -> (new)". OTHER is a change that is not to one record, such as of
+// the nameservers at the registrar; its FQDN and TYPE are "-" and VALUE is
+// its description. Fields never contain tabs. Fields may be added at the
+// end, but existing ones don't change.
+//
+// The changes to records are taken from the diff of the provider, not from
+// its corrections, whose messages each provider writes its own way. The
+// corrections of providers that don't use the diff package are OTHER lines.
+//
+// Only these lines are written to w. Everything else goes to the printer
+// it wraps.
+type porcelainPrinter struct {
+	printer.CLI
+	w      io.Writer
+	domain string
+	// described is true once the changes of the current provider were
+	// printed from its diff, so that its corrections are not printed too.
+	described bool
+}
+
+// changesPrinter is a printer that prints the changes the diff of a provider
+// found, before its corrections are printed.
+type changesPrinter interface {
+	PrintChanges(changes []diff.Correlation, deletesOnly bool)
+}
+
+func newPorcelainPrinter(out printer.CLI, w io.Writer) *porcelainPrinter {
+	return &porcelainPrinter{CLI: out, w: w}
+}
+
+// StartDomain implements printer.CLI.
+func (p *porcelainPrinter) StartDomain(domain string) {
+	p.domain = domain
+	p.CLI.StartDomain(domain)
+}
+
+// StartDNSProvider implements printer.CLI.
+func (p *porcelainPrinter) StartDNSProvider(name string, skip bool) {
+	p.described = false
+	p.CLI.StartDNSProvider(name, skip)
+}
+
+// StartRegistrar implements printer.CLI.
+func (p *porcelainPrinter) StartRegistrar(name string, skip bool) {
+	p.described = false
+	p.CLI.StartRegistrar(name, skip)
+}
+
+// PrintChanges implements changesPrinter. With deletesOnly
+// (-deletes-only) only the deletions are printed.
+func (p *porcelainPrinter) PrintChanges(changes []diff.Correlation, deletesOnly bool) {
+	for _, c := range changes {
+		action, rType, fqdn, value := c.Fields()
+		if !deletesOnly || action == "DELETE" {
+			p.printLine(action, p.domain, fqdn, rType, value)
+		}
+	}
+	p.described = len(changes) > 0
+}
+
+// PrintCorrection implements printer.CLI.
+func (p *porcelainPrinter) PrintCorrection(n int, c *models.Correction) {
+	p.CLI.PrintCorrection(n, c)
+	if p.described {
+		return
+	}
+	for _, line := range strings.Split(c.Msg, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			p.printLine("OTHER", p.domain, "-", "-", line)
+		}
+	}
+}
+
+func (p *porcelainPrinter) printLine(fields ...string) {
+	clean := strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+	for i, f := range fields {
+		fields[i] = clean.Replace(f)
+	}
+	fmt.Fprintln(p.w, strings.Join(fields, "\t"))
+}
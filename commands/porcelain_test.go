@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+)
+
+// msgProvider serves a zone of an A and a TXT record, and describes its
+// corrections the way a real provider does.
+type msgProvider struct {
+	msg func(c diff.Correlation) string
+}
+
+func (msgProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	return nil, nil
+}
+
+func (p msgProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	a := &models.RecordConfig{Type: "A", TTL: 300}
+	a.SetLabel("www", dc.Name)
+	a.SetTarget("1.1.1.1")
+	txt := &models.RecordConfig{Type: "TXT", TTL: 300}
+	txt.SetLabel("@", dc.Name)
+	txt.SetTargetTXT("v=spf1 -all")
+	_, create, del, mod := diff.New(dc).IncrementalDiff([]*models.RecordConfig{a, txt})
+	var corrections []*models.Correction
+	for _, changes := range []diff.Changeset{create, del, mod} {
+		for _, c := range changes {
+			corrections = append(corrections, &models.Correction{Msg: p.msg(c), F: func() error { return nil }})
+		}
+	}
+	return corrections, nil
+}
+
+func init() {
+	// The messages of providers/cloudflare/rest.go.
+	providers.RegisterDomainServiceProviderType("FAKE-CFMSG", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return msgProvider{func(c diff.Correlation) string {
+			switch {
+			case c.Existing == nil:
+				r := c.Desired
+				return fmt.Sprintf("CREATE record: %s %s %d %s", r.GetLabel(), r.Type, r.TTL, r.GetTargetField())
+			case c.Desired == nil:
+				r := c.Existing
+				return fmt.Sprintf("DELETE record: %s %s %d %s (id=%s)", r.GetLabelFQDN(), r.Type, r.TTL, r.GetTargetField(), "023e105f4ecef8ad9ca31a8372d0c353")
+			}
+			return c.String()
+		}}, nil
+	})
+	// The messages of providers/activedir/domains.go.
+	providers.RegisterDomainServiceProviderType("FAKE-ADMSG", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return msgProvider{func(c diff.Correlation) string {
+			switch {
+			case c.Existing == nil:
+				r := c.Desired
+				return fmt.Sprintf("CREATE record: %s %s ttl(%d) %s", r.GetLabel(), r.Type, r.TTL, r.GetTargetField())
+			case c.Desired == nil:
+				r := c.Existing
+				return fmt.Sprintf("DELETE record: %s %s ttl(%d) %s", r.GetLabel(), r.Type, r.TTL, r.GetTargetField())
+			}
+			return c.String()
+		}}, nil
+	})
+}
+
+func TestPorcelainPrinter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "porcelain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	want := []string{
+		"CREATE\texample.com\tnew.example.com\tA\t3.3.3.3 ttl=300",
+		"DELETE\texample.com\texample.com\tTXT\t\"v=spf1 -all\" ttl=300",
+		"MODIFY\texample.com\twww.example.com\tA\t(1.1.1.1 ttl=300) -> (2.2.2.2 ttl=300)",
+	}
+	for _, tst := range []struct {
+		provider string
+		want     []string
+	}{
+		{"FAKE-CFMSG", want},
+		{"FAKE-ADMSG", want},
+		// Without a diff, the corrections are all there is.
+		{"FAKE-PUSH", []string{"OTHER\texample.com\t-\t-\tCREATE A www.example.com"}},
+	} {
+		jsFile := filepath.Join(dir, "dnsconfig.js")
+		err := ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("p", "`+tst.provider+`")),
+	A("www", "2.2.2.2"),
+	A("new", "3.3.3.3")
+);`), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		args := PushArgs{}
+		args.JSFile = jsFile
+		args.CredsFile = filepath.Join(dir, "creds.json")
+		var msgs []string
+		var buf bytes.Buffer
+		if err := run(args, false, newPorcelainPrinter(msgPrinter{msgs: &msgs}, &buf)); err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); !reflect.DeepEqual(lines, tst.want) {
+			t.Errorf("%s: expected:\n%q\ngot:\n%q", tst.provider, tst.want, lines)
+		}
+	}
+
+	var msgs []string
+	var buf bytes.Buffer
+	p := newPorcelainPrinter(msgPrinter{msgs: &msgs}, &buf)
+	p.StartDomain("example.com")
+	p.StartRegistrar("reg", false)
+	p.PrintCorrection(0, &models.Correction{Msg: "Update nameservers ns1.example.net -> ns2.example.net\n\tns3.example.net\n"})
+	wantLines := "OTHER\texample.com\t-\t-\tUpdate nameservers ns1.example.net -> ns2.example.net\n" +
+		"OTHER\texample.com\t-\t-\tns3.example.net\n"
+	if buf.String() != wantLines {
+		t.Errorf("Expected:\n%s\ngot:\n%s", wantLines, buf.String())
+	}
+}
+
+func TestPreviewPorcelain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "porcelain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("diff", "FAKE-DIFF")),
+	A("www", "2.2.2.2"),
+	MX("@", 10, "mx.example.net.")
+);`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := PushArgs{}
+	args.JSFile = jsFile
+	args.CredsFile = filepath.Join(dir, "creds.json")
+	var msgs []string
+	var buf bytes.Buffer
+	if err := run(args, false, newPorcelainPrinter(msgPrinter{msgs: &msgs}, &buf)); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"DELETE\texample.com\texample.com\tTXT\t\"v=spf1 -all\" ttl=300",
+		"MODIFY\texample.com\twww.example.com\tA\t(1.1.1.1 ttl=300) -> (2.2.2.2 ttl=300)",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("Expected:\n%q\ngot:\n%q", want, lines)
+	}
+
+	preview := PreviewArgs{Porcelain: true, Summary: true}
+	if err := Preview(preview); err == nil {
+		t.Errorf("Expected -porcelain -summary to be an error")
+	}
+}
@@ -4,14 +4,19 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/changelog"
+	"github.com/StackExchange/dnscontrol/pkg/metrics"
 	"github.com/StackExchange/dnscontrol/pkg/nameservers"
-	"github.com/StackExchange/dnscontrol/pkg/normalize"
 	"github.com/StackExchange/dnscontrol/pkg/notifications"
 	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/StackExchange/dnscontrol/pkg/snapshot"
 	"github.com/StackExchange/dnscontrol/providers"
 	"github.com/StackExchange/dnscontrol/providers/config"
+	"github.com/StackExchange/dnscontrol/providers/diff"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli"
 )
@@ -33,18 +38,114 @@ type PreviewArgs struct {
 	GetDNSConfigArgs
 	GetCredentialsArgs
 	FilterArgs
-	Notify bool
+	ValidateArgs
+	Notify          bool
+	GroupBy         string
+	Only            string
+	Color           string
+	SinceGit        string
+	Failover        bool
+	Types           string
+	TTLFormat       string
+	Summary         bool
+	DeletesOnly     bool
+	MaxDeletes      int
+	ConsistentSOA   bool
+	ProviderTimeout time.Duration
+	GitHubActions   bool
+	Porcelain       bool
+	ProtectedState  string
 }
 
 func (args *PreviewArgs) flags() []cli.Flag {
 	flags := args.GetDNSConfigArgs.flags()
 	flags = append(flags, args.GetCredentialsArgs.flags()...)
 	flags = append(flags, args.FilterArgs.flags()...)
+	flags = append(flags, args.ValidateArgs.flags()...)
 	flags = append(flags, cli.BoolFlag{
 		Name:        "notify",
 		Destination: &args.Notify,
 		Usage:       `set to true to send notifications to configured destinations`,
 	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "group-by",
+		Destination: &args.GroupBy,
+		Value:       "domain",
+		Usage:       `Group preview output by domain, provider or type (of change)`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "only",
+		Destination: &args.Only,
+		Usage:       `Only make changes to this record (FQDN[:TYPE]). All other records are left alone`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "types",
+		Destination: &args.Types,
+		Usage:       `Only make changes to records of these types (comma separated list). All other records are left alone`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "color",
+		Destination: &args.Color,
+		Value:       "auto",
+		Usage:       `Color the output: auto (terminals only, unless NO_COLOR is set), always or never`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "since-git",
+		Destination: &args.SinceGit,
+		Usage:       `Only process domains whose configuration changed since this git ref`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "provider-failover",
+		Destination: &args.Failover,
+		Usage:       `When reading a domain from one of its DNS providers fails, warn and go on with its other providers`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "ttl-format",
+		Destination: &args.TTLFormat,
+		Value:       diff.TTLSeconds,
+		Usage:       `Show TTLs as seconds (3600), human (1h) or both (3600 (1h))`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "summary",
+		Destination: &args.Summary,
+		Usage:       `Only print the number of changes of each domain, and exit with 2 if there are any`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "deletes-only",
+		Destination: &args.DeletesOnly,
+		Usage:       `Only show the changes that delete records, to review them before a push`,
+	})
+	flags = append(flags, cli.IntFlag{
+		Name:        "max-deletes",
+		Destination: &args.MaxDeletes,
+		Usage:       `Don't change a domain at a DNS provider if that deletes more than this many records (0 is no limit)`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "require-consistent-soa",
+		Destination: &args.ConsistentSOA,
+		Usage:       `Check that all the DNS providers of a domain serve the same SOA refresh, retry, expire and minimum. push skips the domains where they differ`,
+	})
+	flags = append(flags, cli.DurationFlag{
+		Name:        "provider-timeout",
+		Destination: &args.ProviderTimeout,
+		Usage:       "Give up on a provider that takes longer than this to read or change a domain, and fail the domain there (0 waits forever)",
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "github-actions",
+		Destination: &args.GitHubActions,
+		Usage:       `Also print the changes as GitHub Actions annotations, and list them in the job summary ($GITHUB_STEP_SUMMARY)`,
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "porcelain",
+		Destination: &args.Porcelain,
+		Usage:       `Print one line per change, in a format for scripts that doesn't change between versions. The usual output goes to stderr, without colors`,
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "protected-state",
+		Destination: &args.ProtectedState,
+		Value:       "protected.json",
+		Usage:       `File where push notes the PROTECTED() records, so that they stay protected once removed from the configuration ("" to not use one)`,
+	})
 	return flags
 }
 
@@ -63,7 +164,18 @@ var _ = cmd(catMain, func() *cli.Command {
 // PushArgs contains all data/flags needed to run push, independently of CLI
 type PushArgs struct {
 	PreviewArgs
-	Interactive bool
+	Interactive           bool
+	Backup                string
+	MetricsFile           string
+	Changelog             string
+	AllowEmptyZone        bool
+	AllowProtectedChanges bool
+	FailFast              bool
+	ShowIDs               bool
+	PreHook               string
+	PostHook              string
+	HookTimeout           time.Duration
+	VerifyAfter           time.Duration
 }
 
 func (args *PushArgs) flags() []cli.Flag {
@@ -73,69 +185,392 @@ func (args *PushArgs) flags() []cli.Flag {
 		Destination: &args.Interactive,
 		Usage:       "Interactive. Confirm or Exclude each correction before they run",
 	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "backup",
+		Destination: &args.Backup,
+		Usage:       "Before changing a zone, write the records currently at the provider to a timestamped JSON file in this directory",
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "metrics-file",
+		Destination: &args.MetricsFile,
+		Usage:       "Write Prometheus metrics about the run to this file (for node_exporter's textfile collector)",
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "changelog",
+		Destination: &args.Changelog,
+		Usage:       "Append the changes made to this file, as markdown, or as JSON lines if it ends in .json",
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "allow-empty-zone",
+		Destination: &args.AllowEmptyZone,
+		Usage:       "Allow changes that delete every record of a zone",
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "allow-protected-changes",
+		Destination: &args.AllowProtectedChanges,
+		Usage:       "Allow changes to PROTECTED() records",
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "fail-fast",
+		Destination: &args.FailFast,
+		Usage:       "Stop after the first domain with errors. By default the other domains are still pushed",
+	})
+	flags = append(flags, cli.BoolFlag{
+		Name:        "show-ids",
+		Destination: &args.ShowIDs,
+		Usage:       "Print the ID the provider gave each change, for providers that return one (to find it in their audit logs)",
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "pre-hook",
+		Destination: &args.PreHook,
+		Usage:       "Shell command to run before changing a domain at a provider. If it fails, the domain is not changed there. It gets DNSCONTROL_DOMAIN, DNSCONTROL_PROVIDER and DNSCONTROL_CORRECTIONS in its environment",
+	})
+	flags = append(flags, cli.StringFlag{
+		Name:        "post-hook",
+		Destination: &args.PostHook,
+		Usage:       "Shell command to run after changing a domain at a provider. It gets the same environment as -pre-hook, and DNSCONTROL_RESULT (ok or failed)",
+	})
+	flags = append(flags, cli.DurationFlag{
+		Name:        "hook-timeout",
+		Destination: &args.HookTimeout,
+		Value:       defaultHookTimeout,
+		Usage:       "How long -pre-hook and -post-hook may run before they are killed",
+	})
+	flags = append(flags, cli.DurationFlag{
+		Name:        "verify-after",
+		Destination: &args.VerifyAfter,
+		Usage:       "After changing a domain at a provider, wait up to this long for the provider's nameservers to serve the new records, and list which are propagated or pending",
+	})
 	return flags
 }
 
+// console returns the console printer, colored according to -color.
+func (args *PreviewArgs) console() (printer.CLI, error) {
+	color, err := printer.UseColor(args.Color, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	return printer.ConsolePrinter{Color: color}, nil
+}
+
 // Preview implements the preview subcommand.
 func Preview(args PreviewArgs) error {
-	return run(args, false, false, printer.ConsolePrinter{})
+	out, err := args.console()
+	if err != nil {
+		return err
+	}
+	if args.Porcelain {
+		if args.Summary || args.GitHubActions || (args.GroupBy != "" && args.GroupBy != "domain") {
+			return errors.Errorf("-porcelain can't be used with -summary, -github-actions or -group-by")
+		}
+		// Whatever else dnscontrol prints goes to stderr, so that stdout
+		// only has the porcelain lines.
+		return run(PushArgs{PreviewArgs: args}, false, newPorcelainPrinter(printer.ConsolePrinter{W: os.Stderr}, os.Stdout))
+	}
+	if args.GitHubActions {
+		if args.Summary {
+			return errors.Errorf("-github-actions can't be used with -summary")
+		}
+		actions := newActionsPrinter(out, os.Stdout)
+		err := run(PushArgs{PreviewArgs: args}, false, actions)
+		if serr := actions.writeSummary(os.Getenv("GITHUB_STEP_SUMMARY")); serr != nil && err == nil {
+			err = serr
+		}
+		return err
+	}
+	if !args.Summary {
+		return run(PushArgs{PreviewArgs: args}, false, out)
+	}
+	summary := newSummaryPrinter(out)
+	if err := run(PushArgs{PreviewArgs: args}, false, summary); err != nil {
+		return err
+	}
+	if summary.print().total() > 0 {
+		return errPendingChanges
+	}
+	return nil
 }
 
 // Push implements the push subcommand.
 func Push(args PushArgs) error {
-	return run(args.PreviewArgs, true, args.Interactive, printer.ConsolePrinter{})
+	out, err := args.console()
+	if err != nil {
+		return err
+	}
+	return run(args, true, out)
 }
 
 // run is the main routine common to preview/push
-func run(args PreviewArgs, push bool, interactive bool, out printer.CLI) error {
+// The push-only fields of args are ignored unless push is true.
+func run(args PushArgs, push bool, out printer.CLI) (runErr error) {
 	// TODO: make truly CLI independent. Perhaps return results on a channel as they occur
+	switch args.GroupBy {
+	case "", "domain":
+	case "provider", "type":
+		if push {
+			return errors.Errorf("-group-by=%s is only supported by preview", args.GroupBy)
+		}
+	default:
+		return errors.Errorf("Invalid -group-by value %q (must be domain, provider or type)", args.GroupBy)
+	}
+	if args.Summary && push {
+		return errors.Errorf("-summary is only supported by preview")
+	}
+	if args.DeletesOnly && push {
+		return errors.Errorf("-deletes-only is only supported by preview")
+	}
+	if args.GitHubActions && push {
+		return errors.Errorf("-github-actions is only supported by preview")
+	}
+	if args.Porcelain && push {
+		return errors.Errorf("-porcelain is only supported by preview")
+	}
+	if args.Summary && args.GroupBy != "" && args.GroupBy != "domain" {
+		return errors.Errorf("-summary counts the changes by domain, it can't be used with -group-by=%s", args.GroupBy)
+	}
+	switch args.TTLFormat {
+	case "", diff.TTLSeconds, diff.TTLHuman, diff.TTLBoth:
+		if args.TTLFormat != "" {
+			diff.TTLFormat = args.TTLFormat
+			defer func() { diff.TTLFormat = diff.TTLSeconds }()
+		}
+	default:
+		return errors.Errorf("Invalid -ttl-format value %q (must be seconds, human or both)", args.TTLFormat)
+	}
 	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
 	if err != nil {
 		return err
 	}
-	errs := normalize.NormalizeAndValidateConfig(cfg)
-	if PrintValidationErrors(errs) {
+	// Compared before normalization, which changes the domains.
+	var changed map[string]bool
+	if args.SinceGit != "" {
+		if changed, err = changedDomains(args.GetDNSConfigArgs, args.SinceGit, cfg, out); err != nil {
+			return err
+		}
+		if changed != nil {
+			out.Debugf("%d of %d domains changed since %s\n", len(changed), len(cfg.Domains), args.SinceGit)
+		}
+	}
+	res := args.validate(cfg)
+	if PrintValidationErrors(res, args.Strict) {
 		return errors.Errorf("Exiting due to validation errors")
 	}
+	grouped := &correctionGroups{by: args.GroupBy}
+	onlyDomain, err := applyOnly(cfg, args.Only)
+	if err != nil {
+		return err
+	}
+	applyTypes(cfg, args.Types)
+	protected, err := loadProtectedState(args.ProtectedState)
+	if err != nil {
+		return err
+	}
+	for _, d := range cfg.Domains {
+		d.AllowProtectedChanges = args.AllowProtectedChanges
+		d.Protected = protected[d.Name]
+	}
+	// -only and -types leave records alone, which providers that write whole zones can't do.
+	limited, limitFlag := onlyDomain != nil || args.Types != "", "-only"
+	if onlyDomain == nil {
+		limitFlag = "-types"
+	}
 	// TODO:
-	notifier, err := InitializeProviders(args.CredsFile, cfg, args.Notify)
+	notifier, err := InitializeProviders(args.GetCredentialsArgs, cfg, args.Notify)
 	if err != nil {
 		return err
 	}
+	var runMetrics *metrics.Run
+	if push && args.MetricsFile != "" {
+		runMetrics = metrics.New()
+		notifier = metricsNotifier{notifier, runMetrics}
+		defer func() {
+			if err := runMetrics.WriteFile(args.MetricsFile); err != nil {
+				out.Warnf("Writing metrics: %s\n", err)
+				if runErr == nil {
+					runErr = errors.Errorf("Completed with errors")
+				}
+			}
+		}()
+	}
+	var runChangelog *changelog.Run
+	if push && args.Changelog != "" {
+		commit, err := gitHead(args.GetDNSConfigArgs)
+		if err != nil {
+			out.Debugf("No git commit for the changelog: %s\n", err)
+		}
+		runChangelog = changelog.New(time.Now(), commit)
+		notifier = changelogNotifier{notifier, runChangelog}
+	}
 	anyErrors := false
+	results := &domainResults{metrics: runMetrics}
 	totalCorrections := 0
+	backupDir := ""
+	if push {
+		backupDir = args.Backup
+	}
+	late := &lateCorrections{}
+	// The record sets -verify-after checked.
+	var propagated []propagation
+	stale := &staleWindow{}
 DomainLoop:
 	for _, domain := range cfg.Domains {
-		if !args.shouldRunDomain(domain.Name) {
+		if !args.shouldRunDomain(domain.Name) || (onlyDomain != nil && domain != onlyDomain) {
+			continue
+		}
+		if changed != nil && !changed[domain.Name] {
 			continue
 		}
+		if args.FailFast && results.failed > 0 {
+			results.skip(domain.Name)
+			continue
+		}
+		results.start(domain.Name)
 		out.StartDomain(domain.Name)
+		for _, rec := range domain.Records {
+			if flat := rec.Metadata["spf_flattened"]; flat != "" {
+				out.Debugf("SPF %s: flattened includes %s\n", rec.GetLabelFQDN(), flat)
+			}
+		}
 		nsList, err := nameservers.DetermineNameservers(domain)
 		if err != nil {
-			return err
+			out.Warnf("Getting nameservers for %s: %s\n", domain.Name, err)
+			results.fail("")
+			continue
 		}
 		domain.Nameservers = nsList
 		nameservers.AddNSRecords(domain)
+		if args.ConsistentSOA {
+			problems, err := checkSOA(domain)
+			if err != nil {
+				problems = []string{err.Error()}
+			}
+			for _, p := range problems {
+				out.Warnf("SOA of %s: %s\n", domain.Name, p)
+			}
+			if len(problems) > 0 {
+				if push {
+					out.Warnf("Not changing %s: its DNS providers don't serve the same SOA (-require-consistent-soa)\n", domain.Name)
+					results.fail("")
+					continue
+				}
+				results.warn()
+			}
+		}
+		readFailures := 0
 		for _, provider := range domain.DNSProviderInstances {
 			dc, err := domain.Copy()
 			if err != nil {
 				return err
 			}
+			if err := providers.PrepareDomain(provider.ProviderType, dc); err != nil {
+				return err
+			}
 			shouldrun := args.shouldRunProvider(provider.Name, dc)
 			out.StartDNSProvider(provider.Name, !shouldrun)
 			if !shouldrun {
 				continue
 			}
-			corrections, err := provider.Driver.GetDomainCorrections(dc)
+			if limited && providers.ProviderWritesWholeZone(provider.ProviderType) {
+				out.EndProvider(0, errors.Errorf("%s is not supported by %s (%s), it always writes the whole zone", limitFlag, provider.Name, provider.ProviderType))
+				results.fail(provider.Name)
+				continue
+			}
+			corrections, collector, err := diffCorrections(args.ProviderTimeout, provider.Driver, dc)
+			existing, blocked, purged, changes := collector.Existing, collector.Blocked, collector.Purged, collector.Changes
+			index := newChangeIndex(changes)
+			corrections = orderCorrections(corrections, index, domain.Metadata[models.MetaCorrectionOrder])
 			out.EndProvider(len(corrections), err)
 			if err != nil {
-				anyErrors = true
+				// With -provider-failover, the domain only fails if none of its providers can be read.
+				if readFailures++; args.Failover && readFailures < len(domain.DNSProviderInstances) {
+					out.Warnf("Could not read %s from %s, going on with its other providers\n", domain.Name, provider.Name)
+					results.degrade()
+					continue
+				}
+				results.fail(provider.Name)
 				continue DomainLoop
 			}
+			if len(blocked) > 0 {
+				for _, c := range blocked {
+					out.Warnf("BLOCKED %s: the record is PROTECTED()\n", c)
+				}
+				if push && len(corrections) > 0 && providers.ProviderWritesWholeZone(provider.ProviderType) {
+					out.Warnf("Not changing %s at %s: it writes the whole zone, so it can't leave PROTECTED() records alone. Use -allow-protected-changes if this is intended\n", domain.Name, provider.Name)
+					results.fail(provider.Name)
+					continue
+				}
+				if !push {
+					out.Warnf("push won't make the BLOCKED changes without -allow-protected-changes\n")
+				}
+				results.warn()
+			}
+			if len(purged) > 0 {
+				out.Warnf("PURGE %d records of %s at %s, which the configuration doesn't declare (NO_PURGE or IGNORE() would keep them):\n", len(purged), domain.Name, provider.Name)
+				for _, c := range purged {
+					out.Warnf("    %s\n", c)
+				}
+			}
+			if msg := nsDrift(domain, existing, changes); msg != "" && !limited {
+				out.Warnf("NS change for %s at %s: %s\n", domain.Name, provider.Name, msg)
+			}
+			if n := countDeletions(corrections, index); args.MaxDeletes > 0 && n > args.MaxDeletes {
+				if !push {
+					out.Warnf("These changes delete %d records of %s at %s, more than -max-deletes=%d. push won't make them\n", n, domain.Name, provider.Name, args.MaxDeletes)
+				} else {
+					out.Warnf("Not changing %s at %s: it would delete %d records, more than -max-deletes=%d\n", domain.Name, provider.Name, n, args.MaxDeletes)
+					results.fail(provider.Name)
+					continue
+				}
+			}
+			if len(corrections) > 0 && diff.EmptiesZone(domain, existing) {
+				if !push {
+					out.Warnf("These changes delete every record of %s at %s. push needs -allow-empty-zone to make them\n", domain.Name, provider.Name)
+				} else if !args.AllowEmptyZone {
+					out.Warnf("Not changing %s at %s: it would delete every record of the zone. Use -allow-empty-zone if this is intended\n", domain.Name, provider.Name)
+					results.fail(provider.Name)
+					continue
+				} else if emptier, ok := provider.Driver.(providers.ZoneEmptier); ok && len(domain.IgnoredLabels) == 0 {
+					corrections = emptyZoneCorrections(dc, provider, emptier)
+				}
+			}
+			if backupDir != "" && len(corrections) > 0 {
+				if err := writeBackup(backupDir, domain.Name, provider, existing, out); err != nil {
+					out.Warnf("Not changing %s at %s: %s\n", domain.Name, provider.Name, err)
+					results.fail(provider.Name)
+					continue
+				}
+			}
+			if args.DeletesOnly {
+				corrections = onlyDeletions(corrections, index)
+			}
+			timeCorrections(args.ProviderTimeout, corrections, late, domain.Name+" at "+provider.Name)
 			totalCorrections += len(corrections)
-			anyErrors = printOrRunCorrections(domain.Name, provider.Name, corrections, out, push, interactive, notifier) || anyErrors
+			if len(corrections) > 0 {
+				stale.add(changes, args.DeletesOnly)
+				if p, ok := out.(changesPrinter); ok {
+					p.PrintChanges(changes, args.DeletesOnly)
+				}
+			}
+			if grouped.collect(domain.Name, provider.Name, corrections, index) {
+				continue
+			}
+			if args.applyCorrections(domain.Name, provider.Name, corrections, out, push, notifier) {
+				results.fail(provider.Name)
+				continue
+			}
+			if push && args.VerifyAfter > 0 && len(corrections) > 0 {
+				checked, err := verifyPropagation(domain.Name, provider, changedSets(dc, existing, blocked), args.VerifyAfter)
+				if err != nil {
+					out.Warnf("Can't verify the changes to %s at %s: %s\n", domain.Name, provider.Name, err)
+				}
+				propagated = append(propagated, checked...)
+			}
 		}
-		run := args.shouldRunProvider(domain.RegistrarName, domain)
+		if push && !results.failing() {
+			protected.pushed(domain)
+		}
+		// Nameservers are not a record, -only and -types leave them alone.
+		run := args.shouldRunProvider(domain.RegistrarName, domain) && !limited
 		out.StartRegistrar(domain.RegistrarName, !run)
 		if !run {
 			continue
@@ -148,35 +583,293 @@ DomainLoop:
 		if err != nil {
 			log.Fatal(err)
 		}
-		corrections, err := domain.RegistrarInstance.Driver.GetRegistrarCorrections(dc)
+		registrar := domain.RegistrarInstance.Driver
+		corrections, err := getCorrections(args.ProviderTimeout, func() ([]*models.Correction, error) { return registrar.GetRegistrarCorrections(dc) })
 		out.EndProvider(len(corrections), err)
 		if err != nil {
-			anyErrors = true
+			results.fail(domain.RegistrarName)
 			continue
 		}
+		if args.DeletesOnly {
+			corrections = onlyDeletions(corrections, nil)
+		}
+		timeCorrections(args.ProviderTimeout, corrections, late, domain.Name+" at "+domain.RegistrarName)
 		totalCorrections += len(corrections)
-		anyErrors = printOrRunCorrections(domain.Name, domain.RegistrarName, corrections, out, push, interactive, notifier) || anyErrors
+		if grouped.collect(domain.Name, domain.RegistrarName, corrections, nil) {
+			continue
+		}
+		if args.applyCorrections(domain.Name, domain.RegistrarName, corrections, out, push, notifier) {
+			results.fail(domain.RegistrarName)
+		}
 	}
+	grouped.print(out, notifier)
 	if os.Getenv("TEAMCITY_VERSION") != "" {
 		fmt.Fprintf(os.Stderr, "##teamcity[buildStatus status='SUCCESS' text='%d corrections']", totalCorrections)
 	}
 	notifier.Done()
+	if push {
+		if err := protected.save(args.ProtectedState); err != nil {
+			out.Warnf("Writing %s: %s\n", args.ProtectedState, err)
+			anyErrors = true
+		}
+	}
+	if err := runChangelog.AppendFile(args.Changelog); err != nil {
+		out.Warnf("Writing changelog: %s\n", err)
+		anyErrors = true
+	}
 	out.Debugf("Done. %d corrections.\n", totalCorrections)
-	if anyErrors {
+	if !push {
+		stale.print(out)
+	}
+	printPropagation(out, propagated)
+	late.print(out)
+	results.print(out)
+	if anyErrors || results.failed > 0 {
 		return errors.Errorf("Completed with errors")
 	}
 	return nil
 }
 
-// InitializeProviders takes a creds file path and a DNSConfig object. Creates all providers with the proper types, and returns them.
+// domainResults remembers which domains had errors, for the summary at the
+// end of a run.
+type domainResults struct {
+	names, status            []string
+	failed, degraded, warned int
+	metrics                  *metrics.Run
+}
+
+// start notes that domain is being worked on. Errors are counted against it
+// until the next domain starts.
+func (r *domainResults) start(domain string) {
+	r.names = append(r.names, domain)
+	r.status = append(r.status, "ok")
+}
+
+// fail marks the current domain as failed, and counts the failure of
+// provider in the -metrics-file. provider is empty if the domain failed
+// before any of its providers was tried.
+func (r *domainResults) fail(provider string) {
+	r.metrics.Failed(r.names[len(r.names)-1], provider)
+	if i := len(r.status) - 1; r.status[i] != "FAILED" {
+		r.uncount(r.status[i])
+		r.status[i] = "FAILED"
+		r.failed++
+	}
+}
+
+// uncount stops counting a domain with status, when it gets a worse one.
+func (r *domainResults) uncount(status string) {
+	switch status {
+	case "degraded":
+		r.degraded--
+	case "warning":
+		r.warned--
+	}
+}
+
+// failing returns true if the current domain failed.
+func (r *domainResults) failing() bool {
+	return r.status[len(r.status)-1] == "FAILED"
+}
+
+// degrade marks the current domain as having had a provider fail, with the
+// others still working (-provider-failover).
+func (r *domainResults) degrade() {
+	if i := len(r.status) - 1; r.status[i] == "ok" || r.status[i] == "warning" {
+		r.uncount(r.status[i])
+		r.status[i] = "degraded"
+		r.degraded++
+	}
+}
+
+// warn marks the current domain as having had warnings that didn't stop it,
+// such as BLOCKED changes or an inconsistent SOA.
+func (r *domainResults) warn() {
+	if i := len(r.status) - 1; r.status[i] == "ok" {
+		r.status[i] = "warning"
+		r.warned++
+	}
+}
+
+// skip notes that domain was not worked on because of -fail-fast.
+func (r *domainResults) skip(domain string) {
+	r.names = append(r.names, domain)
+	r.status = append(r.status, "skipped")
+}
+
+// print prints the status of every domain, if any had errors.
+func (r *domainResults) print(out printer.CLI) {
+	if r.failed == 0 && r.degraded == 0 && r.warned == 0 {
+		return
+	}
+	msg := fmt.Sprintf("%d of %d domains had errors", r.failed, len(r.names))
+	if r.degraded > 0 {
+		msg += fmt.Sprintf(", %d had a provider fail", r.degraded)
+	}
+	if r.warned > 0 {
+		msg += fmt.Sprintf(", %d had warnings", r.warned)
+	}
+	out.Warnf("%s:\n", msg)
+	for i, name := range r.names {
+		out.Debugf("  %-7s %s\n", r.status[i], name)
+	}
+}
+
+// metricsNotifier counts the corrections that were applied for -metrics-file.
+// The failures are counted by domainResults.fail.
+type metricsNotifier struct {
+	notifications.Notifier
+	metrics *metrics.Run
+}
+
+func (n metricsNotifier) Notify(domain, provider, message string, err error, preview bool) {
+	if !preview && err == nil {
+		n.metrics.Applied(domain, provider)
+	}
+	n.Notifier.Notify(domain, provider, message, err, preview)
+}
+
+// changelogNotifier records the corrections that were applied for -changelog.
+type changelogNotifier struct {
+	notifications.Notifier
+	changelog *changelog.Run
+}
+
+func (n changelogNotifier) Notify(domain, provider, message string, err error, preview bool) {
+	if !preview && err == nil {
+		n.changelog.Applied(domain, provider, message)
+	}
+	n.Notifier.Notify(domain, provider, message, err, preview)
+}
+
+// nsDrift describes how the changes, those of the diff of a provider, change
+// the apex NS records it serves to the nameservers of the domain, which come
+// from NAMESERVER() and the providers' GetNameservers(). It returns "" if
+// none of the changes is to the apex NS records, or if the provider doesn't
+// report apex NS records at all.
+func nsDrift(domain *models.DomainConfig, existing []*models.RecordConfig, changes diff.Changeset) string {
+	apex := false
+	for _, c := range changes {
+		if _, rType, fqdn, _ := c.Fields(); rType == "NS" && fqdn == domain.Name {
+			apex = true
+		}
+	}
+	if !apex {
+		return ""
+	}
+	missing, extra := nameservers.Drift(domain.Nameservers, existing)
+	if len(extra) == 0 && (len(missing) == 0 || len(missing) == len(domain.Nameservers)) {
+		return ""
+	}
+	list := func(names []string) string {
+		if len(names) == 0 {
+			return "(none)"
+		}
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("adding %s, removing %s", list(missing), list(extra))
+}
+
+// emptyZoneCorrections replaces the corrections that empty a zone with a single
+// bulk delete. Whatever is left to do afterwards, such as changing the apex NS
+// records, is done by asking the provider for corrections again, for a copy
+// of dc, the domain as it was given to the provider. It can't be used for
+// domains with IGNOREd labels, which a bulk delete would take too.
+func emptyZoneCorrections(dc *models.DomainConfig, provider *models.DNSProviderInstance, emptier providers.ZoneEmptier) []*models.Correction {
+	return []*models.Correction{{
+		Msg: fmt.Sprintf("DELETE all records of %s", dc.Name),
+		F: func() error {
+			if err := emptier.EmptyZone(dc.Name); err != nil {
+				return err
+			}
+			rest, err := dc.Copy()
+			if err != nil {
+				return err
+			}
+			corrections, err := provider.Driver.GetDomainCorrections(rest)
+			if err != nil {
+				return err
+			}
+			for _, c := range corrections {
+				if err := c.F(); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}}
+}
+
+// writeBackup writes the records read from provider to a snapshot in dir.
+func writeBackup(dir, domain string, provider *models.DNSProviderInstance, existing []*models.RecordConfig, out printer.CLI) error {
+	if existing == nil {
+		return errors.Errorf("-backup: %s did not report the current records", provider.Name)
+	}
+	filename, err := snapshot.New(domain, provider.Name, provider.ProviderType, existing).Write(dir)
+	if err != nil {
+		return errors.Wrap(err, "-backup")
+	}
+	out.Debugf("Backup of %s at %s written to %s\n", domain, provider.Name, filename)
+	return nil
+}
+
+// applyOnly limits the domain containing the record named by -only
+// (FQDN[:TYPE]) to that record, and returns the domain. It returns nil
+// if -only was not given.
+func applyOnly(cfg *models.DNSConfig, only string) (*models.DomainConfig, error) {
+	if only == "" {
+		return nil, nil
+	}
+	fqdn, rType := only, ""
+	if i := strings.LastIndex(only, ":"); i != -1 {
+		fqdn, rType = only[:i], strings.ToUpper(only[i+1:])
+	}
+	fqdn = strings.ToLower(strings.TrimSuffix(fqdn, "."))
+	// The longest domain name wins, so that sub.example.com is preferred over example.com.
+	var found *models.DomainConfig
+	for _, d := range cfg.Domains {
+		if fqdn == d.Name || strings.HasSuffix(fqdn, "."+d.Name) {
+			if found == nil || len(d.Name) > len(found.Name) {
+				found = d
+			}
+		}
+	}
+	if found == nil {
+		return nil, errors.Errorf("-only %s: no domain in the configuration contains %s", only, fqdn)
+	}
+	found.OnlyLabelFQDN = fqdn
+	found.OnlyType = rType
+	return found, nil
+}
+
+// applyTypes limits all domains to the record types listed in types (-types).
+func applyTypes(cfg *models.DNSConfig, types string) {
+	if types == "" {
+		return
+	}
+	var rTypes []string
+	for _, t := range strings.Split(types, ",") {
+		if t = strings.ToUpper(strings.TrimSpace(t)); t != "" {
+			rTypes = append(rTypes, t)
+		}
+	}
+	for _, d := range cfg.Domains {
+		d.OnlyTypes = rTypes
+	}
+}
+
+// InitializeProviders takes the creds and env files and a DNSConfig object. Creates all providers with the proper types, and returns them.
 // nonDefaultProviders is a list of providers that should not be run unless explicitly asked for by flags.
-func InitializeProviders(credsFile string, cfg *models.DNSConfig, notifyFlag bool) (notify notifications.Notifier, err error) {
+func InitializeProviders(creds GetCredentialsArgs, cfg *models.DNSConfig, notifyFlag bool) (notify notifications.Notifier, err error) {
 	var providerConfigs map[string]map[string]string
 	var notificationCfg map[string]string
 	defer func() {
 		notify = notifications.Init(notificationCfg)
 	}()
-	providerConfigs, err = config.LoadProviderConfigs(credsFile)
+	if err = creds.loadEnv(); err != nil {
+		return
+	}
+	providerConfigs, err = config.LoadProviderConfigs(creds.CredsFile)
 	if err != nil {
 		return
 	}
@@ -220,7 +913,9 @@ func InitializeProviders(credsFile string, cfg *models.DNSConfig, notifyFlag boo
 	return
 }
 
-func printOrRunCorrections(domain string, provider string, corrections []*models.Correction, out printer.CLI, push bool, interactive bool, notifier notifications.Notifier) (anyErrors bool) {
+// printOrRunCorrections prints the corrections and, if push is set, runs
+// them. With showIDs, the provider's ID of each applied change is printed.
+func printOrRunCorrections(domain string, provider string, corrections []*models.Correction, out printer.CLI, push, interactive, showIDs bool, notifier notifications.Notifier) (anyErrors bool) {
 	anyErrors = false
 	if len(corrections) == 0 {
 		return false
@@ -236,9 +931,67 @@ func printOrRunCorrections(domain string, provider string, corrections []*models
 			out.EndCorrection(err)
 			if err != nil {
 				anyErrors = true
+			} else if showIDs && correction.ID != "" {
+				out.Debugf("ID: %s\n", correction.ID)
 			}
 		}
 		notifier.Notify(domain, provider, correction.Msg, err, !push)
 	}
 	return anyErrors
 }
+
+// groupedCorrection is a correction remembered for grouped output.
+type groupedCorrection struct {
+	domain, provider string
+	correction       *models.Correction
+}
+
+// correctionGroups collects corrections so that preview can print them
+// grouped by provider or by type of change instead of by domain.
+type correctionGroups struct {
+	by     string
+	order  []string
+	groups map[string][]groupedCorrection
+}
+
+// collect remembers corrections for grouped output, with the changes of the
+// diff that tell their type. It returns false if output is not grouped, in
+// which case the corrections should be printed as usual.
+func (g *correctionGroups) collect(domain, provider string, corrections []*models.Correction, ix changeIndex) bool {
+	if g.by == "" || g.by == "domain" {
+		return false
+	}
+	if g.groups == nil {
+		g.groups = map[string][]groupedCorrection{}
+	}
+	for _, c := range corrections {
+		key := provider
+		if g.by == "type" {
+			key = correctionType(c, ix)
+		}
+		if _, ok := g.groups[key]; !ok {
+			g.order = append(g.order, key)
+		}
+		g.groups[key] = append(g.groups[key], groupedCorrection{domain, provider, c})
+	}
+	return true
+}
+
+func (g *correctionGroups) print(out printer.CLI, notifier notifications.Notifier) {
+	for _, key := range g.order {
+		out.Debugf("******************** %s: %s\n", strings.ToUpper(g.by[:1])+g.by[1:], key)
+		for i, gc := range g.groups[key] {
+			out.Debugf("#%d: [%s %s] %s\n", i+1, gc.domain, gc.provider, gc.correction.Msg)
+			notifier.Notify(gc.domain, gc.provider, gc.correction.Msg, nil, true)
+		}
+	}
+}
+
+// correctionType returns the kind of the first change c makes, or OTHER if
+// it makes none of the changes of the diff (see changeIndex).
+func correctionType(c *models.Correction, ix changeIndex) string {
+	if kinds := ix.kinds(c); len(kinds) > 0 {
+		return kinds[0]
+	}
+	return "OTHER"
+}
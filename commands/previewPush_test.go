@@ -0,0 +1,1152 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/StackExchange/dnscontrol/providers"
+	_ "github.com/StackExchange/dnscontrol/providers/bind"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+	"github.com/pkg/errors"
+)
+
+// fakeProvider has one correction for every domain. Applying it fails for
+// fail.example.com.
+type fakeProvider struct {
+	applied *[]string
+}
+
+func (f fakeProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	return nil, nil
+}
+
+func (f fakeProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	name := dc.Name
+	return []*models.Correction{{
+		Msg: "CREATE A www." + name,
+		F: func() error {
+			if name == "fail.example.com" {
+				return errors.Errorf("failing on purpose")
+			}
+			*f.applied = append(*f.applied, name)
+			return nil
+		},
+	}}, nil
+}
+
+// brokenProvider can't read any domain.
+type brokenProvider struct{}
+
+func (brokenProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	return nil, nil
+}
+
+func (brokenProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	return nil, errors.Errorf("connection reset by peer")
+}
+
+// diffProvider serves a zone of an A, an MX and a TXT record, and records the
+// changes it is asked to make in applied.
+type diffProvider struct{}
+
+var diffApplied []string
+
+func (diffProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	return nil, nil
+}
+
+func (diffProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	a := &models.RecordConfig{Type: "A", TTL: 300}
+	a.SetLabel("www", dc.Name)
+	a.SetTarget("1.1.1.1")
+	mx := &models.RecordConfig{Type: "MX", TTL: 300}
+	mx.SetLabel("@", dc.Name)
+	mx.SetTargetMX(10, "mx.example.net.")
+	txt := &models.RecordConfig{Type: "TXT", TTL: 300}
+	txt.SetLabel("@", dc.Name)
+	txt.SetTargetTXT("v=spf1 -all")
+	_, create, del, mod := diff.New(dc).IncrementalDiff([]*models.RecordConfig{a, mx, txt})
+	var corrections []*models.Correction
+	for _, changes := range []diff.Changeset{create, del, mod} {
+		for _, c := range changes {
+			msg := c.String()
+			corrections = append(corrections, &models.Correction{Msg: msg, F: func() error {
+				diffApplied = append(diffApplied, msg)
+				return nil
+			}})
+		}
+	}
+	return corrections, nil
+}
+
+// apexProvider serves a zone of two apex NS records with a TTL of 3600 and
+// an A record.
+type apexProvider struct{}
+
+func (apexProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	return nil, nil
+}
+
+func (apexProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	var existing []*models.RecordConfig
+	for _, ns := range []string{"ns1.example.net.", "ns2.example.net."} {
+		rc := &models.RecordConfig{Type: "NS", TTL: 3600}
+		rc.SetLabel("@", dc.Name)
+		rc.SetTarget(ns)
+		existing = append(existing, rc)
+	}
+	a := &models.RecordConfig{Type: "A", TTL: 300}
+	a.SetLabel("www", dc.Name)
+	a.SetTarget("1.1.1.1")
+	existing = append(existing, a)
+	_, create, del, mod := diff.New(dc).IncrementalDiff(existing)
+	var corrections []*models.Correction
+	for _, changes := range []diff.Changeset{create, del, mod} {
+		for _, c := range changes {
+			corrections = append(corrections, &models.Correction{Msg: c.String(), F: func() error { return nil }})
+		}
+	}
+	return corrections, nil
+}
+
+// idProvider creates www with a correction that gets an ID when applied,
+// and deletes old with one that doesn't.
+type idProvider struct{}
+
+func (idProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	return nil, nil
+}
+
+func (idProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	create := &models.Correction{Msg: "CREATE www." + dc.Name}
+	create.F = func() error {
+		create.ID = "change-" + dc.Name
+		return nil
+	}
+	return []*models.Correction{create, {Msg: "DELETE old." + dc.Name, F: func() error { return nil }}}, nil
+}
+
+// swapProvider serves a zone where www is a CNAME. Like a real DNS server, it
+// refuses to create another record at a name that has a CNAME. Its
+// corrections create before they delete, unless it makes them in batches
+// like Route53: one correction that deletes, then one that creates.
+type swapProvider struct {
+	zone    map[string]string
+	batches bool
+}
+
+func (swapProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	return nil, nil
+}
+
+func (p swapProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	var existing []*models.RecordConfig
+	for name, typ := range p.zone {
+		rc := &models.RecordConfig{Type: typ, TTL: 300}
+		rc.SetLabel(name, dc.Name)
+		if typ == "CNAME" {
+			rc.SetTarget("web.example.net.")
+		} else {
+			rc.SetTarget("1.1.1.1")
+		}
+		existing = append(existing, rc)
+	}
+	_, create, del, _ := diff.New(dc).IncrementalDiff(existing)
+	var corrections []*models.Correction
+	for _, c := range create {
+		rc := c.Desired
+		corrections = append(corrections, &models.Correction{Msg: c.String(), F: func() error {
+			if p.zone[rc.GetLabel()] == "CNAME" {
+				return errors.Errorf("%s already has a CNAME", rc.GetLabel())
+			}
+			p.zone[rc.GetLabel()] = rc.Type
+			return nil
+		}})
+	}
+	for _, c := range del {
+		rc := c.Existing
+		corrections = append(corrections, &models.Correction{Msg: c.String(), F: func() error {
+			delete(p.zone, rc.GetLabel())
+			return nil
+		}})
+	}
+	if p.batches && len(create) > 0 && len(del) > 0 {
+		creates, deletes := corrections[:len(create)], corrections[len(create):]
+		return []*models.Correction{batch(deletes), batch(creates)}, nil
+	}
+	return corrections, nil
+}
+
+// batch makes one correction of several.
+func batch(corrections []*models.Correction) *models.Correction {
+	var msgs []string
+	for _, c := range corrections {
+		msgs = append(msgs, c.Msg)
+	}
+	return &models.Correction{Msg: strings.Join(msgs, "\n") + "\n", F: func() error {
+		for _, c := range corrections {
+			if err := c.F(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+}
+
+var swapZone = map[string]string{}
+
+var fakeApplied []string
+
+func init() {
+	providers.RegisterDomainServiceProviderType("FAKE-PUSH", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return fakeProvider{&fakeApplied}, nil
+	})
+	providers.RegisterDomainServiceProviderType("FAKE-BROKEN", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return brokenProvider{}, nil
+	})
+	providers.RegisterDomainServiceProviderType("FAKE-DIFF", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return diffProvider{}, nil
+	})
+	providers.RegisterDomainServiceProviderType("FAKE-WHOLE", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return diffProvider{}, nil
+	}, providers.DocumentationNotes{providers.CantUseNOPURGE: providers.Cannot()})
+	providers.RegisterDomainServiceProviderType("FAKE-APEX", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return apexProvider{}, nil
+	}, providers.ApexTTL(3600))
+	providers.RegisterDomainServiceProviderType("FAKE-IDS", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return idProvider{}, nil
+	})
+	providers.RegisterDomainServiceProviderType("FAKE-SWAP", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return swapProvider{swapZone, false}, nil
+	})
+	providers.RegisterDomainServiceProviderType("FAKE-BATCHES", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return swapProvider{swapZone, true}, nil
+	})
+	providers.RegisterDomainServiceProviderType("FAKE-NOAPEX", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return apexProvider{}, nil
+	})
+	providers.RegisterDomainServiceProviderType("FAKE-EMPTIER", func(conf map[string]string, _ json.RawMessage) (providers.DNSServiceProvider, error) {
+		return emptierProvider{migrateZones[conf["zone"]]}, nil
+	}, providers.ApexTTL(86400))
+	providers.RegisterDomainServiceProviderType("FAKE-REQUIRED", func(conf map[string]string, _ json.RawMessage) (providers.DNSServiceProvider, error) {
+		return migrateZones[conf["zone"]], nil
+	}, providers.RequiredRecords{{Label: "@", Type: "TXT", Content: `"owner=1234"`, TTL: 3600}})
+}
+
+// writeIR writes a configuration with the domains, all at the fake provider.
+func writeIR(t *testing.T, dir string, domains ...string) string {
+	cfg := &models.DNSConfig{
+		Registrars:   []*models.RegistrarConfig{{Name: "none", Type: "NONE"}},
+		DNSProviders: []*models.DNSProviderConfig{{Name: "fake", Type: "FAKE-PUSH"}},
+	}
+	for _, d := range domains {
+		cfg.Domains = append(cfg.Domains, &models.DomainConfig{
+			Name:             d,
+			RegistrarName:    "none",
+			DNSProviderNames: map[string]int{"fake": 0},
+			Metadata:         map[string]string{},
+		})
+	}
+	return writeConfig(t, dir, cfg)
+}
+
+// writeConfig writes cfg as an IR file.
+func writeConfig(t *testing.T, dir string, cfg *models.DNSConfig) string {
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "ir.json")
+	if err := ioutil.WriteFile(filename, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestPushFailingDomain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, tst := range []struct {
+		failFast bool
+		applied  []string
+	}{
+		// By default, the domains after the failing one are still pushed.
+		{false, []string{"a.example.com", "z.example.com"}},
+		{true, []string{"a.example.com"}},
+	} {
+		fakeApplied = nil
+		args := PushArgs{FailFast: tst.failFast}
+		args.JSONFile = writeIR(t, dir, "a.example.com", "fail.example.com", "z.example.com")
+		args.CredsFile = filepath.Join(dir, "creds.json")
+		err := run(args, true, printer.ConsolePrinter{})
+		if err == nil {
+			t.Errorf("-fail-fast=%v: expected an error", tst.failFast)
+		}
+		if !reflect.DeepEqual(fakeApplied, tst.applied) {
+			t.Errorf("-fail-fast=%v: expected %v to be pushed, got %v", tst.failFast, tst.applied, fakeApplied)
+		}
+	}
+}
+
+func TestPushChangelog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	head := gitHead
+	gitHead = func(GetDNSConfigArgs) (string, error) { return "1a2b3c4", nil }
+	defer func() { gitHead = head }()
+
+	args := PushArgs{Changelog: filepath.Join(dir, "changelog.json")}
+	args.JSONFile = writeIR(t, dir, "a.example.com", "fail.example.com", "z.example.com")
+	args.CredsFile = filepath.Join(dir, "creds.json")
+	run(args, true, printer.ConsolePrinter{})
+	// preview doesn't write it.
+	run(args, false, printer.ConsolePrinter{})
+
+	b, err := ioutil.ReadFile(args.Changelog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry struct {
+		Commit  string
+		Changes []struct{ Domain, Provider, Change string }
+	}
+	if err := json.Unmarshal(b, &entry); err != nil {
+		t.Fatalf("expected one JSON entry, got %s: %s", b, err)
+	}
+	// The failed correction isn't in it.
+	if entry.Commit != "1a2b3c4" || len(entry.Changes) != 2 || entry.Changes[0].Domain != "a.example.com" ||
+		entry.Changes[1].Domain != "z.example.com" || entry.Changes[1].Change != "CREATE A www.z.example.com" {
+		t.Errorf("unexpected changelog %s", b)
+	}
+}
+
+func TestPushMetrics(t *testing.T) {
+	dir, err := ioutil.TempDir("", "push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	args := PushArgs{MetricsFile: filepath.Join(dir, "dnscontrol.prom"), FailFast: true}
+	args.JSONFile = writeIR(t, dir, "a.example.com", "fail.example.com", "z.example.com")
+	args.CredsFile = filepath.Join(dir, "creds.json")
+	if err := run(args, true, printer.ConsolePrinter{}); err == nil {
+		t.Error("expected an error")
+	}
+	b, err := ioutil.ReadFile(args.MetricsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"dnscontrol_last_run_success 0",
+		`dnscontrol_domain_corrections_applied{domain="a.example.com"} 1`,
+		`dnscontrol_domain_failures{domain="a.example.com"} 0`,
+		`dnscontrol_domain_failures{domain="fail.example.com"} 1`,
+		`dnscontrol_provider_failures{provider="fake"} 1`,
+	} {
+		if !strings.Contains(string(b), line+"\n") {
+			t.Errorf("expected %q in:\n%s", line, b)
+		}
+	}
+}
+
+func TestProviderFailover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cfg := &models.DNSConfig{
+		Registrars: []*models.RegistrarConfig{{Name: "none", Type: "NONE"}},
+		DNSProviders: []*models.DNSProviderConfig{
+			{Name: "broken", Type: "FAKE-BROKEN"},
+			{Name: "fake", Type: "FAKE-PUSH"},
+		},
+	}
+	for name, dsps := range map[string]map[string]int{
+		"dual.example.com":   {"broken": 0, "fake": 0},
+		"single.example.com": {"broken": 0},
+	} {
+		cfg.Domains = append(cfg.Domains, &models.DomainConfig{
+			Name:             name,
+			RegistrarName:    "none",
+			DNSProviderNames: dsps,
+			Metadata:         map[string]string{},
+		})
+	}
+	filename := writeConfig(t, dir, cfg)
+
+	for _, tst := range []struct {
+		failover bool
+		applied  []string
+	}{
+		// The broken provider is first, the domain is given up on.
+		{false, nil},
+		{true, []string{"dual.example.com"}},
+	} {
+		fakeApplied = nil
+		args := PushArgs{}
+		args.Failover = tst.failover
+		args.JSONFile = filename
+		args.CredsFile = filepath.Join(dir, "creds.json")
+		// single.example.com fails either way.
+		if err := run(args, true, printer.ConsolePrinter{}); err == nil {
+			t.Errorf("-provider-failover=%v: expected an error", tst.failover)
+		}
+		if !reflect.DeepEqual(fakeApplied, tst.applied) {
+			t.Errorf("-provider-failover=%v: expected %v to be pushed, got %v", tst.failover, tst.applied, fakeApplied)
+		}
+	}
+}
+
+func TestPushTypes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("diff", "FAKE-DIFF")),
+	A("www", "2.2.2.2"),
+	AAAA("www", "2001:db8::1"),
+	MX("@", 20, "mx.example.net."),
+	TXT("@", "v=spf1 include:_spf.example.net -all")
+);`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tst := range []struct {
+		types   string
+		applied int
+	}{
+		{"", 4},
+		{"A", 1},
+		{"a,aaaa", 2},
+		{"CNAME", 0},
+	} {
+		diffApplied = nil
+		args := PushArgs{}
+		args.JSFile = jsFile
+		args.CredsFile = filepath.Join(dir, "creds.json")
+		args.Types = tst.types
+		if err := run(args, true, printer.ConsolePrinter{}); err != nil {
+			t.Fatalf("-types %s: %s", tst.types, err)
+		}
+		if len(diffApplied) != tst.applied {
+			t.Errorf("-types %s: expected %d changes, got %q", tst.types, tst.applied, diffApplied)
+		}
+		for _, c := range diffApplied {
+			if tst.types != "" && (strings.Contains(c, " MX ") || strings.Contains(c, " TXT ")) {
+				t.Errorf("-types %s: MX or TXT record changed: %s", tst.types, c)
+			}
+		}
+	}
+}
+
+func TestPushLimitedWholeZone(t *testing.T) {
+	dir, err := ioutil.TempDir("", "push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, tst := range []struct {
+		provider string
+		only     string
+		types    string
+		applied  int
+	}{
+		{"FAKE-DIFF", "www.example.com:A", "", 1},
+		{"FAKE-DIFF", "", "A", 1},
+		// It would write the MX and TXT records too.
+		{"FAKE-WHOLE", "www.example.com:A", "", 0},
+		{"FAKE-WHOLE", "", "A", 0},
+	} {
+		jsFile := filepath.Join(dir, "dnsconfig.js")
+		err := ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("p", "`+tst.provider+`")),
+	A("www", "2.2.2.2"),
+	MX("@", 20, "mx.example.net."),
+	TXT("@", "v=spf1 include:_spf.example.net -all")
+);`), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		diffApplied = nil
+		args := PushArgs{}
+		args.JSFile = jsFile
+		args.CredsFile = filepath.Join(dir, "creds.json")
+		args.Only = tst.only
+		args.Types = tst.types
+		err = run(args, true, printer.ConsolePrinter{})
+		if tst.applied == 0 && err == nil {
+			t.Errorf("%s -only %q -types %q: expected an error", tst.provider, tst.only, tst.types)
+		} else if tst.applied != 0 && err != nil {
+			t.Errorf("%s -only %q -types %q: %s", tst.provider, tst.only, tst.types, err)
+		}
+		if len(diffApplied) != tst.applied {
+			t.Errorf("%s -only %q -types %q: expected %d changes, got %q", tst.provider, tst.only, tst.types, tst.applied, diffApplied)
+		}
+	}
+}
+
+// countingPrinter counts the corrections that are printed.
+type countingPrinter struct {
+	printer.ConsolePrinter
+	corrections *int
+}
+
+func (p countingPrinter) PrintCorrection(n int, c *models.Correction) {
+	*p.corrections++
+	p.ConsolePrinter.PrintCorrection(n, c)
+}
+
+func TestApexTTL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preview")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, tst := range []struct {
+		pType       string
+		corrections int
+	}{
+		// The NS records are asked for with ns_ttl's default of 300.
+		{"FAKE-APEX", 0},
+		{"FAKE-NOAPEX", 2},
+	} {
+		jsFile := filepath.Join(dir, "dnsconfig.js")
+		err = ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("apex", "`+tst.pType+`")),
+	NAMESERVER("ns1.example.net."),
+	NAMESERVER("ns2.example.net."),
+	A("www", "1.1.1.1")
+);`), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		args := PushArgs{}
+		args.JSFile = jsFile
+		args.CredsFile = filepath.Join(dir, "creds.json")
+		n := 0
+		if err := run(args, false, countingPrinter{corrections: &n}); err != nil {
+			t.Fatalf("%s: %s", tst.pType, err)
+		}
+		if n != tst.corrections {
+			t.Errorf("%s: expected %d corrections, got %d", tst.pType, tst.corrections, n)
+		}
+	}
+}
+
+func TestDomainResults(t *testing.T) {
+	r := &domainResults{}
+	r.start("a.example.com")
+	r.start("fail.example.com")
+	r.fail("diff")
+	r.fail("diff")
+	r.start("dual.example.com")
+	r.degrade()
+	r.start("dual-down.example.com")
+	r.degrade()
+	r.fail("diff")
+	r.start("blocked.example.com")
+	r.warn()
+	r.start("blocked-dual.example.com")
+	r.warn()
+	r.degrade()
+	r.warn()
+	r.skip("z.example.com")
+	if r.failed != 2 || r.degraded != 2 || r.warned != 1 || !reflect.DeepEqual(r.status, []string{"ok", "FAILED", "degraded", "FAILED", "warning", "degraded", "skipped"}) {
+		t.Errorf("unexpected results %+v", r)
+	}
+	var msgs, warnings []string
+	r.print(warnPrinter{msgPrinter{printer.ConsolePrinter{W: ioutil.Discard}, &msgs}, &warnings})
+	if len(warnings) != 1 || warnings[0] != "2 of 7 domains had errors, 2 had a provider fail, 1 had warnings:\n" {
+		t.Errorf("unexpected summary %q", warnings)
+	}
+}
+
+func TestPreviewSummary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preview")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+var REG = NewRegistrar("none", "NONE"), DIFF = DnsProvider(NewDnsProvider("diff", "FAKE-DIFF"));
+D("example.com", REG, DIFF,
+	A("www", "2.2.2.2"),
+	AAAA("www", "2001:db8::1"),
+	MX("@", 20, "mx.example.net."),
+	TXT("@", "v=spf1 include:_spf.example.net -all")
+);
+D("example.net", REG, DIFF,
+	AAAA("www", "2001:db8::1")
+);
+D("example.org", REG, DIFF,
+	A("www", "1.1.1.1"),
+	MX("@", 10, "mx.example.net."),
+	TXT("@", "v=spf1 -all")
+);`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := PreviewArgs{Summary: true}
+	args.JSFile = jsFile
+	args.CredsFile = filepath.Join(dir, "creds.json")
+
+	summary := newSummaryPrinter(printer.ConsolePrinter{})
+	if err := run(PushArgs{PreviewArgs: args}, false, summary); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]changeCounts{
+		"example.com": {creates: 1, modifies: 3},
+		"example.net": {creates: 1, deletes: 3},
+		"example.org": {},
+	}
+	if len(summary.counts) != len(want) {
+		t.Errorf("expected counts for %d domains, got %v", len(want), summary.counts)
+	}
+	for d, c := range want {
+		if got := summary.counts[d]; got == nil || *got != c {
+			t.Errorf("%s: expected %+v, got %+v", d, c, got)
+		}
+	}
+	if total := summary.print(); *total != (changeCounts{creates: 2, modifies: 3, deletes: 3}) {
+		t.Errorf("expected a total of 2 creates, 3 modifies and 3 deletes, got %+v", total)
+	}
+
+	if err := Preview(args); err != errPendingChanges {
+		t.Errorf("expected the pending changes exit, got %v", err)
+	}
+	args.Domains = "example.org"
+	if err := Preview(args); err != nil {
+		t.Errorf("expected no error without changes, got %v", err)
+	}
+	if err := run(PushArgs{PreviewArgs: args}, true, summary); err == nil {
+		t.Error("expected push -summary to be refused")
+	}
+}
+
+// debugPrinter keeps the debug output.
+type debugPrinter struct {
+	printer.ConsolePrinter
+	lines *[]string
+}
+
+func (p debugPrinter) Debugf(format string, args ...interface{}) {
+	*p.lines = append(*p.lines, fmt.Sprintf(format, args...))
+}
+
+func TestPushShowIDs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cfg := &models.DNSConfig{
+		Registrars:   []*models.RegistrarConfig{{Name: "none", Type: "NONE"}},
+		DNSProviders: []*models.DNSProviderConfig{{Name: "ids", Type: "FAKE-IDS"}},
+		Domains: []*models.DomainConfig{{
+			Name:             "example.com",
+			RegistrarName:    "none",
+			DNSProviderNames: map[string]int{"ids": -1},
+		}},
+	}
+	for _, tst := range []struct {
+		push, showIDs bool
+		ids           []string
+	}{
+		{true, true, []string{"ID: change-example.com\n"}},
+		{true, false, nil},
+		{false, true, nil},
+	} {
+		args := PushArgs{ShowIDs: tst.showIDs}
+		args.JSONFile = writeConfig(t, dir, cfg)
+		args.CredsFile = filepath.Join(dir, "creds.json")
+		var lines []string
+		if err := run(args, tst.push, debugPrinter{lines: &lines}); err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, l := range lines {
+			if strings.HasPrefix(l, "ID: ") {
+				ids = append(ids, l)
+			}
+		}
+		if !reflect.DeepEqual(ids, tst.ids) {
+			t.Errorf("push=%v -show-ids=%v: expected %q, got %q", tst.push, tst.showIDs, tst.ids, ids)
+		}
+	}
+}
+
+func TestCorrectionOrder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a := &models.RecordConfig{Type: "A", TTL: 300}
+	a.SetLabel("www", "example.com")
+	a.SetTarget("1.1.1.1")
+
+	for _, tst := range []struct {
+		provider string
+		order    string
+		ok       bool
+	}{
+		// The A record can't be created while the CNAME is there.
+		{"FAKE-SWAP", "", false},
+		{"FAKE-SWAP", models.CreatesFirst, false},
+		{"FAKE-SWAP", models.DeletesFirst, true},
+		// Like Route53, it deletes the CNAME first by itself.
+		{"FAKE-BATCHES", "", true},
+		{"FAKE-BATCHES", models.CreatesFirst, false},
+		{"FAKE-BATCHES", models.DeletesFirst, true},
+	} {
+		swapZone["www"] = "CNAME"
+		cfg := &models.DNSConfig{
+			Registrars:   []*models.RegistrarConfig{{Name: "none", Type: "NONE"}},
+			DNSProviders: []*models.DNSProviderConfig{{Name: "swap", Type: tst.provider}},
+			Domains: []*models.DomainConfig{{
+				Name:             "example.com",
+				RegistrarName:    "none",
+				DNSProviderNames: map[string]int{"swap": -1},
+				Metadata:         map[string]string{models.MetaCorrectionOrder: tst.order},
+				Records:          []*models.RecordConfig{a},
+			}},
+		}
+		args := PushArgs{}
+		args.JSONFile = writeConfig(t, dir, cfg)
+		args.CredsFile = filepath.Join(dir, "creds.json")
+		err := run(args, true, printer.ConsolePrinter{})
+		if tst.ok && (err != nil || swapZone["www"] != "A") {
+			t.Errorf("%s %q: expected www to be swapped to an A record, got %v and %v", tst.provider, tst.order, err, swapZone)
+		} else if !tst.ok && (err == nil || swapZone["www"] == "A") {
+			t.Errorf("%s %q: expected the create to fail, got %v and %v", tst.provider, tst.order, err, swapZone)
+		}
+	}
+}
+
+// msgPrinter keeps the corrections printed.
+type msgPrinter struct {
+	printer.ConsolePrinter
+	msgs *[]string
+}
+
+func (p msgPrinter) PrintCorrection(n int, c *models.Correction) {
+	*p.msgs = append(*p.msgs, c.Msg)
+}
+
+func TestPreviewDeletesOnly(t *testing.T) {
+	dir, err := ioutil.TempDir("", "preview")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+var REG = NewRegistrar("none", "NONE"), DIFF = DnsProvider(NewDnsProvider("diff", "FAKE-DIFF"));
+D("example.com", REG, DIFF,
+	A("www", "2.2.2.2"),
+	AAAA("www", "2001:db8::1"),
+	MX("@", 10, "mx.example.net."),
+	TXT("@", "v=spf1 -all")
+);
+D("example.net", REG, DIFF,
+	AAAA("www", "2001:db8::1")
+);`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := PushArgs{}
+	args.JSFile = jsFile
+	args.CredsFile = filepath.Join(dir, "creds.json")
+
+	args.DeletesOnly = true
+	var msgs []string
+	if err := run(args, false, msgPrinter{msgs: &msgs}); err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 3 {
+		t.Errorf("expected the 3 deletions of example.net, got %q", msgs)
+	}
+	for _, m := range msgs {
+		if !strings.HasPrefix(m, "DELETE ") || !strings.Contains(m, "example.net") {
+			t.Errorf("expected only deletions of example.net, got %q", m)
+		}
+	}
+	if err := run(args, true, printer.ConsolePrinter{}); err == nil {
+		t.Error("expected push -deletes-only to be refused")
+	}
+
+	// example.net deletes 3 records, more than allowed.
+	args.DeletesOnly = false
+	args.MaxDeletes = 2
+	diffApplied = nil
+	if err := run(args, true, printer.ConsolePrinter{}); err == nil {
+		t.Error("expected -max-deletes to fail the push")
+	}
+	for _, m := range diffApplied {
+		if strings.Contains(m, "example.net") {
+			t.Errorf("expected example.net to be left alone, got %q", m)
+		}
+	}
+	if len(diffApplied) != 2 {
+		t.Errorf("expected the 2 changes of example.com to be made, got %q", diffApplied)
+	}
+}
+
+// warnPrinter also records the warnings.
+type warnPrinter struct {
+	msgPrinter
+	warnings *[]string
+}
+
+func (p warnPrinter) Warnf(format string, args ...interface{}) {
+	*p.warnings = append(*p.warnings, fmt.Sprintf(format, args...))
+}
+
+func TestProtectedRecords(t *testing.T) {
+	dir, err := ioutil.TempDir("", "protected")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("diff", "FAKE-DIFF")),
+	A("www", "2.2.2.2"),
+	MX("@", 10, "mx.example.net."),
+	TXT("@", "v=spf1 ~all", PROTECTED())
+);`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := PushArgs{}
+	args.JSFile = jsFile
+	args.CredsFile = filepath.Join(dir, "creds.json")
+
+	var msgs, warnings []string
+	if err := run(args, false, warnPrinter{msgPrinter{msgs: &msgs}, &warnings}); err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || !strings.HasPrefix(msgs[0], "MODIFY A www.example.com") {
+		t.Errorf("Expected only the A record to change, got %q", msgs)
+	}
+	want := `BLOCKED MODIFY TXT example.com: ("v=spf1 -all" ttl=300) -> ("v=spf1 ~all" ttl=300): the record is PROTECTED()`
+	if len(warnings) < 2 || strings.TrimSpace(warnings[0]) != want || !strings.Contains(warnings[1], "-allow-protected-changes") {
+		t.Errorf("Expected %q, got %q", want, warnings)
+	}
+
+	diffApplied = nil
+	if err := run(args, true, printer.ConsolePrinter{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(diffApplied) != 1 {
+		t.Errorf("Expected push to leave the TXT record alone, got %q", diffApplied)
+	}
+	args.AllowProtectedChanges = true
+	diffApplied = nil
+	if err := run(args, true, printer.ConsolePrinter{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(diffApplied) != 2 {
+		t.Errorf("Expected -allow-protected-changes to change the TXT record, got %q", diffApplied)
+	}
+}
+
+func TestProtectedState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "protected")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	writeJS := func(txt string) {
+		err := ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("diff", "FAKE-DIFF")),
+	A("www", "1.1.1.1"),
+	MX("@", 10, "mx.example.net.")`+txt+`
+);`), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	args := PushArgs{}
+	args.JSFile = jsFile
+	args.CredsFile = filepath.Join(dir, "creds.json")
+	args.ProtectedState = filepath.Join(dir, "protected.json")
+
+	// Nothing is protected yet, so there is no state.
+	writeJS("")
+	if err := run(args, true, printer.ConsolePrinter{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(args.ProtectedState); !os.IsNotExist(err) {
+		t.Errorf("Expected no state file, got %v", err)
+	}
+	writeJS(`, TXT("@", "v=spf1 -all", PROTECTED())`)
+	if err := run(args, true, printer.ConsolePrinter{}); err != nil {
+		t.Fatal(err)
+	}
+	state, err := loadProtectedState(args.ProtectedState)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (protectedState{"example.com": {`example.com TXT "v=spf1 -all"`}}); !reflect.DeepEqual(state, want) {
+		t.Errorf("Expected the state %v, got %v", want, state)
+	}
+
+	// Removing the record doesn't delete it...
+	writeJS("")
+	var msgs, warnings []string
+	diffApplied = nil
+	if err := run(args, true, warnPrinter{msgPrinter{msgs: &msgs}, &warnings}); err != nil {
+		t.Fatal(err)
+	}
+	want := `BLOCKED DELETE TXT example.com "v=spf1 -all" ttl=300: the record is PROTECTED()`
+	if diffApplied != nil || len(warnings) == 0 || strings.TrimSpace(warnings[0]) != want {
+		t.Errorf("Expected %q, got %q, applied %q", want, warnings, diffApplied)
+	}
+	// ...until -allow-protected-changes, which also drops it from the state.
+	args.AllowProtectedChanges = true
+	if err := run(args, true, printer.ConsolePrinter{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(diffApplied) != 1 || !strings.HasPrefix(diffApplied[0], "DELETE TXT") {
+		t.Errorf("Expected the TXT record to be deleted, got %q", diffApplied)
+	}
+	if state, err := loadProtectedState(args.ProtectedState); err != nil || len(state) != 0 {
+		t.Errorf("Expected an empty state, got %v, %v", state, err)
+	}
+}
+
+func TestProtectedRecordsWholeZone(t *testing.T) {
+	dir, err := ioutil.TempDir("", "protected")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("bind", "BIND")),
+	A("www", "2.2.2.2"),
+	TXT("@", "v=spf1 ~all", PROTECTED())
+);`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credsFile := filepath.Join(dir, "creds.json")
+	if err := ioutil.WriteFile(credsFile, []byte(`{"bind": {"directory": "`+dir+`"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	zonefile := filepath.Join(dir, "example.com.zone")
+	zone := []byte(`$TTL 300
+@ IN SOA ns1.example.net. hostmaster.example.net. 2020010101 3600 600 604800 1440
+@ IN TXT "v=spf1 -all"
+www IN A 1.1.1.1
+`)
+	if err := ioutil.WriteFile(zonefile, zone, 0644); err != nil {
+		t.Fatal(err)
+	}
+	args := PushArgs{}
+	args.JSFile = jsFile
+	args.CredsFile = credsFile
+	// BIND writes the whole zone, the A record can't be changed without the TXT record.
+	if err := run(args, true, printer.ConsolePrinter{}); err == nil {
+		t.Error("Expected the push to fail")
+	}
+	if got, err := ioutil.ReadFile(zonefile); err != nil || string(got) != string(zone) {
+		t.Errorf("Expected the zonefile to be left alone, got %v:\n%s", err, got)
+	}
+}
+
+func TestPurgedRecords(t *testing.T) {
+	dir, err := ioutil.TempDir("", "purge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("diff", "FAKE-DIFF")),
+	A("www", "2.2.2.2"),
+	TXT("@", "v=spf1 -all")
+);`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := PushArgs{}
+	args.JSFile = jsFile
+	args.CredsFile = filepath.Join(dir, "creds.json")
+
+	var msgs, warnings []string
+	if err := run(args, false, warnPrinter{msgPrinter{msgs: &msgs}, &warnings}); err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 {
+		t.Errorf("Expected the A record to change and the MX record to be deleted, got %q", msgs)
+	}
+	// The A record changes, only the MX record is purged.
+	want := []string{
+		"PURGE 1 records of example.com at diff, which the configuration doesn't declare (NO_PURGE or IGNORE() would keep them):",
+		"DELETE MX example.com 10 mx.example.net. ttl=300",
+	}
+	var got []string
+	for _, w := range warnings {
+		got = append(got, strings.TrimSpace(w))
+	}
+	if len(got) < 2 || !reflect.DeepEqual(got[:2], want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestRequiredRecords(t *testing.T) {
+	dir, err := ioutil.TempDir("", "required")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("required", "FAKE-REQUIRED"), 0),
+	A("www", "1.1.1.1"),
+	TXT("@", "v=spf1 -all")
+);`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credsFile := filepath.Join(dir, "creds.json")
+	if err := ioutil.WriteFile(credsFile, []byte(`{"required": {"zone": "required"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	zone := &zoneProvider{map[string]uint32{
+		"www A 1.1.1.1":    300,
+		"@ TXT owner=1234": 3600,
+		"old A 1.1.1.2":    300,
+	}, nil}
+	migrateZones["required"] = zone
+	args := PushArgs{}
+	args.JSFile = jsFile
+	args.CredsFile = credsFile
+	if err := run(args, true, printer.ConsolePrinter{}); err != nil {
+		t.Fatal(err)
+	}
+	// The required record is neither purged nor changed.
+	want := map[string]uint32{
+		"www A 1.1.1.1":     300,
+		"@ TXT owner=1234":  3600,
+		"@ TXT v=spf1 -all": 300,
+	}
+	if !reflect.DeepEqual(zone.zone, want) {
+		t.Errorf("Expected %v, got %v", want, zone.zone)
+	}
+
+	// It is created where it's missing.
+	delete(zone.zone, "@ TXT owner=1234")
+	if err := run(args, true, printer.ConsolePrinter{}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(zone.zone, want) {
+		t.Errorf("Expected %v, got %v", want, zone.zone)
+	}
+}
+
+// emptierProvider is a zoneProvider that can empty the zone in one call.
+type emptierProvider struct {
+	*zoneProvider
+}
+
+func (p emptierProvider) EmptyZone(domain string) error {
+	for k := range p.zone {
+		if !strings.HasPrefix(k, "@ NS ") {
+			delete(p.zone, k)
+		}
+	}
+	return nil
+}
+
+func TestEmptyZone(t *testing.T) {
+	dir, err := ioutil.TempDir("", "empty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("empty", "FAKE-EMPTIER")));`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credsFile := filepath.Join(dir, "creds.json")
+	if err := ioutil.WriteFile(credsFile, []byte(`{"empty": {"zone": "empty"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	zone := &zoneProvider{map[string]uint32{
+		"@ NS ns1.example.net.": 86400,
+		"www A 1.1.1.1":         300,
+		"old A 1.1.1.2":         300,
+	}, []string{"ns1.example.net"}}
+	migrateZones["empty"] = zone
+	var msgs []string
+	args := PushArgs{AllowEmptyZone: true}
+	args.JSFile = jsFile
+	args.CredsFile = credsFile
+	if err := run(args, true, msgPrinter{msgs: &msgs}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(msgs, []string{"DELETE all records of example.com"}) {
+		t.Errorf("Expected the zone to be emptied in one correction, got %q", msgs)
+	}
+	// The apex NS records keep the TTL of the provider.
+	want := map[string]uint32{"@ NS ns1.example.net.": 86400}
+	if !reflect.DeepEqual(zone.zone, want) {
+		t.Errorf("Expected %v, got %v", want, zone.zone)
+	}
+}
+
+func TestNSDrift(t *testing.T) {
+	ns := func(name string) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: "NS", TTL: 300, Metadata: map[string]string{}}
+		rc.SetLabel("@", "example.com")
+		rc.SetTarget(name)
+		return rc
+	}
+	domain := &models.DomainConfig{
+		Name:        "example.com",
+		Nameservers: models.StringsToNameservers([]string{"ns1.example.net", "ns2.example.net"}),
+		Records:     []*models.RecordConfig{ns("ns1.example.net."), ns("ns2.example.net.")},
+	}
+	existing := []*models.RecordConfig{ns("ns1.example.net."), ns("old.example.net.")}
+	_, create, del, mod := diff.New(domain).IncrementalDiff(existing)
+	changes := append(append(create, del...), mod...)
+	if msg := nsDrift(domain, existing, changes); msg != "adding ns2.example.net, removing old.example.net" {
+		t.Errorf("Expected the change of the NS records, got %q", msg)
+	}
+	// A provider that leaves its apex NS records alone doesn't change them.
+	if msg := nsDrift(domain, existing, nil); msg != "" {
+		t.Errorf("Expected no warning without changes to the NS records, got %q", msg)
+	}
+	// Nor does one that doesn't serve them.
+	_, create, _, _ = diff.New(domain).IncrementalDiff(nil)
+	if msg := nsDrift(domain, nil, create); msg != "" {
+		t.Errorf("Expected no warning without NS records, got %q", msg)
+	}
+}
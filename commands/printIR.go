@@ -51,11 +51,13 @@ var _ = cmd(catDebug, func() *cli.Command {
 type PrintIRArgs struct {
 	GetDNSConfigArgs
 	PrintJSONArgs
+	ValidateArgs
 	Raw bool
 }
 
 func (args *PrintIRArgs) flags() []cli.Flag {
 	flags := append(args.GetDNSConfigArgs.flags(), args.PrintJSONArgs.flags()...)
+	flags = append(flags, args.ValidateArgs.flags()...)
 	flags = append(flags, &cli.BoolFlag{
 		Name:        "raw",
 		Usage:       "Skip validation and normalization. Just print js result.",
@@ -71,8 +73,8 @@ func PrintIR(args PrintIRArgs) error {
 		return err
 	}
 	if !args.Raw {
-		errs := normalize.NormalizeAndValidateConfig(cfg)
-		if PrintValidationErrors(errs) {
+		res := args.validate(cfg)
+		if PrintValidationErrors(res, args.Strict) {
 			return errors.Errorf("Exiting due to validation errors")
 		}
 	}
@@ -80,20 +82,25 @@ func PrintIR(args PrintIRArgs) error {
 }
 
 // PrintValidationErrors formats and prints the validation errors and warnings.
-func PrintValidationErrors(errs []error) (fatal bool) {
-	if len(errs) == 0 {
-		return false
-	}
-	fmt.Printf("%d Validation errors:\n", len(errs))
-	for _, err := range errs {
-		if _, ok := err.(normalize.Warning); ok {
-			fmt.Printf("WARNING: %s\n", err)
-		} else {
-			fatal = true
+// Warnings are only fatal if strict is set.
+func PrintValidationErrors(res normalize.Result, strict bool) (fatal bool) {
+	if len(res.Errors) > 0 {
+		fmt.Printf("%d Validation errors:\n", len(res.Errors))
+		for _, err := range res.Errors {
 			fmt.Printf("ERROR: %s\n", err)
 		}
 	}
-	return
+	if len(res.Warnings) > 0 {
+		fmt.Printf("%d Validation warnings:\n", len(res.Warnings))
+		for _, err := range res.Warnings {
+			if w, ok := err.(normalize.Warning); ok && w.Record != nil {
+				fmt.Printf("WARNING: %s (IGNORE_WARNING(%q) on the record suppresses this)\n", err, w.ID)
+			} else {
+				fmt.Printf("WARNING: %s\n", err)
+			}
+		}
+	}
+	return res.Failed(strict)
 }
 
 // ExecuteDSL executes the dnsconfig.js contents.
@@ -105,13 +112,25 @@ func ExecuteDSL(args ExecuteDSLArgs) (*models.DNSConfig, error) {
 	if err != nil {
 		return nil, errors.Errorf("Reading js file %s: %s", args.JSFile, err)
 	}
-	dnsConfig, err := js.ExecuteJavascript(string(text), args.DevMode)
+	inv, err := args.inventory()
+	if err != nil {
+		return nil, err
+	}
+	dnsConfig, err := js.ExecuteJavascript(string(text), args.DevMode, inv)
 	if err != nil {
 		return nil, errors.Errorf("Executing javascript in %s: %s", args.JSFile, err)
 	}
 	return dnsConfig, nil
 }
 
+// inventory loads the -inventory file, if any.
+func (args ExecuteDSLArgs) inventory() (js.Inventory, error) {
+	if args.Inventory == "" {
+		return nil, nil
+	}
+	return js.LoadInventory(args.Inventory)
+}
+
 // PrintJSON outputs/prettyprints the IR data.
 func PrintJSON(args PrintJSONArgs, config *models.DNSConfig) (err error) {
 	var dat []byte
@@ -140,5 +159,8 @@ func exit(err error) error {
 	if err == nil {
 		return nil
 	}
+	if _, ok := err.(cli.ExitCoder); ok {
+		return err
+	}
 	return cli.NewExitError(err, 1)
 }
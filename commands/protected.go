@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
+)
+
+// protectedState is the file of -protected-state: the ProtectedIDs of the
+// records that were PROTECTED() when their domain was pushed, by domain.
+// push keeps blocking the changes to them after they or their PROTECTED()
+// are removed from dnsconfig.js, until -allow-protected-changes.
+type protectedState map[string][]string
+
+// loadProtectedState reads the state file. There is none before the first
+// push.
+func loadProtectedState(filename string) (protectedState, error) {
+	state := protectedState{}
+	if filename == "" {
+		return state, nil
+	}
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, errors.Errorf("%s: %s", filename, err)
+	}
+	return state, nil
+}
+
+// pushed notes the PROTECTED() records of domain once it was pushed. The
+// records protected before stay protected, unless the push had
+// -allow-protected-changes and could change them (see MatchesOnly).
+func (s protectedState) pushed(domain *models.DomainConfig) {
+	found := map[string]bool{}
+	for _, id := range s[domain.Name] {
+		found[id] = !domain.AllowProtectedChanges || !domain.MatchesOnly(protectedRecord(domain, id))
+	}
+	for _, r := range domain.Records {
+		if r.Metadata[models.MetaProtected] == "true" {
+			found[models.ProtectedID(r)] = true
+		}
+	}
+	var ids []string
+	for id, ok := range found {
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		delete(s, domain.Name)
+		return
+	}
+	sort.Strings(ids)
+	s[domain.Name] = ids
+}
+
+// protectedRecord returns a record with the name and type of a ProtectedID,
+// enough for MatchesOnly.
+func protectedRecord(domain *models.DomainConfig, id string) *models.RecordConfig {
+	fields := strings.SplitN(id, " ", 3)
+	r := &models.RecordConfig{}
+	r.SetLabelFromFQDN(fields[0], domain.Name)
+	if len(fields) > 1 {
+		r.Type = fields[1]
+	}
+	return r
+}
+
+// save writes the state file, unless there is nothing to write to a file
+// that doesn't exist yet.
+func (s protectedState) save(filename string) error {
+	if filename == "" {
+		return nil
+	}
+	if _, err := os.Stat(filename); len(s) == 0 && os.IsNotExist(err) {
+		return nil
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(b, '\n'), 0644)
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+	"github.com/pkg/errors"
+)
+
+// timeoutError is the error of a provider call that took longer than
+// -provider-timeout.
+type timeoutError time.Duration
+
+func (e timeoutError) Error() string {
+	return fmt.Sprintf("no answer after %s (-provider-timeout)", time.Duration(e))
+}
+
+// correctionsResult is what a call to get corrections returned.
+type correctionsResult struct {
+	corrections []*models.Correction
+	err         error
+}
+
+// getCorrections calls get, and gives up on it with a timeoutError after
+// timeout. A call given up on is left running, as providers can't be
+// interrupted, and its result is thrown away. A timeout of 0 waits forever.
+func getCorrections(timeout time.Duration, get func() ([]*models.Correction, error)) ([]*models.Correction, error) {
+	if timeout <= 0 {
+		return get()
+	}
+	done := make(chan correctionsResult, 1)
+	go func() {
+		corrections, err := get()
+		done <- correctionsResult{corrections, err}
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.corrections, r.err
+	case <-timer.C:
+		return nil, timeoutError(timeout)
+	}
+}
+
+// diffCorrections gets the corrections of dc from driver, like
+// getCorrections, with what the diffs of the call reported. dc must be the
+// call's own copy of the domain, as a call given up on may still use it.
+// The Existing records of the collector are nil if the provider doesn't use
+// the diff package.
+func diffCorrections(timeout time.Duration, driver providers.DNSServiceProvider, dc *models.DomainConfig) ([]*models.Correction, *diff.Collector, error) {
+	collector := diff.Collect(dc)
+	corrections, err := getCorrections(timeout, func() ([]*models.Correction, error) { return driver.GetDomainCorrections(dc) })
+	collector.Stop()
+	return corrections, collector, err
+}
+
+// timeCorrections makes each of the corrections give up after timeout, like
+// getCorrections. Once one has, the provider is taken to be hung, and the
+// corrections after it fail without being tried. A correction given up on may
+// still be made: its error says so, and late notes what became of it, as of
+// where ("DOMAIN at PROVIDER").
+func timeCorrections(timeout time.Duration, corrections []*models.Correction, late *lateCorrections, where string) {
+	if timeout <= 0 {
+		return
+	}
+	hung := false
+	for _, c := range corrections {
+		c, f := c, c.F
+		c.F = func() error {
+			if hung {
+				return errors.Errorf("not tried, an earlier change got no answer")
+			}
+			done := make(chan error, 1)
+			go func() { done <- f() }()
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+			select {
+			case err := <-done:
+				return err
+			case <-timer.C:
+				hung = true
+				go func() { late.add(where, c.Msg, <-done) }()
+				return errors.Errorf("%s, the change may still be made", timeoutError(timeout))
+			}
+		}
+	}
+}
+
+// lateCorrections notes what became of the corrections that were given up
+// on, and reported failed, once their provider answers.
+type lateCorrections struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (l *lateCorrections) add(where, correction string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		l.msgs = append(l.msgs, fmt.Sprintf("%s: %q failed after all, once the provider answered: %s", where, correction, err))
+	} else {
+		l.msgs = append(l.msgs, fmt.Sprintf("%s: %q was made after all, once the provider answered", where, correction))
+	}
+}
+
+// print warns about the corrections reported failed whose provider answered
+// since.
+func (l *lateCorrections) print(out printer.CLI) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, msg := range l.msgs {
+		out.Warnf("%s\n", msg)
+	}
+}
@@ -0,0 +1,239 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+)
+
+// hangProvider never answers reads of hang-read.example.com, nor the changes
+// to hang-apply.example.com, until hang is closed. The changes to the other
+// domains are recorded in applied.
+type hangProvider struct {
+	hang    chan struct{}
+	mu      *sync.Mutex
+	applied *[]string
+}
+
+var hung = hangProvider{mu: &sync.Mutex{}, applied: &[]string{}}
+
+func (hangProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	return nil, nil
+}
+
+func (p hangProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	name := dc.Name
+	if name == "hang-read.example.com" {
+		<-p.hang
+	}
+	var corrections []*models.Correction
+	for _, label := range []string{"www", "mail"} {
+		msg := "CREATE A " + label + "." + name
+		corrections = append(corrections, &models.Correction{Msg: msg, F: func() error {
+			p.mu.Lock()
+			*p.applied = append(*p.applied, msg)
+			p.mu.Unlock()
+			if name == "hang-apply.example.com" {
+				<-p.hang
+			}
+			return nil
+		}})
+	}
+	return corrections, nil
+}
+
+// lateProvider answers the read of late.example.com only once another domain
+// is read, after it was given up on. It then diffs it, while the other domain
+// is being worked on.
+type lateProvider struct {
+	release, done chan struct{}
+}
+
+func (lateProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	return nil, nil
+}
+
+func (p lateProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	var existing []*models.RecordConfig
+	if dc.Name == "late.example.com" {
+		<-p.release
+		defer close(p.done)
+		old := &models.RecordConfig{Type: "A", TTL: 86400}
+		old.SetLabel("old", dc.Name)
+		old.SetTarget("1.1.1.1")
+		existing = append(existing, old)
+	} else {
+		close(p.release)
+		<-p.done
+	}
+	_, create, del, mod := diff.New(dc).IncrementalDiff(existing)
+	var corrections []*models.Correction
+	for _, changes := range []diff.Changeset{create, del, mod} {
+		for _, c := range changes {
+			corrections = append(corrections, &models.Correction{Msg: c.String(), F: func() error { return nil }})
+		}
+	}
+	return corrections, nil
+}
+
+var late lateProvider
+
+func init() {
+	providers.RegisterDomainServiceProviderType("FAKE-HANG", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return hung, nil
+	})
+	providers.RegisterDomainServiceProviderType("FAKE-LATE", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return late, nil
+	})
+}
+
+func TestProviderTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "timeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	hung.hang = make(chan struct{})
+	defer close(hung.hang)
+	cfg := &models.DNSConfig{
+		Registrars:   []*models.RegistrarConfig{{Name: "none", Type: "NONE"}},
+		DNSProviders: []*models.DNSProviderConfig{{Name: "hang", Type: "FAKE-HANG"}},
+	}
+	for _, name := range []string{"a.example.com", "hang-read.example.com", "hang-apply.example.com", "z.example.com"} {
+		cfg.Domains = append(cfg.Domains, &models.DomainConfig{
+			Name:             name,
+			RegistrarName:    "none",
+			DNSProviderNames: map[string]int{"hang": 0},
+			Metadata:         map[string]string{},
+		})
+	}
+	args := PushArgs{}
+	args.JSONFile = writeConfig(t, dir, cfg)
+	args.CredsFile = filepath.Join(dir, "creds.json")
+	args.ProviderTimeout = 50 * time.Millisecond
+
+	start := time.Now()
+	err = run(args, true, printer.ConsolePrinter{})
+	if err == nil {
+		t.Errorf("Expected the hung domains to fail")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("Expected the hung provider to be given up on, the push took %s", d)
+	}
+	hung.mu.Lock()
+	applied := append([]string{}, *hung.applied...)
+	hung.mu.Unlock()
+	// The second change to hang-apply.example.com isn't tried.
+	want := []string{
+		"CREATE A www.a.example.com", "CREATE A mail.a.example.com",
+		"CREATE A www.hang-apply.example.com",
+		"CREATE A www.z.example.com", "CREATE A mail.z.example.com",
+	}
+	if !reflect.DeepEqual(applied, want) {
+		t.Errorf("Expected %q to be tried, got %q", want, applied)
+	}
+
+	_, err = getCorrections(args.ProviderTimeout, func() ([]*models.Correction, error) {
+		<-hung.hang
+		return nil, nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "no answer after 50ms") {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+}
+
+func TestProviderTimeoutLateDiff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "timeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	late = lateProvider{make(chan struct{}), make(chan struct{})}
+	www := &models.RecordConfig{Type: "A", TTL: 300}
+	www.SetLabel("www", "next.example.com")
+	www.SetTarget("1.1.1.2")
+	cfg := &models.DNSConfig{
+		Registrars:   []*models.RegistrarConfig{{Name: "none", Type: "NONE"}},
+		DNSProviders: []*models.DNSProviderConfig{{Name: "late", Type: "FAKE-LATE"}},
+	}
+	for _, name := range []string{"late.example.com", "next.example.com"} {
+		cfg.Domains = append(cfg.Domains, &models.DomainConfig{
+			Name:             name,
+			RegistrarName:    "none",
+			DNSProviderNames: map[string]int{"late": 0},
+			Metadata:         map[string]string{},
+		})
+	}
+	cfg.Domains[1].Records = []*models.RecordConfig{www}
+	args := PushArgs{}
+	args.JSONFile = writeConfig(t, dir, cfg)
+	args.CredsFile = filepath.Join(dir, "creds.json")
+	args.ProviderTimeout = 50 * time.Millisecond
+	var msgs, warnings, debug []string
+	out := warnPrinter{msgPrinter{msgs: &msgs}, &warnings}
+	if err := run(args, false, debugWarnPrinter{out, &debug}); err == nil {
+		t.Errorf("Expected late.example.com to fail")
+	}
+	// The diff of late.example.com is done while next.example.com is read:
+	// its purge and its stale window are not those of next.example.com.
+	for _, w := range append(warnings, debug...) {
+		if strings.Contains(w, "old.late.example.com") || strings.Contains(w, "PURGE") || strings.Contains(w, "stale window") {
+			t.Errorf("Expected nothing of late.example.com, got %q", w)
+		}
+	}
+	if want := []string{"CREATE A www.next.example.com 1.1.1.2 ttl=300"}; !reflect.DeepEqual(msgs, want) {
+		t.Errorf("Expected %q, got %q", want, msgs)
+	}
+}
+
+// debugWarnPrinter also records the debug output.
+type debugWarnPrinter struct {
+	warnPrinter
+	debug *[]string
+}
+
+func (p debugWarnPrinter) Debugf(format string, args ...interface{}) {
+	*p.debug = append(*p.debug, fmt.Sprintf(format, args...))
+}
+
+func TestTimeCorrectionsLate(t *testing.T) {
+	release := make(chan struct{})
+	c := &models.Correction{Msg: "CREATE A www.example.com", F: func() error {
+		<-release
+		return nil
+	}}
+	late := &lateCorrections{}
+	timeCorrections(10*time.Millisecond, []*models.Correction{c}, late, "example.com at slow")
+	if err := c.F(); err == nil || !strings.Contains(err.Error(), "may still be made") {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+	close(release)
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+		late.mu.Lock()
+		n := len(late.msgs)
+		late.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the late correction to be noted")
+		}
+	}
+	var warnings []string
+	late.print(warnPrinter{msgPrinter{msgs: &[]string{}}, &warnings})
+	if want := []string{`example.com at slow: "CREATE A www.example.com" was made after all, once the provider answered` + "\n"}; !reflect.DeepEqual(warnings, want) {
+		t.Errorf("Expected %q, got %q", want, warnings)
+	}
+}
@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/StackExchange/dnscontrol/pkg/snapshot"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var _ = cmd(catMain, func() *cli.Command {
+	var args RestoreArgs
+	return &cli.Command{
+		Name:      "restore",
+		Usage:     "revert a zone to a snapshot written by push -backup",
+		ArgsUsage: "SNAPSHOT",
+		Action: func(ctx *cli.Context) error {
+			if ctx.NArg() != 1 {
+				return cli.NewExitError("restore needs exactly one SNAPSHOT file", 1)
+			}
+			args.Snapshot = ctx.Args().First()
+			return exit(Restore(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// RestoreArgs contains all data/flags needed to run restore, independently of CLI.
+type RestoreArgs struct {
+	GetDNSConfigArgs
+	GetCredentialsArgs
+	ValidateArgs
+	Snapshot string
+	Yes      bool
+}
+
+func (args *RestoreArgs) flags() []cli.Flag {
+	flags := args.GetDNSConfigArgs.flags()
+	flags = append(flags, args.GetCredentialsArgs.flags()...)
+	flags = append(flags, args.ValidateArgs.flags()...)
+	flags = append(flags, cli.BoolFlag{
+		Name:        "y",
+		Destination: &args.Yes,
+		Usage:       "Do not ask for confirmation before restoring",
+	})
+	return flags
+}
+
+// Restore pushes the records in a snapshot back to the provider it was taken from.
+// The provider is found by name in the configuration, so that its credentials
+// and metadata are used. Everything in the zone that is not in the snapshot is
+// deleted, including IGNOREd records.
+func Restore(args RestoreArgs) error {
+	return restore(args, printer.ConsolePrinter{})
+}
+
+func restore(args RestoreArgs, out printer.CLI) error {
+	snap, err := snapshot.Read(args.Snapshot)
+	if err != nil {
+		return err
+	}
+	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
+	if err != nil {
+		return err
+	}
+	res := args.validate(cfg)
+	if PrintValidationErrors(res, args.Strict) {
+		return errors.Errorf("Exiting due to validation errors")
+	}
+	notifier, err := InitializeProviders(args.GetCredentialsArgs, cfg, false)
+	if err != nil {
+		return err
+	}
+	domain := cfg.FindDomain(snap.Domain)
+	if domain == nil {
+		return errors.Errorf("%s is not in the configuration", snap.Domain)
+	}
+	var provider *models.DNSProviderInstance
+	for _, p := range domain.DNSProviderInstances {
+		if p.Name == snap.Provider {
+			provider = p
+		}
+	}
+	if provider == nil {
+		return errors.Errorf("%s does not use the provider %s", snap.Domain, snap.Provider)
+	}
+	if provider.ProviderType != snap.ProviderType {
+		return errors.Errorf("snapshot is from a %s provider, but %s is now %s", snap.ProviderType, provider.Name, provider.ProviderType)
+	}
+
+	dc, err := domain.Copy()
+	if err != nil {
+		return err
+	}
+	snap.Records = withoutSOA(snap.Records)
+	dc.Records = snap.Records
+	dc.KeepUnknown = false
+	dc.IgnoredLabels = nil
+	if err := providers.PrepareDomain(provider.ProviderType, dc); err != nil {
+		return err
+	}
+
+	corrections, collector, err := diffCorrections(0, provider.Driver, dc)
+	if err != nil {
+		return err
+	}
+
+	out.StartDomain(snap.Domain)
+	if len(corrections) == 0 {
+		out.Debugf("%s at %s already matches the snapshot of %s\n", snap.Domain, provider.Name, snap.Time)
+		return nil
+	}
+	for _, r := range snap.Added(withoutSOA(collector.Existing)) {
+		out.Warnf("%s %s %s is not in the snapshot and will be removed\n", r.GetLabelFQDN(), r.Type, r.GetTargetCombined())
+	}
+	var anyErrors bool
+	if args.Yes {
+		anyErrors = printOrRunCorrections(snap.Domain, provider.Name, corrections, out, true, false, false, notifier)
+	} else {
+		// The corrections are printed once, before asking, and only their
+		// failures after.
+		for i, c := range corrections {
+			out.PrintCorrection(i, c)
+		}
+		if !confirm(fmt.Sprintf("Restore %s at %s to the snapshot of %s?", snap.Domain, provider.Name, snap.Time)) {
+			return errors.Errorf("Restore cancelled")
+		}
+		for i, c := range corrections {
+			err := c.F()
+			if err != nil {
+				out.Warnf("#%d failed: %s\n", i+1, err)
+				anyErrors = true
+			}
+			notifier.Notify(snap.Domain, provider.Name, c.Msg, err, false)
+		}
+	}
+	notifier.Done()
+	if anyErrors {
+		return errors.Errorf("Completed with errors")
+	}
+	return nil
+}
+
+// withoutSOA drops SOA records. Providers manage the SOA themselves and some
+// include it in the records they read, so it can't be restored as a record.
+func withoutSOA(records []*models.RecordConfig) []*models.RecordConfig {
+	var result []*models.RecordConfig
+	for _, r := range records {
+		if r.Type != "SOA" {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// confirm asks a yes/no question. Anything but yes means no.
+func confirm(question string) bool {
+	fmt.Printf("%s (y/N): ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/snapshot"
+)
+
+func TestRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "restore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("zone", "FAKE-ZONE")),
+	A("@", "1.1.1.1")
+);`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credsFile := filepath.Join(dir, "creds.json")
+	err = ioutil.WriteFile(credsFile, []byte(`{"zone": {"zone": "restore"}}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zone := &zoneProvider{map[string]uint32{"@ A 1.1.1.1": 300, "new A 1.1.1.3": 300}, nil}
+	migrateZones["restore"] = zone
+	var records []*models.RecordConfig
+	for _, r := range []struct{ label, target string }{{"@", "1.1.1.1"}, {"www", "1.1.1.2"}} {
+		rc := &models.RecordConfig{Type: "A", TTL: 300, Metadata: map[string]string{}}
+		rc.SetLabel(r.label, "example.com")
+		rc.SetTarget(r.target)
+		records = append(records, rc)
+	}
+	snapFile, err := snapshot.New("example.com", "zone", "FAKE-ZONE", records).Write(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	args := RestoreArgs{Snapshot: snapFile, Yes: true}
+	args.JSFile = jsFile
+	args.CredsFile = credsFile
+	var msgs, warnings []string
+	out := warnPrinter{msgPrinter{msgs: &msgs}, &warnings}
+	if err := restore(args, out); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]uint32{"@ A 1.1.1.1": 300, "www A 1.1.1.2": 300}; !reflect.DeepEqual(zone.zone, want) {
+		t.Errorf("Expected the zone of the snapshot %v, got %v", want, zone.zone)
+	}
+	// Each correction is printed once.
+	if len(msgs) != 2 {
+		t.Errorf("Expected the 2 corrections to be printed once, got %q", msgs)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "new.example.com A 1.1.1.3 is not in the snapshot") {
+		t.Errorf("Expected a warning about the record that is removed, got %q", warnings)
+	}
+
+	msgs = nil
+	if err := restore(args, out); err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 0 {
+		t.Errorf("Expected nothing to restore, got %q", msgs)
+	}
+}
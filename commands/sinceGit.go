@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/js"
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/pkg/errors"
+)
+
+// gitShow returns the contents of file at ref. It is replaced in tests.
+var gitShow = func(ref, file string) ([]byte, error) {
+	return exec.Command("git", "show", ref+":./"+filepath.ToSlash(file)).Output()
+}
+
+// gitChangedFiles returns the files that differ between ref and the working
+// tree, relative to the current directory. It is replaced in tests.
+var gitChangedFiles = func(ref string) ([]string, error) {
+	out, err := exec.Command("git", "diff", "--name-only", "--relative", ref).Output()
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// gitHead returns the commit the configuration file is at, with a "+" if it
+// has uncommitted changes. It is replaced in tests.
+var gitHead = func(args GetDNSConfigArgs) (string, error) {
+	file := args.JSFile
+	if args.JSONFile != "" {
+		file = args.JSONFile
+	}
+	dir := filepath.Dir(file)
+	cmd := exec.Command("git", "rev-parse", "--short", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	commit := strings.TrimSpace(string(out))
+	cmd = exec.Command("git", "status", "--porcelain", "--", filepath.Base(file))
+	cmd.Dir = dir
+	if out, err := cmd.Output(); err == nil && len(bytes.TrimSpace(out)) > 0 {
+		commit += "+"
+	}
+	return commit, nil
+}
+
+// changedDomains returns the domains of cfg whose configuration is not the
+// same as at the git ref, as told by domainHashes: a change to one of their
+// providers changes them too. It returns nil if all domains have to be
+// processed, because a file other than the configuration itself changed: a
+// require()d file is read from the working tree for both versions, so changes
+// to it can't be seen by comparing them.
+func changedDomains(args GetDNSConfigArgs, ref string, cfg *models.DNSConfig, out printer.CLI) (map[string]bool, error) {
+	file := args.JSFile
+	if args.JSONFile != "" {
+		file = args.JSONFile
+	}
+	file = relPath(file)
+
+	files, err := gitChangedFiles(ref)
+	if err != nil {
+		return nil, errors.Errorf("Listing files changed since %s: %s", ref, err)
+	}
+	configChanged := false
+	for _, f := range files {
+		if f = relPath(f); f == file {
+			configChanged = true
+		} else if args.JSONFile == "" && strings.HasSuffix(f, ".js") {
+			out.Debugf("%s changed since %s, processing all domains\n", f, ref)
+			return nil, nil
+		}
+	}
+	changed := map[string]bool{}
+	if !configChanged {
+		return changed, nil
+	}
+
+	text, err := gitShow(ref, file)
+	if err != nil {
+		return nil, errors.Errorf("Reading %s at %s: %s", file, ref, err)
+	}
+	base := &models.DNSConfig{}
+	if args.JSONFile != "" {
+		err = json.Unmarshal(text, base)
+	} else {
+		var inv js.Inventory
+		if inv, err = args.inventory(); err == nil {
+			base, err = js.ExecuteJavascript(string(text), args.DevMode, inv)
+		}
+	}
+	if err != nil {
+		return nil, errors.Errorf("Executing %s at %s: %s", file, ref, err)
+	}
+
+	before, after := domainHashes(base), domainHashes(cfg)
+	for _, d := range cfg.Domains {
+		name := strings.ToLower(d.Name)
+		if h, ok := before[name]; !ok || h != after[name] {
+			changed[d.Name] = true
+		}
+	}
+	return changed, nil
+}
+
+// relPath returns file relative to the current directory, as git prints it.
+func relPath(file string) string {
+	if filepath.IsAbs(file) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, file); err == nil {
+				return rel
+			}
+		}
+	}
+	return filepath.Clean(file)
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+)
+
+const sinceGitBase = `
+var REG = NewRegistrar("none", "NONE");
+var FAKE = NewDnsProvider("fake", "FAKE-PUSH");
+D("a.example.com", REG, DnsProvider(FAKE), A("www", "1.2.3.4"));
+D("b.example.com", REG, DnsProvider(FAKE), A("www", "1.2.3.4"));
+D("c.example.com", REG, DnsProvider(FAKE), A("www", "1.2.3.4"));
+`
+
+const sinceGitCurrent = `
+var REG = NewRegistrar("none", "NONE");
+var FAKE = NewDnsProvider("fake", "FAKE-PUSH");
+D("a.example.com", REG, DnsProvider(FAKE), A("www", "1.2.3.4"));
+D("b.example.com", REG, DnsProvider(FAKE), A("www", "1.2.3.5"));
+D("c.example.com", REG, DnsProvider(FAKE), A("www", "1.2.3.4"));
+D("d.example.com", REG, DnsProvider(FAKE), A("www", "1.2.3.4"));
+`
+
+// mockGit makes the git helpers return base for the file at any ref, and
+// files as the changed files. It returns a func that puts the real ones back.
+func mockGit(t *testing.T, base string, files ...string) func() {
+	show, diff := gitShow, gitChangedFiles
+	gitShow = func(ref, file string) ([]byte, error) {
+		if ref != "origin/master" {
+			t.Errorf("unexpected ref %s", ref)
+		}
+		return []byte(base), nil
+	}
+	gitChangedFiles = func(ref string) ([]string, error) { return files, nil }
+	return func() { gitShow, gitChangedFiles = show, diff }
+}
+
+func TestSinceGit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sincegit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	if err := ioutil.WriteFile(jsFile, []byte(sinceGitCurrent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	all := []string{"a.example.com", "b.example.com", "c.example.com", "d.example.com"}
+	// The same domains, with another setting of the provider.
+	providerBase := strings.Replace(sinceGitCurrent, `"FAKE-PUSH"`, `"FAKE-PUSH", {"note": "old"}`, 1)
+	for _, tst := range []struct {
+		desc    string
+		base    string
+		files   []string
+		applied []string
+	}{
+		{"config changed", sinceGitBase, []string{jsFile, "README.md"}, []string{"b.example.com", "d.example.com"}},
+		{"nothing changed", sinceGitBase, []string{"README.md"}, nil},
+		{"included file changed", sinceGitBase, []string{jsFile, filepath.Join(dir, "common.js")}, all},
+		{"provider changed", providerBase, []string{jsFile}, all},
+	} {
+		defer mockGit(t, tst.base, tst.files...)()
+		fakeApplied = nil
+		args := PushArgs{}
+		args.JSFile = jsFile
+		args.CredsFile = filepath.Join(dir, "creds.json")
+		args.SinceGit = "origin/master"
+		if err := run(args, true, printer.ConsolePrinter{}); err != nil {
+			t.Fatalf("%s: %s", tst.desc, err)
+		}
+		sort.Strings(fakeApplied)
+		if !reflect.DeepEqual(fakeApplied, tst.applied) {
+			t.Errorf("%s: expected %v to be pushed, got %v", tst.desc, tst.applied, fakeApplied)
+		}
+	}
+}
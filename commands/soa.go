@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+// soaQuery asks a nameserver for the SOA of a domain. Tests replace it.
+var soaQuery = func(nameserver, domain string) (*dns.SOA, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(domain), dns.TypeSOA)
+	m.RecursionDesired = false
+	c := &dns.Client{Timeout: 5 * time.Second}
+	r, _, err := c.Exchange(m, net.JoinHostPort(strings.TrimSuffix(nameserver, "."), "53"))
+	if err != nil {
+		return nil, err
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		return nil, errors.Errorf("%s", dns.RcodeToString[r.Rcode])
+	}
+	for _, rr := range r.Answer {
+		if soa, ok := rr.(*dns.SOA); ok {
+			return soa, nil
+		}
+	}
+	return nil, errors.Errorf("no SOA in the answer")
+}
+
+// providerSOA reads the SOA that one of the nameservers of a provider serves
+// for domain. The first nameserver that answers is used.
+func providerSOA(provider *models.DNSProviderInstance, domain string) (*dns.SOA, error) {
+	nss, err := provider.Driver.GetNameservers(domain)
+	if err != nil {
+		return nil, err
+	}
+	if len(nss) == 0 {
+		return nil, errors.Errorf("%s has no nameservers for %s", provider.Name, domain)
+	}
+	var errs []string
+	for _, ns := range nss {
+		soa, err := soaQuery(ns.Name, domain)
+		if err == nil {
+			return soa, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", ns.Name, err))
+	}
+	return nil, errors.Errorf("no nameserver of %s answered for the SOA of %s (%s)", provider.Name, domain, strings.Join(errs, "; "))
+}
+
+// checkSOA compares the SOA that the DNS providers of a domain serve, for
+// -require-consistent-soa. Serials are usually kept by each provider, so only
+// the refresh, retry, expire and minimum (negative caching) times are
+// compared. It returns one line per time that differs. Providers whose
+// nameservers aren't used (NumberOfNameservers 0) are left out, and a domain
+// with fewer than two providers left has nothing to compare.
+func checkSOA(domain *models.DomainConfig) ([]string, error) {
+	var serving []*models.DNSProviderInstance
+	for _, provider := range domain.DNSProviderInstances {
+		if provider.NumberOfNameservers != 0 {
+			serving = append(serving, provider)
+		}
+	}
+	if len(serving) < 2 {
+		return nil, nil
+	}
+	var names []string
+	var soas []*dns.SOA
+	for _, provider := range serving {
+		soa, err := providerSOA(provider, domain.Name)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, provider.Name)
+		soas = append(soas, soa)
+	}
+	var problems []string
+	for _, field := range []struct {
+		name  string
+		value func(*dns.SOA) uint32
+	}{
+		{"refresh", func(s *dns.SOA) uint32 { return s.Refresh }},
+		{"retry", func(s *dns.SOA) uint32 { return s.Retry }},
+		{"expire", func(s *dns.SOA) uint32 { return s.Expire }},
+		{"minimum", func(s *dns.SOA) uint32 { return s.Minttl }},
+	} {
+		var values []string
+		differ := false
+		for i, soa := range soas {
+			differ = differ || field.value(soa) != field.value(soas[0])
+			values = append(values, fmt.Sprintf("%d at %s", field.value(soa), names[i]))
+		}
+		if differ {
+			problems = append(problems, fmt.Sprintf("%s differs: %s", field.name, strings.Join(values, ", ")))
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+// nsProvider is a fakeProvider with nameservers.
+type nsProvider struct {
+	fakeProvider
+	nss []string
+}
+
+func (p nsProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	return models.StringsToNameservers(p.nss), nil
+}
+
+func init() {
+	providers.RegisterDomainServiceProviderType("FAKE-SOA-A", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return nsProvider{fakeProvider{&fakeApplied}, []string{"ns1.a.example.net"}}, nil
+	})
+	providers.RegisterDomainServiceProviderType("FAKE-SOA-B", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return nsProvider{fakeProvider{&fakeApplied}, []string{"ns1.b.example.net", "ns2.b.example.net"}}, nil
+	})
+}
+
+// fakeSOAs replaces soaQuery with the SOAs in soas, by nameserver, until
+// the func it returns is called. Other nameservers don't answer.
+func fakeSOAs(soas map[string]*dns.SOA) func() {
+	query := soaQuery
+	soaQuery = func(nameserver, domain string) (*dns.SOA, error) {
+		if soa := soas[nameserver]; soa != nil {
+			return soa, nil
+		}
+		return nil, errors.Errorf("i/o timeout")
+	}
+	return func() { soaQuery = query }
+}
+
+func soa(serial, refresh, retry, expire, minimum uint32) *dns.SOA {
+	return &dns.SOA{Serial: serial, Refresh: refresh, Retry: retry, Expire: expire, Minttl: minimum}
+}
+
+func TestCheckSOA(t *testing.T) {
+	instance := func(name string, n int, nss ...string) *models.DNSProviderInstance {
+		return &models.DNSProviderInstance{
+			ProviderBase:        models.ProviderBase{Name: name},
+			Driver:              nsProvider{nss: nss},
+			NumberOfNameservers: n,
+		}
+	}
+	a := instance("a", -1, "ns1.a.example.net")
+	b := instance("b", -1, "ns1.b.example.net", "ns2.b.example.net")
+	secondary := instance("secondary", 0, "ns1.c.example.net")
+
+	for _, tst := range []struct {
+		desc     string
+		soas     map[string]*dns.SOA
+		dsps     []*models.DNSProviderInstance
+		problems []string
+		err      string
+	}{
+		{"same", map[string]*dns.SOA{
+			"ns1.a.example.net": soa(1, 7200, 900, 1209600, 300),
+			"ns1.b.example.net": soa(2019010101, 7200, 900, 1209600, 300),
+		}, []*models.DNSProviderInstance{a, b}, nil, ""},
+		{"mismatched", map[string]*dns.SOA{
+			"ns1.a.example.net": soa(1, 7200, 900, 1209600, 300),
+			"ns2.b.example.net": soa(1, 3600, 900, 604800, 300),
+		}, []*models.DNSProviderInstance{a, b}, []string{
+			"refresh differs: 7200 at a, 3600 at b",
+			"expire differs: 1209600 at a, 604800 at b",
+		}, ""},
+		{"unused nameservers", map[string]*dns.SOA{
+			"ns1.a.example.net": soa(1, 7200, 900, 1209600, 300),
+			"ns1.c.example.net": soa(1, 3600, 900, 604800, 60),
+		}, []*models.DNSProviderInstance{a, secondary}, nil, ""},
+		{"no answer", map[string]*dns.SOA{
+			"ns1.a.example.net": soa(1, 7200, 900, 1209600, 300),
+		}, []*models.DNSProviderInstance{a, b}, nil, "no nameserver of b answered for the SOA of example.com (ns1.b.example.net: i/o timeout; ns2.b.example.net: i/o timeout)"},
+	} {
+		t.Run(tst.desc, func(t *testing.T) {
+			defer fakeSOAs(tst.soas)()
+			problems, err := checkSOA(&models.DomainConfig{Name: "example.com", DNSProviderInstances: tst.dsps})
+			if tst.err != "" {
+				if err == nil || err.Error() != tst.err {
+					t.Fatalf("Expected error %q, got %v", tst.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(problems, tst.problems) {
+				t.Errorf("Expected %q, got %q", tst.problems, problems)
+			}
+		})
+	}
+}
+
+func TestRequireConsistentSOA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "push")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer fakeSOAs(map[string]*dns.SOA{
+		"ns1.a.example.net": soa(1, 7200, 900, 1209600, 300),
+		"ns1.b.example.net": soa(1, 7200, 900, 1209600, 3600),
+	})()
+	cfg := &models.DNSConfig{
+		Registrars: []*models.RegistrarConfig{{Name: "none", Type: "NONE"}},
+		DNSProviders: []*models.DNSProviderConfig{
+			{Name: "a", Type: "FAKE-SOA-A"},
+			{Name: "b", Type: "FAKE-SOA-B"},
+		},
+		Domains: []*models.DomainConfig{
+			{Name: "dual.example.com", RegistrarName: "none", DNSProviderNames: map[string]int{"a": -1, "b": -1}, Metadata: map[string]string{}},
+			{Name: "single.example.com", RegistrarName: "none", DNSProviderNames: map[string]int{"a": -1}, Metadata: map[string]string{}},
+		},
+	}
+	args := PushArgs{}
+	args.JSONFile = writeConfig(t, dir, cfg)
+	args.CredsFile = filepath.Join(dir, "creds.json")
+	args.ConsistentSOA = true
+
+	// preview only warns.
+	if err := run(args, false, printer.ConsolePrinter{}); err != nil {
+		t.Fatal(err)
+	}
+	fakeApplied = nil
+	err = run(args, true, printer.ConsolePrinter{})
+	if err == nil || !strings.Contains(err.Error(), "Completed with errors") {
+		t.Errorf("Expected the push to fail, got %v", err)
+	}
+	if want := []string{"single.example.com"}; !reflect.DeepEqual(fakeApplied, want) {
+		t.Errorf("Expected only %v to be pushed, got %v", want, fakeApplied)
+	}
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+)
+
+// staleWindow estimates how long after a push resolvers may still serve the
+// old values of the records it changes: the highest TTL the modified and
+// deleted records had. Created records had no value to cache.
+type staleWindow struct {
+	ttl uint32
+	// record is the record with that TTL, as "TYPE fqdn".
+	record string
+}
+
+// add notes the old TTLs of the changes. With deletesOnly (-deletes-only)
+// only the deletions are made.
+func (w *staleWindow) add(changes []diff.Correlation, deletesOnly bool) {
+	for _, c := range changes {
+		if c.Existing == nil || (deletesOnly && c.Desired != nil) {
+			continue
+		}
+		if c.Existing.TTL > w.ttl {
+			w.ttl = c.Existing.TTL
+			w.record = fmt.Sprintf("%s %s", c.Existing.Type, c.Existing.GetLabelFQDN())
+		}
+	}
+}
+
+func (w *staleWindow) print(out printer.CLI) {
+	if w.ttl == 0 {
+		return
+	}
+	out.Debugf("Max stale window: %s (based on old TTLs, the longest is %s). Resolvers may serve the old values until then\n", diff.HumanTTL(w.ttl), w.record)
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+)
+
+func TestStaleWindow(t *testing.T) {
+	rec := func(label string, ttl uint32) *models.RecordConfig {
+		return &models.RecordConfig{Type: "A", Name: label, NameFQDN: label + ".example.com", TTL: ttl}
+	}
+	changes := []diff.Correlation{
+		{Existing: nil, Desired: rec("new", 86400)},
+		{Existing: rec("www", 3600), Desired: rec("www", 300)},
+		{Existing: rec("old", 600), Desired: nil},
+	}
+	for _, tst := range []struct {
+		changes     []diff.Correlation
+		deletesOnly bool
+		ttl         uint32
+		record      string
+	}{
+		{nil, false, 0, ""},
+		// A created record had no old value.
+		{changes[:1], false, 0, ""},
+		{changes, false, 3600, "A www.example.com"},
+		// -deletes-only leaves the modification out.
+		{changes, true, 600, "A old.example.com"},
+	} {
+		w := &staleWindow{}
+		w.add(tst.changes, tst.deletesOnly)
+		if w.ttl != tst.ttl || w.record != tst.record {
+			t.Errorf("%d changes, deletesOnly=%v: expected %d (%s), got %d (%s)", len(tst.changes), tst.deletesOnly, tst.ttl, tst.record, w.ttl, w.record)
+		}
+	}
+}
+
+func TestPreviewStaleWindow(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stale")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("zone", "FAKE-ZONE"), 0),
+	A("www", "1.1.1.2", TTL(300)),
+	A("mail", "1.1.1.3", TTL(86400))
+);`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credsFile := filepath.Join(dir, "creds.json")
+	if err := ioutil.WriteFile(credsFile, []byte(`{"zone": {"zone": "stale"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	migrateZones["stale"] = &zoneProvider{map[string]uint32{
+		"www A 1.1.1.1": 7200,
+		"ftp A 1.1.1.4": 3600,
+	}, nil}
+	args := PushArgs{}
+	args.JSFile = jsFile
+	args.CredsFile = credsFile
+	var debug []string
+	if err := run(args, false, debugPrinter{lines: &debug}); err != nil {
+		t.Fatal(err)
+	}
+	want := "Max stale window: 2h (based on old TTLs, the longest is A www.example.com)"
+	if got := strings.Join(debug, ""); !strings.Contains(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
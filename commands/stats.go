@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+var _ = cmd(catUtils, func() *cli.Command {
+	var args StatsArgs
+	return &cli.Command{
+		Name:  "stats",
+		Usage: "count the records of each type that every domain declares, without accessing providers",
+		Action: func(ctx *cli.Context) error {
+			return exit(Stats(args))
+		},
+		Flags: args.flags(),
+	}
+}())
+
+// StatsArgs contains all data/flags needed to run stats, independently of CLI.
+type StatsArgs struct {
+	GetDNSConfigArgs
+	ValidateArgs
+	JSON bool
+	Top  int
+}
+
+func (args *StatsArgs) flags() []cli.Flag {
+	// -json is the output format here, so the hidden -json alias of -ir is
+	// left out.
+	flags := withoutFlag(args.GetDNSConfigArgs.flags(), "json")
+	flags = append(flags, args.ValidateArgs.flags()...)
+	flags = append(flags, cli.BoolFlag{
+		Name:        "json",
+		Destination: &args.JSON,
+		Usage:       "Print the statistics as JSON",
+	})
+	flags = append(flags, cli.IntFlag{
+		Name:        "top",
+		Destination: &args.Top,
+		Value:       10,
+		Usage:       "How many of the largest domains to list",
+	})
+	return flags
+}
+
+// configStats are the statistics stats prints.
+type configStats struct {
+	Domains      []domainStats   `json:"domains"`
+	Records      int             `json:"records"`
+	ByType       map[string]int  `json:"by_type"`
+	Largest      []string        `json:"largest"`
+	DNSProviders []providerUsage `json:"dns_providers"`
+	Registrars   []providerUsage `json:"registrars"`
+}
+
+// domainStats counts the records of one domain.
+type domainStats struct {
+	Name    string         `json:"name"`
+	Records int            `json:"records"`
+	ByType  map[string]int `json:"by_type"`
+}
+
+// providerUsage is how many domains use a provider.
+type providerUsage struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Domains int    `json:"domains"`
+}
+
+// Stats implements the stats subcommand. The records are counted once the
+// configuration is normalized, so IMPORT_TRANSFORM() and the like have
+// added theirs, but no provider is accessed: the NS records that come from
+// the providers' nameservers are not counted.
+func Stats(args StatsArgs) error {
+	cfg, err := GetDNSConfig(args.GetDNSConfigArgs)
+	if err != nil {
+		return err
+	}
+	res := args.validate(cfg)
+	if PrintValidationErrors(res, args.Strict) {
+		return errors.Errorf("Exiting due to validation errors")
+	}
+	return printStats(os.Stdout, countStats(cfg, args.Top), args.JSON)
+}
+
+// countStats counts the records of cfg, and lists its top largest domains.
+func countStats(cfg *models.DNSConfig, top int) *configStats {
+	s := &configStats{ByType: map[string]int{}, Largest: []string{}}
+	dsps := map[string]*providerUsage{}
+	regs := map[string]*providerUsage{}
+	for _, domain := range cfg.Domains {
+		d := domainStats{Name: domain.Name, ByType: map[string]int{}}
+		for _, r := range domain.Records {
+			d.ByType[r.Type]++
+			s.ByType[r.Type]++
+		}
+		d.Records = len(domain.Records)
+		s.Records += d.Records
+		s.Domains = append(s.Domains, d)
+		for _, p := range domain.DNSProviderInstances {
+			if dsps[p.Name] == nil {
+				dsps[p.Name] = &providerUsage{Name: p.Name, Type: p.ProviderType}
+			}
+			dsps[p.Name].Domains++
+		}
+		if r := domain.RegistrarInstance; r != nil {
+			if regs[r.Name] == nil {
+				regs[r.Name] = &providerUsage{Name: r.Name, Type: r.ProviderType}
+			}
+			regs[r.Name].Domains++
+		}
+	}
+	largest := append([]domainStats(nil), s.Domains...)
+	sort.SliceStable(largest, func(i, j int) bool { return largest[i].Records > largest[j].Records })
+	for i := 0; i < len(largest) && i < top; i++ {
+		s.Largest = append(s.Largest, largest[i].Name)
+	}
+	s.DNSProviders = mostUsed(dsps)
+	s.Registrars = mostUsed(regs)
+	return s
+}
+
+// mostUsed sorts the providers by the number of domains that use them, then
+// by name.
+func mostUsed(m map[string]*providerUsage) []providerUsage {
+	list := []providerUsage{}
+	for _, p := range m {
+		list = append(list, *p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Domains != list[j].Domains {
+			return list[i].Domains > list[j].Domains
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// byType formats record counts as "A 3, MX 1", by type.
+func byType(counts map[string]int) string {
+	var types []string
+	for t := range counts {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	var parts []string
+	for _, t := range types {
+		parts = append(parts, fmt.Sprintf("%s %d", t, counts[t]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printStats(w io.Writer, s *configStats, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+	counts := map[string]int{}
+	for _, d := range s.Domains {
+		fmt.Fprintf(w, "%s: %d records (%s)\n", d.Name, d.Records, byType(d.ByType))
+		counts[d.Name] = d.Records
+	}
+	fmt.Fprintf(w, "Total: %d records in %d domains (%s)\n", s.Records, len(s.Domains), byType(s.ByType))
+	if len(s.Largest) > 0 {
+		fmt.Fprintf(w, "\nLargest domains:\n")
+		for _, name := range s.Largest {
+			fmt.Fprintf(w, "  %-30s %d\n", name, counts[name])
+		}
+	}
+	for _, list := range []struct {
+		title     string
+		providers []providerUsage
+	}{{"DNS providers", s.DNSProviders}, {"Registrars", s.Registrars}} {
+		if len(list.providers) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", list.title)
+		for _, p := range list.providers {
+			fmt.Fprintf(w, "  %-30s %d domains\n", p.Name+" ("+p.Type+")", p.Domains)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	args := GetDNSConfigArgs{}
+	args.JSFile = filepath.Join("testdata", "stats", "dnsconfig.js")
+	cfg, err := GetDNSConfig(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := (&ValidateArgs{}).validate(cfg); len(res.Errors) != 0 {
+		t.Fatal(res.Errors)
+	}
+	s := countStats(cfg, 2)
+
+	want := []domainStats{
+		{"example.com", 7, map[string]int{"A": 3, "AAAA": 1, "CNAME": 1, "MX": 1, "TXT": 1}},
+		{"example.net", 2, map[string]int{"A": 1, "MX": 1}},
+		// The A record imported from example.net counts too.
+		{"example.org", 2, map[string]int{"A": 2}},
+	}
+	if !reflect.DeepEqual(s.Domains, want) {
+		t.Errorf("Expected domains %v, got %v", want, s.Domains)
+	}
+	if s.Records != 11 || !reflect.DeepEqual(s.ByType, map[string]int{"A": 6, "AAAA": 1, "CNAME": 1, "MX": 2, "TXT": 1}) {
+		t.Errorf("Expected 11 records in total, got %d %v", s.Records, s.ByType)
+	}
+	if want := []string{"example.com", "example.net"}; !reflect.DeepEqual(s.Largest, want) {
+		t.Errorf("Expected the largest domains to be %v, got %v", want, s.Largest)
+	}
+	if want := []providerUsage{{"r53", "ROUTE53", 3}, {"cf", "CLOUDFLAREAPI", 1}}; !reflect.DeepEqual(s.DNSProviders, want) {
+		t.Errorf("Expected DNS providers %v, got %v", want, s.DNSProviders)
+	}
+	if want := []providerUsage{{"none", "NONE", 3}}; !reflect.DeepEqual(s.Registrars, want) {
+		t.Errorf("Expected registrars %v, got %v", want, s.Registrars)
+	}
+
+	var text bytes.Buffer
+	if err := printStats(&text, s, false); err != nil {
+		t.Fatal(err)
+	}
+	wantText := `example.com: 7 records (A 3, AAAA 1, CNAME 1, MX 1, TXT 1)
+example.net: 2 records (A 1, MX 1)
+example.org: 2 records (A 2)
+Total: 11 records in 3 domains (A 6, AAAA 1, CNAME 1, MX 2, TXT 1)
+
+Largest domains:
+  example.com                    7
+  example.net                    2
+
+DNS providers:
+  r53 (ROUTE53)                  3 domains
+  cf (CLOUDFLAREAPI)             1 domains
+
+Registrars:
+  none (NONE)                    3 domains
+`
+	if text.String() != wantText {
+		t.Errorf("Expected:\n%s\ngot:\n%s", wantText, text.String())
+	}
+
+	var out bytes.Buffer
+	if err := printStats(&out, s, true); err != nil {
+		t.Fatal(err)
+	}
+	var back configStats
+	if err := json.Unmarshal(out.Bytes(), &back); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&back, s) {
+		t.Errorf("Expected the JSON to hold the same statistics, got %s", out.String())
+	}
+}
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+	"github.com/urfave/cli"
+)
+
+// errPendingChanges is the exit of preview -summary when there are changes
+// to push. The summary has already been printed.
+var errPendingChanges = cli.NewExitError("", 2)
+
+// changeCounts counts the changes of a domain, by type.
+type changeCounts struct {
+	creates, modifies, deletes, other int
+}
+
+// add counts the changes of a correction, by their kinds (see changeIndex).
+// A correction that makes none of the changes of the diff is one change of
+// another type.
+func (c *changeCounts) add(kinds []string) {
+	for _, k := range kinds {
+		switch k {
+		case "CREATE":
+			c.creates++
+		case "MODIFY":
+			c.modifies++
+		case "DELETE":
+			c.deletes++
+		}
+	}
+	if len(kinds) == 0 {
+		c.other++
+	}
+}
+
+func (c *changeCounts) plus(o *changeCounts) {
+	c.creates += o.creates
+	c.modifies += o.modifies
+	c.deletes += o.deletes
+	c.other += o.other
+}
+
+func (c *changeCounts) total() int {
+	return c.creates + c.modifies + c.deletes + c.other
+}
+
+func (c *changeCounts) String() string {
+	s := fmt.Sprintf("%d to create, %d to modify, %d to delete", c.creates, c.modifies, c.deletes)
+	if c.other > 0 {
+		s += fmt.Sprintf(", %d other", c.other)
+	}
+	return s
+}
+
+// summaryPrinter implements preview -summary. It counts the corrections of
+// each domain instead of printing them. Warnings and errors are still
+// printed.
+type summaryPrinter struct {
+	printer.CLI
+	domain, provider string
+	domains          []string
+	counts           map[string]*changeCounts
+	changes          changeIndex
+}
+
+func newSummaryPrinter(out printer.CLI) *summaryPrinter {
+	return &summaryPrinter{CLI: out, counts: map[string]*changeCounts{}}
+}
+
+// StartDomain implements printer.CLI.
+func (p *summaryPrinter) StartDomain(domain string) {
+	p.domain = domain
+	if p.counts[domain] == nil {
+		p.domains = append(p.domains, domain)
+		p.counts[domain] = &changeCounts{}
+	}
+}
+
+// StartDNSProvider implements printer.CLI.
+func (p *summaryPrinter) StartDNSProvider(name string, skip bool) { p.provider, p.changes = name, nil }
+
+// StartRegistrar implements printer.CLI.
+func (p *summaryPrinter) StartRegistrar(name string, skip bool) { p.provider, p.changes = name, nil }
+
+// PrintChanges implements changesPrinter. The changes are counted as the
+// corrections that make them are printed.
+func (p *summaryPrinter) PrintChanges(changes []diff.Correlation, deletesOnly bool) {
+	p.changes = newChangeIndex(changes)
+}
+
+// EndProvider implements printer.CLI.
+func (p *summaryPrinter) EndProvider(numCorrections int, err error) {
+	if err != nil {
+		p.Warnf("Error getting corrections for %s from %s: %s\n", p.domain, p.provider, err)
+	}
+}
+
+// PrintCorrection implements printer.CLI.
+func (p *summaryPrinter) PrintCorrection(n int, c *models.Correction) {
+	p.counts[p.domain].add(p.changes.kinds(c))
+}
+
+// Debugf implements printer.Printer. The details are left out of the summary.
+func (p *summaryPrinter) Debugf(format string, args ...interface{}) {}
+
+// print prints the domains with changes, and the total.
+func (p *summaryPrinter) print() (total *changeCounts) {
+	total = &changeCounts{}
+	changed := 0
+	for _, d := range p.domains {
+		c := p.counts[d]
+		if c.total() == 0 {
+			continue
+		}
+		changed++
+		total.plus(c)
+		fmt.Printf("%s: %s\n", d, c)
+	}
+	fmt.Printf("Total: %s, in %d of %d domains\n", total, changed, len(p.domains))
+	return total
+}
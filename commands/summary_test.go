@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+func TestChangeCounts(t *testing.T) {
+	c := &changeCounts{}
+	ix := newChangeIndex(testChanges(t,
+		[]string{"b A 1.1.1.2", "c A 1.1.1.3"},
+		[]string{"www A 1.1.1.1", "a A 1.1.1.1", "c A 1.1.1.4"}))
+	for _, msg := range []string{
+		"CREATE A www.example.com 1.1.1.1 ttl=300",
+		"GENERATE_ZONEFILE: example.com\nCREATE A a.example.com 1.1.1.1 ttl=300\nDELETE A b.example.com 1.1.1.2 ttl=300\nMODIFY A c.example.com: (1.1.1.3 ttl=300) -> (1.1.1.4 ttl=300)\n",
+		"Update nameservers ns1.example.net -> ns2.example.net",
+	} {
+		c.add(ix.kinds(&models.Correction{Msg: msg}))
+	}
+	if *c != (changeCounts{creates: 2, modifies: 1, deletes: 1, other: 1}) {
+		t.Errorf("got %+v", c)
+	}
+	if s := c.String(); s != "2 to create, 1 to modify, 1 to delete, 1 other" {
+		t.Errorf("got %q", s)
+	}
+}
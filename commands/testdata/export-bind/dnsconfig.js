@@ -0,0 +1,23 @@
+var REG = NewRegistrar("none", "NONE");
+var BIND = NewDnsProvider("bind", "BIND");
+
+D("example.com", REG, DnsProvider(BIND),
+    NAMESERVER("ns1.example.net."),
+    NAMESERVER("ns2.example.net."),
+    A("@", "1.2.3.4"),
+    A("www", "1.2.3.4", TTL(600)),
+    AAAA("www", "2001:db8::1"),
+    CNAME("blog", "example.github.io."),
+    MX("@", 10, "mx1"),
+    MX("@", 20, "mx2.example.net."),
+    TXT("@", "v=spf1 include:_spf.example.net -all"),
+    TXT("quoted", 'say "hello"; and \\ bye'),
+    SRV("_sip._tcp", 10, 5, 5060, "sip.example.com."),
+    CAA("@", "issue", "letsencrypt.org")
+);
+
+D("example.org", REG, DnsProvider(BIND),
+    NAMESERVER("ns1.example.net."),
+    A("@", "5.6.7.8"),
+    TXT("multi", ["first string", "second string"])
+);
@@ -0,0 +1,31 @@
+var REG = NewRegistrar("none", "NONE");
+var BIND = NewDnsProvider("bind", "BIND");
+
+D("example.com", REG, DnsProvider(BIND),
+    NAMESERVER("ns1.example.net."),
+    NAMESERVER("ns2.example.net."),
+    A("@", "1.2.3.4"),
+    A("www", "1.2.3.4", TTL(600)),
+    AAAA("www", "2001:db8::1"),
+    CNAME("blog", "example.github.io."),
+    MX("@", 10, "mx1"),
+    MX("@", 20, "mx2.example.net."),
+    TXT("@", "v=spf1 include:_spf.example.net -all"),
+    TXT("quoted", 'say "hello": and \\ bye'),
+    TXT("multi", ["first string", "second string"]),
+    SRV("_sip._tcp", 10, 5, 5060, "sip.example.com."),
+    CAA("@", "issue", "letsencrypt.org"),
+    NS("sub", "ns.sub.example.net.")
+);
+
+D("example.org", REG, DnsProvider(BIND),
+    SOA("ns1.example.org.", "admin.example.org.", 3600, 600, 604800, 1440),
+    NAMESERVER("ns1.example.org."),
+    A("@", "5.6.7.8"),
+    A("ns1", "5.6.7.9")
+);
+
+D(REV("1.2.3.0/24"), REG, DnsProvider(BIND),
+    NAMESERVER("ns1.example.net."),
+    PTR("4", "example.com.")
+);
@@ -0,0 +1,23 @@
+var REG = NewRegistrar("none", "NONE");
+var R53 = NewDnsProvider("r53", "ROUTE53");
+var CF = NewDnsProvider("cf", "CLOUDFLAREAPI");
+
+D("example.com", REG, DnsProvider(R53), DnsProvider(CF),
+    A("@", "10.0.0.1"),
+    A("www", "10.0.0.1"),
+    A("www", "10.0.0.2"),
+    AAAA("www", "2001:db8::1"),
+    MX("@", 10, "mail"),
+    TXT("@", "v=spf1 mx -all"),
+    CNAME("ftp", "www")
+);
+
+D("example.net", REG, DnsProvider(R53),
+    A("www", "10.0.1.1"),
+    MX("@", 10, "mail.example.com.")
+);
+
+D("example.org", REG, DnsProvider(R53),
+    A("@", "10.0.2.1"),
+    IMPORT_TRANSFORM([{low: "10.0.1.0", high: "10.0.1.255", newBase: "192.0.2.0"}], "example.net", 300)
+);
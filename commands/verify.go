@@ -0,0 +1,209 @@
+package commands
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/printer"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+// verifyQuery asks a nameserver for the records of a name and type. Tests
+// replace it.
+var verifyQuery = func(nameserver, name string, rtype uint16) ([]dns.RR, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(name), rtype)
+	m.RecursionDesired = false
+	return exchangeQuery(m, net.JoinHostPort(strings.TrimSuffix(nameserver, "."), "53"))
+}
+
+// exchangeQuery sends m to addr over UDP, and again over TCP if the answer
+// was truncated, and returns the records of the answer.
+func exchangeQuery(m *dns.Msg, addr string) ([]dns.RR, error) {
+	c := &dns.Client{Timeout: 5 * time.Second}
+	r, _, err := c.Exchange(m, addr)
+	if err == dns.ErrTruncated || err == nil && r.Truncated {
+		c.Net = "tcp"
+		r, _, err = c.Exchange(m, addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.Rcode != dns.RcodeSuccess && r.Rcode != dns.RcodeNameError {
+		return nil, errors.Errorf("%s", dns.RcodeToString[r.Rcode])
+	}
+	return r.Answer, nil
+}
+
+// verifyInterval is how long -verify-after waits between rounds of queries.
+var verifyInterval = 2 * time.Second
+
+// verifiedTypes are the record types whose values are compared with what the
+// nameservers serve. Other types are not served as themselves (ALIAS,
+// URL...).
+var verifiedTypes = map[string]bool{
+	"A": true, "AAAA": true, "CAA": true, "CNAME": true, "MX": true,
+	"NS": true, "PTR": true, "SRV": true, "TLSA": true, "TXT": true,
+}
+
+// recordSet is a set of records push changed, with the values the
+// nameservers should serve for it.
+type recordSet struct {
+	name, rType string
+	values      []string
+}
+
+// propagation is whether the nameservers of a provider serve a recordSet.
+type propagation struct {
+	domain, provider string
+	set              recordSet
+	propagated       bool
+}
+
+// rdata is the value of an RR as compared by -verify-after: its text
+// without the header, so without the TTL.
+func rdata(rr dns.RR) string {
+	return strings.ToLower(strings.TrimPrefix(rr.String(), rr.Header().String()))
+}
+
+// changedSets returns the record sets of dc whose values differ from those
+// in existing, the records the provider had. Record sets with a blocked
+// change, GEO() records, which clients are served by location, and records
+// outside -only or -types are left out. Record sets that were deleted are not
+// checked.
+func changedSets(dc *models.DomainConfig, existing []*models.RecordConfig, blocked []diff.Correlation) []recordSet {
+	key := func(r *models.RecordConfig) string { return r.GetLabelFQDN() + " " + r.Type }
+	skip := map[string]bool{}
+	for _, c := range blocked {
+		for _, r := range []*models.RecordConfig{c.Existing, c.Desired} {
+			if r != nil {
+				skip[key(r)] = true
+			}
+		}
+	}
+	values := func(records []*models.RecordConfig) (map[string][]string, []string) {
+		sets := map[string][]string{}
+		var order []string
+		for _, r := range records {
+			if !verifiedTypes[r.Type] || !dc.MatchesOnly(r) {
+				continue
+			}
+			if _, ok := r.Metadata[models.MetaGeo]; ok {
+				skip[key(r)] = true
+				continue
+			}
+			k := key(r)
+			if sets[k] == nil {
+				order = append(order, k)
+			}
+			sets[k] = append(sets[k], rdata(r.ToRR()))
+		}
+		for _, v := range sets {
+			sort.Strings(v)
+		}
+		return sets, order
+	}
+	had, _ := values(existing)
+	want, order := values(dc.Records)
+	var changed []recordSet
+	for _, k := range order {
+		if skip[k] || strings.Join(had[k], "\n") == strings.Join(want[k], "\n") {
+			continue
+		}
+		i := strings.LastIndex(k, " ")
+		changed = append(changed, recordSet{k[:i], k[i+1:], want[k]})
+	}
+	return changed
+}
+
+// serves returns true if nameserver answers with exactly the values of set.
+func serves(nameserver string, set recordSet) bool {
+	answer, err := verifyQuery(nameserver, set.name, dns.StringToType[set.rType])
+	if err != nil {
+		return false
+	}
+	var got []string
+	for _, rr := range answer {
+		if dns.TypeToString[rr.Header().Rrtype] == set.rType {
+			got = append(got, rdata(rr))
+		}
+	}
+	sort.Strings(got)
+	return strings.Join(got, "\n") == strings.Join(set.values, "\n")
+}
+
+// verifyPropagation waits up to timeout (-verify-after) for every nameserver
+// of the provider to serve the record sets, asking again every
+// verifyInterval.
+func verifyPropagation(domain string, provider *models.DNSProviderInstance, sets []recordSet, timeout time.Duration) ([]propagation, error) {
+	if len(sets) == 0 {
+		return nil, nil
+	}
+	nss, err := provider.Driver.GetNameservers(domain)
+	if err != nil {
+		return nil, err
+	}
+	if len(nss) == 0 {
+		return nil, errors.Errorf("%s has no nameservers for %s", provider.Name, domain)
+	}
+	results := make([]propagation, len(sets))
+	for i, set := range sets {
+		results[i] = propagation{domain, provider.Name, set, false}
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		pending := 0
+		for i := range results {
+			if results[i].propagated {
+				continue
+			}
+			results[i].propagated = true
+			for _, ns := range nss {
+				if !serves(ns.Name, results[i].set) {
+					results[i].propagated = false
+					pending++
+					break
+				}
+			}
+		}
+		wait := time.Until(deadline)
+		if pending == 0 || wait <= 0 {
+			return results, nil
+		}
+		if wait > verifyInterval {
+			wait = verifyInterval
+		}
+		time.Sleep(wait)
+	}
+}
+
+// printPropagation prints the status of each record set that -verify-after
+// checked, for the summary at the end of push.
+func printPropagation(out printer.CLI, results []propagation) {
+	if len(results) == 0 {
+		return
+	}
+	pending := 0
+	for _, r := range results {
+		if !r.propagated {
+			pending++
+		}
+	}
+	if pending == 0 {
+		out.Debugf("All %d changed record sets are served by the nameservers of their providers:\n", len(results))
+	} else {
+		out.Warnf("%d of %d changed record sets are not served by all the nameservers of their providers yet:\n", pending, len(results))
+	}
+	for _, r := range results {
+		status := "propagated"
+		if !r.propagated {
+			status = "pending"
+		}
+		out.Debugf("  %-10s %s %s at %s\n", status, r.set.rType, r.set.name, r.provider)
+	}
+}
@@ -0,0 +1,230 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+// verifyProvider is a diffProvider with two nameservers.
+type verifyProvider struct {
+	diffProvider
+}
+
+func (verifyProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	return models.StringsToNameservers([]string{"ns1.example.net", "ns2.example.net"}), nil
+}
+
+func init() {
+	providers.RegisterDomainServiceProviderType("FAKE-VERIFY", func(map[string]string, json.RawMessage) (providers.DNSServiceProvider, error) {
+		return verifyProvider{}, nil
+	})
+}
+
+// fakeServed replaces verifyQuery with the records in served, by nameserver,
+// until the func it returns is called. It counts the queries in *queries.
+func fakeServed(t *testing.T, served map[string][]string, queries *int) func() {
+	query, interval := verifyQuery, verifyInterval
+	verifyQuery = func(nameserver, name string, rtype uint16) ([]dns.RR, error) {
+		*queries++
+		if served[nameserver] == nil {
+			return nil, errors.Errorf("i/o timeout")
+		}
+		var answer []dns.RR
+		for _, s := range served[nameserver] {
+			rr, err := dns.NewRR(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rr.Header().Name == dns.Fqdn(name) && rr.Header().Rrtype == rtype {
+				answer = append(answer, rr)
+			}
+		}
+		return answer, nil
+	}
+	verifyInterval = 10 * time.Millisecond
+	return func() { verifyQuery, verifyInterval = query, interval }
+}
+
+func TestChangedSets(t *testing.T) {
+	rc := func(typ, label, target string) *models.RecordConfig {
+		r := &models.RecordConfig{Type: typ, Metadata: map[string]string{}}
+		r.SetLabel(label, "example.com")
+		r.SetTarget(target)
+		return r
+	}
+	existing := []*models.RecordConfig{
+		rc("A", "www", "1.1.1.1"),
+		rc("A", "www", "1.1.1.2"),
+		rc("CNAME", "old", "www.example.com."),
+		rc("MX", "@", "mx.example.net."),
+	}
+	geo := rc("A", "geo", "3.3.3.3")
+	geo.Metadata[models.MetaGeo] = "country:DE"
+	dc := &models.DomainConfig{Name: "example.com", Records: []*models.RecordConfig{
+		// Reordered, but the same.
+		rc("A", "www", "1.1.1.2"),
+		rc("A", "www", "1.1.1.1"),
+		rc("AAAA", "www", "2001:db8::1"),
+		rc("MX", "@", "mx2.example.net."),
+		rc("ALIAS", "@", "lb.example.net."),
+		geo,
+	}}
+	got := changedSets(dc, existing, nil)
+	want := []recordSet{
+		{"www.example.com", "AAAA", []string{"2001:db8::1"}},
+		{"example.com", "MX", []string{"0 mx2.example.net."}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// The push left the records outside -only and -types alone.
+	dc.OnlyTypes = []string{"MX"}
+	got = changedSets(dc, existing, nil)
+	if want := want[1:]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v with -types, got %v", want, got)
+	}
+	dc.OnlyTypes = nil
+	dc.OnlyLabelFQDN, dc.OnlyType = "www.example.com", "AAAA"
+	got = changedSets(dc, existing, nil)
+	if want := want[:1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v with -only, got %v", want, got)
+	}
+}
+
+func TestExchangeQueryTruncated(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", pc.LocalAddr().String())
+	if err != nil {
+		pc.Close()
+		t.Skip(err)
+	}
+	// Over UDP the answer is truncated, over TCP it is whole.
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(req)
+		if _, ok := w.RemoteAddr().(*net.UDPAddr); ok {
+			m.Truncated = true
+		} else {
+			rr, _ := dns.NewRR("www.example.com. 300 IN A 1.1.1.1")
+			m.Answer = append(m.Answer, rr)
+		}
+		w.WriteMsg(m)
+	})
+	udp := &dns.Server{PacketConn: pc, Handler: handler}
+	tcp := &dns.Server{Listener: l, Handler: handler}
+	go udp.ActivateAndServe()
+	go tcp.ActivateAndServe()
+	defer udp.Shutdown()
+	defer tcp.Shutdown()
+
+	m := new(dns.Msg)
+	m.SetQuestion("www.example.com.", dns.TypeA)
+	answer, err := exchangeQuery(m, pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(answer) != 1 {
+		t.Errorf("Expected the answer over TCP, got %v", answer)
+	}
+}
+
+func TestVerifyPropagation(t *testing.T) {
+	queries := 0
+	defer fakeServed(t, map[string][]string{
+		"ns1.example.net": {"www.example.com. 300 IN A 2.2.2.2", "www.example.com. 300 IN AAAA 2001:db8::1", "mail.example.com. 300 IN A 3.3.3.3"},
+		// Still serves the old A record, and has no AAAA record yet.
+		"ns2.example.net": {"www.example.com. 300 IN A 1.1.1.1", "mail.example.com. 60 IN A 3.3.3.3"},
+	}, &queries)()
+	provider := &models.DNSProviderInstance{ProviderBase: models.ProviderBase{Name: "verify"}, Driver: verifyProvider{}}
+	sets := []recordSet{
+		{"www.example.com", "A", []string{"2.2.2.2"}},
+		{"www.example.com", "AAAA", []string{"2001:db8::1"}},
+		// TTLs aren't compared.
+		{"mail.example.com", "A", []string{"3.3.3.3"}},
+	}
+	start := time.Now()
+	results, err := verifyPropagation("example.com", provider, sets[:2], 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 50*time.Millisecond || d > 5*time.Second {
+		t.Errorf("Expected to wait for the timeout, waited %s", d)
+	}
+	if len(results) != 2 || results[0].propagated || results[1].propagated || queries < 4 {
+		t.Errorf("Expected both to be pending after %d queries, got %v", queries, results)
+	}
+
+	results, err = verifyPropagation("example.com", provider, sets[2:], time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].propagated {
+		t.Errorf("Expected mail to be propagated at once, got %v", results)
+	}
+}
+
+func TestVerifyAfter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	jsFile := filepath.Join(dir, "dnsconfig.js")
+	err = ioutil.WriteFile(jsFile, []byte(`
+D("example.com", NewRegistrar("none", "NONE"), DnsProvider(NewDnsProvider("verify", "FAKE-VERIFY"), 0),
+	A("www", "2.2.2.2"),
+	AAAA("www", "2001:db8::1"),
+	MX("@", 10, "mx.example.net."),
+	TXT("@", "v=spf1 -all")
+);`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	queries := 0
+	defer fakeServed(t, map[string][]string{
+		"ns1.example.net": {"www.example.com. 300 IN A 2.2.2.2", "www.example.com. 300 IN AAAA 2001:db8::1"},
+		"ns2.example.net": {"www.example.com. 300 IN A 2.2.2.2"},
+	}, &queries)()
+	args := PushArgs{VerifyAfter: 50 * time.Millisecond}
+	args.JSFile = jsFile
+	args.CredsFile = filepath.Join(dir, "creds.json")
+
+	var msgs, warnings []string
+	diffApplied = nil
+	if err := run(args, true, warnPrinter{msgPrinter{msgs: &msgs}, &warnings}); err != nil {
+		t.Fatal(err)
+	}
+	if len(diffApplied) != 2 {
+		t.Fatalf("Expected the A and AAAA records to change, got %q", diffApplied)
+	}
+	want := "1 of 2 changed record sets are not served by all the nameservers of their providers yet:"
+	found := false
+	for _, w := range warnings {
+		found = found || strings.TrimSpace(w) == want
+	}
+	if !found {
+		t.Errorf("Expected %q, got %q", want, warnings)
+	}
+
+	// preview doesn't ask.
+	queries = 0
+	if err := run(args, false, warnPrinter{msgPrinter{msgs: &msgs}, &warnings}); err != nil || queries != 0 {
+		t.Errorf("Expected preview to verify nothing, got %d queries, %v", queries, err)
+	}
+}
@@ -7,6 +7,42 @@ import (
 // DefaultTTL is applied to any DNS record without an explicit TTL.
 const DefaultTTL = uint32(300)
 
+// MetaAutoTTL is the record metadata set by TTL("auto"). Providers that have
+// an "automatic" TTL (CanUseAutoTTL) map such records to their own sentinel.
+const MetaAutoTTL = "auto_ttl"
+
+// MetaTTLJitter is the domain metadata that spreads the TTLs of the records
+// of the domain by up to that percentage, such as "10" (or "10%"), so that
+// caches don't expire them all at once.
+const MetaTTLJitter = "ttl_jitter"
+
+// MetaGeo is the record metadata set by GEO(): the location of the clients
+// the record is served to (see ParseGeoLocation). Providers that route by
+// location (CanUseGeoRecords) keep one record set per location.
+const MetaGeo = "geo"
+
+// MetaProtected is the record metadata set by PROTECTED(). Changes to such
+// records are left out unless the domain has AllowProtectedChanges.
+const MetaProtected = "protected"
+
+// MetaReplacedDefaults is the record metadata set by OVERRIDE_DEFAULTS() on
+// a record that replaced records of DEFAULTS: how many it replaced.
+const MetaReplacedDefaults = "replaced_defaults"
+
+// MetaCorrectionOrder is the domain metadata that orders the corrections of
+// the domain: CreatesFirst or DeletesFirst. Without it they are made in the
+// order of the provider.
+const MetaCorrectionOrder = "correction_order"
+
+// The orders of MetaCorrectionOrder. Making the new records before deleting
+// the old ones is safest when records are replaced. Deleting first frees a
+// name for a record that conflicts with the old one, such as an A record
+// replacing a CNAME.
+const (
+	CreatesFirst = "creates-first"
+	DeletesFirst = "deletes-first"
+)
+
 // DNSConfig describes the desired DNS configuration, usually loaded from dnsconfig.js.
 type DNSConfig struct {
 	Registrars         []*RegistrarConfig            `json:"registrars"`
@@ -55,7 +91,11 @@ func StringsToNameservers(nss []string) []*Nameserver {
 }
 
 // Correction is anything that can be run. Implementation is up to the specific provider.
+// Providers that get an ID for the change (a change ID, or the ID of the
+// record) set ID from F once it is applied, so that it can be found in their
+// audit logs.
 type Correction struct {
 	F   func() error `json:"-"`
 	Msg string
+	ID  string `json:",omitempty"`
 }
@@ -13,17 +13,34 @@ type DomainConfig struct {
 	DNSProviderNames map[string]int `json:"dnsProviders"`
 
 	Metadata      map[string]string `json:"meta,omitempty"`
+	ProviderMeta  map[string]string `json:"provider_meta,omitempty"` // Zone settings, see providers.ZoneSettings.
 	Records       Records           `json:"records"`
 	Nameservers   []*Nameserver     `json:"nameservers,omitempty"`
 	KeepUnknown   bool              `json:"keepunknown,omitempty"`
 	IgnoredLabels []string          `json:"ignored_labels,omitempty"`
 
+	// OnlyLabelFQDN and OnlyType limit corrections to a single record (see push -only).
+	// All other records are left alone, as if they were IGNOREd. OnlyType may be empty.
+	OnlyLabelFQDN string `json:"-"`
+	OnlyType      string `json:"-"`
+	// OnlyTypes limits corrections to records of these types (see push -types) in the same way.
+	OnlyTypes []string `json:"-"`
+	// AllowProtectedChanges lets corrections change PROTECTED() records (see push -allow-protected-changes).
+	AllowProtectedChanges bool `json:"-"`
+	// Protected has the ProtectedIDs of the records that were PROTECTED() when
+	// the domain was last pushed (see push -protected-state). Changes to them
+	// are left out like changes to PROTECTED() records, even once the record or
+	// its PROTECTED() is removed from the configuration.
+	Protected []string `json:"-"`
+
 	// These fields contain instantiated provider instances once everything is linked up.
 	// This linking is in two phases:
 	// 1. Metadata (name/type) is availible just from the dnsconfig. Validation can use that.
 	// 2. Final driver instances are loaded after we load credentials. Any actual provider interaction requires that.
 	RegistrarInstance    *RegistrarInstance     `json:"-"`
 	DNSProviderInstances []*DNSProviderInstance `json:"-"`
+
+	copiedFrom *DomainConfig
 }
 
 // Copy returns a deep copy of the DomainConfig.
@@ -43,9 +60,15 @@ func (dc *DomainConfig) Copy() (*DomainConfig, error) {
 	newDc.RegistrarInstance = reg
 	dc.DNSProviderInstances = dnsps
 	newDc.DNSProviderInstances = dnsps
+	newDc.copiedFrom = dc
 	return newDc, err
 }
 
+// CopiedFrom returns the domain dc is a Copy of, or nil.
+func (dc *DomainConfig) CopiedFrom() *DomainConfig {
+	return dc.copiedFrom
+}
+
 // HasRecordTypeName returns True if there is a record with this rtype and name.
 func (dc *DomainConfig) HasRecordTypeName(rtype, name string) bool {
 	for _, r := range dc.Records {
@@ -56,6 +79,57 @@ func (dc *DomainConfig) HasRecordTypeName(rtype, name string) bool {
 	return false
 }
 
+// ProtectedID identifies r in DomainConfig.Protected: its name, type and value.
+func ProtectedID(r *RecordConfig) string {
+	return r.GetLabelFQDN() + " " + r.Type + " " + r.GetTargetCombined()
+}
+
+// WasProtected returns true if r is one of the records of dc.Protected.
+func (dc *DomainConfig) WasProtected(r *RecordConfig) bool {
+	if len(dc.Protected) == 0 {
+		return false
+	}
+	id := ProtectedID(r)
+	for _, p := range dc.Protected {
+		if p == id {
+			return true
+		}
+	}
+	return false
+}
+
+// IgnoresLabel returns true if the records of label, a short name like
+// "www" or "@", are left alone because of IGNORE().
+func (dc *DomainConfig) IgnoresLabel(label string) bool {
+	for _, l := range dc.IgnoredLabels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesOnly returns false if the domain is limited to a single record
+// (-only) or to some types (-types) and r is not one of them.
+func (dc *DomainConfig) MatchesOnly(r *RecordConfig) bool {
+	if len(dc.OnlyTypes) != 0 {
+		found := false
+		for _, t := range dc.OnlyTypes {
+			if r.Type == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if dc.OnlyLabelFQDN == "" {
+		return true
+	}
+	return r.GetLabelFQDN() == dc.OnlyLabelFQDN && (dc.OnlyType == "" || r.Type == dc.OnlyType)
+}
+
 // Filter removes all records that don't match the filter f.
 func (dc *DomainConfig) Filter(f func(r *RecordConfig) bool) {
 	recs := []*RecordConfig{}
@@ -87,7 +161,7 @@ func (dc *DomainConfig) Punycode() error {
 			if err != nil {
 				return err
 			}
-		case "A", "AAAA", "CAA", "TXT", "TLSA":
+		case "A", "AAAA", "CAA", "TXT", "TLSA", "SOA":
 			// Nothing to do.
 		default:
 			msg := fmt.Sprintf("Punycode rtype %v unimplemented", rec.Type)
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GeoLocation is where the clients that a record with MetaGeo is served to
+// are. The zero GeoLocation is the default location, "*": the clients that
+// no other location of the record set matches.
+type GeoLocation struct {
+	Continent   string // Two letter continent code, such as EU.
+	Country     string // ISO 3166-1 alpha-2 country code, such as DE.
+	Subdivision string // ISO 3166-2 subdivision of Country, such as CA for US-CA.
+}
+
+// ParseGeoLocation parses a location as GEO() takes it: "continent:EU",
+// "country:DE", "country:US-CA" or "*". Codes are not case sensitive. It
+// only checks the syntax; normalize checks that the codes exist.
+func ParseGeoLocation(s string) (GeoLocation, error) {
+	if s == "*" {
+		return GeoLocation{}, nil
+	}
+	kv := strings.SplitN(strings.ToUpper(s), ":", 2)
+	if len(kv) == 2 && kv[1] != "" {
+		switch kv[0] {
+		case "CONTINENT":
+			return GeoLocation{Continent: kv[1]}, nil
+		case "COUNTRY":
+			g := GeoLocation{Country: kv[1]}
+			if i := strings.Index(kv[1], "-"); i != -1 {
+				g.Country, g.Subdivision = kv[1][:i], kv[1][i+1:]
+			}
+			if g.Country != "" && (g.Subdivision != "" || !strings.Contains(kv[1], "-")) {
+				return g, nil
+			}
+		}
+	}
+	return GeoLocation{}, errors.Errorf("%q is not a location (must be continent:CODE, country:CODE, country:CODE-SUBDIVISION or *)", s)
+}
+
+// String returns the location the way ParseGeoLocation takes it.
+func (g GeoLocation) String() string {
+	switch {
+	case g.Continent != "":
+		return "continent:" + g.Continent
+	case g.Subdivision != "":
+		return "country:" + g.Country + "-" + g.Subdivision
+	case g.Country != "":
+		return "country:" + g.Country
+	}
+	return "*"
+}
@@ -1,6 +1,10 @@
 package models
 
-import "strings"
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
 
 // IsQuoted returns true if the string starts and ends with a double quote.
 func IsQuoted(s string) bool {
@@ -28,10 +32,75 @@ func StripQuotes(s string) string {
 // `foo`  -> []string{"foo"}
 // `"foo"` -> []string{"foo"}
 // `"foo" "bar"` -> []string{"foo", "bar"}
-// NOTE: it is assumed there is exactly one space between the quotes.
+// Whitespace around and between the quoted strings is ignored, and \" and
+// \\ within them are unescaped. If s is not a proper list of quoted strings,
+// it is split at every `" "`.
 func ParseQuotedTxt(s string) []string {
-	if !IsQuoted(s) {
+	t := strings.TrimSpace(s)
+	if !IsQuoted(t) {
 		return []string{s}
 	}
-	return strings.Split(StripQuotes(s), `" "`)
+	if parts, ok := splitQuoted(t); ok {
+		return parts
+	}
+	return strings.Split(StripQuotes(t), `" "`)
+}
+
+// splitQuoted splits a list of quoted strings, separated by whitespace.
+func splitQuoted(s string) ([]string, bool) {
+	var parts []string
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case ' ', '\t':
+			i++
+			continue
+		case '"':
+		default:
+			return nil, false
+		}
+		var b bytes.Buffer
+		for i++; ; i++ {
+			if i >= len(s) {
+				return nil, false
+			}
+			if s[i] == '\\' && i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '\\') {
+				i++
+			} else if s[i] == '"' {
+				break
+			}
+			b.WriteByte(s[i])
+		}
+		parts = append(parts, b.String())
+		i++
+	}
+	return parts, true
+}
+
+// UnescapeTxt undoes the zonefile escaping (\X and \DDD) that miekg/dns
+// keeps in the strings of TXT records. RecordConfig.ToRR() escapes them the
+// same way.
+func UnescapeTxt(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b = append(b, s[i])
+			continue
+		}
+		if i+3 < len(s) && isDigit(s[i+1]) && isDigit(s[i+2]) && isDigit(s[i+3]) {
+			n, _ := strconv.Atoi(s[i+1 : i+4])
+			b = append(b, byte(n))
+			i += 3
+		} else {
+			b = append(b, s[i+1])
+			i++
+		}
+	}
+	return string(b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
 }
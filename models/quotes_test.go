@@ -61,6 +61,10 @@ func TestSetTxtParse(t *testing.T) {
 		{`"foo bar"`, `foo bar`, []string{`foo bar`}},
 		{`foo bar`, `foo bar`, []string{`foo bar`}},
 		{`"aaa" "bbb"`, `aaa`, []string{`aaa`, `bbb`}},
+		{` "aaa"  "bbb" `, `aaa`, []string{`aaa`, `bbb`}},
+		{`"aaa""bbb"`, `aaa`, []string{`aaa`, `bbb`}},
+		{`"say \"hi\""`, `say "hi"`, []string{`say "hi"`}},
+		{`"a"b"`, `a"b`, []string{`a"b`}},
 	}
 	for i, test := range tests {
 		ls := ParseQuotedTxt(test.d1)
@@ -77,3 +81,46 @@ func TestSetTxtParse(t *testing.T) {
 		}
 	}
 }
+
+func TestUnquoteTXT(t *testing.T) {
+	tests := []struct {
+		txts     []string
+		expected []string
+	}{
+		{[]string{`v=spf1 -all`}, []string{`v=spf1 -all`}},
+		{[]string{`"v=spf1 -all"`}, []string{`v=spf1 -all`}},
+		{[]string{`"v=spf1 ip4:1.2.3.4" "-all"`}, []string{`v=spf1 ip4:1.2.3.4`, `-all`}},
+		{[]string{`"aaa"`, `bbb`}, []string{`aaa`, `bbb`}},
+	}
+	for i, test := range tests {
+		rc := &RecordConfig{Type: "TXT"}
+		rc.SetTargetTXTs(test.txts)
+		unquoteTXT([]*RecordConfig{rc})
+		if len(rc.TxtStrings) != len(test.expected) {
+			t.Fatalf("%v: expected %q, got %q", i, test.expected, rc.TxtStrings)
+		}
+		for j := range rc.TxtStrings {
+			if rc.TxtStrings[j] != test.expected[j] {
+				t.Errorf("%v: expected %q, got %q", i, test.expected, rc.TxtStrings)
+			}
+		}
+		if rc.GetTargetField() != test.expected[0] {
+			t.Errorf("%v: expected target %q, got %q", i, test.expected[0], rc.GetTargetField())
+		}
+	}
+}
+
+func TestUnescapeTxt(t *testing.T) {
+	for _, test := range []struct{ in, want string }{
+		{`plain`, `plain`},
+		{`say \"hi\"`, `say "hi"`},
+		{`back\\slash`, `back\slash`},
+		{`semi\059colon`, `semi;colon`},
+		{`short\05`, `short05`},
+		{`trailing\`, `trailing\`},
+	} {
+		if got := UnescapeTxt(test.in); got != test.want {
+			t.Errorf("%s: expected %q, got %q", test.in, test.want, got)
+		}
+	}
+}
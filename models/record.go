@@ -80,6 +80,11 @@ type RecordConfig struct {
 	TxtStrings       []string          `json:"txtstrings,omitempty"` // TxtStrings stores all strings (including the first). Target stores only the first one.
 	R53Alias         map[string]string `json:"r53_alias,omitempty"`
 
+	// Preserved holds the fields of a record at the provider that dnscontrol
+	// doesn't model, as the provider read them. The diff copies them to the
+	// desired record that replaces it, for the provider to write them back.
+	Preserved map[string]string `json:"-"`
+
 	Original interface{} `json:"-"` // Store pointer to provider-specific record object. Used in diffing.
 }
 
@@ -228,7 +233,13 @@ func (rc *RecordConfig) ToRR() dns.RR {
 		rr.(*dns.TLSA).Selector = rc.TlsaSelector
 		rr.(*dns.TLSA).Certificate = rc.GetTargetField()
 	case dns.TypeTXT:
-		rr.(*dns.TXT).Txt = rc.TxtStrings
+		// miekg/dns keeps TXT strings escaped as in a zonefile, where a
+		// backslash starts an escape.
+		txts := make([]string, len(rc.TxtStrings))
+		for i, t := range rc.TxtStrings {
+			txts[i] = strings.Replace(t, `\`, `\\`, -1)
+		}
+		rr.(*dns.TXT).Txt = txts
 	default:
 		panic(fmt.Sprintf("ToRR: Unimplemented rtype %v", rc.Type))
 		// We panic so that we quickly find any switch statements
@@ -277,17 +288,26 @@ func (r Records) GroupedByLabel() ([]string, map[string]Records) {
 // PostProcessRecords does any post-processing of the downloaded DNS records.
 func PostProcessRecords(recs []*RecordConfig) {
 	downcase(recs)
+	unquoteTXT(recs)
 }
 
 // Downcase converts all labels and targets to lowercase in a list of RecordConfig.
+// Hostnames are compared without case, but TXT strings and CAA values are
+// case sensitive and left alone.
 func downcase(recs []*RecordConfig) {
 	for _, r := range recs {
 		r.Name = strings.ToLower(r.Name)
 		r.NameFQDN = strings.ToLower(r.NameFQDN)
 		switch r.Type {
-		case "ANAME", "CNAME", "MX", "NS", "PTR":
+		case "ALIAS", "ANAME", "CNAME", "MX", "NS", "PTR", "SRV":
+			r.Target = strings.ToLower(r.Target)
+		case "TLSA":
+			// The certificate data is hex.
 			r.Target = strings.ToLower(r.Target)
-		case "A", "AAAA", "ALIAS", "CAA", "IMPORT_TRANSFORM", "SRV", "TLSA", "TXT", "SOA", "CF_REDIRECT", "CF_TEMP_REDIRECT":
+		case "CAA":
+			// Only the tag ("issue", "iodef", ...) is case insensitive.
+			r.CaaTag = strings.ToLower(r.CaaTag)
+		case "A", "AAAA", "IMPORT_TRANSFORM", "TXT", "SOA", "CF_REDIRECT", "CF_TEMP_REDIRECT":
 			// Do nothing.
 		default:
 			// TODO: we'd like to panic here, but custom record types complicate things.
@@ -1,5 +1,7 @@
 package models
 
+import "strings"
+
 // SetTargetTXT sets the TXT fields when there is 1 string.
 func (rc *RecordConfig) SetTargetTXT(s string) error {
 	rc.SetTarget(s)
@@ -34,3 +36,27 @@ func (rc *RecordConfig) SetTargetTXTs(s []string) error {
 func (rc *RecordConfig) SetTargetTXTString(s string) error {
 	return rc.SetTargetTXTs(ParseQuotedTxt(s))
 }
+
+// unquoteTXT replaces TXT strings that are still quoted, as some providers
+// return them, with their content. Otherwise `"foo"` and `foo` would be
+// different records, and be changed on every push.
+func unquoteTXT(recs []*RecordConfig) {
+	for _, rc := range recs {
+		if rc.Type != "TXT" {
+			continue
+		}
+		var txts []string
+		changed := false
+		for _, s := range rc.TxtStrings {
+			if IsQuoted(strings.TrimSpace(s)) {
+				txts = append(txts, ParseQuotedTxt(s)...)
+				changed = true
+			} else {
+				txts = append(txts, s)
+			}
+		}
+		if changed {
+			rc.SetTargetTXTs(txts)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Package changelog appends the corrections applied by a push to a file
+// that can be committed next to the configuration, as a history of the DNS
+// changes.
+package changelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Header starts a markdown changelog. It is only written to new files.
+const Header = "# DNS changelog\n"
+
+// Run collects the corrections applied by one run. A nil *Run is valid and
+// records nothing, so callers don't need to check whether a changelog was
+// requested.
+type Run struct {
+	Time    time.Time `json:"time"`
+	Commit  string    `json:"commit,omitempty"`
+	Changes []Change  `json:"changes"`
+}
+
+// Change is one applied correction.
+type Change struct {
+	Domain   string `json:"domain"`
+	Provider string `json:"provider"`
+	Message  string `json:"change"`
+}
+
+// New starts a changelog entry for a run of the configuration at the git
+// commit, which may be "".
+func New(now time.Time, commit string) *Run {
+	return &Run{Time: now.UTC(), Commit: commit}
+}
+
+// Applied records a correction that was applied successfully.
+func (r *Run) Applied(domain, provider, message string) {
+	if r == nil {
+		return
+	}
+	r.Changes = append(r.Changes, Change{domain, provider, message})
+}
+
+// WriteMarkdown writes the run as a markdown section, the changes grouped by
+// domain.
+func (r *Run) WriteMarkdown(w io.Writer) error {
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "\n## %s", r.Time.Format(time.RFC3339))
+	if r.Commit != "" {
+		fmt.Fprintf(b, " (%s)", r.Commit)
+	}
+	fmt.Fprintf(b, "\n")
+	// Stable sort, the changes of a domain stay in the order they were made.
+	changes := append([]Change(nil), r.Changes...)
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Domain < changes[j].Domain })
+	for i, c := range changes {
+		if i == 0 || c.Domain != changes[i-1].Domain {
+			fmt.Fprintf(b, "\n### %s\n\n", c.Domain)
+		}
+		fmt.Fprintf(b, "- %s: %s\n", c.Provider, strings.Replace(strings.TrimSpace(c.Message), "\n", "\n  ", -1))
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// WriteJSON writes the run as one line of JSON.
+func (r *Run) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// AppendFile appends the run to filename, as JSON lines if its name ends in
+// .json and as markdown otherwise. A new markdown file starts with Header.
+// Nothing is written if no change was applied.
+func (r *Run) AppendFile(filename string) error {
+	if r == nil || len(r.Changes) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(filename, ".json") {
+		err = r.WriteJSON(f)
+	} else {
+		var fi os.FileInfo
+		if fi, err = f.Stat(); err == nil && fi.Size() == 0 {
+			_, err = io.WriteString(f, Header)
+		}
+		if err == nil {
+			err = r.WriteMarkdown(f)
+		}
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,101 @@
+package changelog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testRun(commit string) *Run {
+	r := New(time.Date(2018, 6, 1, 12, 0, 0, 0, time.FixedZone("CEST", 7200)), commit)
+	r.Applied("example.org", "bind", "CREATE A www.example.org 1.2.3.4 ttl=300")
+	r.Applied("example.com", "r53", "MODIFY A www.example.com: (1.1.1.1 ttl=300) -> (1.2.3.4 ttl=300)")
+	r.Applied("example.com", "r53", "DELETE MX example.com 10 mx.example.net. ttl=300")
+	r.Applied("example.com", "cloudflare", "Multi-line\ncorrection")
+	return r
+}
+
+func TestAppendMarkdown(t *testing.T) {
+	dir, err := ioutil.TempDir("", "changelog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "CHANGELOG-DNS.md")
+
+	if err := testRun("1a2b3c4").AppendFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	// The header is only written once, and runs without changes are left out.
+	if err := New(time.Now(), "").AppendFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	second := New(time.Date(2018, 6, 2, 10, 0, 0, 0, time.UTC), "")
+	second.Applied("example.com", "r53", "CREATE TXT example.com \"v=spf1 -all\" ttl=300")
+	if err := second.AppendFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `# DNS changelog
+
+## 2018-06-01T10:00:00Z (1a2b3c4)
+
+### example.com
+
+- r53: MODIFY A www.example.com: (1.1.1.1 ttl=300) -> (1.2.3.4 ttl=300)
+- r53: DELETE MX example.com 10 mx.example.net. ttl=300
+- cloudflare: Multi-line
+  correction
+
+### example.org
+
+- bind: CREATE A www.example.org 1.2.3.4 ttl=300
+
+## 2018-06-02T10:00:00Z
+
+### example.com
+
+- r53: CREATE TXT example.com "v=spf1 -all" ttl=300
+`
+	if string(b) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, b)
+	}
+}
+
+func TestAppendJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "changelog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "changelog.json")
+
+	r := New(time.Date(2018, 6, 1, 10, 0, 0, 0, time.UTC), "1a2b3c4")
+	r.Applied("example.com", "r53", "CREATE A www.example.com 1.2.3.4 ttl=300")
+	for i := 0; i < 2; i++ {
+		if err := r.AppendFile(filename); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := `{"time":"2018-06-01T10:00:00Z","commit":"1a2b3c4","changes":[{"domain":"example.com","provider":"r53","change":"CREATE A www.example.com 1.2.3.4 ttl=300"}]}` + "\n"
+	if string(b) != line+line {
+		t.Errorf("expected\n%s\ngot\n%s", line+line, b)
+	}
+}
+
+func TestNilRun(t *testing.T) {
+	var r *Run
+	r.Applied("example.com", "r53", "CREATE A www.example.com 1.2.3.4 ttl=300")
+	if err := r.AppendFile(filepath.Join(os.TempDir(), "never-written.md")); err != nil {
+		t.Error(err)
+	}
+}
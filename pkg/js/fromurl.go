@@ -0,0 +1,74 @@
+package js
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
+	"github.com/robertkrimen/otto"
+)
+
+// fetchClient sends the requests of FROM_URL. Tests replace it.
+var fetchClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxFetchSize is the largest response FROM_URL accepts.
+const maxFetchSize = 10 << 20
+
+// urlFetcher implements FROM_URL. It fetches each URL once per run of the
+// configuration, however many domains use it.
+type urlFetcher struct {
+	cache map[string]string
+}
+
+func newURLFetcher() *urlFetcher {
+	return &urlFetcher{cache: map[string]string{}}
+}
+
+// fetch returns the records at a URL, as a JSON string.
+func (f *urlFetcher) fetch(call otto.FunctionCall) otto.Value {
+	if len(call.ArgumentList) != 1 {
+		throw(call.Otto, "FROM_URL takes exactly one argument")
+	}
+	u := call.Argument(0).String()
+	dat, ok := f.cache[u]
+	if !ok {
+		recs, err := fetchRecords(u)
+		if err != nil {
+			throw(call.Otto, fmt.Sprintf("FROM_URL %s: %s", u, err))
+		}
+		b, _ := json.Marshal(recs)
+		dat = string(b)
+		f.cache[u] = dat
+	}
+	v, _ := otto.ToValue(dat)
+	return v
+}
+
+func fetchRecords(rawURL string) ([]*models.RecordConfig, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return nil, errors.Errorf("not an https:// URL")
+	}
+	resp, err := fetchClient.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("%s", resp.Status)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxFetchSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxFetchSize {
+		return nil, errors.Errorf("the response is larger than %d bytes", maxFetchSize)
+	}
+	return parseExecRecords(body)
+}
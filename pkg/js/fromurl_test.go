@@ -0,0 +1,65 @@
+package js
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromURL(t *testing.T) {
+	requests := 0
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/edges":
+			w.Write([]byte(`[{"type":"A","name":"cdn","target":"10.0.0.1","ttl":60},{"type":"TXT","name":"@","target":"edge"}]`))
+		case "/bad":
+			w.Write([]byte(`[{"type":"A","name":"cdn","address":"10.0.0.1"}]`))
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	client := srv.Client()
+	client.Timeout = 50 * time.Millisecond
+	fetchClient = client
+	defer func() { fetchClient = &http.Client{Timeout: 30 * time.Second} }()
+
+	conf, err := ExecuteJavascript(`
+D("example.com", "reg", FROM_URL("`+srv.URL+`/edges"));
+D("example.net", "reg", DefaultTTL(600), FROM_URL("`+srv.URL+`/edges"));
+`, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected the URL to be fetched once, got %d requests", requests)
+	}
+	for _, d := range conf.Domains {
+		if len(d.Records) != 2 || d.Records[0].Target != "10.0.0.1" || d.Records[0].TTL != 60 {
+			t.Fatalf("%s: expected the records of the URL, got %+v", d.Name, d.Records)
+		}
+	}
+	if ttl := conf.Domains[1].Records[1].TTL; ttl != 600 {
+		t.Errorf("Expected the default TTL of the domain, got %d", ttl)
+	}
+
+	for path, want := range map[string]string{
+		"/missing": "404 Not Found",
+		"/bad":     "unknown field",
+		"/slow":    "Timeout",
+	} {
+		_, err := ExecuteJavascript(`D("example.com", "reg", FROM_URL("`+srv.URL+path+`"))`, true, nil)
+		if err == nil || !strings.Contains(err.Error(), "FROM_URL "+srv.URL+path) || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: expected an error about %q, got %v", path, want, err)
+		}
+	}
+	_, err = ExecuteJavascript(`D("example.com", "reg", FROM_URL("http://`+srv.Listener.Addr().String()+`/edges"))`, true, nil)
+	if err == nil || !strings.Contains(err.Error(), "not an https:// URL") {
+		t.Errorf("Expected plain HTTP to be refused, got %v", err)
+	}
+}
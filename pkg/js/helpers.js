@@ -41,6 +41,7 @@ function newDomain(name, registrar) {
         name: name,
         registrar: registrar,
         meta: {},
+        provider_meta: {},
         records: [],
         dnsProviders: {},
         defaultTTL: 0,
@@ -61,6 +62,13 @@ function processDargs(m, domain) {
             processDargs(m[j], domain);
         }
     } else if (_.isObject(m)) {
+        // providerMeta holds zone settings for providers, not metadata.
+        if (_.isObject(m.providerMeta)) {
+            _.each(m.providerMeta, function(v, k) {
+                domain.provider_meta[k] = String(v);
+            });
+            m = _.omit(m, 'providerMeta');
+        }
         _.extend(domain.meta, m);
     } else {
         throw 'WARNING: domain modifier type unsupported: ' +
@@ -76,10 +84,12 @@ function D(name, registrar) {
     for (var i = 0; i < defaultArgs.length; i++) {
         processDargs(defaultArgs[i], domain);
     }
+    var defaults = domain.records.length;
     for (var i = 2; i < arguments.length; i++) {
         var m = arguments[i];
         processDargs(m, domain);
     }
+    overrideDefaults(domain, defaults);
     if (conf.domain_names.indexOf(name) !== -1) {
         throw name + ' is declared more than once';
     }
@@ -87,8 +97,36 @@ function D(name, registrar) {
     conf.domain_names.push(name);
 }
 
+// overrideDefaults drops the records that came from DEFAULTS (the first n)
+// that a record of the domain with OVERRIDE_DEFAULTS() replaces: those of
+// the same name and type. The first record that replaces them notes how
+// many it did, so that the validation warns about it.
+function overrideDefaults(domain, n) {
+    var key = function(r) {
+        return r.type + ' ' + (r.name || '').toLowerCase();
+    };
+    var defaults = domain.records.slice(0, n);
+    var own = domain.records.slice(n);
+    _.each(own, function(r) {
+        if (r.meta['override_defaults'] !== 'true') {
+            return;
+        }
+        delete r.meta['override_defaults'];
+        var replaced = _.filter(defaults, function(d) {
+            return key(d) === key(r);
+        });
+        if (replaced.length > 0) {
+            r.meta['replaced_defaults'] = String(replaced.length);
+            defaults = _.difference(defaults, replaced);
+        }
+    });
+    domain.records = defaults.concat(own);
+}
+
 // DEFAULTS provides a set of default arguments to apply to all future domains.
-// Each call to DEFAULTS will clear any previous values set.
+// Each call to DEFAULTS will clear any previous values set. Records of a
+// domain with OVERRIDE_DEFAULTS() replace the default records of the same
+// name and type.
 function DEFAULTS() {
     defaultArgs = [];
     for (var i = 0; i < arguments.length; i++) {
@@ -97,7 +135,13 @@ function DEFAULTS() {
 }
 
 // TTL(v): Set the TTL for a DNS record.
+// TTL('auto') asks for the provider's "automatic" TTL, if it has one.
 function TTL(v) {
+    if (v === 'auto') {
+        return function(r) {
+            r.meta['auto_ttl'] = 'true';
+        };
+    }
     if (_.isString(v)) {
         v = stringToDuration(v);
     }
@@ -106,23 +150,67 @@ function TTL(v) {
     };
 }
 
+// IGNORE_WARNING(id, ...): Suppress the named validation warnings for this
+// record only.
+function IGNORE_WARNING() {
+    var ids = Array.prototype.slice.call(arguments);
+    return function(r) {
+        var ignored = r.meta['ignore_warnings']
+            ? r.meta['ignore_warnings'].split(',')
+            : [];
+        r.meta['ignore_warnings'] = ignored.concat(ids).join(',');
+    };
+}
+
+// GEO(location): Serve this record only to clients in location:
+// 'continent:EU', 'country:DE', 'country:US-CA', or '*' for the clients
+// that no other location matches.
+function GEO(location) {
+    if (!_.isString(location)) {
+        throw 'GEO() takes a location, such as "country:DE"';
+    }
+    return function(r) {
+        r.meta['geo'] = location;
+    };
+}
+
+// PROTECTED(): DNSControl won't change or replace this record once it
+// exists, unless push is given -allow-protected-changes.
+function PROTECTED() {
+    return function(r) {
+        r.meta['protected'] = 'true';
+    };
+}
+
+// OVERRIDE_DEFAULTS(): The record replaces the records of DEFAULTS that
+// have its name and type, instead of being added to them.
+function OVERRIDE_DEFAULTS() {
+    return function(r) {
+        r.meta['override_defaults'] = 'true';
+    };
+}
+
+// stringToDuration returns the seconds of a duration such as '300', '5m'
+// or '1d12h', the form preview -ttl-format=human shows.
 function stringToDuration(v) {
-    var matches = v.match(/^(\d+)([smhdwny]?)$/);
-    if (matches == null) {
-        throw v + ' is not a valid duration string';
+    if (v.match(/^\d+$/)) {
+        return parseInt(v);
     }
-    unit = 's';
-    if (matches[2]) {
-        unit = matches[2];
+    if (!v.match(/^(\d+[smhdwny])+$/)) {
+        throw v + ' is not a valid duration string';
     }
-    v = parseInt(matches[1]);
     var u = { s: 1, m: 60, h: 3600 };
     u['d'] = u.h * 24;
     u['w'] = u.d * 7;
     u['n'] = u.d * 30;
     u['y'] = u.d * 365;
-    v *= u[unit];
-    return v;
+    var total = 0;
+    var re = /(\d+)([smhdwny])/g;
+    var matches;
+    while ((matches = re.exec(v)) !== null) {
+        total += parseInt(matches[1]) * u[matches[2]];
+    }
+    return total;
 }
 
 // DefaultTTL(v): Set the default TTL for the domain.
@@ -157,10 +245,12 @@ function DnsProvider(name, nsCount) {
 }
 
 // A(name,ip, recordModifiers...)
-var A = recordBuilder('A');
+// A(name,[ip, ip, ...], recordModifiers...)
+var A = recordBuilder('A', { multiple: true });
 
 // AAAA(name,ip, recordModifiers...)
-var AAAA = recordBuilder('AAAA');
+// AAAA(name,[ip, ip, ...], recordModifiers...)
+var AAAA = recordBuilder('AAAA', { multiple: true });
 
 // ALIAS(name,target, recordModifiers...)
 var ALIAS = recordBuilder('ALIAS');
@@ -217,13 +307,19 @@ var CNAME = recordBuilder('CNAME');
 // PTR(name,target, recordModifiers...)
 var PTR = recordBuilder('PTR');
 
+// isUint16 accepts the values of 16-bit fields: MX and SRV priorities, SRV
+// weights and ports.
+function isUint16(x) {
+    return _.isNumber(x) && x % 1 === 0 && x >= 0 && x <= 65535;
+}
+
 // SRV(name,priority,weight,port,target, recordModifiers...)
 var SRV = recordBuilder('SRV', {
     args: [
         ['name', _.isString],
-        ['priority', _.isNumber],
-        ['weight', _.isNumber],
-        ['port', _.isNumber],
+        ['priority', isUint16],
+        ['weight', isUint16],
+        ['port', isUint16],
         ['target', _.isString],
     ],
     transform: function(record, args, modifiers) {
@@ -284,7 +380,7 @@ var TXT = recordBuilder('TXT', {
 var MX = recordBuilder('MX', {
     args: [
         ['name', _.isString],
-        ['priority', _.isNumber],
+        ['priority', isUint16],
         ['target', _.isString],
     ],
     transform: function(record, args, modifiers) {
@@ -295,7 +391,29 @@ var MX = recordBuilder('MX', {
 });
 
 // NS(name,target, recordModifiers...)
-var NS = recordBuilder('NS');
+var NS = recordBuilder('NS', { multiple: true });
+
+// isUint32 accepts the values of 32-bit fields: the SOA timers.
+function isUint32(x) {
+    return _.isNumber(x) && x % 1 === 0 && x >= 0 && x <= 4294967295;
+}
+
+// SOA(ns, mbox, refresh, retry, expire, minttl, recordModifiers...)
+var SOA = recordBuilder('SOA', {
+    args: [
+        ['ns', _.isString],
+        ['mbox', _.isString],
+        ['refresh', isUint32],
+        ['retry', isUint32],
+        ['expire', isUint32],
+        ['minttl', isUint32],
+    ],
+    transform: function(record, args, modifiers) {
+        record.name = '@';
+        // The serial (0) is left to the provider.
+        record.target = [args.ns, args.mbox, 0, args.refresh, args.retry, args.expire, args.minttl].join(' ');
+    },
+});
 
 // NAMESERVER(name,target)
 function NAMESERVER(name) {
@@ -357,6 +475,102 @@ var IMPORT_TRANSFORM = recordBuilder('IMPORT_TRANSFORM', {
     },
 });
 
+// FROM_EXEC(command, args...)
+// Runs command at config-load time and adds the records it prints to the
+// domain. The command must print a JSON list of records in IR format.
+function FROM_EXEC() {
+    var args = Array.prototype.slice.call(arguments);
+    return function(d) {
+        addIRRecords(d, _fromExec.apply(null, args));
+    };
+}
+
+// FROM_URL(url)
+// Fetches url over HTTPS at config-load time and adds the records it returns
+// to the domain. The response must be a JSON list of records in IR format.
+function FROM_URL(url) {
+    return function(d) {
+        addIRRecords(d, _fromURL(url));
+    };
+}
+
+// addIRRecords adds the records of a JSON list in IR format to the domain.
+function addIRRecords(d, json) {
+    var recs = JSON.parse(json);
+    for (var i = 0; i < recs.length; i++) {
+        var rec = recs[i];
+        if (!rec.meta) {
+            rec.meta = {};
+        }
+        if (!rec.ttl) {
+            rec.ttl = d.defaultTTL;
+        }
+        d.records.push(rec);
+    }
+}
+
+// HOST(name, inventoryKey, recordModifiers...)
+// Adds an A or AAAA record for each address of inventoryKey in the
+// inventory file given with -inventory.
+function HOST(name, key) {
+    var modifiers = Array.prototype.slice.call(arguments, 2);
+    return function(d) {
+        var addrs = JSON.parse(_inventory(name, key));
+        for (var i = 0; i < addrs.length; i++) {
+            var builder = addrs[i].indexOf(':') === -1 ? A : AAAA;
+            builder.apply(null, [name, addrs[i]].concat(modifiers))(d);
+        }
+    };
+}
+
+// FOREACH(list, fn)
+// Calls fn(item, index) for each item of list, or fn(value, key) for each
+// entry of an object, and adds what it returns (anything D() accepts) to the
+// domain. Names are relative to the domain.
+function FOREACH(list, fn) {
+    if (!_.isFunction(fn)) {
+        throw 'FOREACH: the second argument must be a function';
+    }
+    if (!_.isArray(list) && !_.isObject(list)) {
+        throw 'FOREACH: the first argument must be a list or an object';
+    }
+    return function(d) {
+        _.each(list, function(item, key) {
+            var m = fn(item, key);
+            if (m !== undefined && m !== null) {
+                processDargs(m, d);
+            }
+        });
+    };
+}
+
+// INCLUDE(recordSet, label)
+// Adds a reusable set of records (anything D() accepts, usually an array of
+// records) to a domain. Names in the set are relative to the domain. If label
+// is given they are relative to that label instead: "@" becomes label and
+// "www" becomes "www.label".
+function INCLUDE(recordSet, label) {
+    return function(d) {
+        var tmp = newDomain(d.name, d.registrar);
+        tmp.defaultTTL = d.defaultTTL;
+        processDargs(recordSet, tmp);
+        for (var i = 0; i < tmp.records.length; i++) {
+            var r = tmp.records[i];
+            if (label && label !== '@') {
+                r.name = r.name === '@' ? label : r.name + '.' + label;
+            }
+            d.records.push(r);
+        }
+        for (var i = 0; i < tmp.nameservers.length; i++) {
+            d.nameservers.push(tmp.nameservers[i]);
+        }
+        for (var i = 0; i < tmp.ignored_labels.length; i++) {
+            d.ignored_labels.push(tmp.ignored_labels[i]);
+        }
+        _.extend(d.meta, tmp.meta);
+    };
+}
+
 // PURGE()
 function PURGE(d) {
     d.KeepUnknown = false;
@@ -464,17 +678,29 @@ function recordBuilder(type, opts) {
             modifiers.push(arguments[i]);
         }
 
-        return function(d) {
-            var record = {
-                type: type,
-                meta: {},
-                ttl: d.defaultTTL,
-            };
-
-            opts.applyModifier(record, modifiers);
-            opts.transform(record, parsedArgs, modifiers);
+        // With opts.multiple, an array of targets makes a record for each.
+        var targets = [parsedArgs.target];
+        if (opts.multiple && _.isArray(parsedArgs.target)) {
+            if (parsedArgs.target.length === 0) {
+                throw type + ' record ' + parsedArgs.name + ' has an empty list of targets';
+            }
+            targets = parsedArgs.target;
+        }
 
-            d.records.push(record);
+        return function(d) {
+            var record;
+            for (var i = 0; i < targets.length; i++) {
+                record = {
+                    type: type,
+                    meta: {},
+                    ttl: d.defaultTTL,
+                };
+
+                opts.applyModifier(record, modifiers);
+                opts.transform(record, _.extend({}, parsedArgs, { target: targets[i] }), modifiers);
+
+                d.records.push(record);
+            }
             return record;
         };
     };
@@ -613,9 +839,11 @@ function SPF_BUILDER(value) {
         value.raw = '_rawspf';
     }
 
-    r = []; // The list of records to return.
-    p = {}; // The metaparameters to set on the main TXT record.
-    rawspf = value.parts.join(' '); // The unaltered SPF settings.
+    var r = []; // The list of records to return.
+    var p = {}; // The metaparameters to set on the main TXT record.
+    // SPF is order-sensitive: mechanisms are evaluated left to right, so the
+    // parts are joined exactly in the order they were declared.
+    var rawspf = value.parts.join(' '); // The unaltered SPF settings.
 
     // If flattening is requested, generate a TXT record with the raw SPF settings.
     if (value.flatten && value.flatten.length > 0) {
@@ -634,11 +862,116 @@ function SPF_BUILDER(value) {
     return r;
 }
 
-// Split a DKIM string if it is >254 bytes.
-function DKIM(arr) {
-    chunkSize = 255;
-    var R = [];
-    for (var i = 0, len = arr.length; i < len; i += chunkSize)
-        R.push(arr.slice(i, i + chunkSize));
-    return R;
+// DMARC_BUILDER takes an object:
+// label: The DNS label for the DMARC record. (default: '_dmarc')
+// policy: What receivers should do with failing mail: 'none', 'quarantine' or 'reject'. (required)
+// sp: The policy for subdomains. (default: policy)
+// adkim: DKIM alignment, 'relaxed' or 'strict'. (default: relaxed)
+// aspf: SPF alignment, 'relaxed' or 'strict'. (default: relaxed)
+// pct: The percentage of failing mail the policy applies to, 0 to 100. (default: 100)
+// rua: A URI, or a list of URIs, to send aggregate reports to (mailto: or https:).
+// ruf: A URI, or a list of URIs, to send failure reports to.
+// ttl: The TTL of the record. (optional)
+
+var DMARC_POLICIES = ['none', 'quarantine', 'reject'];
+
+function DMARC_BUILDER(value) {
+    if (!value || DMARC_POLICIES.indexOf(value.policy) === -1) {
+        throw 'DMARC_BUILDER policy must be one of ' + DMARC_POLICIES.join(', ');
+    }
+    // v and p must come first, the other tags are in the order of RFC 7489.
+    var tags = ['v=DMARC1', 'p=' + value.policy];
+    if (value.sp !== undefined) {
+        if (DMARC_POLICIES.indexOf(value.sp) === -1) {
+            throw 'DMARC_BUILDER sp must be one of ' + DMARC_POLICIES.join(', ');
+        }
+        tags.push('sp=' + value.sp);
+    }
+    _.each(['adkim', 'aspf'], function(tag) {
+        if (value[tag] === undefined) {
+            return;
+        }
+        var mode = { relaxed: 'r', r: 'r', strict: 's', s: 's' }[value[tag]];
+        if (!mode) {
+            throw 'DMARC_BUILDER ' + tag + ' must be relaxed or strict';
+        }
+        tags.push(tag + '=' + mode);
+    });
+    if (value.pct !== undefined) {
+        if (
+            typeof value.pct !== 'number' ||
+            value.pct % 1 !== 0 ||
+            value.pct < 0 ||
+            value.pct > 100
+        ) {
+            throw 'DMARC_BUILDER pct must be a whole number from 0 to 100';
+        }
+        tags.push('pct=' + value.pct);
+    }
+    _.each(['rua', 'ruf'], function(tag) {
+        if (value[tag] === undefined) {
+            return;
+        }
+        var uris = _.isArray(value[tag]) ? value[tag] : [value[tag]];
+        _.each(uris, function(uri) {
+            // An optional !SIZE limits the size of the reports.
+            if (
+                !/^(mailto:[^@\s,;!]+@[^@\s,;!]+|https?:\/\/[^\s,;!]+)(![0-9]+[kmgt]?)?$/.test(
+                    uri
+                )
+            ) {
+                throw 'DMARC_BUILDER ' + tag + ': ' + uri + ' is not a mailto: or https: URI';
+            }
+        });
+        tags.push(tag + '=' + uris.join(','));
+    });
+
+    var label = value.label || '_dmarc';
+    if (value.ttl) {
+        return TXT(label, tags.join('; '), TTL(value.ttl));
+    }
+    return TXT(label, tags.join('; '));
+}
+
+// DKIM(string) splits a DKIM string if it is >254 bytes.
+// DKIM(selector, pubkey, modifiers...) is the TXT record of a DKIM key at
+// selector._domainkey. The key is in base64, with or without the PEM
+// armor. A key of 44 characters (32 bytes) is an ed25519 key, any other
+// is an RSA key.
+function DKIM(arr, pubkey) {
+    if (pubkey === undefined) {
+        chunkSize = 255;
+        var R = [];
+        for (var i = 0, len = arr.length; i < len; i += chunkSize)
+            R.push(arr.slice(i, i + chunkSize));
+        return R;
+    }
+    var selector = arr;
+    if (!_.isString(selector) || !/^[a-z0-9_-]+(\.[a-z0-9_-]+)*$/i.test(selector)) {
+        throw 'DKIM: ' + selector + ' is not a selector';
+    }
+    if (!_.isString(pubkey)) {
+        throw 'DKIM ' + selector + ': the public key must be a string';
+    }
+    var key = pubkey.replace(/-----(BEGIN|END)[^-]*-----/g, '').replace(/\s+/g, '');
+    if (!/^[A-Za-z0-9+\/]+={0,2}$/.test(key) || key.length % 4 !== 0) {
+        throw 'DKIM ' + selector + ': the public key is not base64';
+    }
+    var k = key.length === 44 ? 'ed25519' : 'rsa';
+    var args = [selector + '._domainkey', DKIM('v=DKIM1; k=' + k + '; p=' + key)];
+    return TXT.apply(null, args.concat(Array.prototype.slice.call(arguments, 2)));
+}
+
+// DKIM_ROTATION(selectors, modifiers...) publishes the keys of several
+// selectors at once, so that a key can be rotated: the next key is
+// published while mail is still signed with the current one. selectors
+// maps each selector to its public key.
+function DKIM_ROTATION(selectors) {
+    if (!_.isObject(selectors) || _.isArray(selectors) || _.size(selectors) < 2) {
+        throw 'DKIM_ROTATION needs an object with at least two selectors, the current key and the next one';
+    }
+    var modifiers = Array.prototype.slice.call(arguments, 1);
+    return _.map(selectors, function(pubkey, selector) {
+        return DKIM.apply(null, [selector, pubkey].concat(modifiers));
+    });
 }
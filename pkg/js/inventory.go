@@ -0,0 +1,74 @@
+package js
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+
+	"github.com/pkg/errors"
+	"github.com/robertkrimen/otto"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Inventory maps the keys of a host inventory to their addresses. HOST()
+// looks its addresses up in it.
+type Inventory map[string][]string
+
+// LoadInventory reads a host inventory: a JSON or YAML map from each key to
+// an address or a list of addresses.
+//
+//	web1: 10.0.0.1
+//	web2: [10.0.0.2, "2001:db8::2"]
+func LoadInventory(filename string) (Inventory, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Errorf("Reading inventory %s: %s", filename, err)
+	}
+	// JSON is YAML, so one decoder reads both.
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Errorf("Parsing inventory %s: %s", filename, err)
+	}
+	inv := Inventory{}
+	for key, v := range raw {
+		var values []interface{}
+		switch x := v.(type) {
+		case string:
+			values = []interface{}{x}
+		case []interface{}:
+			values = x
+		default:
+			return nil, errors.Errorf("inventory %s: %s must be an address or a list of addresses", filename, key)
+		}
+		if len(values) == 0 {
+			return nil, errors.Errorf("inventory %s: %s has no address", filename, key)
+		}
+		for _, a := range values {
+			s, ok := a.(string)
+			if !ok || net.ParseIP(s) == nil {
+				return nil, errors.Errorf("inventory %s: %v (for %s) is not an IP address", filename, a, key)
+			}
+			inv[key] = append(inv[key], s)
+		}
+	}
+	return inv, nil
+}
+
+// lookup returns the addresses of a key, as a JSON list, to HOST(name, key).
+func (inv Inventory) lookup(call otto.FunctionCall) otto.Value {
+	if len(call.ArgumentList) != 2 {
+		throw(call.Otto, "HOST takes a name and an inventory key")
+	}
+	name, key := call.Argument(0).String(), call.Argument(1).String()
+	if inv == nil {
+		throw(call.Otto, fmt.Sprintf("HOST %s: no inventory was loaded. Pass one with -inventory", name))
+	}
+	addrs, ok := inv[key]
+	if !ok {
+		throw(call.Otto, fmt.Sprintf("HOST %s: %s is not in the inventory", name, key))
+	}
+	dat, _ := json.Marshal(addrs)
+	v, _ := otto.ToValue(string(dat))
+	return v
+}
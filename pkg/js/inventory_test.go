@@ -0,0 +1,98 @@
+package js
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeInventory(t *testing.T, dir, name, content string) string {
+	filename := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestLoadInventory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "inventory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	want := Inventory{
+		"web1": {"10.0.0.1"},
+		"web2": {"10.0.0.2", "2001:db8::2"},
+	}
+	for _, tst := range []struct{ name, content string }{
+		{"hosts.yaml", "web1: 10.0.0.1\nweb2: [10.0.0.2, \"2001:db8::2\"]\n"},
+		{"hosts.json", `{"web1": "10.0.0.1", "web2": ["10.0.0.2", "2001:db8::2"]}`},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			inv, err := LoadInventory(writeInventory(t, dir, tst.name, tst.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(inv, want) {
+				t.Errorf("Expected %v, got %v", want, inv)
+			}
+		})
+	}
+
+	for _, content := range []string{
+		"web1: not-an-ip\n",
+		"web1: [10.0.0.1, 10.0.0.300]\n",
+		"web1: []\n",
+		"web1: {ip: 10.0.0.1}\n",
+		"web1: 10\n",
+		"- 10.0.0.1\n",
+	} {
+		if _, err := LoadInventory(writeInventory(t, dir, "bad.yaml", content)); err == nil {
+			t.Errorf("%q: expected an error", content)
+		}
+	}
+	if _, err := LoadInventory(filepath.Join(os.TempDir(), "missing-inventory.yaml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestHost(t *testing.T) {
+	inv := Inventory{
+		"web1": {"10.0.0.1"},
+		"web2": {"10.0.0.2", "2001:db8::2"},
+	}
+	conf, err := ExecuteJavascript(`D("example.com", "reg", HOST("www", "web1"), HOST("@", "web2", TTL(60)))`, true, inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range conf.Domains[0].Records {
+		got = append(got, strings.Join([]string{r.Type, r.Name, r.GetTargetField()}, " "))
+		if r.Name == "@" && r.TTL != 60 {
+			t.Errorf("%s %s: expected TTL 60, got %d", r.Type, r.Name, r.TTL)
+		}
+	}
+	want := []string{"A www 10.0.0.1", "A @ 10.0.0.2", "AAAA @ 2001:db8::2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	for _, tst := range []struct {
+		desc string
+		inv  Inventory
+		want string
+	}{
+		{"missing key", inv, "HOST www: web9 is not in the inventory"},
+		{"no inventory", nil, "HOST www: no inventory was loaded"},
+	} {
+		t.Run(tst.desc, func(t *testing.T) {
+			_, err := ExecuteJavascript(`D("example.com", "reg", HOST("www", "web9"))`, true, tst.inv)
+			if err == nil || !strings.Contains(err.Error(), tst.want) {
+				t.Errorf("Expected an error containing %q, got %v", tst.want, err)
+			}
+		})
+	}
+}
@@ -1,12 +1,18 @@
 package js
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os/exec"
+	"strings"
+	"time"
 
 	"github.com/StackExchange/dnscontrol/models"
 	"github.com/StackExchange/dnscontrol/pkg/transform"
+	"github.com/pkg/errors"
 
 	"github.com/robertkrimen/otto"
 	// load underscore js into vm by default
@@ -15,11 +21,15 @@ import (
 )
 
 // ExecuteJavascript accepts a javascript string and runs it, returning the resulting dnsConfig.
-func ExecuteJavascript(script string, devMode bool) (*models.DNSConfig, error) {
+// HOST() looks addresses up in inv, which may be nil.
+func ExecuteJavascript(script string, devMode bool, inv Inventory) (*models.DNSConfig, error) {
 	vm := otto.New()
 
 	vm.Set("require", require)
 	vm.Set("REV", reverse)
+	vm.Set("_fromExec", fromExec)
+	vm.Set("_fromURL", newURLFetcher().fetch)
+	vm.Set("_inventory", inv.lookup)
 
 	helperJs := GetHelpers(devMode)
 	// run helper script to prime vm and initialize variables
@@ -86,3 +96,69 @@ func reverse(call otto.FunctionCall) otto.Value {
 	v, _ := otto.ToValue(rev)
 	return v
 }
+
+// execTimeout is how long a FROM_EXEC command may run before it is killed.
+var execTimeout = 30 * time.Second
+
+// fromExec runs an external command and returns the records it printed
+// (a JSON list of records, in the same format as the IR) as a JSON string.
+func fromExec(call otto.FunctionCall) otto.Value {
+	if len(call.ArgumentList) < 1 {
+		throw(call.Otto, "FROM_EXEC requires a command")
+	}
+	args := []string{}
+	for _, a := range call.ArgumentList {
+		args = append(args, a.String())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), execTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		throw(call.Otto, fmt.Sprintf("FROM_EXEC %s: timed out after %s", args[0], execTimeout))
+	}
+	if err != nil {
+		throw(call.Otto, fmt.Sprintf("FROM_EXEC %s: %s: %s", args[0], err, strings.TrimSpace(stderr.String())))
+	}
+	recs, err := parseExecRecords(out)
+	if err != nil {
+		throw(call.Otto, fmt.Sprintf("FROM_EXEC %s: %s", args[0], err))
+	}
+	dat, _ := json.Marshal(recs)
+	v, _ := otto.ToValue(string(dat))
+	return v
+}
+
+// parseExecRecords decodes and validates the output of a FROM_EXEC command,
+// or the response to FROM_URL.
+func parseExecRecords(out []byte) ([]*models.RecordConfig, error) {
+	var recs []*models.RecordConfig
+	dec := json.NewDecoder(bytes.NewReader(out))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&recs); err != nil {
+		return nil, errors.Errorf("not a JSON list of records: %s", err)
+	}
+	for i, r := range recs {
+		if r == nil {
+			return nil, errors.Errorf("record %d is null", i)
+		}
+		if r.Type == "" {
+			return nil, errors.Errorf("record %d has no type", i)
+		}
+		if r.Name == "" {
+			return nil, errors.Errorf("record %d (%s) has no name", i, r.Type)
+		}
+		if r.Target == "" && len(r.TxtStrings) == 0 {
+			return nil, errors.Errorf("record %d (%s %s) has no target", i, r.Type, r.Name)
+		}
+		if r.Type == "TXT" && len(r.TxtStrings) == 0 {
+			r.TxtStrings = []string{r.Target}
+		}
+		if r.Type == "TXT" && r.Target == "" {
+			r.Target = r.TxtStrings[0]
+		}
+	}
+	return recs, nil
+}
@@ -38,7 +38,7 @@ func TestParsedFiles(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			conf, err := ExecuteJavascript(string(content), true)
+			conf, err := ExecuteJavascript(string(content), true, nil)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -74,15 +74,46 @@ func TestErrors(t *testing.T) {
 		{"old dsp style", `D("foo.com","reg","dsp")`},
 		{"MX no priority", `D("foo.com","reg",MX("@","test."))`},
 		{"MX reversed", `D("foo.com","reg",MX("@","test.", 5))`},
+		{"MX priority too big", `D("foo.com","reg",MX("@",65536,"test."))`},
+		{"MX negative priority", `D("foo.com","reg",MX("@",-1,"test."))`},
+		{"MX fractional priority", `D("foo.com","reg",MX("@",1.5,"test."))`},
+		{"SRV priority too big", `D("foo.com","reg",SRV("_sip._tcp",70000,5,5060,"sip.foo.com."))`},
+		{"SRV weight too big", `D("foo.com","reg",SRV("_sip._tcp",10,65536,5060,"sip.foo.com."))`},
+		{"SRV negative port", `D("foo.com","reg",SRV("_sip._tcp",10,5,-5060,"sip.foo.com."))`},
 		{"CF_REDIRECT With comma", `D("foo.com","reg",CF_REDIRECT("foo.com,","baaa"))`},
 		{"CF_TEMP_REDIRECT With comma", `D("foo.com","reg",CF_TEMP_REDIRECT("foo.com","baa,a"))`},
+		{"TTL unknown unit", `D("foo.com","reg",A("@","1.2.3.4",TTL("5x")))`},
+		{"TTL unit without number", `D("foo.com","reg",A("@","1.2.3.4",TTL("1dh")))`},
 		{"Bad cidr", `D(reverse("foo.com"), "reg")`},
 		{"Dup domains", `D("example.org", "reg"); D("example.org", "reg")`},
 		{"Bad NAMESERVER", `D("example.com","reg", NAMESERVER("@","ns1.foo.com."))`},
+		{"FROM_EXEC failing command", `D("example.com","reg", FROM_EXEC("false"))`},
+		{"FROM_EXEC missing command", `D("example.com","reg", FROM_EXEC("no-such-command-dnscontrol"))`},
+		{"FROM_EXEC not json", `D("example.com","reg", FROM_EXEC("echo", "hello"))`},
+		{"FROM_EXEC no type", `D("example.com","reg", FROM_EXEC("echo", '[{"name":"www","target":"1.2.3.4"}]'))`},
+		{"FROM_EXEC no target", `D("example.com","reg", FROM_EXEC("echo", '[{"type":"A","name":"www"}]'))`},
+		{"FROM_EXEC unknown field", `D("example.com","reg", FROM_EXEC("echo", '[{"type":"A","name":"www","target":"1.2.3.4","color":"red"}]'))`},
+		{"DMARC_BUILDER no policy", `D("example.com","reg", DMARC_BUILDER({rua: "mailto:dmarc@example.com"}))`},
+		{"DMARC_BUILDER bad policy", `D("example.com","reg", DMARC_BUILDER({policy: "drop"}))`},
+		{"DMARC_BUILDER bad sp", `D("example.com","reg", DMARC_BUILDER({policy: "none", sp: "Reject"}))`},
+		{"DMARC_BUILDER bad alignment", `D("example.com","reg", DMARC_BUILDER({policy: "none", adkim: "loose"}))`},
+		{"DMARC_BUILDER bad pct", `D("example.com","reg", DMARC_BUILDER({policy: "none", pct: 101}))`},
+		{"DMARC_BUILDER pct not a number", `D("example.com","reg", DMARC_BUILDER({policy: "none", pct: "50"}))`},
+		{"DMARC_BUILDER rua without scheme", `D("example.com","reg", DMARC_BUILDER({policy: "none", rua: "dmarc@example.com"}))`},
+		{"FOREACH no function", `D("example.com","reg", FOREACH(["www"], A("@", "1.2.3.4")))`},
+		{"FOREACH not a list", `D("example.com","reg", FOREACH("www", function(n) { return A(n, "1.2.3.4"); }))`},
+		{"GEO not a string", `D("example.com","reg", A("www", "1.2.3.4", GEO(["country:DE"])))`},
+		{"DMARC_BUILDER bad ruf", `D("example.com","reg", DMARC_BUILDER({policy: "none", ruf: ["mailto:a@example.com", "ftp://example.com"]}))`},
+		{"DKIM bad selector", `D("example.com","reg", DKIM("bad selector", "MCowBQYDK2VwAyEA"))`},
+		{"DKIM key not base64", `D("example.com","reg", DKIM("s1", "not a key!"))`},
+		{"DKIM_ROTATION one selector", `D("example.com","reg", DKIM_ROTATION({s1: "LbwnbvJqcOwgJnJSTTQeIsm4Ln0eytWRA6++bZfh4CU="}))`},
+		{"DKIM_ROTATION not an object", `D("example.com","reg", DKIM_ROTATION(["s1", "s2"]))`},
+		{"A empty list", `D("example.com","reg", A("www", []))`},
+		{"SOA timer not a number", `D("example.com","reg", SOA("ns1", "hostmaster", "1h", 600, 604800, 1440))`},
 	}
 	for _, tst := range tests {
 		t.Run(tst.desc, func(t *testing.T) {
-			if _, err := ExecuteJavascript(tst.text, true); err == nil {
+			if _, err := ExecuteJavascript(tst.text, true, nil); err == nil {
 				t.Fatal("Expected error but found none")
 			}
 		})
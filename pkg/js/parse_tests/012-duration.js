@@ -3,5 +3,6 @@ D("foo.com","none",
     A("@","1.2.3.4", TTL("300")),
     A("@","1.2.3.4", TTL("3m")),
     A("@","1.2.3.4", TTL("3h")),
-    A("@","1.2.3.4", TTL("3d"))
+    A("@","1.2.3.4", TTL("3d")),
+    A("@","1.2.3.4", TTL("1d12h30s"))
 );
\ No newline at end of file
@@ -0,0 +1,13 @@
+D("foo.com","none"
+  , SPF_BUILDER({
+      label: "@",
+      parts: [
+        "v=spf1",
+        "ip4:198.252.206.0/24",
+        "include:mailgun.org",
+        "include:_spf.google.com",
+        "ip4:192.111.0.0/24",
+        "~all"
+      ]
+    })
+);
@@ -0,0 +1,4 @@
+D("foo.com","none"
+  , A("@","1.2.3.4")
+  , FROM_EXEC("echo", '[{"type":"A","name":"cdn","target":"10.0.0.1","ttl":60},{"type":"TXT","name":"@","target":"generated"}]')
+);
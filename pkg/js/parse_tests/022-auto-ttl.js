@@ -0,0 +1,4 @@
+D("foo.com","none"
+  , A("@","1.2.3.4",TTL("auto"))
+  , A("www","1.2.3.4",TTL("5m"))
+);
@@ -0,0 +1,14 @@
+var GSUITE_MX = [
+  MX("@", 1, "aspmx.l.google.com."),
+  MX("@", 5, "alt1.aspmx.l.google.com."),
+  CNAME("mail", "ghs.googlehosted.com."),
+];
+
+D("foo.com","none"
+  , INCLUDE(GSUITE_MX)
+);
+D("bar.com","none"
+  , DefaultTTL(600)
+  , A("@","1.2.3.4")
+  , INCLUDE(GSUITE_MX, "eu")
+);
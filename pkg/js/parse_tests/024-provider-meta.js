@@ -0,0 +1,4 @@
+D("foo.com","none",
+    {providerMeta: {ssl: "full", always_use_https: "on", ipv6: true}, cloudflare_proxy_default: "on"},
+    A("@","1.2.3.4")
+);
@@ -0,0 +1,3 @@
+D(REV("2001:db8:302::/48"),"none");
+D(REV("192.0.2.128/26"),"none");
+D(REV("192.0.2.1"),"none");
@@ -0,0 +1,18 @@
+D("example.com","none",
+  DMARC_BUILDER({policy: 'none', rua: 'mailto:dmarc@example.com'})
+);
+D("example.net","none",
+  DMARC_BUILDER({
+    policy: 'reject',
+    sp: 'quarantine',
+    adkim: 'strict',
+    aspf: 'relaxed',
+    pct: 50,
+    rua: ['mailto:dmarc@example.net', 'mailto:reports@dmarc.example.org!10m'],
+    ruf: 'https://dmarc.example.org/failures',
+    ttl: 3600
+  })
+);
+D("example.org","none",
+  DMARC_BUILDER({label: '_dmarc.mail', policy: 'quarantine', pct: 0})
+);
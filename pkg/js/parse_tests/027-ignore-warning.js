@@ -0,0 +1,5 @@
+D("foo.com","none",
+    A("_foo","1.2.3.4", IGNORE_WARNING("underscore")),
+    MX("@", 10, "mail", IGNORE_WARNING("mx-cname", "duplicate"), IGNORE_WARNING("min-ttl")),
+    CNAME("mail","mail.example.com.")
+);
@@ -0,0 +1,9 @@
+var WEB = ["web1", "web2", "web3"];
+
+D("foo.com","none"
+  , FOREACH(WEB, function(name, i) { return A(name, "10.0.0." + (i + 1)); })
+  , FOREACH({mail: "10.0.1.1", smtp: "10.0.1.2"}, function(ip, name) {
+      return [A(name, ip, TTL(600)), TXT(name, "v=spf1 ip4:" + ip + " -all")];
+    })
+  , FOREACH(WEB, function(name) { if (name !== "web2") { return CNAME(name + ".old", name); } })
+);
@@ -0,0 +1,5 @@
+D("foo.com","none",
+    A("www","1.2.3.4", GEO("continent:EU")),
+    A("www","5.6.7.8", GEO("country:US-CA"), TTL(60)),
+    A("www","9.9.9.9", GEO("*"))
+);
@@ -0,0 +1,6 @@
+D("foo.com","none"
+  , DKIM_ROTATION({
+      "2026a": "MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQDUuoIc1LhuQ2IXjQNkpJSlT+paCtgECgtvnZ/mPOot0+lvD9p1kkkWq0nRWn1YMdq2IipsJBP59SCv1+XrAk5jQdc+eyypHX6Xr/NF2vvSKRlId1A7K5hlfGzL9o3GCtrFmuAjhmYawk/Ff+kYK4PIoGh99i1wE8gLxXXU0pn/dQIDAQAB",
+      "2026b": "LbwnbvJqcOwgJnJSTTQeIsm4Ln0eytWRA6++bZfh4CU="
+    }, TTL(3600))
+);
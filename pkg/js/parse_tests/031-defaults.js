@@ -0,0 +1,15 @@
+DEFAULTS(
+  TXT("@", "google-site-verification=abc"),
+  CAA("@", "issue", "letsencrypt.org"),
+  CAA("@", "iodef", "mailto:security@example.com")
+);
+D("foo.com","none"
+  , A("@","1.2.3.4")
+);
+D("bar.com","none"
+  , CAA("@", "issue", "digicert.com", OVERRIDE_DEFAULTS())
+  , TXT("www", "not the same name", OVERRIDE_DEFAULTS())
+);
+D("baz.com","none"
+  , CAA("@", "issue", "digicert.com")
+);
@@ -0,0 +1,3 @@
+D("foo.com","none"
+  , TXT("@","google-site-verification=abc", PROTECTED())
+);
@@ -0,0 +1,5 @@
+D("foo.com","none",
+    A("www", ["1.2.3.4", "1.2.3.5"], TTL(600)),
+    AAAA("www", ["2001:db8::1", "2001:db8::2"]),
+    NS("sub", ["ns1.example.net.", "ns2.example.net."])
+);
@@ -192,92 +192,153 @@ var _escData = map[string]*_escFile{
 
 	"/helpers.js": {
 		local:   "pkg/js/helpers.js",
-		size:    18327,
+		size:    30926,
 		modtime: 0,
 		compressed: `
-H4sIAAAAAAAC/+w8a3fbtpLf/SumOXtLMWboV53eI1fdqn50ferXkZXe3PVqdWARkpBQIBcApait89v3
-4EUCJCW7PW3vl5sPiQgMBjODwcxgMEhQcAxcMDIRwcnOzhIxmGR0Cj34ZQcAgOEZ4YIhxrvwMIpUW0L5
-OGfZkiTYa84WiNBGw5iiBTatT2aKBE9RkYo+m3HowcPoZGdnWtCJIBkFQokgKCU/405oiPAo2kTVFspa
-qXs60UQ2SHlyiLnBq4GdqyMZiUCscxzBAgtkySNT6MjW0KFQfkOvB8F1/+Zd/yrQkz2pv6UEGJ5JjkDi
-7EKFuevg76q/LaFSCHHFeJwXfN5heBaemIUSBaMKU4OFM8rvjFSeZSKb6ll7kvjs8QOeiAC+/BICko8n
-GV1ixklGeQCEeuPlH/kd+3DQg2nGFkiMhei09Id1wSQ8/z2C8VZeyybh+XOyoXh1pvTCiKUUb1iqvxpZ
-seiQ1dTGbvUz8oTShV+eXPhJxpKm6t5VmuuCGw0dDq+6sB95lHDMlg1NJzOaMZyMU/SIU1/hXd5zlk0w
-52eIzXhnEZkNYhnf25PrBhhN5rDIEjIlmEVSSYgAwgHFcVzCGYxdmKA0lQArIuYGnwVCjKF1104qRVAw
-TpY4XVsIrWtyadkMq2moyJT0EiRQqaPjmPALM2NnEXrq1zE8GJ0CnHJcDupLCmojJIsdqXUflDq7XfKP
-L6KHD6NSSicl3FPbXLeKl9pk4xh/EpgmhspYshbBwqfWsSBzlq0g+Ed/cHN580PXzFwuhrYwBeVFnmdM
-4KQLAex65NvtXGsOQOt8c4AhTO8TzdzTzs7eHpzp/VFtjy6cMowEBgRnN/cGYQzvOAYxx5AjhhZYYMYB
-cavvgGgiyedxpYRnmzaeMgWa496WbarJLJeRQA/2T4DAN65dj1NMZ2J+AmR3110Qb3kd+AdSX+in5jSH
-ehrEZsUCU7FxEgm/gF4F+EBGJ+0kLFpnlTqlTZzjTmNCE/zpdqoEEsIXvR68OQgb2iN7YRcCuWUTPEkR
-w3IJmFwlRCGjE+x5Jmcea0RdgppkKBhFw4lVlfOL/rur4T0Ya8wBAccCsqldkkoUIDJAeZ6u1Y80hWkh
-Coatr44lvnNpgZRhEVmFfEXSFCYpRgwQXUPO8JJkBYclSgvM5YSukplRZTzR9PmbtOjZ5XXVTAnDXefQ
-30XD4VVnGXbhHgu1S4bDKzWp3kN6lzhka3DHPUvLci8YobPO0rMsS+ipGI7OhtlZwZCyjUtPi4wjs8g7
-zB3PYiFS6MHypM1RtGB2NukCickcSzkuY/W7s/e/nf9JdsPOA1/MkxVdj/4z/I89Q4xkoxzRA1qkaVNr
-l1ZlaSYAyTUlCSRmdkOOp7YFJQJ6EPCgMcvD4cidwEBWnV74AT1puTi+pKIcf2BXUTJbqNCEd+EggkUX
-3u5HMO/C0dv9fRuMFA9BEoygB0U8h9dw+FXZvDLNCbyGr8tW6rQe7ZfNa7f57bGhAF73oHiQPIy8wGZZ
-br4yVPAUzW48q3CqTZtsZ5e4Y/8krUu8rRNXkc1G5Vugj/i0379I0ayjNnctMqsUWm0fT6v1hpogNE3R
-DH7taevgTrO3B6f9/vh0cDm8PO1fSa9GBJmgVDaDHKaOKy6M0p6KpgP45hv4OjzR4nfi7Fc2Gr1BC/wq
-gv1QQlB+mhVUWcN9WGBEOSQZDQTIY1jGjGfD2qo5EV7sDpbbwmI3SORwlKbucjZifjO8JeC3iFXMX9AE
-TwnFSeAKswSBNwe/ZYWdqPZBkiHV2uCqLURfk0nyyKzctYl0eBzHoVqHPvRM3/cFSSVnQT8wsu/3+y/B
-0O+3Ien3KzxXl/17jUggNsNiCzIJ2oJNNlt0g+OjsYMSLE59mNmEuRzVxF52BZGRtIwduvDwEMgZggiq
-DTuK4CGQMwWRtqJI4MHxUT8liA/XOdb9iiJ/nDkxCIYol8e3brnAYDZapKaNynCUt+w8FX2oyIc7MaUD
-oKe2IPqrAqoF02YMOz4aI8lAWI/W6wCG9VGJf507JDTi7TYUytxrNN0KibX1Tvgf7Tw5C/7ftzfnnZ8z
-isckCast2ehqN2XgO+e6GLZJwGXeTKL4N7+f477OuEXRtQgMuw7jvrVuUzLfbEtuvnBdiur0lUdLA6Uc
-t1iah6AfRKC3bATB6U3/+lz90N/X7+Xfw/dD+c/dcCD/ub+7UP8MfpL/3PRl86iMoA15X2jLVjoFawJm
-kQLYvFdP2yyKpqY8Sg9vz247IiWLsAuXAvg8K9IEHjEgCpixjEm5qHls2LMvvcHB4d/jF21xNGs2KnQv
-3dZ/5K6eICTQrNrVs2f2veuVNYF2+pti8YhZC5WeSjV9Pa87+2p7Kn15mXlXoC1LqzTOoLsbDl6G7G44
-aKKSimgQ3Q9+0ohyRjJGxDpaYTKbi0ge7p/Ffj/4qYld67vnI0p5tWqS02upMBB6ITwITd7mfkn35t42
-p6P7/xod5WxpWbRw9rsNVjNrIfVXK86MlVDy92/weI6O6kih4GiGI+A4xRORsUgfWgid6dBhgpkgUzJB
-AisVGF7dt9gh2fq7lUBRsHkNLWWbIVyKf6MuSKvp8QIU44QDglca/lV5Nv8L1UakHCmpWCj10QpmpWMh
-7XcrsCsoO8Bt+x16VN2nGJneMp0B/VQLOxxn/CmEX3+FKln6qczqDN8PX2bnhu+HLVqo3PHLolWrDDWy
-/2zfJU2w0IkxbE61HMSKTHDXhQGwoidcgU4J48IMqAN+EhaRASY0IUuSFCi1U8T+mJvb4XkXLqcSmmFA
-DDvZugMzKCoPf9xGEhlN14AmE8z5RiIiEPOCAxGQZJjLM+cCCXnUXM2RgJXkWk5FqGWxRtt/ZSu8xCyC
-x7UCJXTWkICmO1LZ+4WkEnN4RJOPK8SSGmWTbJEjQR5JKm3wao6pwpZi2lF3BSH0enCgcsYdQgWmcqlR
-mq5DeGQYfayhe2TZR0wdyWDE0rXkRgte4JnJHwnMhSP3WorD2U+bDhjbTy0uYKUAPXhwoEcvO4a0TfSw
-P3p+rlbCGieV6/e1iOO5vX39vrm1Vbz9Z8UY/+ooYfEpZ3iKGaYT/GyY8CLXfvPC1MJNy8n/pkwqyAj0
-/nzw07kXfDonzRqAe/iqZ7TlwecgrKVgO68qDJVxyQWHjOLS8apcosQfvwpfnhJys1oqY+7e9cJT2JoG
-rO6QyyUfC/SYYue+cqiOhw9ptlL52TmZzbtwGAHFq+8Rx104kh5GdX9lu49V9+VdF96ORhaRunh8dQCf
-4RA+wxF8PoGv4DMcw2eAz/D2VZkOTgnFz90g1Ojddk1EcujV4b3bIgmkyIUekDxWP/18iWqq2y3/BlSD
-1GFUjs+gHscLlGu4qFpG0jbEvV0vFodJJjokPGmAPYXxh4zQThAFtd5W++cSY9FqsmuDd5q/jIzkipdS
-kh8NOcnGZyWlgDbIykxRSkt+/0vlZQhyJKbIf5nMWLaSmlxSlcdptgojcBrklgnL/WR2jqOeajuYupRs
-ZTiAzxCEbZcCGtoAnUBQxpqXP9zcDvQZ3TFpbuumvFnN0viFEN5dpZd5vry+ux0Mx8NB/+b+4nZwrW1M
-qsIFvQvLi1llnOvwTVNdh2hGv40pAhX+6mn0byFS3zX+kU4v+C54xoNpUpo+EQtkyK+slEoyVjZae8A6
-h2FzQnXrqKFF2nCWd+8GP5x3HB3QDeUqJ/GPGOfv6EearagkQKcM9aLe3I4b48u2jSgEKwyG16934DV8
-l+CcYXnITnbg9V6FaoZF6bU7WupcICa8q9Es2egdFHB5x7zxelmVS9h7Ze9K2dkAEsgleqCkqwtEHrVK
-Kl5UVQb8ouPEJ93vwLbBZLngsZp69LA/gr71/FKLXHgrl54/5GAEt7kO3G1uOGPbxpV6BbbGp6oR8MoG
-7G05vLaiGqKPeNPtRAiIO3f50KfrapPoYoJH7OCSExKcwCOe6uMX4eVei50M7qIQSOgz44wsMXXJ2iga
-yYzVnRY2K7pEpjBrnL76+fZGZ4Qkdqs78rfyTeaKlXd+edIQkaNdLzuLS7tThYe/z/iYyEpDaoHP0RI7
-zKKUYZSsrejrIyVuu1CAqKkWU3vKKTYyN5dtB6TNwb7r+LWl3XoKbDOY1km6417ot198qHQct7Menja1
-rMnG1WiLVUvgTebIK2rKEuhVQ1Sg2gBsVuxlSbgpMFpkib3GbwmJ2ivstqDb2wNdaCoqrVWbyhyUWwep
-0pEscQzRl186GTGva+PMhhkHiVcF6+E4acXw1NpaVhA6vlgt8WZ5tRNoagvPB4PbQRes+/NKC4MWlJv1
-UQetRgHqB8D6OUfV2CSm+uqXJ/98U1kEUxjurkzj8PpN5W5MU31NJM5y2BXhco+VYxosqli+CuEFXjwT
-xUuQRk5GS6OJ3MT0UA/q9XIof7zbGBVYq8nw/xWEYd4o27QG3xVDK6LKg3bacPhiakEQxnBL0zVsHbyN
-gBVmGHihTXxQT2RJgbr5qh1vJ6epNPjlNDvbDFldGq2GzGjGmfQZRHlVRzO8c7eF1je0m2o5HSWtcFpp
-fAsHbZokfWJBq9hIIrDyaTWmX3jYHw5GLTfoL1athooFW4D8ifdHW/GVKSLDmcrhIJI2Vn2bXVEFsqWt
-eKgTIM8cziXvZp0pTUq7zrQoy0sqP92L6s21nzWqtubGqrchajF6LUvqvIRo9DUfGpSjRNr1yu18kKea
-426GqS3hxElzSOnUSvBq9fyhftV5bArE7ZOWlgjAyE33OZL1TvLPHNlQkujTTiex9Vd+TZY8Rzn5RDKF
-6q6HqsAwAsR5scBAcomOYc7jMsgg5sakFku2hJGNuNELGd1HQhNPC9pWv+1BikbXtYztvEAPbFrbe2Li
-a5QRdvvLkARPSILhEXGcgDzOSFIt/JvymGPfiHD9RqQ63sgDmvzyLnXV0NvWdyES1nsbomBtwcjlBVy/
-rzDrJVPraPnccYI93vokxI+Ln/UkCx0Mt7uELY9WqscrDE/aDw1bX5X87mhXMb8xzn1BlLvYFN9ujW6b
-ka0b1dYexfxGsI0x7ySjPEtxnGazTisv1TOb643va4Ko3cOaVzbtvUHn/iPJc0JnX4RBA+KZ3OzTTrt9
-9J+1MTyxSS+SQ/W2rvQyHKYsW8BciLy7t8cFmnzMlphN02wVT7LFHtr7+8H+8ddf7e8dHB68fbsvMS0J
-sgM+oCXiE0ZyEaPHrBBqTEoeGWLrvceU5Ebv4rlYOPnau06Seekw6dGSTMQ8T4noBLGNgvf2IGdYCILZ
-G52ydbnrqD+7ycP+KITXcHj8NoRdkA0Ho7DWcthoORqFtRd/NjleLNxbMVosVPVzWfzcUpEYBPVnOc69
-scTXMoYWi8YDR2334W+SzpbM4JG0Od8q0/PmjVeCLWmEayTm8TTNMqaI3lPcVmrkYYddCOIAdiFpyRom
-ZbFjmhXJNEUMg6r9xLyr74exUE93hLpVljQ69QtWJXWl3MX4bnD7/p/j24sLVTk6KVGOc5Z9WnchyKbT
-AJ5O5GrfySZICEePKU7qKG42YqA+Akzbxl+8u7rahGFapKmHY3eASDoraIVL9mD2xj62c0XQ3aloNw8q
-sulUO0MqSPluCTrOm4uw65Nn3iJtlNTYjKsk1jIrbU66aZqbZ2dRUtWK8O5+eHsdwd3g9qfLs/MB3N+d
-n15eXJ7C4Pz0dnAGw3/end87m2ls632VCl1I/AOcECa91B9b9asGlCW7QRSEaruail3D+uD87HJwftpS
-gOR0bilX4FnBJioPupkvrz4hwVwQqk43Lxr1117gaHakDYikDdCXOhXF/nWLEeHw/Ppuuxw9iH8Lc6Mw
-3w2umvJ7N7iSXs/0H+0ftIIc7R9YqItBaw2yai5Lh+8uxt+/u7ySO1agj5hX+XFlsnLEBO/CUL/LFRwy
-VV8mx9kQuSMyeMTwIZOuT4fmAQShMofq9lQPP7u515/lK7KckQViawdXDJ3KuHwXqFdPDK268A9V0tZZ
-zclkrrGEOjzNmMroFxSlAjOcgI1fHDqtDVYUqQBCUyTwIk+RwPodZZIQc9lknxxrvibqrXLiUjbm+fRv
-iSZvmiIhMO1CH1LC9VNV/QLVjDcA0j9Uxs8Re4ux0wZLy/vXX8H5rFKXh82nj4G7mGXCDwlIMeICDgGn
-WGUYGrGImdEI1k24ls2uojcGMrRqDmNoJQeNGVrxfFoO1ZZZJ2hV5c0cl5JzJK9ttz4U5zrVa6GlY3Xu
-baQeYOXY1LlOOtHh+2F1myanUyTYlI8RpakeCMIScaVFvtrYSPNyaleT0Jk8EEohYy5wEsEMU8z0o/Zq
-duegilY1pFaEmiSDVx6kvIYqBbjvvT4vB/Rq8C2lH0zH/sP3w065MpGRSVVd4TBpA3zJIs/xRFrAJDJx
-jt5Bkok6D3aYT6gCL8m0MPVZf9guPn/JzaLW2VJ6ahmLIA9rdwrMBq33iiQEZz9eXtvi0/J/p/j28Pgr
-eFwL7P1XAz9eXncQK99WTeYF/XhPfpb2//D4uHrkO9hY0RVBqpYLMeblClNM5Y/dXoW0yv4PbG6QxTwl
-E9whkYR1QP3j3ECy+P8BAAD//1ihD1KXRwAA
+H4sIAAAAAAAC/+x9e3fjtrH4//4Us3vaUFrTlO19tJGjJqofqX9ZP46sTXOrKDowCUmIKVIXAK11d53P
+/juDBwm+ZGdv2/vP9TnJSsBgMBgMZgaDAeRlgoKQnIXSO9rZuSccwjSZwwA+7QAAcLpgQnLCRR8mU1+V
+RYmYrXl6zyJaKk5XhCW1gllCVtSUPpouIjonWSyHfCFgAJPp0c7OPEtCydIEWMIkIzH7J+10DRElitqo
+2kJZI3WPR5rIGimPDjGXdDOyfXVwID7IhzX1YUUlseSxOXSwtOtQiN9hMADvYnj5Yfje0509qv8jBzhd
+4IgAcfahwNx38PfV/y2hyISgGHiwzsSyw+mie2QmSmY8UZhqQzhJxLXhypODSOeqGAZIfHr7Kw2lB199
+BR5bz8I0uadcsDQRHrCk1B7/8HtQhoMBzFO+InImZaehvltlTCTWX8KY0sxr3kRi/RRvEro5UXJh2JKz
+twuf3JbFEB2y6tLYLz76Jab04dNjUWLpnNWrOA1THtWl+roQahfcCO94/L4P+36JSEH5fW0RsEWSchrN
+YnJL4/JacNmy5mlIhTghfCE6K9+sHcuTXg+nFCgJl7BKIzZnlPvA5sAkMAEkCIIczmDsQ0jiGAE2TC4N
+PgtEOCcPfdspsiDjgt3T+MFCaDHEWecLqrpJZKoYGxFJcvGdBUycmR47q25JMjtmDEbcgMaC5o2GSEGl
+BQ6xgwL5q5J0t8rMocOiya9TH0o9FEJd6etKjaXSWa+XC8UFlQSWaRwJ+GeaUBBUSpYshCIol3AfklTm
+HAiKCa70Erhou9VBzAKcxAqUn89a596Hu2qbQpcGJTme3E1hADeSs2TRuXe4gH+Ple8rGMAsSFdMonh5
+bvdejYGG0o+SJpGZxmClCF2Vp7MgVC55ugHv78PR5fnl931DcC6tWjtnicjW65RLGvXBg90ShVYVVoo9
+0Pqi3sAQpnWMJv5xZ6fXgxOtWwrV0odjTomkQODk8sYgDOCDoCCXFNaEkxWVlAsgwioEIEmE5IugWKUn
+bUpLqVE94sEWFafJzOWcwQD2j4DBN65NDGKaLOTyCNjurisKJfl34CesuhIc1a7BBAwst8zobCd1gg41
+QYQvshVNZCs5CI9ClQNO2PSomdhVI33pPeWcRfTE0GgEzc+JNtC4wLTBcZybgCUR/Xg1VyzuwovBAPYO
+ujV5xFrYBQ+YgIiGMeEUJ5XjvJME0iSkJT/B6ceaNJfwOhkKRtFwZISvOiyIeLoWSs6sZMklkRAiaXOe
+ruDk9Gz44f34BjoINGdcSEi6iEsBEtMO0rnCovvXav3qx9PR6PzkdGZxdLrA6TomIfp/cpkKCulco6Ig
+sEvFEpRtXG8BjPMuTS+qT4sDm60gSSUVsEw3iGhFkgdgEiIW+SBSDY/Y70nMIqLWyYbwRAC5TTMJTDoL
+qHXOE3cl3dEHGBQ6kXdLfqhyD3iA5Kup9WAXOlypAfj8GTyvG8j0fbqh/JgI2rEyd/SMRSFiFtLOPpJT
+gKebpA3Sghmtnm4Sv4VsFGKudOjEs0yYWUK8qRJgT/KMelXtrwfcpKEjGlNJYQvao9JqNZMaKUswZ7Gk
+3OoR4dAdNVOAs4J16KXiR+5aje5ReaSmJ6M74C+wX0NqqLagLjNyo1bBUzFpzjzOgojN55TTJKTOmGz7
+uodgSsqzitNs2gZhmoRE4pTmKztfp8Z4CiAgqIR0bpsVyhBkCmS9jh/UhziGeSYzbhevCBDfKbpzykuT
+aYF8w+IYwpgSDrjS1pzeszQTuLwyKrDDAEaG3nQOBDE9UyVo9WFI5QUOqxsQVVk9OKavwPapZRfXZtue
+NCWu8VMK1bUp3bJtH4/fd+67fbihWuuMx+9Vp9qy60EFFtAjmUy9LhBxp705Ze2N6+MJeIn1KyJZ+BLh
+rUu9JALSxB297tXZt93r3ZrBX1NPzUrAlXtsOZMyVuKuV74jpK5Nsg5m7uiVrDAMVCwhWYzTk4wT7USW
+7OxWknggZQwDuD9ydiW9Hpx/f3k1Op0Zd66Duj4IAuR7tl5zKrRBQ2GJqoo/d53lkgnEZc1XEj84LK30
+4Op/ppai2iKgxytTJYtK5Qa4Xgr5KO82m4eoMOpdGAxy/uuSmSXYm5Zm6dt2uECsYyY7nu91S036+Rpw
+p7nWHAaWGKtiWCS6wa8pSxTOyjx8f3rVidNQcVdJPb+nirEuV0GmEMYMGQIsAQvfRwRemCaSJTSR/dMP
+no/fs0Tyh/7Jqfvtw83e8dDzIeXgvfLytWKw5r5IkkIql5TnfcCKyHBJXSe5RLKzZF44QpzX1102D9t3
+QZI7pWAtpA8iC5dABLwsRvDS+x2CridkQVM1CRZvhd/Xo6vx6fH49KTT7aNGOU4TydMYNmniSQiXJFlQ
+SLmjUd2pCCkwiWjoRybQAGVJjGsFlRowAQt2TxPYI3GcbvZQsmkoabSn0bo8dMgoh0W2Dy5HWdMq+QAb
+zENfOYFmEK7n59oIC67kABEtyT2OVpQthg8sEZIS5a3eUpYsgEQRjUCmypd0xthAye8Za5MX1TLmqoI0
+HegxChqmibGlEFkIK2ze6/19XCdvVx5iwuVxEB0cLj1fNcZAmzbRdAN7UsZ7OvQ2WGYrkoBYpht3Whs0
+tWtTArWYOr1ffo52/9DrNtiVNeGCnieyouLV8iqad36OdiditYw2ycO0W0Wll9m93RYlqQSidbgzfEVo
+LYSaqTih6MOBD6s+vNv3YdmH1+/2963JyiaeFr4sWMIrOHyTF29McQSv4E95aeKUvt7Pix/c4ndvC2dc
+ppLEyrXYKdxaGEAPx9ztFKPuLQoIo6N0wWbJYgqdjimEAXAa0I80VJYV/fAki+MSw1Sfu4OC+6bt5GDa
+hVeQTez3w+m0QSGp9rkTmccPS26MEeLcnSk2eq4P5rZ1BOdf6R2UvP8oKMKdVSfBtoAVuaPHw+FZTBYd
+5aS2LmOlTcpSjSVBSMg8Jgv4PNBebmX5Hg+Hs+PR+fj8ePgeIzlMspDEWAzYTB1vuDAwKNF0AN98A3/q
+Hmn2O3H5l9YTvCQr+tKHfbXnTsQx2hdI57APK0oSAZFS/pkwmh+jOVR7507YN3Ab46Ky2A0SbE7i2J3O
+2hmBad5wQGBqtNeZJRGds4RGJdczB4G9g98zwwUVYoJk4MozuCoTMdRksrVvZu7CRPcE+oYOxARB8L8g
+CKbNwDhpQxiYur9mLEY2eOiDfIJVFku2jmkfUJ2r3ZrCPhw+g4Th8HdSMRw2ETIcbqfl/fnwRvcjCV9Q
+uaUDBG3oAYs9g2709vXMQQkWp7apbZjzVnXseRUOYgcAAENxfZhMPOzB86HQGlMfJh725PnWmaejt6+H
+MSNi/LCmul5RVG5nzjIkJ4lAw9fPpQzMavdVt34eBxYNyx/p0YFE4QRzHQDdtQXR347aAvCmDX/7ekZw
+AN16OKMMYIY+zfE/rB0SaoHuJhTKLGo0/QKJtYlO2MHfeXQm/B9Xl6cdPHKYsahb6IVaVbM+hXqEyWXD
+Ng64gzedqPGbz0+Nvjpwi6JvEThBqscmk9EkZGXbUd0w6MqmnTaJBW1QdxOlSuwy9o4vhxdqw3Osv1/8
+hP8f/zTGf67HI/zn5vpM/TP6Ef+5HGLxNA80G/JeaPWaWyarAha+Amhfq8dNWkZTkx/yja9OrjoyZqtu
+H84luo9ZHMEtBZIA5TzlyBfVj/Xc9iHlcHD45+BZS5ws6oUK3XOX9b9yVYeESLIoVvXiiXXvugaaQNv9
+Zba6pbyBypJI1R0OUfU4iuWp5OV56l2BNkytkjiD7no8eh6y6/GojgoF0SBi4gNL5ME7IGFI11LYyHtG
+1Q7m4N3eLZMwZzTGI+2Ln9TG7Gb0I6w5S9FzosLH74hrQ9liKfVBFx7KuTsV20/nY0X7oJhofmPVV1/B
+R/gjHCi/ZF9//Uv+6ZsBvHv79vVbu1xuRj9qLhhiHnxNgo+9P8kaHEWNNXqxlgxcPtmNy8CptVR4fj7c
+Ur0mrq0WaW6ra7KVuv4/s7QEv7eDs3D2exOsHqiF1N8acaY8h8LPv8NQO0tLSQBkgiyoD4LGNJQp9/Um
+jSUL7fGElEs2ZyGRVE3++P1Ng/rE0i+efkWB5zsSXaq2lLVDuBS3QzXKAvR65bFAQmkkgMBLDf8yj5r/
+B8VGxoIorlgo9aURzHLHQtrvjcAuo2wDt+wL5MhRVJqnV1ynlDTpKw2BVZ8/Q5F98jE/2Rn/NH6eeh7/
+NG6QQuVFPM/JtsJQIfvfbXJR+Up97E1NRECA3LCQ9l0YAMt6pi2LPhnWDaqAH6VFZIBZErF7FmUktl0E
+5TaXV+PTPpyrAydOgXDqnMUfmEZ+kXJjHSAV40aDJ0QrERiPy4Q6mE6pSDyJCkVSDpslkbDBUWNXLLFD
+rND2t3RD7yn34fZBgbJkUeOAptvHTtgKqaQCbkl4tyE8qlAWpqs1keyWxaiDN0uaKGwxTToq+QrPUeFA
+md4OSyRNcKpJHD904ZZTcldBd8vTO5o4nKGExw/ANFZEsDCRO0mFFPXsJLMEnPXUti/avtlyAQsBGMDE
+gZ4+b/fU1NFkf/p0X42E1TZYFz9VfI2n1vbFT/WlrbYJ/x7v4n/bR1h9XHNqjsyfdBKeZdgvnxkPuWwI
+V1zebAu2aAa+Pmzxel8flrxerLy5GoJkK+y26tS+PvyfOrVvDr9+8/W7Px1+XXi2V8NOgjNym37Egc85
+FUv8IPmDD/TjmnHqw4olUsZbPNyrBh/n5mqriyPaJRCJaa81RObi+fqwUi35Q1ulHlBbrR5mvfZfKdfe
+d17Juo3VSQ5nJIbOfheYgJjOpTl1yvVm0CrXWn/hFKoPeh73zbd8Ps03Nanqs51Z3UiNe2rOcsHr1lfI
+8OL05nT042lpN+iEfioAbjSkmkWBkYiDbuVYp/OywFCYTVwxaUJzl1KdMCD+4GX3+YFiN9atsjTcjPE8
+o6YS6Sky0fNpn0lyG1MntXms4jWTON2og6UlWyz7cOhDQjd/JYL24TX6Tqr6ja1+q6rPr/vwbjq1iFSO
+8ssD+A0O4Td4Db8dwRv4Dd7CbwC/wbuX+YFQzBL6VNZKhd5taZBsDYMqfCkbEoEUuTAAtg7Ux3IAUxVV
+LXI5WVqDVGHwz6KeBSuy1nBOPhdrauLMd5KtDqNUdlglvUrnSVUzE7ZadpcYi1aTXWnckM1meIQznnMJ
+v9T4hIVPckoBtfDKdJFzC7//r/LLEORwTJH/PJ7hYe4AJjlV6yBON10fnAJcMt18PZmV44inWg56TfN0
+Y0YAv4HXbToq1NAG6EipOTdnSCuuap5PSZ3VAtkVTVO+M1HKsS0nKV1cX43Gs/FoeHlzdjW60DomVo6w
+XoV54rGyr1X4urGtQtT3dbUuPLWx093oz9r4OeGAf5vha/TONCk1IJ01UdFSKupf6GjVvjbCbr1DlTOm
+oWVcM3Nno6uL2elPp8edMF2tSGLGZ0/oRlkiwNQAkSqrmi324pREymdTuyISReUcFCZhzZlJrZRLWuQ9
+6iRmi3CVCQMJBP7fzdUlxEyo49wcUwLnIzNqxzssqHZz0YjOb/wfJKOVhJtE0fnIpG92Ih9mmAF++pGG
+gcoW7WDugeZWtyrtir4Po/edjMeKj2dUZzBkPFaJ1fC38fj65ndxVNOq87tSN+nApASJdZoIqnl6S7+E
+oZZg+PSFzLEIquxwgetjS+clYl0KKyMt6K12/6tIS2npnIYoCog1UKkgHQXR7kJgg21+A6ehVkHluxPq
+7IvTMKhe9jMrUJXDAD49NmWF562ljJsa67XrZnY0YYnyTHdz6TGs5OH+7erGxMyAJfc0kSl/+IE+tB/L
+4wyRBIaQmiN3DVhcbCNRpBJL03kJowly7PR6RTHMWUxNNp1Ket7Lq5z5dEi8ow/uTOZ69pkr24fD5yxu
+pS+iiFekZJYT51DjKNXGnGlE0yY7tq9bbbZgoMEnrDit9PqeTtXfO4BvYQh9xfOyR2GalzTPRFNo8U1t
+kmphmbqdhnT6XEddjU6Hx3/r4KLzYa5vsRyTOBYwTzpM0hWKS0Q/dot5x1KcdN0m5QhpzlLVtFlAREUx
+8VMt7sRcUfQL3aYifYVSgw5JHiTGmAHTKM1GqNtgPjD/RwDhFDhFw3dPW3VEbYDV8+r8NuS8MbnVtO87
+qYfFtqxQs7Y/r5blV3i5SIIKVrxwjv1V4VP96phmQ7dasfOCu96zE4nMFRjDFwuiZ9xZfdXbY3MX5qjm
+069UQl6e74SjXTXm6LXeO2v3omve5OXx+w8np8Ylu6HSB+WAOuoLOM0EukT23oc1N42ihodcGcZ0gST6
+yq25i2VaKVEkFSnU2k7h3yKQcD7XxOnomFGFckkfGloRqWFtam4fXn73Em5pmGKHuookEaJ6udlsiir8
+Fqj6l27ifhufnmPicd7lal26Jal9W1/ZnPJNSfyTq3U5C7HZdJUm3iFNrtZPqFrsoHIrsk3hchi44CWr
+bUVW8/Orrwxj1cWu77wmWeXWp7cfNCh8a5r2bcUueIEHu7q4TZ6brHbjxd42HrjxnS18aAgEVVoXl3ae
+13Flu7e176atYR1HGwXFnWZznRmb4qeqLrj+MPr+tOPsYnVBLspR8AOl6w/JXaJvB5osJN348mpWa5+X
+taKQPDMYXr3agVfwXUTXnOIBeLQDr3oFqgWVuXfV0ftGIQmXFQenNb6lgPObWa38RhT5bazSRSxnkSOQ
+S/TI3CHFg17jXqixqBvW8Emf4Tzqege2CSZdSxGorqeT/SkMrbVCQXPhLV8G5SYHU7ha60M1m26W8m3t
+8p0xWBNe3OErXeuzd8zglWXVmNzRtoTHLhBRtA9gmDzkdUJf9rulDi7skFFM+prro1EmclUaOElhq0wS
+SZVN0MrfIauVNTgYKzsNwyzoMtZG4yyLXzliorM1UuVYadnBz/pGpr3j++lRQ/juVu9Z5+QYOcmbfGH4
+xMSGNaRmuLq6kgMDiTkl0YNlfbUl4rYTVXhG+nGI4uEA46E0HV62H8W5oUtz/rTthLYp5GPDfG67Z0Ye
+n33g6zhNznyUpKlhTlpno8kO5MDb1L+j3WBQNFFmuAZYf54kjbptod1VGhm6m4K6zc+JbEHX64F+cEcW
+UqsWlTnEbmyk/N00chTRV1852SqlqtaezWAKyPJrQCUcR40YHhtLc8vpRBPVFLfzq5lAsx85HY2uRn2w
+5q/0TIjXgLJdHq0v3+h7Vl1PtSGPzJ1lN35SjQpMXJFqPH77pjA3pqjJY8ybvWdCwqBoUxuiOo0obZye
+OIdAkFq+hOZGHbk5lYDqsYSeDv2WQq2VV1wK/O+McSrAa4CqsqERUc4H6DThKLOpAUE3gCs8ztzaeBsB
+G8opiEyreO/oiScWdkorOcbctqKbrQ5tlRutewnCFydoMxjOtysZtV0FQuuk77bXVhwhLXAWjy8cNEkS
+2sQsKXwjRGD506hMX5SwTw6mDUn5zxatmoh5W4DKHe9Pt+KzHHKvpc8Ji2uzvk2v4F+hKyZVAvDUxMkb
+b5eZXKU0y0yDsDznvQRwct/bX0yoU/V3Jpe6T5tn47uhCXPpSKjbc6J4d8YG4ILyLt4AD2BScKqeAYaS
+U+qybNVqTbtNR7s1KCvbKkOnSQBd4SsJmTOt+eZaPfhAEqCrtXzITzfMAL1tG+6CCTUSGydha2wEimOB
+lEdHT/pMpvOnPCYz9kHbMi1e+musr7+Y5/5JGfdLEZk62GODc1bfjjS4jUfNzXIHJm+SOye40ygmwodP
+hkd98y+uDnjsljup9VI/+6j7hY8NBqQ2cY+lqMIT23oSRXpHjEdPajqgfBUQ99pO1gyzEgpM4C7glnIf
+iBDZigJb29OUIHdEmcl4rew3GlZdbW9R2lY87pSPrz7tbJOkpscby3Pi7zxDlmxiYunNxbJkPh7lTyDW
+n0qMaMgiCrdE0AjSRJNq4ffgrPJootCnSsUWGIg+Xiwl5aumV40PJSJs6bFEBWvvKZ2fYbJpjllPmZpH
+O84dZ0MgGt9ILO+dnvQ2VnrD1Ow2bHnF0f6tnCPAJ/ZF3RZv4/ftiNTgW/dCz9gJrdr2QFt3QPXdj7vz
+qTyC+DvBWvdFYZqIFFPM0kWncSzFs4oXre8pen6rek/nsGqu9To3d2y9ZsniRderQTyRgfS4A9tOigul
+aAOjbA3FO7S5ERT6FbyllOt+ryckCe8wm2Eep5sgTFc90vvzwf7bP73Z7x0cHrx7t4+Y7hmxDX4l90SE
+nK1loB6cU21idssJf+jdxmxt5C5YypVziHHdidJSyDRSj7xJ+7xPYHdK6qFQKiWjfE9Hmd3RddTfbjTZ
+V09BHL5914VdwIKDabdSclgreT3tVl7HtSlg2co9WUyyFQzcg7CGi7Cet+XRKMTX0CbJVrUXI7Xehz8i
+nQ3R49dHwPA9tyNge3suSkUjXBC5DOZxmnJFdE+NthCjEvb8XCNqiCxH+R3bOM2ieUw4BXXlmIq+Klev
+tpaeanXun1iR1Bc0z2bXo6uf/mt2dXaGBgvCHCU+YPzxoQ9eOp978HiEs32NRRAxddAXVVFctmJIygho
+0tT+7MP7920Y5lkcl3DsjgiLF1lS4MIayvfs46ouC/o7tln+mEg6n2tjmEiWvz2nX5k0IN1+mTzzFE8r
+p+xjOwXHGnpN6p22dXP5ZC+Kq1oQPtyMry58fBvpx/OT0xHcXJ8en5+dH8Po9PhqdALj/7o+vXEW08xe
+M1cidIb4RzRiHK3Uv/ayuWpQ5F74ee6FEmIz9NHpyfno9LjhAplTuSXVX6QZD1WsvH1cpTz8iArJErUD
+flar/2yaoh4O6gAfdYAqcyguJxUaFo5PL66387EE8X/MbGXmh9H7Ov8+jN6j1TP1r/cPGkFe7x9YqLNR
+49V3VWxvrN9cn83++uH8Pa5Y86ibPUNRKmtNuBT6CTL10T5IeXN9ZvBCR6ZwSwFjmDTSrjler1DqUB34
+6ub4AKT66jz1yFaEPzi4AugUyuU7T+V2cLLpw9/VlcTOZsnCpc1nUO5pyilSnCUklpTTCKz/4tBpdbCi
+SDkQmiJJV+uYSPVOGO7ImDmQzB8CVuMK1dvUkUvZTKznf4w0efOYSEmTPgzz0IR5RdS0NwBoHwrl57C9
+QdmpkkDz+/NncL4W4e3DhjQiB2sRFCYSYkqEhEOgMVVRqHreku6ilCiiXY682BX0WkNONvVmnGyw0YyT
+jVjP86bFBlUH8u39oGraqkyN/g7yFmt9LGBboIF1zvhkqvN+dIoOToG6EJyfvJqLttdnwASkPKJ8T9BE
+MEzFwR0ivvPHxEqnmlEcg5p3e1OJq7cQ9JvKNPc91RwRnos//UhCWdz9VN2AyvxRIW77ynUxJs0dGJRm
+ubijZMdaCHhZoi0h53MraCxZ4ABx/qmQNPJhQRPK9fvqBUOcPTTZVJDa2dUkGby4xysVtDwfvM4bDCrw
+DXcvuN6W4A3vXGh8w5PieoMzSLv3wCGKNQ1ROUe+ccH04sZBVMdgm5UJVeA5mRam2uv329lXlsJgp3FY
+agnZgfmw7laOxHj+AN3FcHS8VSNvVamqeZMynUUrwkOtstZpzMIHVKpEIixl98517ijVY8OoPMrSirC4
+D16SJmiQvf/OCCfqzVIPUg4ep0iZF0DHaJyoq9WsMRqqL0WfyG7t+8oOZRqgq3PG79iqDyc/nF/gZmKR
+oLLysYuYfKSR7s/8NI+LwtRrHGI97ytx/lIM69CYhzXlIU0kWVBI5yV2aOulR6bTEgTI1Id9kCkc7O+7
+qA/29cN1PCNoIz6Mzn1IeZ7NOccS4WvllURAFgtOF0RS4FS9+oI1HexUpn1siPtq0dfWlWfz5+BE0jPu
+YlStVUBvbB5pNuYyl5zUZOd0tUOixfL66v358fnpDSruJoHwc3Eo/YBRSabbzB0aunIv5bedAiMoxnVv
+sH6lbuz02OzZNFGziO5XpZfq2eujXff3+ukdjQJTLnVmrn5aVL+zK8lCa/+Suk/nMDo7hj+9+fPXhZ5X
+oMi3+4Ei4AC7XA+QIHd806OK5hLrcpJt9VGxrTwT6yZ+tfJMrL+AX+XYEw5TKz9PuKPLf3ro0X2Sf+Kp
+NY/YcOHiBa3cD5dkUR2rQjWRZDFtj7fA1kf5TSIBVRtbs+r74HHPB27+1eqhD57AL+pfeJwUXVevgiC6
+Z3EXmSHJQh1pWTYbEiDlxW+ObWOqaa8Yqzo+Kj2YX4jNOpTb5abpx13KTT19bOHB58+VgLWFwlv4L9Qt
+/FaQb7ZV/gW1Y173LCZisyIjfrNMY2qOV3Sc0qrgJ/jorUPprr1QNosnz4jSatl/SDYzznR6no2lF2i7
+8C0U36APzTJpSEdEDsEZZw1Zd8MErJ6HFzfn/ziFmK2YecBBsH/SwiyYF8iqZwClAvx70fvFWqvJL9/9
+LPyjF9Pd74qPn5X9+rb/c+/n3uQXU9jtvJjs73093Z3crRZy+m332z/0AkmFbI63Z5zVystJNe1nzu1r
+Uv2CEeIuP71cM71oXL2nri20r1qcmMITdtZvcf3dbLfczdfnz7kLV13plYtkWr7Um0nG6VR06B7xPrCv
+fy4hb9uUONzeuvi5jR/OLzr6eKir/W4BRJWaQ6PiB9D+cvj2Ddw+SKp34Lpl/rLYOru9ow9OpAXvpNlH
+ixyHW90ZVPjxB2j0K+cWSzDTnuUdfdB3IxGECWCJOlR898bXPm3K1b9ppp91vj692FEppKuUBzBUrdI5
+vHkD4ZJwEqptZef1oSZeEUUSoNHh27cHX4OimiQP2hcwVz1IAqMbhcl9ShhHTHg+WNf70SXtOiNcZsnd
+Da7FARy+fVvOiRs5WeT180cfYrULI5yXMlhimuCH3UGBvLx8RjZrhZtf0mE+wjvgpUvHSmRG1aOK0htk
+/KgxlGth1NNfL3q/TMjeP/f3vp7tTXc7PwfOt+6rP/SYVgl5myYX8IfzC72O895Li9mWNl/cMkSZOWpB
+X8OuL22ts9uYhUqCCvPU8k67mm8jCoF50b/T28O/zl9Pvz+//Hx6edKd/LI3faUKewtf/W5SDvqz2DVl
+Dlt7v0yGe//QLNv9uTfdHXza9w8frSbFISGXsU8tDPBHeKPN9xcP1TBWr7H6OGHgdoci/uYNfAueWUEe
+oMsliHdUvc89cXt1Frfn67WETvQP5xcHR3CnlOodwh2BdjhxpNOjijqrXeC2Vyefe8G0rPlmo6vxcHx+
+dZmLo6hqMMUmsTS/1XBHH1TsVNB7yknsKi8BRKpfpyh+sIsgPIQkUT5iKjEQpZmf0I/SsF5tVk0nkXk4
+X+1OmQAhWRyDYIs8JIuNw4xzmkj16zlF9/pnw9ZCZ2XYYpApMCmc2a4otAYW1E5sTNKBA1B64q9aji6H
+W9gc5Sx1bl9mzBP/1WDzmKfcpODMkMsEZUWSqOAq7merIvz7ryIflM/X9TMmDgm5S2btXqEDa0YcR1q+
+/1s1m003gAuX4nHn/w8AkF48jM54AAA=
 `,
 	},
 
@@ -0,0 +1,124 @@
+// Package lint checks a normalized configuration against best practices.
+// Unlike validation, which rejects what can't work, its rules report what
+// works but is likely a mistake or a weakness.
+package lint
+
+import (
+	"sort"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
+)
+
+// Severity is how much a finding matters.
+type Severity int
+
+// The severities, from least to most severe.
+const (
+	Info Severity = iota
+	Warning
+	Error
+)
+
+var severityNames = []string{"info", "warning", "error"}
+
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+// ParseSeverity returns the severity of a name, such as "warning".
+func ParseSeverity(name string) (Severity, error) {
+	for i, n := range severityNames {
+		if n == name {
+			return Severity(i), nil
+		}
+	}
+	return 0, errors.Errorf("unknown severity %q (must be info, warning or error)", name)
+}
+
+// Problem is something a rule found in a domain. Record is nil for a
+// problem with the domain as a whole, such as a missing record.
+type Problem struct {
+	Record  *models.RecordConfig
+	Message string
+}
+
+// Rule is a best-practice check of a domain.
+type Rule struct {
+	ID string
+	// Severity is the default severity of the problems the rule finds.
+	Severity Severity
+	// Description says what the rule checks, for lint -list.
+	Description string
+	Check       func(dc *models.DomainConfig) []Problem
+}
+
+var rules = map[string]*Rule{}
+
+// Register adds a rule. It panics if there is already a rule with its ID.
+func Register(r *Rule) {
+	if rules[r.ID] != nil {
+		panic("lint rule " + r.ID + " registered twice")
+	}
+	rules[r.ID] = r
+}
+
+// Rules returns the registered rules, sorted by ID.
+func Rules() []*Rule {
+	var list []*Rule
+	for _, r := range rules {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Config turns rules off and changes their severities.
+type Config struct {
+	Disabled   map[string]bool
+	Severities map[string]Severity
+}
+
+// Validate returns an error if conf names a rule that doesn't exist.
+func (conf Config) Validate() error {
+	for id := range conf.Disabled {
+		if rules[id] == nil {
+			return errors.Errorf("unknown lint rule %q", id)
+		}
+	}
+	for id := range conf.Severities {
+		if rules[id] == nil {
+			return errors.Errorf("unknown lint rule %q", id)
+		}
+	}
+	return nil
+}
+
+// Finding is a problem found by a rule, in a domain.
+type Finding struct {
+	Rule     string
+	Severity Severity
+	Domain   string
+	Problem
+}
+
+// Run checks every domain of cfg with the rules that conf leaves on. The
+// findings are in the order of the domains, then of the rules.
+func Run(cfg *models.DNSConfig, conf Config) []Finding {
+	var findings []Finding
+	for _, dc := range cfg.Domains {
+		for _, r := range Rules() {
+			if conf.Disabled[r.ID] {
+				continue
+			}
+			severity, ok := conf.Severities[r.ID]
+			if !ok {
+				severity = r.Severity
+			}
+			for _, p := range r.Check(dc) {
+				findings = append(findings, Finding{r.ID, severity, dc.Name, p})
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,113 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+func rec(label, rType, target string, ttl uint32) *models.RecordConfig {
+	r := &models.RecordConfig{Type: rType, TTL: ttl, Metadata: map[string]string{}}
+	r.SetLabel(label, "example.com")
+	if rType == "TXT" {
+		r.SetTargetTXT(target)
+	} else {
+		r.SetTarget(target)
+	}
+	return r
+}
+
+// hits returns "rule label" for each finding, "rule -" for the domain.
+func hits(findings []Finding) []string {
+	var got []string
+	for _, f := range findings {
+		label := "-"
+		if f.Record != nil {
+			label = f.Record.GetLabel()
+		}
+		got = append(got, f.Rule+" "+label)
+	}
+	return got
+}
+
+func TestRules(t *testing.T) {
+	good := []*models.RecordConfig{
+		rec("@", "A", "192.0.2.1", 3600),
+		rec("@", "CAA", "letsencrypt.org", 3600),
+		rec("@", "MX", "mail.example.com.", 3600),
+		rec("@", "TXT", "v=spf1 mx -all", 3600),
+		rec("_dmarc", "TXT", "v=DMARC1; p=reject", 3600),
+		rec("mail", "A", "192.0.2.2", 300),
+		rec("*", "A", "192.0.2.3", 300),
+		rec("*.dev", "A", "192.0.2.3", 300),
+	}
+	for _, tst := range []struct {
+		name    string
+		records []*models.RecordConfig
+		want    []string
+	}{
+		{"good", good, nil},
+		{"missing CAA and DMARC", []*models.RecordConfig{good[0], good[2]}, []string{"caa-missing -", "dmarc-missing -"}},
+		{"no mail, no DMARC needed", []*models.RecordConfig{good[0], good[1]}, nil},
+		{"SPF over 10 lookups", append(good[:3:3], good[4],
+			rec("@", "TXT", "v=spf1 a mx include:a.example.net include:b.example.net include:c.example.net a:x mx:y include:d.example.net include:e.example.net a:z mx:w -all", 3600)),
+			[]string{"spf-lookups @"}},
+		{"MX to a CNAME", append(good[1:2:2], rec("@", "MX", "alias.example.com.", 3600), rec("alias", "CNAME", "mail.example.net.", 3600), good[4]),
+			[]string{"mx-cname @"}},
+		{"low apex TTL", append(good[1:2:2], rec("@", "A", "192.0.2.1", 60)), []string{"apex-ttl @"}},
+		{"wildcard misuse", append(good[1:2:2], rec("a.*", "A", "192.0.2.1", 300), rec("*x", "A", "192.0.2.1", 300), rec("*.sub", "NS", "ns.example.net.", 300)),
+			[]string{"wildcard a.*", "wildcard *x", "wildcard *.sub"}},
+	} {
+		cfg := &models.DNSConfig{Domains: []*models.DomainConfig{{Name: "example.com", Records: tst.records}}}
+		if got := hits(Run(cfg, Config{})); !reflect.DeepEqual(got, tst.want) {
+			t.Errorf("%s: expected %q, got %q", tst.name, tst.want, got)
+		}
+	}
+
+	// The validation checks the SPF records it flattens.
+	flattened := rec("@", "TXT", "v=spf1 a mx include:a.example.net include:b.example.net include:c.example.net a:x mx:y include:d.example.net include:e.example.net a:z mx:w -all", 3600)
+	flattened.Metadata["flatten"] = "*"
+	cfg := &models.DNSConfig{Domains: []*models.DomainConfig{{Name: "example.com", Records: append(good[:3:3], good[4], flattened)}}}
+	if got := Run(cfg, Config{}); len(got) != 0 {
+		t.Errorf("Expected nothing for a flattened SPF record, got %v", got)
+	}
+
+	// The rules that reuse a check of the validation honor IGNORE_WARNING().
+	mx := rec("@", "MX", "alias.example.com.", 3600)
+	mx.Metadata["ignore_warnings"] = "mx-cname"
+	cfg = &models.DNSConfig{Domains: []*models.DomainConfig{{Name: "example.com", Records: append(good[1:2:2], mx, rec("alias", "CNAME", "mail.example.net.", 3600), good[4])}}}
+	if got := Run(cfg, Config{}); len(got) != 0 {
+		t.Errorf("Expected nothing for an MX that ignores mx-cname, got %v", got)
+	}
+
+	// Reverse zones need no CAA.
+	cfg = &models.DNSConfig{Domains: []*models.DomainConfig{{Name: "2.0.192.in-addr.arpa"}}}
+	if got := Run(cfg, Config{}); len(got) != 0 {
+		t.Errorf("Expected nothing for a reverse zone, got %v", got)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	cfg := &models.DNSConfig{Domains: []*models.DomainConfig{{Name: "example.com", Records: []*models.RecordConfig{
+		rec("@", "MX", "mail.example.net.", 60),
+	}}}}
+	findings := Run(cfg, Config{
+		Disabled:   map[string]bool{"caa-missing": true},
+		Severities: map[string]Severity{"apex-ttl": Error},
+	})
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Severity.String()+" "+f.Rule)
+	}
+	if want := []string{"error apex-ttl", "warning dmarc-missing"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	if err := (Config{Disabled: map[string]bool{"no-such-rule": true}}).Validate(); err == nil {
+		t.Errorf("Expected an unknown rule to be an error")
+	}
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Errorf("Expected an unknown severity to be an error")
+	}
+}
@@ -0,0 +1,143 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/normalize"
+	"github.com/StackExchange/dnscontrol/pkg/spflib"
+)
+
+// The smallest TTL of the apex records that apex-ttl accepts.
+const minApexTTL = 300
+
+func init() {
+	Register(&Rule{
+		ID:          "caa-missing",
+		Severity:    Warning,
+		Description: "The domain has no CAA record at the apex, so any CA may issue certificates for it",
+		Check: func(dc *models.DomainConfig) []Problem {
+			if isReverse(dc) || hasRecord(dc, func(r *models.RecordConfig) bool { return r.Type == "CAA" && r.GetLabel() == "@" }) {
+				return nil
+			}
+			return []Problem{{nil, "no CAA record at the apex: any CA may issue certificates for " + dc.Name}}
+		},
+	})
+	Register(&Rule{
+		ID:          "dmarc-missing",
+		Severity:    Warning,
+		Description: "The domain has MX or SPF records, but no DMARC record at _dmarc",
+		Check: func(dc *models.DomainConfig) []Problem {
+			mail := hasRecord(dc, func(r *models.RecordConfig) bool {
+				return r.GetLabel() == "@" && (r.Type == "MX" || r.Type == "TXT" && isSPF(r))
+			})
+			dmarc := hasRecord(dc, func(r *models.RecordConfig) bool {
+				return r.Type == "TXT" && r.GetLabel() == "_dmarc" && strings.HasPrefix(txt(r), "v=DMARC1")
+			})
+			if !mail || dmarc {
+				return nil
+			}
+			return []Problem{{nil, "mail is sent or received for " + dc.Name + ", but there is no DMARC record at _dmarc"}}
+		},
+	})
+	Register(&Rule{
+		ID:          "mx-cname",
+		Severity:    Error,
+		Description: "An MX record points to a CNAME of the domain (RFC 2181 section 10.3)",
+		Check: func(dc *models.DomainConfig) []Problem {
+			return warnings(normalize.MXCNAMEs(dc)...)
+		},
+	})
+	Register(&Rule{
+		ID:          "spf-lookups",
+		Severity:    Error,
+		Description: fmt.Sprintf("An SPF record needs more than %d DNS lookups, without counting those of its includes", spflib.MaxLookups),
+		Check: func(dc *models.DomainConfig) []Problem {
+			var errs []error
+			for _, r := range dc.Records {
+				// The validation counts the lookups of the records it
+				// flattens or splits, includes and all.
+				if r.Type != "TXT" || !isSPF(r) || r.Metadata["flatten"] != "" || r.Metadata["split"] != "" {
+					continue
+				}
+				// Records spflib can't parse are left to -lint-email.
+				rec, err := spflib.Parse(txt(r), nil)
+				if err != nil {
+					continue
+				}
+				if err := normalize.SPFLookups(r, rec); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return warnings(errs...)
+		},
+	})
+	Register(&Rule{
+		ID:          "apex-ttl",
+		Severity:    Info,
+		Description: fmt.Sprintf("A record at the apex has a TTL below %d seconds", minApexTTL),
+		Check: func(dc *models.DomainConfig) (problems []Problem) {
+			for _, r := range dc.Records {
+				if r.GetLabel() == "@" && r.Type != "SOA" && r.TTL < minApexTTL {
+					problems = append(problems, Problem{r, fmt.Sprintf("%s %s has a TTL of %d, below %d: resolvers ask for it again that often", r.Type, r.GetLabelFQDN(), r.TTL, minApexTTL)})
+				}
+			}
+			return problems
+		},
+	})
+	Register(&Rule{
+		ID:          "wildcard",
+		Severity:    Warning,
+		Description: `A "*" that is not a whole leftmost label, which is no wildcard (RFC 4592), or an NS record at a wildcard`,
+		Check: func(dc *models.DomainConfig) (problems []Problem) {
+			for _, r := range dc.Records {
+				labels := strings.Split(r.GetLabel(), ".")
+				for i, l := range labels {
+					if strings.Contains(l, "*") && (i > 0 || l != "*") {
+						problems = append(problems, Problem{r, fmt.Sprintf("%s %s: only a leftmost label of just \"*\" is a wildcard, here the \"*\" is literal", r.Type, r.GetLabelFQDN())})
+						break
+					}
+				}
+				if labels[0] == "*" && r.Type == "NS" {
+					problems = append(problems, Problem{r, fmt.Sprintf("NS %s: a wildcard can't delegate subdomains", r.GetLabelFQDN())})
+				}
+			}
+			return problems
+		},
+	})
+}
+
+// warnings returns the problems of the validation warnings of the rules
+// that reuse a check of the validation, but those of records that have
+// IGNORE_WARNING() for them.
+func warnings(errs ...error) (problems []Problem) {
+	var res normalize.Result
+	res.Add(errs...)
+	for _, err := range res.Warnings {
+		problems = append(problems, Problem{err.(normalize.Warning).Record, err.Error()})
+	}
+	return problems
+}
+
+func isReverse(dc *models.DomainConfig) bool {
+	return strings.HasSuffix(dc.Name, ".arpa")
+}
+
+func hasRecord(dc *models.DomainConfig, match func(*models.RecordConfig) bool) bool {
+	for _, r := range dc.Records {
+		if match(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func txt(r *models.RecordConfig) string {
+	return strings.Join(r.TxtStrings, "")
+}
+
+func isSPF(r *models.RecordConfig) bool {
+	t := txt(r)
+	return t == "v=spf1" || strings.HasPrefix(t, "v=spf1 ")
+}
@@ -0,0 +1,144 @@
+// Package metrics writes metrics about a push in the Prometheus text format,
+// for node_exporter's textfile collector.
+// https://github.com/prometheus/node_exporter#textfile-collector
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Run collects the metrics of one run. A nil *Run is valid and records nothing,
+// so callers don't need to check whether metrics were requested.
+type Run struct {
+	start     time.Time
+	providers map[string]*counts
+	domains   map[string]*counts
+}
+
+type counts struct {
+	applied, failed int
+}
+
+// New starts collecting metrics for a run that starts now.
+func New() *Run {
+	return &Run{
+		start:     time.Now(),
+		providers: map[string]*counts{},
+		domains:   map[string]*counts{},
+	}
+}
+
+func (r *Run) get(domain, provider string) (*counts, *counts) {
+	p := r.providers[provider]
+	if p == nil {
+		p = &counts{}
+		if provider != "" {
+			r.providers[provider] = p
+		}
+	}
+	d := r.domains[domain]
+	if d == nil {
+		d = &counts{}
+		r.domains[domain] = d
+	}
+	return p, d
+}
+
+// Applied records a correction that was applied successfully.
+func (r *Run) Applied(domain, provider string) {
+	if r == nil {
+		return
+	}
+	p, d := r.get(domain, provider)
+	p.applied++
+	d.applied++
+}
+
+// Failed records that the changes of domain at provider failed or were not
+// made, or that provider could not be read. An empty provider is a failure of
+// the domain before any of its providers was tried.
+func (r *Run) Failed(domain, provider string) {
+	if r == nil {
+		return
+	}
+	p, d := r.get(domain, provider)
+	p.failed++
+	d.failed++
+}
+
+// Write writes the metrics of the run as of now.
+func (r *Run) Write(w io.Writer, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	failed := 0
+	for _, c := range r.domains {
+		failed += c.failed
+	}
+	success := 1
+	if failed > 0 {
+		success = 0
+	}
+
+	gauge(bw, "dnscontrol_last_run_timestamp_seconds", "Time the last run finished.")
+	fmt.Fprintf(bw, "dnscontrol_last_run_timestamp_seconds %d\n", now.Unix())
+	gauge(bw, "dnscontrol_last_run_duration_seconds", "Duration of the last run.")
+	fmt.Fprintf(bw, "dnscontrol_last_run_duration_seconds %.3f\n", now.Sub(r.start).Seconds())
+	gauge(bw, "dnscontrol_last_run_success", "1 if the last run had no failures.")
+	fmt.Fprintf(bw, "dnscontrol_last_run_success %d\n", success)
+
+	writeCounts(bw, "provider", r.providers)
+	writeCounts(bw, "domain", r.domains)
+	return bw.Flush()
+}
+
+func writeCounts(w io.Writer, label string, m map[string]*counts) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	metric := "dnscontrol_" + label + "_corrections_applied"
+	gauge(w, metric, "Corrections applied by the last run, by "+label+".")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s{%s=\"%s\"} %d\n", metric, label, escape(name), m[name].applied)
+	}
+	metric = "dnscontrol_" + label + "_failures"
+	gauge(w, metric, "Changes that failed or were not made in the last run, by "+label+".")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s{%s=\"%s\"} %d\n", metric, label, escape(name), m[name].failed)
+	}
+}
+
+func gauge(w io.Writer, name, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// WriteFile writes the metrics to filename. The file is replaced atomically
+// so that the textfile collector never reads a partial file.
+func (r *Run) WriteFile(filename string) error {
+	tmp, err := os.Create(filepath.Join(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp"))
+	if err != nil {
+		return err
+	}
+	err = r.Write(tmp, time.Now())
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
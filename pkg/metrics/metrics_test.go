@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	r := New()
+	r.start = time.Unix(1500000000, 0)
+	r.Applied("example.com", "cloudflare")
+	r.Applied("example.com", "cloudflare")
+	r.Applied("example.net", `r53"main`)
+	r.Failed("example.net", `r53"main`)
+
+	buf := &bytes.Buffer{}
+	if err := r.Write(buf, time.Unix(1500000002, 500000000)); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP dnscontrol_last_run_timestamp_seconds Time the last run finished.
+# TYPE dnscontrol_last_run_timestamp_seconds gauge
+dnscontrol_last_run_timestamp_seconds 1500000002
+# HELP dnscontrol_last_run_duration_seconds Duration of the last run.
+# TYPE dnscontrol_last_run_duration_seconds gauge
+dnscontrol_last_run_duration_seconds 2.500
+# HELP dnscontrol_last_run_success 1 if the last run had no failures.
+# TYPE dnscontrol_last_run_success gauge
+dnscontrol_last_run_success 0
+# HELP dnscontrol_provider_corrections_applied Corrections applied by the last run, by provider.
+# TYPE dnscontrol_provider_corrections_applied gauge
+dnscontrol_provider_corrections_applied{provider="cloudflare"} 2
+dnscontrol_provider_corrections_applied{provider="r53\"main"} 1
+# HELP dnscontrol_provider_failures Changes that failed or were not made in the last run, by provider.
+# TYPE dnscontrol_provider_failures gauge
+dnscontrol_provider_failures{provider="cloudflare"} 0
+dnscontrol_provider_failures{provider="r53\"main"} 1
+# HELP dnscontrol_domain_corrections_applied Corrections applied by the last run, by domain.
+# TYPE dnscontrol_domain_corrections_applied gauge
+dnscontrol_domain_corrections_applied{domain="example.com"} 2
+dnscontrol_domain_corrections_applied{domain="example.net"} 1
+# HELP dnscontrol_domain_failures Changes that failed or were not made in the last run, by domain.
+# TYPE dnscontrol_domain_failures gauge
+dnscontrol_domain_failures{domain="example.com"} 0
+dnscontrol_domain_failures{domain="example.net"} 1
+`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestNilRun(t *testing.T) {
+	var r *Run
+	// Must not panic.
+	r.Applied("example.com", "bind")
+	r.Failed("example.com", "bind")
+}
+
+func TestFailedDomain(t *testing.T) {
+	r := New()
+	r.Failed("example.com", "")
+
+	buf := &bytes.Buffer{}
+	if err := r.Write(buf, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("dnscontrol_last_run_success 0\n")) {
+		t.Errorf("expected the run to fail, got:\n%s", buf)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`dnscontrol_domain_failures{domain="example.com"} 1`)) {
+		t.Errorf("expected the failure of example.com, got:\n%s", buf)
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`provider=""`)) {
+		t.Errorf("expected no empty provider, got:\n%s", buf)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "dnscontrol.prom")
+	r := New()
+	r.Applied("example.com", "bind")
+	if err := r.WriteFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 || files[0].Name() != "dnscontrol.prom" {
+		t.Errorf("expected only dnscontrol.prom in %s, got %v", dir, files)
+	}
+	b, _ := ioutil.ReadFile(filename)
+	if !bytes.Contains(b, []byte(`dnscontrol_last_run_success 1`)) {
+		t.Errorf("unexpected metrics file:\n%s", b)
+	}
+}
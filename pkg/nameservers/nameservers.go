@@ -64,3 +64,32 @@ func AddNSRecords(dc *models.DomainConfig) {
 		dc.Records = append(dc.Records, rc)
 	}
 }
+
+// Drift compares the nameservers of a domain with the apex NS records a
+// provider currently serves. It returns the nameservers that are missing
+// from the records and the records that are not nameservers of the domain.
+// Names are compared without case or trailing dot.
+func Drift(nss []*models.Nameserver, existing []*models.RecordConfig) (missing, extra []string) {
+	norm := func(s string) string { return strings.ToLower(strings.TrimSuffix(s, ".")) }
+	want := map[string]bool{}
+	for _, ns := range nss {
+		want[norm(ns.Name)] = true
+	}
+	served := map[string]bool{}
+	for _, r := range existing {
+		if r.Type == "NS" && r.GetLabel() == "@" {
+			name := norm(r.GetTargetField())
+			if !want[name] && !served[name] {
+				extra = append(extra, name)
+			}
+			served[name] = true
+		}
+	}
+	for _, ns := range nss {
+		if name := norm(ns.Name); !served[name] {
+			missing = append(missing, name)
+			served[name] = true
+		}
+	}
+	return missing, extra
+}
@@ -0,0 +1,49 @@
+package nameservers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+func apexNS(targets ...string) []*models.RecordConfig {
+	var records []*models.RecordConfig
+	for _, t := range targets {
+		rc := &models.RecordConfig{Type: "NS"}
+		rc.SetLabel("@", "example.com")
+		rc.SetTarget(t)
+		records = append(records, rc)
+	}
+	return records
+}
+
+func TestDrift(t *testing.T) {
+	www := &models.RecordConfig{Type: "A"}
+	www.SetLabel("www", "example.com")
+	www.SetTarget("1.2.3.4")
+	sub := &models.RecordConfig{Type: "NS"}
+	sub.SetLabel("sub", "example.com")
+	sub.SetTarget("ns9.example.net.")
+
+	for _, tst := range []struct {
+		desc           string
+		nss            []string
+		existing       []*models.RecordConfig
+		missing, extra []string
+	}{
+		{"same set", []string{"ns-1.awsdns-01.com", "ns-2.awsdns-02.net"},
+			apexNS("NS-2.awsdns-02.net.", "ns-1.awsdns-01.com."), nil, nil},
+		{"delegation set reassigned", []string{"ns-3.awsdns-03.org", "ns-2.awsdns-02.net"},
+			apexNS("ns-1.awsdns-01.com.", "ns-2.awsdns-02.net."), []string{"ns-3.awsdns-03.org"}, []string{"ns-1.awsdns-01.com"}},
+		{"other records don't count", []string{"ns1.example.net"},
+			append(apexNS("ns1.example.net."), www, sub), nil, nil},
+		{"no apex NS served", []string{"ns1.example.net"},
+			nil, []string{"ns1.example.net"}, nil},
+	} {
+		missing, extra := Drift(models.StringsToNameservers(tst.nss), tst.existing)
+		if !reflect.DeepEqual(missing, tst.missing) || !reflect.DeepEqual(extra, tst.extra) {
+			t.Errorf("%s: got missing %v, extra %v, expected %v, %v", tst.desc, missing, extra, tst.missing, tst.extra)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package normalize
+
+import (
+	"net"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/miekg/dns/dnsutil"
+	"github.com/pkg/errors"
+)
+
+// lookupHost is replaced in tests.
+var lookupHost = net.LookupHost
+
+// CheckCNAMETargets warns about CNAME records whose target doesn't exist.
+// Targets in a domain of cfg must have a record there, or be covered by a
+// wildcard. Other targets are looked up in the DNS, and only "no such host"
+// counts. Domains with NO_PURGE, IGNOREd labels or delegated subdomains may
+// have records that cfg doesn't know about, and such targets are skipped.
+// It must run after NormalizeAndValidateConfig.
+func CheckCNAMETargets(cfg *models.DNSConfig) (errs []error) {
+	for _, domain := range cfg.Domains {
+		for _, rec := range domain.Records {
+			if rec.Type != "CNAME" {
+				continue
+			}
+			target := strings.ToLower(strings.TrimSuffix(rec.GetTargetField(), "."))
+			if cnameTargetExists(cfg, target) {
+				continue
+			}
+			errs = append(errs, Warning{errors.Errorf("CNAME %s points to %s, which does not exist", rec.GetLabelFQDN(), target), "cname-target", rec})
+		}
+	}
+	return errs
+}
+
+// cnameTargetExists returns false if there is certainly nothing at name.
+func cnameTargetExists(cfg *models.DNSConfig, name string) bool {
+	var zone *models.DomainConfig
+	for _, d := range cfg.Domains {
+		if (name == d.Name || strings.HasSuffix(name, "."+d.Name)) && (zone == nil || len(d.Name) > len(zone.Name)) {
+			zone = d
+		}
+	}
+	if zone == nil {
+		_, err := lookupHost(name)
+		if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.Err == "no such host" {
+			return false
+		}
+		return true
+	}
+	if zone.KeepUnknown {
+		return true
+	}
+	if zone.IgnoresLabel(dnsutil.TrimDomainName(name, zone.Name)) {
+		return true
+	}
+	// A wildcard covers names one or more labels below it.
+	wildcards := map[string]bool{}
+	for n := name; strings.Contains(n, "."); {
+		n = n[strings.Index(n, ".")+1:]
+		if len(n) < len(zone.Name) {
+			break
+		}
+		wildcards["*."+n] = true
+	}
+	for _, r := range zone.Records {
+		fqdn := r.GetLabelFQDN()
+		if fqdn == name || wildcards[fqdn] {
+			return true
+		}
+		// Below a delegation, the records are in another zone.
+		if r.Type == "NS" && fqdn != zone.Name && strings.HasSuffix(name, "."+fqdn) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package normalize
+
+import (
+	"net"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+func TestCheckCNAMETargets(t *testing.T) {
+	defer func() { lookupHost = net.LookupHost }()
+	lookupHost = func(name string) ([]string, error) {
+		switch name {
+		case "www.example.org":
+			return []string{"192.0.2.1"}, nil
+		case "broken.example.org":
+			return nil, &net.DNSError{Err: "i/o timeout", Name: name}
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: name}
+	}
+	rec := func(label, rType, target string) *models.RecordConfig {
+		return makeRC(label, "example.com", target, models.RecordConfig{Type: rType})
+	}
+	for _, tst := range []struct {
+		target   string
+		warnings int
+	}{
+		{"www.example.com.", 0},
+		{"example.com.", 0},
+		// A CNAME target is fine too.
+		{"alias.example.com.", 0},
+		{"missing.example.com.", 1},
+		// Covered by *.wild.example.com.
+		{"a.wild.example.com.", 0},
+		{"a.b.wild.example.com.", 0},
+		// But the wildcard itself doesn't cover wild.example.com.
+		{"wild.example.com.", 1},
+		// Delegated, so in another zone.
+		{"a.sub.example.com.", 0},
+		{"ignored.example.com.", 0},
+		// The longest matching domain is used.
+		{"www.other.example.com.", 1},
+		{"host.other.example.com.", 0},
+		// Looked up in the DNS.
+		{"www.example.org.", 0},
+		{"missing.example.org.", 1},
+		{"broken.example.org.", 0},
+	} {
+		cfg := &models.DNSConfig{Domains: []*models.DomainConfig{
+			{Name: "example.com", IgnoredLabels: []string{"ignored"}, Records: []*models.RecordConfig{
+				rec("@", "A", "192.0.2.1"),
+				rec("www", "A", "192.0.2.1"),
+				rec("*.wild", "A", "192.0.2.1"),
+				rec("sub", "NS", "ns.example.net."),
+				rec("alias", "CNAME", "www.example.com."),
+				rec("test", "CNAME", tst.target),
+			}},
+			{Name: "other.example.com", Records: []*models.RecordConfig{
+				makeRC("host", "other.example.com", "192.0.2.1", models.RecordConfig{Type: "A"}),
+			}},
+		}}
+		errs := CheckCNAMETargets(cfg)
+		if len(errs) != tst.warnings {
+			t.Errorf("%s: expected %d warnings, got %v", tst.target, tst.warnings, errs)
+		}
+		for _, err := range errs {
+			if w, ok := err.(Warning); !ok || w.ID != "cname-target" || w.Record.GetLabel() != "test" {
+				t.Errorf("%s: expected a cname-target warning about test, got %v", tst.target, err)
+			}
+		}
+	}
+}
+
+func TestCheckCNAMETargetsNoPurge(t *testing.T) {
+	cfg := &models.DNSConfig{Domains: []*models.DomainConfig{
+		{Name: "example.com", KeepUnknown: true, Records: []*models.RecordConfig{
+			makeRC("test", "example.com", "missing.example.com.", models.RecordConfig{Type: "CNAME"}),
+		}},
+	}}
+	if errs := CheckCNAMETargets(cfg); len(errs) != 0 {
+		t.Errorf("expected no warnings with NO_PURGE, got %v", errs)
+	}
+}
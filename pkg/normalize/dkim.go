@@ -0,0 +1,129 @@
+package normalize
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
+)
+
+// minDKIMKeyBits is the shortest RSA key verifiers accept (RFC 8301
+// section 3.2).
+const minDKIMKeyBits = 1024
+
+// checkDKIM checks the public keys of the DKIM records of a domain: TXT
+// records starting with "v=DKIM1" at SELECTOR._domainkey. The problems are
+// warnings, as mail servers only disregard a bad key.
+func checkDKIM(dc *models.DomainConfig) []error {
+	_, _, errs := dkimSelectors(dc)
+	return errs
+}
+
+// CheckDKIMRotation warns when a signing domain has only one selector with
+// a key, because then a key can't be rotated without mail failing DKIM while
+// the new one propagates.
+// It must run after NormalizeAndValidateConfig.
+func CheckDKIMRotation(cfg *models.DNSConfig) (errs []error) {
+	for _, dc := range cfg.Domains {
+		selectors, signers, _ := dkimSelectors(dc)
+		for _, signer := range signers {
+			if recs := selectors[signer]; len(recs) == 1 {
+				errs = append(errs, Warning{errors.Errorf("DKIM record %s is the only selector of %s. Publish the next key under a second selector before switching to it (see DKIM_ROTATION)",
+					recs[0].GetLabelFQDN(), signer), "dkim-rotation", recs[0]})
+			}
+		}
+	}
+	return errs
+}
+
+// dkimSelectors returns the DKIM records of dc with a valid key, by signing
+// domain, and the signing domains in the order of the records. errs has the
+// problems of the other keys.
+func dkimSelectors(dc *models.DomainConfig) (selectors map[string][]*models.RecordConfig, signers []string, errs []error) {
+	selectors = map[string][]*models.RecordConfig{}
+	for _, rec := range dc.Records {
+		label := rec.GetLabel()
+		i := strings.Index(label, "._domainkey")
+		if rec.Type != "TXT" || i <= 0 || (label[i+11:] != "" && label[i+11] != '.') {
+			continue
+		}
+		txt := strings.Join(rec.TxtStrings, "")
+		if !strings.HasPrefix(txt, "v=DKIM1") {
+			continue
+		}
+		revoked, err := checkDKIMKey(txt)
+		if err != nil {
+			id := "dkim-key"
+			if w, ok := err.(Warning); ok {
+				id = w.ID
+			}
+			errs = append(errs, Warning{errors.Errorf("DKIM record %s: %s", rec.GetLabelFQDN(), err), id, rec})
+			continue
+		}
+		if revoked {
+			continue
+		}
+		signer := strings.TrimPrefix(rec.GetLabelFQDN()[i:], "._domainkey.")
+		if selectors[signer] == nil {
+			signers = append(signers, signer)
+		}
+		selectors[signer] = append(selectors[signer], rec)
+	}
+	return selectors, signers, errs
+}
+
+// checkDKIMKey checks the k= and p= tags of a DKIM record (RFC 6376 section
+// 3.6.1). An empty p= revokes the key. A key that is too short is a
+// dkim-key-size Warning, as it may be on purpose.
+func checkDKIMKey(txt string) (revoked bool, err error) {
+	tags := map[string]string{}
+	for _, tag := range strings.Split(txt, ";") {
+		if eq := strings.Index(tag, "="); eq != -1 {
+			tags[strings.TrimSpace(tag[:eq])] = strings.TrimSpace(tag[eq+1:])
+		}
+	}
+	p, ok := tags["p"]
+	if !ok {
+		return false, errors.Errorf("there is no p= tag")
+	}
+	// The value may be folded with whitespace.
+	p = strings.Join(strings.Fields(p), "")
+	if p == "" {
+		return true, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(p)
+	if err != nil {
+		return false, errors.Errorf("p= is not base64")
+	}
+	switch k := tags["k"]; k {
+	case "", "rsa":
+		// Usually a SubjectPublicKeyInfo, but RFC 6376 section 3.6.1 says
+		// an RSAPublicKey (PKCS#1), and some publish that.
+		rsaKey := &rsa.PublicKey{}
+		if rest, err := asn1.Unmarshal(key, rsaKey); err != nil || len(rest) != 0 || rsaKey.N.Sign() <= 0 || rsaKey.E <= 0 {
+			pub, err := x509.ParsePKIXPublicKey(key)
+			if err != nil {
+				return false, errors.Errorf("p= is not an RSA public key")
+			}
+			var ok bool
+			if rsaKey, ok = pub.(*rsa.PublicKey); !ok {
+				return false, errors.Errorf("p= is not an RSA public key")
+			}
+		}
+		if bits := rsaKey.N.BitLen(); bits < minDKIMKeyBits {
+			return false, Warning{errors.Errorf("the key is %d bits, verifiers need at least %d", bits, minDKIMKeyBits), "dkim-key-size", nil}
+		}
+	case "ed25519":
+		// The raw key, not a SubjectPublicKeyInfo (RFC 8463 section 4.2).
+		if len(key) != 32 {
+			return false, errors.Errorf("an ed25519 key is 32 bytes, p= is %d", len(key))
+		}
+	default:
+		return false, errors.Errorf("k=%s is not a key type (must be rsa or ed25519)", k)
+	}
+	return false, nil
+}
@@ -0,0 +1,157 @@
+package normalize
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+func rsaDKIMKey(t *testing.T, pub *rsa.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+func TestCheckDKIMKey(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey := rsaDKIMKey(t, &priv.PublicKey)
+	pkcs1, err := asn1.Marshal(priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs1Key := base64.StdEncoding.EncodeToString(pkcs1)
+	// Too short to be generated, but it can be published.
+	short := rsaDKIMKey(t, &rsa.PublicKey{N: new(big.Int).Lsh(big.NewInt(1), 511), E: 65537})
+	edPub := make([]byte, 32)
+	if _, err := rand.Read(edPub); err != nil {
+		t.Fatal(err)
+	}
+	edKey := base64.StdEncoding.EncodeToString(edPub)
+
+	for _, tst := range []struct {
+		txt     string
+		revoked bool
+		err     string
+	}{
+		{"v=DKIM1; k=rsa; p=" + rsaKey, false, ""},
+		{"v=DKIM1; p=" + rsaKey, false, ""},
+		{"v=DKIM1; p=" + rsaKey[:100] + " " + rsaKey[100:], false, ""},
+		{"v=DKIM1; k=rsa; p=" + pkcs1Key, false, ""},
+		{"v=DKIM1; k=ed25519; p=" + edKey, false, ""},
+		{"v=DKIM1; p=", true, ""},
+		{"v=DKIM1; k=rsa", false, "there is no p= tag"},
+		{"v=DKIM1; p=not!base64", false, "p= is not base64"},
+		{"v=DKIM1; p=" + edKey, false, "p= is not an RSA public key"},
+		{"v=DKIM1; p=" + short, false, "the key is 512 bits, verifiers need at least 1024"},
+		{"v=DKIM1; k=ed25519; p=" + rsaKey, false, "an ed25519 key is 32 bytes, p= is 162"},
+		{"v=DKIM1; k=dsa; p=" + rsaKey, false, "k=dsa is not a key type (must be rsa or ed25519)"},
+	} {
+		revoked, err := checkDKIMKey(tst.txt)
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		if revoked != tst.revoked || msg != tst.err {
+			t.Errorf("%.40s: expected %v %q, got %v %q", tst.txt, tst.revoked, tst.err, revoked, msg)
+		}
+	}
+}
+
+func TestCheckDKIM(t *testing.T) {
+	edPub := make([]byte, 32)
+	if _, err := rand.Read(edPub); err != nil {
+		t.Fatal(err)
+	}
+	key := "v=DKIM1; k=ed25519; p=" + base64.StdEncoding.EncodeToString(edPub)
+	short := "v=DKIM1; p=" + rsaDKIMKey(t, &rsa.PublicKey{N: new(big.Int).Lsh(big.NewInt(1), 511), E: 65537})
+	txt := func(label, target string) *models.RecordConfig {
+		return makeRC(label, "example.com", target, models.RecordConfig{Type: "TXT", TxtStrings: []string{target}, Metadata: map[string]string{}})
+	}
+	type warning struct{ id, msg string }
+	for _, tst := range []struct {
+		desc     string
+		records  []*models.RecordConfig
+		keys     []warning
+		rotation []warning
+	}{
+		{"two selectors", []*models.RecordConfig{
+			txt("2026a._domainkey", key),
+			txt("2026b._domainkey", key),
+		}, nil, nil},
+		{"one selector", []*models.RecordConfig{
+			txt("2026a._domainkey", key),
+		}, nil, []warning{{"dkim-rotation", "DKIM record 2026a._domainkey.example.com is the only selector of example.com"}}},
+		{"a revoked key doesn't count", []*models.RecordConfig{
+			txt("2026a._domainkey", key),
+			txt("2025b._domainkey", "v=DKIM1; p="),
+		}, nil, []warning{{"dkim-rotation", "DKIM record 2026a._domainkey.example.com is the only selector of example.com"}}},
+		{"subdomains sign on their own", []*models.RecordConfig{
+			txt("2026a._domainkey", key),
+			txt("2026b._domainkey", key),
+			txt("s1._domainkey.mail", key),
+		}, nil, []warning{{"dkim-rotation", "DKIM record s1._domainkey.mail.example.com is the only selector of mail.example.com"}}},
+		// A bad key doesn't count either.
+		{"bad key", []*models.RecordConfig{
+			txt("2026a._domainkey", key),
+			txt("2026b._domainkey", "v=DKIM1; p=AAAA"),
+			txt("2026c._domainkey", short),
+		}, []warning{
+			{"dkim-key", "DKIM record 2026b._domainkey.example.com: p= is not an RSA public key"},
+			{"dkim-key-size", "DKIM record 2026c._domainkey.example.com: the key is 512 bits"},
+		}, []warning{{"dkim-rotation", "DKIM record 2026a._domainkey.example.com is the only selector of example.com"}}},
+		{"not DKIM", []*models.RecordConfig{
+			txt("2026a._domainkey", "k=rsa; p=AAAA"),
+			txt("www", "v=DKIM1; p=AAAA"),
+			txt("x._domainkeys", "v=DKIM1; p=AAAA"),
+		}, nil, nil},
+	} {
+		dc := &models.DomainConfig{Name: "example.com", Records: tst.records}
+		for _, check := range []struct {
+			name string
+			errs []error
+			want []warning
+		}{
+			{"keys", checkDKIM(dc), tst.keys},
+			{"rotation", CheckDKIMRotation(&models.DNSConfig{Domains: []*models.DomainConfig{dc}}), tst.rotation},
+		} {
+			if len(check.errs) != len(check.want) {
+				t.Errorf("%s: expected %d %s warnings, got %v", tst.desc, len(check.want), check.name, check.errs)
+				continue
+			}
+			for i, err := range check.errs {
+				if w, ok := err.(Warning); !ok || w.ID != check.want[i].id || !strings.HasPrefix(err.Error(), check.want[i].msg) {
+					t.Errorf("%s: expected the %s warning %q, got %#v", tst.desc, check.want[i].id, check.want[i].msg, err)
+				}
+			}
+		}
+	}
+
+	// The rotation is only checked on demand, and the warnings can be ignored.
+	rec := txt("2026a._domainkey", key)
+	bad := txt("2026b._domainkey", "v=DKIM1; p=AAAA")
+	dc := &models.DomainConfig{Name: "example.com", RegistrarName: "BIND", Records: []*models.RecordConfig{rec, bad}}
+	cfg := &models.DNSConfig{Domains: []*models.DomainConfig{dc}}
+	res := NormalizeAndValidateConfig(cfg)
+	if len(res.Errors) != 0 || len(res.Warnings) != 1 {
+		t.Errorf("Expected only the warning about the bad key, got %v %v", res.Errors, res.Warnings)
+	}
+	rec.Metadata["ignore_warnings"] = "dkim-rotation"
+	bad.Metadata["ignore_warnings"] = "dkim-key"
+	res = NormalizeAndValidateConfig(cfg)
+	res.Add(CheckDKIMRotation(cfg)...)
+	if len(res.Errors) != 0 || len(res.Warnings) != 0 {
+		t.Errorf("Expected IGNORE_WARNING() to drop the warnings, got %v %v", res.Errors, res.Warnings)
+	}
+}
@@ -0,0 +1,105 @@
+package normalize
+
+import (
+	"net"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
+)
+
+// lookupTXT is replaced in tests.
+var lookupTXT = net.LookupTXT
+
+// CheckDMARCReports warns about DMARC records that send reports (rua, ruf)
+// to a domain outside of their own, if that domain does not authorize it
+// with a "v=DMARC1" TXT record at DOMAIN._report._dmarc.REPORTDOMAIN
+// (RFC 7489 section 7.1). The record is looked for in the configuration if
+// the report domain is in it, and in the DNS otherwise.
+// It must run after NormalizeAndValidateConfig.
+func CheckDMARCReports(cfg *models.DNSConfig) (errs []error) {
+	for _, domain := range cfg.Domains {
+		for _, rec := range domain.Records {
+			label := rec.GetLabel()
+			if rec.Type != "TXT" || !(label == "_dmarc" || strings.HasPrefix(label, "_dmarc.")) || !strings.HasPrefix(rec.GetTargetField(), "v=DMARC1") {
+				continue
+			}
+			// The domain the DMARC policy is for.
+			policyDomain := strings.TrimPrefix(rec.GetLabelFQDN(), "_dmarc.")
+			for _, reportDomain := range dmarcReportDomains(strings.Join(rec.TxtStrings, "")) {
+				if reportDomain == policyDomain || strings.HasSuffix(reportDomain, "."+policyDomain) {
+					continue
+				}
+				auth := policyDomain + "._report._dmarc." + reportDomain
+				if !dmarcAuthorized(cfg, auth) {
+					errs = append(errs, Warning{errors.Errorf("DMARC record %s sends reports to %s, but there is no v=DMARC1 TXT record at %s to allow it",
+						rec.GetLabelFQDN(), reportDomain, auth), "dmarc-report", rec})
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// dmarcReportDomains returns the domains of the mailto: URIs in the rua and
+// ruf tags of a DMARC record.
+func dmarcReportDomains(txt string) []string {
+	var domains []string
+	seen := map[string]bool{}
+	for _, tag := range strings.Split(txt, ";") {
+		kv := strings.SplitN(strings.TrimSpace(tag), "=", 2)
+		if len(kv) != 2 || (kv[0] != "rua" && kv[0] != "ruf") {
+			continue
+		}
+		for _, uri := range strings.Split(kv[1], ",") {
+			uri = strings.TrimSpace(uri)
+			if !strings.HasPrefix(uri, "mailto:") {
+				continue
+			}
+			at := strings.LastIndex(uri, "@")
+			if at == -1 {
+				continue
+			}
+			d := strings.ToLower(strings.TrimSuffix(strings.SplitN(uri[at+1:], "!", 2)[0], "."))
+			if !seen[d] {
+				seen[d] = true
+				domains = append(domains, d)
+			}
+		}
+	}
+	return domains
+}
+
+// dmarcAuthorized returns false if there is certainly no authorization
+// record at name. DNS errors other than "no such host" don't count.
+func dmarcAuthorized(cfg *models.DNSConfig, name string) bool {
+	var zone *models.DomainConfig
+	for _, d := range cfg.Domains {
+		if (name == d.Name || strings.HasSuffix(name, "."+d.Name)) && (zone == nil || len(d.Name) > len(zone.Name)) {
+			zone = d
+		}
+	}
+	var txts []string
+	if zone != nil {
+		for _, rec := range zone.Records {
+			if rec.Type == "TXT" && rec.GetLabelFQDN() == name {
+				txts = append(txts, strings.Join(rec.TxtStrings, ""))
+			}
+		}
+	} else {
+		var err error
+		txts, err = lookupTXT(name)
+		if err != nil {
+			if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.Err == "no such host" {
+				return false
+			}
+			return true
+		}
+	}
+	for _, txt := range txts {
+		if strings.HasPrefix(txt, "v=DMARC1") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package normalize
+
+import (
+	"net"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+func TestDMARCReportDomains(t *testing.T) {
+	got := dmarcReportDomains("v=DMARC1; p=none; rua=mailto:a@example.com,mailto:b@Reports.Example.NET!10m; ruf=mailto:c@example.com, https://example.org/r")
+	if len(got) != 2 || got[0] != "example.com" || got[1] != "reports.example.net" {
+		t.Errorf("unexpected report domains %v", got)
+	}
+}
+
+func TestCheckDMARCReports(t *testing.T) {
+	defer func() { lookupTXT = net.LookupTXT }()
+	lookupTXT = func(name string) ([]string, error) {
+		switch name {
+		case "example.com._report._dmarc.authorized.example":
+			return []string{"v=DMARC1"}, nil
+		case "example.com._report._dmarc.broken.example":
+			return nil, &net.DNSError{Err: "i/o timeout", Name: name}
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: name}
+	}
+	txt := func(label, domain, target string) *models.RecordConfig {
+		return makeRC(label, domain, target, models.RecordConfig{Type: "TXT", TxtStrings: []string{target}})
+	}
+	for _, tst := range []struct {
+		rua      string
+		warnings int
+	}{
+		// Reports to the domain itself, or a subdomain of it, need no authorization.
+		{"mailto:dmarc@example.com", 0},
+		{"mailto:dmarc@reports.example.com", 0},
+		// Authorized in the configuration.
+		{"mailto:dmarc@example.net", 0},
+		// Authorized in the DNS.
+		{"mailto:dmarc@authorized.example", 0},
+		// Not authorized.
+		{"mailto:dmarc@example.org", 1},
+		{"mailto:dmarc@unauthorized.example", 1},
+		{"mailto:dmarc@unauthorized.example,mailto:dmarc@example.org!10m", 2},
+		// The DNS can't tell.
+		{"mailto:dmarc@broken.example", 0},
+	} {
+		cfg := &models.DNSConfig{Domains: []*models.DomainConfig{
+			{Name: "example.com", Records: []*models.RecordConfig{
+				txt("_dmarc", "example.com", "v=DMARC1; p=reject; rua="+tst.rua),
+			}},
+			{Name: "example.net", Records: []*models.RecordConfig{
+				txt("example.com._report._dmarc", "example.net", "v=DMARC1"),
+			}},
+			{Name: "example.org"},
+		}}
+		errs := CheckDMARCReports(cfg)
+		if len(errs) != tst.warnings {
+			t.Errorf("rua=%s: expected %d warnings, got %v", tst.rua, tst.warnings, errs)
+		}
+		for _, err := range errs {
+			if _, ok := err.(Warning); !ok {
+				t.Errorf("rua=%s: expected a warning, got error %s", tst.rua, err)
+			}
+		}
+	}
+}
+
+func TestDMARCReportsOptIn(t *testing.T) {
+	defer func() { lookupTXT = net.LookupTXT }()
+	lookupTXT = func(name string) ([]string, error) {
+		t.Errorf("Expected no lookup without -check-dmarc-reports, looked up %s", name)
+		return nil, nil
+	}
+	rec := makeRC("_dmarc", "example.com", "v=DMARC1; p=reject; rua=mailto:dmarc@example.org",
+		models.RecordConfig{Type: "TXT", TxtStrings: []string{"v=DMARC1; p=reject; rua=mailto:dmarc@example.org"}})
+	dc := &models.DomainConfig{Name: "example.com", RegistrarName: "BIND", Records: []*models.RecordConfig{rec}}
+	if res := NormalizeAndValidateConfig(&models.DNSConfig{Domains: []*models.DomainConfig{dc}}); len(res.Errors) != 0 || len(res.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v %v", res.Errors, res.Warnings)
+	}
+}
@@ -33,6 +33,9 @@ func flattenSPFs(cfg *models.DNSConfig) []error {
 				}
 			}
 			if flatten, ok := txt.Metadata["flatten"]; ok && strings.HasPrefix(txt.GetTargetField(), "v=spf1") {
+				if flat := rec.FlattenedIncludes(flatten); len(flat) > 0 {
+					txt.Metadata["spf_flattened"] = strings.Join(flat, ",")
+				}
 				rec = rec.Flatten(flatten)
 				err = txt.SetTargetTXT(rec.TXT())
 				if err != nil {
@@ -40,10 +43,18 @@ func flattenSPFs(cfg *models.DNSConfig) []error {
 					continue
 				}
 			}
+			if rec != nil {
+				if err := SPFLookups(txt, rec); err != nil {
+					errs = append(errs, err)
+				}
+				if _, ok := txt.Metadata["split"]; !ok && len(rec.TXT()) > spflib.MaxLen {
+					errs = append(errs, Warning{errors.Errorf("SPF record %s is %d bytes, longer than the %d byte TXT string limit. Set overflow to split it", txt.GetLabelFQDN(), len(rec.TXT()), spflib.MaxLen), "spf-length", txt})
+				}
+			}
 			// now split if needed
 			if split, ok := txt.Metadata["split"]; ok {
 				if !strings.Contains(split, "%d") {
-					errs = append(errs, Warning{errors.Errorf("Split format `%s` in `%s` is not proper format (should have %%d in it)", split, txt.GetLabelFQDN())})
+					errs = append(errs, Warning{errors.Errorf("Split format `%s` in `%s` is not proper format (should have %%d in it)", split, txt.GetLabelFQDN()), "spf-split", nil})
 					continue
 				}
 				recs := rec.TXTSplit(split + "." + domain.Name)
@@ -52,6 +63,7 @@ func flattenSPFs(cfg *models.DNSConfig) []error {
 						txt.SetTargetTXT(v)
 					} else {
 						cp, _ := txt.Copy()
+						delete(cp.Metadata, "spf_flattened")
 						cp.SetTargetTXT(v)
 						cp.SetLabelFromFQDN(k, domain.Name)
 						domain.Records = append(domain.Records, cp)
@@ -65,7 +77,7 @@ func flattenSPFs(cfg *models.DNSConfig) []error {
 	}
 	// check if cache is stale
 	for _, e := range cache.ResolveErrors() {
-		errs = append(errs, Warning{errors.Errorf("problem resolving SPF record: %s", e)})
+		errs = append(errs, Warning{errors.Errorf("problem resolving SPF record: %s", e), "spf-resolve", nil})
 	}
 	if len(cache.ResolveErrors()) == 0 {
 		changed := cache.ChangedRecords()
@@ -73,9 +85,19 @@ func flattenSPFs(cfg *models.DNSConfig) []error {
 			if err := cache.Save("spfcache.updated.json"); err != nil {
 				errs = append(errs, err)
 			} else {
-				errs = append(errs, Warning{errors.Errorf("%d spf record lookups are out of date with cache (%s).\nWrote changes to spfcache.updated.json. Please rename and commit:\n    $ mv spfcache.updated.json spfcache.json\n    $ git commit spfcache.json", len(changed), strings.Join(changed, ","))})
+				errs = append(errs, Warning{errors.Errorf("%d spf record lookups are out of date with cache (%s).\nWrote changes to spfcache.updated.json. Please rename and commit:\n    $ mv spfcache.updated.json spfcache.json\n    $ git commit spfcache.json", len(changed), strings.Join(changed, ",")), "spf-cache", nil})
 			}
 		}
 	}
 	return errs
 }
+
+// SPFLookups returns the spf-lookups warning of txt if rec, its parsed SPF
+// record, needs more DNS lookups than allowed. The lint rule of the same
+// name uses it too.
+func SPFLookups(txt *models.RecordConfig, rec *spflib.SPFRecord) error {
+	if n := rec.Lookups(); n > spflib.MaxLookups {
+		return Warning{errors.Errorf("SPF record %s requires %d lookups (limit is %d). Consider flattening more includes", txt.GetLabelFQDN(), n, spflib.MaxLookups), "spf-lookups", txt}
+	}
+	return nil
+}
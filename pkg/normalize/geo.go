@@ -0,0 +1,110 @@
+package normalize
+
+import (
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
+)
+
+// geoContinents are the continent codes of GEO("continent:...").
+var geoContinents = codeSet("AF AN AS EU NA OC SA")
+
+// geoCountries are the ISO 3166-1 alpha-2 country codes.
+var geoCountries = codeSet(`
+	AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ
+	BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ
+	CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ
+	DE DJ DK DM DO DZ
+	EC EE EG EH ER ES ET
+	FI FJ FK FM FO FR
+	GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY
+	HK HM HN HR HT HU
+	ID IE IL IM IN IO IQ IR IS IT
+	JE JM JO JP
+	KE KG KH KI KM KN KP KR KW KY KZ
+	LA LB LC LI LK LR LS LT LU LV LY
+	MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ
+	NA NC NE NF NG NI NL NO NP NR NU NZ
+	OM
+	PA PE PF PG PH PK PL PM PN PR PS PT PW PY
+	QA
+	RE RO RS RU RW
+	SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ
+	TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ
+	UA UG UM US UY UZ
+	VA VC VE VG VI VN VU
+	WF WS
+	YE YT
+	ZA ZM ZW`)
+
+// geoSubdivisions are the subdivisions that GEO("country:XX-YY") accepts,
+// by country: the states of the US and DC.
+var geoSubdivisions = map[string]map[string]bool{
+	"US": codeSet(`
+		AK AL AR AZ CA CO CT DC DE FL GA HI IA ID IL IN KS KY LA MA MD ME MI MN
+		MO MS MT NC ND NE NH NJ NM NV NY OH OK OR PA RI SC SD TN TX UT VA VT WA
+		WI WV WY`),
+}
+
+func codeSet(codes string) map[string]bool {
+	set := map[string]bool{}
+	for _, c := range strings.Fields(codes) {
+		set[c] = true
+	}
+	return set
+}
+
+// checkGeo checks the GEO() of the records of dc, and rewrites each to the
+// form the providers compare. The records of a name and type must either all
+// have a location or none, since a record served everywhere can't be mixed
+// with records served by location.
+// It must run once the records are final, after the transforms.
+func checkGeo(dc *models.DomainConfig) (errs []error) {
+	type nameType struct{ name, rType string }
+	withGeo := map[nameType]bool{}
+	withoutGeo := map[nameType]bool{}
+	var order []nameType
+	for _, r := range dc.Records {
+		k := nameType{r.GetLabelFQDN(), r.Type}
+		if !withGeo[k] && !withoutGeo[k] {
+			order = append(order, k)
+		}
+		loc, ok := r.Metadata[models.MetaGeo]
+		if !ok {
+			withoutGeo[k] = true
+			continue
+		}
+		withGeo[k] = true
+		g, err := models.ParseGeoLocation(loc)
+		if err == nil {
+			err = checkGeoLocation(g)
+		}
+		if err != nil {
+			errs = append(errs, errors.Errorf("%s %s: GEO(): %s", r.Type, r.GetLabelFQDN(), err))
+			continue
+		}
+		r.Metadata[models.MetaGeo] = g.String()
+	}
+	for _, k := range order {
+		if withGeo[k] && withoutGeo[k] {
+			errs = append(errs, errors.Errorf("%s %s: either all the records or none must have GEO()", k.rType, k.name))
+		}
+	}
+	return errs
+}
+
+// checkGeoLocation returns an error if a code of g is unknown.
+func checkGeoLocation(g models.GeoLocation) error {
+	switch {
+	case g.Continent != "" && !geoContinents[g.Continent]:
+		return errors.Errorf("%s is not a continent code (must be one of AF, AN, AS, EU, NA, OC, SA)", g.Continent)
+	case g.Country != "" && !geoCountries[g.Country]:
+		return errors.Errorf("%s is not an ISO 3166-1 country code", g.Country)
+	case g.Subdivision != "" && geoSubdivisions[g.Country] == nil:
+		return errors.Errorf("subdivisions of %s are not supported (only those of US are)", g.Country)
+	case g.Subdivision != "" && !geoSubdivisions[g.Country][g.Subdivision]:
+		return errors.Errorf("%s is not a subdivision of %s", g.Subdivision, g.Country)
+	}
+	return nil
+}
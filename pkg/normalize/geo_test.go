@@ -0,0 +1,85 @@
+package normalize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers"
+)
+
+func TestCheckGeo(t *testing.T) {
+	providers.RegisterDomainServiceProviderType("FAKE-GEO", nil, providers.CanUseGeoRecords)
+	providers.RegisterDomainServiceProviderType("FAKE-NOGEO", nil)
+
+	geo := func(label, target, loc string) *models.RecordConfig {
+		rc := makeRC(label, "example.com", target, models.RecordConfig{Type: "A", Metadata: map[string]string{}})
+		if loc != "" {
+			rc.Metadata[models.MetaGeo] = loc
+		}
+		return rc
+	}
+	for _, tst := range []struct {
+		desc    string
+		pType   string
+		records []*models.RecordConfig
+		errors  []string
+		geos    []string // the locations after normalization
+	}{
+		{"valid", "FAKE-GEO", []*models.RecordConfig{
+			geo("www", "10.0.0.1", "continent:eu"),
+			geo("www", "10.0.0.2", "Country:US-ca"),
+			geo("www", "10.0.0.3", "country:DE"),
+			geo("www", "10.0.0.4", "*"),
+			geo("mail", "10.0.0.5", ""),
+		}, nil, []string{"continent:EU", "country:US-CA", "country:DE", "*", ""}},
+		{"unknown codes", "FAKE-GEO", []*models.RecordConfig{
+			geo("a", "10.0.0.1", "continent:XX"),
+			geo("b", "10.0.0.1", "country:UK"),
+			geo("c", "10.0.0.1", "country:DE-BY"),
+			geo("d", "10.0.0.1", "country:US-XX"),
+			geo("e", "10.0.0.1", "region:eu-west-1"),
+			geo("f", "10.0.0.1", "country:"),
+		}, []string{
+			"A a.example.com: GEO(): XX is not a continent code",
+			"A b.example.com: GEO(): UK is not an ISO 3166-1 country code",
+			"A c.example.com: GEO(): subdivisions of DE are not supported",
+			"A d.example.com: GEO(): XX is not a subdivision of US",
+			`A e.example.com: GEO(): "region:eu-west-1" is not a location`,
+			`A f.example.com: GEO(): "country:" is not a location`,
+		}, nil},
+		{"mixed", "FAKE-GEO", []*models.RecordConfig{
+			geo("www", "10.0.0.1", "country:DE"),
+			geo("www", "10.0.0.2", ""),
+		}, []string{"A www.example.com: either all the records or none must have GEO()"}, nil},
+		{"unsupported", "FAKE-NOGEO", []*models.RecordConfig{
+			geo("www", "10.0.0.1", "country:DE"),
+			geo("www", "10.0.0.2", "*"),
+		}, []string{"Domain example.com uses GEO(), but DNS provider type FAKE-NOGEO does not support it"}, nil},
+	} {
+		t.Run(tst.desc, func(t *testing.T) {
+			dc := &models.DomainConfig{
+				Name:          "example.com",
+				RegistrarName: "BIND",
+				Records:       tst.records,
+				DNSProviderInstances: []*models.DNSProviderInstance{
+					{ProviderBase: models.ProviderBase{Name: "fake", ProviderType: tst.pType}},
+				},
+			}
+			res := NormalizeAndValidateConfig(&models.DNSConfig{Domains: []*models.DomainConfig{dc}})
+			if len(res.Errors) != len(tst.errors) {
+				t.Fatalf("Expected %d errors, got %v", len(tst.errors), res.Errors)
+			}
+			for i, want := range tst.errors {
+				if !strings.Contains(res.Errors[i].Error(), want) {
+					t.Errorf("Error %d: expected %q, got %q", i, want, res.Errors[i])
+				}
+			}
+			for i, want := range tst.geos {
+				if got := dc.Records[i].Metadata[models.MetaGeo]; got != want {
+					t.Errorf("Record %d: expected location %q, got %q", i, want, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package normalize
+
+import (
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/miekg/dns/dnsutil"
+	"github.com/pkg/errors"
+)
+
+// checkGlue warns about nameservers of a domain that are inside it, such as
+// ns1.example.com for example.com, but have no A or AAAA record there.
+// Resolvers can't find such a nameserver without the glue records, and the
+// registrar can only publish glue for addresses the zone has. The apex NS
+// records come from NAMESERVER(), as a domain can't have NS("@").
+func checkGlue(dc *models.DomainConfig) (errs []error) {
+	if dc.KeepUnknown {
+		return nil
+	}
+	addrs := map[string]bool{}
+	for _, rec := range dc.Records {
+		if rec.Type == "A" || rec.Type == "AAAA" {
+			addrs[rec.GetLabelFQDN()] = true
+		}
+	}
+	warned := map[string]bool{}
+	for _, n := range dc.Nameservers {
+		ns := strings.ToLower(strings.TrimSuffix(n.Name, "."))
+		if ns != dc.Name && !strings.HasSuffix(ns, "."+dc.Name) {
+			continue
+		}
+		if addrs[ns] || warned[ns] || dc.IgnoresLabel(dnsutil.TrimDomainName(ns, dc.Name)) {
+			continue
+		}
+		warned[ns] = true
+		errs = append(errs, Warning{errors.Errorf("nameserver %s of %s is in the zone, but has no A or AAAA record for the glue", ns, dc.Name), "missing-glue", nil})
+	}
+	return errs
+}
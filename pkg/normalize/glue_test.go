@@ -0,0 +1,55 @@
+package normalize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+func TestCheckGlue(t *testing.T) {
+	rc := func(typ, label, target string) *models.RecordConfig {
+		return makeRC(label, "example.com", target, models.RecordConfig{Type: typ, Metadata: map[string]string{}})
+	}
+	for _, tst := range []struct {
+		desc        string
+		nameservers []string
+		records     []*models.RecordConfig
+		ignored     []string
+		want        []string
+	}{
+		{"glue for both", []string{"ns1.example.com", "ns2.example.com"}, []*models.RecordConfig{
+			rc("A", "ns1", "192.0.2.1"),
+			rc("AAAA", "ns2", "2001:db8::1"),
+		}, nil, nil},
+		{"missing glue", []string{"ns1.example.com", "ns2.example.com"}, []*models.RecordConfig{
+			rc("A", "ns1", "192.0.2.1"),
+			rc("CNAME", "ns2", "ns.example.net."),
+		}, nil, []string{"nameserver ns2.example.com of example.com is in the zone, but has no A or AAAA record"}},
+		{"glue at the apex", []string{"example.com", "ns1.sub.example.com"}, []*models.RecordConfig{
+			rc("A", "@", "192.0.2.1"),
+		}, nil, []string{"nameserver ns1.sub.example.com of example.com is in the zone, but has no A or AAAA record"}},
+		{"once per nameserver", []string{"ns1.example.com", "NS1.example.com."}, nil,
+			nil, []string{"nameserver ns1.example.com of example.com is in the zone, but has no A or AAAA record"}},
+		{"out of the zone", []string{"ns1.example.net", "ns1.notexample.com"}, nil, nil, nil},
+		{"IGNOREd", []string{"ns1.example.com"}, nil, []string{"ns1"}, nil},
+	} {
+		dc := &models.DomainConfig{Name: "example.com", Records: tst.records, IgnoredLabels: tst.ignored, Nameservers: models.StringsToNameservers(tst.nameservers)}
+		errs := checkGlue(dc)
+		if len(errs) != len(tst.want) {
+			t.Errorf("%s: expected %d problems, got %v", tst.desc, len(tst.want), errs)
+			continue
+		}
+		for i, err := range errs {
+			if !strings.HasPrefix(err.Error(), tst.want[i]) {
+				t.Errorf("%s: expected %q, got %q", tst.desc, tst.want[i], err)
+			}
+		}
+	}
+
+	// NAMESERVER() names are relative to the domain.
+	dc := &models.DomainConfig{Name: "example.com", RegistrarName: "BIND", Nameservers: models.StringsToNameservers([]string{"ns1"})}
+	if res := NormalizeAndValidateConfig(&models.DNSConfig{Domains: []*models.DomainConfig{dc}}); len(res.Errors) != 0 || len(res.Warnings) != 1 {
+		t.Errorf("Expected a warning for NAMESERVER('ns1'), got %v %v", res.Errors, res.Warnings)
+	}
+}
@@ -33,8 +33,8 @@ func TestImportTransform(t *testing.T) {
 	cfg := &models.DNSConfig{
 		Domains: []*models.DomainConfig{src, dst},
 	}
-	if errs := NormalizeAndValidateConfig(cfg); len(errs) != 0 {
-		for _, err := range errs {
+	if res := NormalizeAndValidateConfig(cfg); res.Failed(true) {
+		for _, err := range append(res.Errors, res.Warnings...) {
 			t.Error(err)
 		}
 		t.FailNow()
@@ -0,0 +1,109 @@
+package normalize
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/pkg/errors"
+)
+
+// capabilityTypes are the record types that a provider must declare a
+// capability for.
+var capabilityTypes = []struct {
+	rType string
+	cap   providers.Capability
+}{
+	{"ALIAS", providers.CanUseAlias},
+	{"PTR", providers.CanUsePTR},
+	{"SRV", providers.CanUseSRV},
+	{"CAA", providers.CanUseCAA},
+	{"TLSA", providers.CanUseTLSA},
+	{"URL", providers.CanUseURLForward},
+	{"URL301", providers.CanUseURLForward},
+	{"FRAME", providers.CanUseURLForward},
+}
+
+// RecordTypes returns the record types that DNS provider type pType can
+// manage: the ones every provider supports, those it declares a capability
+// for, and its custom record types.
+func RecordTypes(pType string) []string {
+	types := []string{"A", "AAAA", "CNAME", "MX", "NS", "TXT"}
+	for _, ty := range capabilityTypes {
+		if providers.ProviderHasCabability(pType, ty.cap) {
+			types = append(types, ty.rType)
+		}
+	}
+	if providers.ProviderHasCabability(pType, providers.CanUseSOA) {
+		types = append(types, "SOA")
+	}
+	types = append(types, providers.ProviderCustomRecordTypes(pType)...)
+	sort.Strings(types)
+	return types
+}
+
+// checkProviderLimits checks the records of dc against the declared
+// capabilities and limits of each of its DNS providers: the record types it
+// supports, GEO(), wildcards and TXT records with several strings. Every
+// violation is reported, so that they can all be fixed before a push is
+// attempted. TTLs below the highest MinTTL of the providers are raised to it,
+// with a warning, and an SOA() that a provider ignores is only a warning.
+// It must run once the records are final, after the transforms.
+func checkProviderLimits(dc *models.DomainConfig) (errs []error) {
+	minTTL, minTTLProvider := uint32(0), ""
+	for _, provider := range dc.DNSProviderInstances {
+		if m := providers.ProviderMinTTL(provider.ProviderType); m > minTTL {
+			minTTL, minTTLProvider = m, provider.Name
+		}
+	}
+	for _, r := range dc.Records {
+		if r.TTL < minTTL {
+			errs = append(errs, Warning{errors.Errorf("TTL %d for %s %s is below the minimum of %s (%d). Using %d",
+				r.TTL, r.Type, r.GetLabelFQDN(), minTTLProvider, minTTL, minTTL), "min-ttl", r})
+			r.TTL = minTTL
+		}
+	}
+	for _, provider := range dc.DNSProviderInstances {
+		pType := provider.ProviderType
+		for _, ty := range capabilityTypes {
+			if providers.ProviderHasCabability(pType, ty.cap) {
+				continue
+			}
+			for _, r := range dc.Records {
+				if r.Type == ty.rType {
+					errs = append(errs, errors.Errorf("Domain %s uses %s records, but DNS provider type %s does not support them", dc.Name, ty.rType, pType))
+					break
+				}
+			}
+		}
+		if !providers.ProviderHasCabability(pType, providers.CanUseGeoRecords) {
+			for _, r := range dc.Records {
+				if _, ok := r.Metadata[models.MetaGeo]; ok {
+					errs = append(errs, errors.Errorf("Domain %s uses GEO(), but DNS provider type %s does not support it", dc.Name, pType))
+					break
+				}
+			}
+		}
+		if !providers.ProviderHasCabability(pType, providers.CanUseSOA) {
+			for _, r := range dc.Records {
+				if r.Type == "SOA" {
+					errs = append(errs, Warning{errors.Errorf("Domain %s uses SOA(), but DNS provider type %s manages the SOA itself and ignores it", dc.Name, pType), "soa-ignored", r})
+					break
+				}
+			}
+		}
+		txtMulti := providers.ProviderHasCabability(pType, providers.CanUseTXTMulti)
+		for _, r := range dc.Records {
+			if r.GetLabel() == "*" || strings.HasPrefix(r.GetLabel(), "*.") {
+				if !providers.ProviderAllowsWildcard(pType, r.Type) {
+					errs = append(errs, errors.Errorf("%s %s: %s does not support wildcard %s records", r.Type, r.GetLabelFQDN(), pType, r.Type))
+				}
+			}
+			if r.Type == "TXT" && !txtMulti && len(r.TxtStrings) > 1 {
+				errs = append(errs, errors.Errorf("TXT records with multiple strings (label %v domain: %v) not supported by %s", r.GetLabel(), dc.Name, provider.Name))
+			}
+		}
+	}
+	return errs
+}
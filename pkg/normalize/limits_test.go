@@ -0,0 +1,72 @@
+package normalize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers"
+)
+
+func TestProviderLimits(t *testing.T) {
+	providers.RegisterDomainServiceProviderType("FAKE-LIMITS", nil,
+		providers.MinTTL(300), providers.NoWildcards{"CNAME"})
+	providers.RegisterDomainServiceProviderType("FAKE-NOLIMITS", nil,
+		providers.CanUseSRV, providers.CanUseTXTMulti)
+
+	txt := func(label string, txts ...string) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: "TXT"}
+		rc.SetLabel(label, "example.com")
+		rc.SetTargetTXTs(txts)
+		return rc
+	}
+	records := func() []*models.RecordConfig {
+		srv := &models.RecordConfig{Type: "SRV"}
+		srv.SetLabel("_sip._tcp", "example.com")
+		srv.SetTargetSRV(10, 5, 5060, "sip.example.com.")
+		return []*models.RecordConfig{
+			makeRC("low", "example.com", "1.2.3.4", models.RecordConfig{Type: "A", TTL: 60}),
+			makeRC("*", "example.com", "www", models.RecordConfig{Type: "CNAME"}),
+			makeRC("*.hosts", "example.com", "1.2.3.5", models.RecordConfig{Type: "A"}),
+			txt("multi", "short", "012345678901"),
+			srv,
+		}
+	}
+
+	for _, tst := range []struct {
+		pType    string
+		errors   []string
+		warnings int
+	}{
+		{"FAKE-LIMITS", []string{
+			"uses SRV records",
+			"CNAME *.example.com: FAKE-LIMITS does not support wildcard CNAME records",
+			"TXT records with multiple strings (label multi domain: example.com) not supported by fake",
+		}, 1},
+		{"FAKE-NOLIMITS", nil, 0},
+	} {
+		t.Run(tst.pType, func(t *testing.T) {
+			config := &models.DNSConfig{
+				Domains: []*models.DomainConfig{
+					{
+						Name:          "example.com",
+						RegistrarName: "BIND",
+						Records:       records(),
+						DNSProviderInstances: []*models.DNSProviderInstance{
+							{ProviderBase: models.ProviderBase{Name: "fake", ProviderType: tst.pType}},
+						},
+					},
+				},
+			}
+			res := NormalizeAndValidateConfig(config)
+			if len(res.Errors) != len(tst.errors) || len(res.Warnings) != tst.warnings {
+				t.Fatalf("Expected %d errors and %d warnings, got %v", len(tst.errors), tst.warnings, res)
+			}
+			for i, want := range tst.errors {
+				if !strings.Contains(res.Errors[i].Error(), want) {
+					t.Errorf("Error %d: expected %q, got %q", i, want, res.Errors[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package normalize
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/spflib"
+	"github.com/pkg/errors"
+)
+
+// LintEmail warns about obvious syntax errors in SPF (TXT records starting
+// with "v=spf1") and DMARC (TXT records starting with "v=DMARC1" at _dmarc)
+// records. It only checks each record on its own: includes are not looked
+// up. It must run after NormalizeAndValidateConfig.
+func LintEmail(cfg *models.DNSConfig) (errs []error) {
+	for _, domain := range cfg.Domains {
+		spfs := map[string]int{}
+		for _, rec := range domain.Records {
+			if rec.Type != "TXT" {
+				continue
+			}
+			txt := strings.Join(rec.TxtStrings, "")
+			label := rec.GetLabel()
+			switch {
+			case txt == "v=spf1" || strings.HasPrefix(txt, "v=spf1 "):
+				if spfs[label]++; spfs[label] == 2 {
+					errs = append(errs, Warning{errors.Errorf("SPF record %s: there is more than one, which makes SPF checks fail", rec.GetLabelFQDN()), "spf-syntax", rec})
+				}
+				for _, problem := range lintSPF(txt) {
+					errs = append(errs, Warning{errors.Errorf("SPF record %s: %s", rec.GetLabelFQDN(), problem), "spf-syntax", rec})
+				}
+			case (label == "_dmarc" || strings.HasPrefix(label, "_dmarc.")) && strings.HasPrefix(txt, "v=DMARC1"):
+				for _, problem := range lintDMARC(txt) {
+					errs = append(errs, Warning{errors.Errorf("DMARC record %s: %s", rec.GetLabelFQDN(), problem), "dmarc-syntax", rec})
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// lintSPF returns the problems of an SPF record (RFC 7208 section 12).
+func lintSPF(txt string) (problems []string) {
+	all := ""
+	modifiers := map[string]bool{}
+	terms := strings.Fields(txt)[1:]
+	for _, term := range terms {
+		if eq := strings.Index(term, "="); eq > 0 && !strings.ContainsAny(term[:eq], ":/") {
+			name := strings.ToLower(term[:eq])
+			if modifiers[name] && (name == "redirect" || name == "exp") {
+				problems = append(problems, fmt.Sprintf("%s is given more than once", name))
+			}
+			modifiers[name] = true
+			if (name == "redirect" || name == "exp") && term[eq+1:] == "" {
+				problems = append(problems, fmt.Sprintf("%s has no domain", name))
+			}
+			continue
+		}
+		mech := strings.TrimLeft(term, "+-~?")
+		if len(term)-len(mech) > 1 {
+			problems = append(problems, fmt.Sprintf("%q has more than one qualifier", term))
+		}
+		name, arg := mech, ""
+		if i := strings.IndexAny(mech, ":/"); i != -1 {
+			name, arg = mech[:i], mech[i:]
+		}
+		if all != "" {
+			problems = append(problems, fmt.Sprintf("%q is never used, because it comes after %q", term, all))
+		}
+		switch strings.ToLower(name) {
+		case "all":
+			if arg != "" {
+				problems = append(problems, fmt.Sprintf("%q: all takes no argument", term))
+			}
+			if all == "" {
+				all = term
+			}
+		case "include", "exists":
+			if !strings.HasPrefix(arg, ":") || len(arg) == 1 || strings.Contains(arg, "/") {
+				problems = append(problems, fmt.Sprintf("%q: %s needs a domain", term, name))
+			}
+		case "a", "mx":
+			if p := lintSPFCIDR(arg); p != "" {
+				problems = append(problems, fmt.Sprintf("%q: %s", term, p))
+			}
+		case "ptr":
+			if strings.Contains(arg, "/") || arg == ":" {
+				problems = append(problems, fmt.Sprintf("%q: ptr takes only a domain", term))
+			}
+		case "ip4", "ip6":
+			if p := lintSPFAddress(strings.ToLower(name), strings.TrimPrefix(arg, ":")); p != "" || !strings.HasPrefix(arg, ":") {
+				if p == "" {
+					p = "needs an address"
+				}
+				problems = append(problems, fmt.Sprintf("%q: %s", term, p))
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("%q is not an SPF mechanism", term))
+		}
+	}
+	if modifiers["redirect"] && all != "" {
+		problems = append(problems, fmt.Sprintf("redirect is never used, because of %q", all))
+	}
+	// The includes are not looked up, so their lookups are not counted.
+	if rec, err := spflib.Parse(txt, nil); err == nil && rec.Lookups() > spflib.MaxLookups {
+		problems = append(problems, fmt.Sprintf("it needs at least %d DNS lookups, more than the %d allowed", rec.Lookups(), spflib.MaxLookups))
+	}
+	return problems
+}
+
+// lintSPFCIDR checks the optional [:domain][/ip4-cidr][//ip6-cidr] of a and
+// mx.
+func lintSPFCIDR(arg string) string {
+	if strings.HasPrefix(arg, ":") {
+		arg = arg[1:]
+		if arg == "" || strings.HasPrefix(arg, "/") {
+			return "the domain is empty"
+		}
+		if i := strings.Index(arg, "/"); i != -1 {
+			arg = arg[i:]
+		} else {
+			arg = ""
+		}
+	}
+	if arg == "" {
+		return ""
+	}
+	parts := strings.SplitN(arg[1:], "//", 2)
+	if parts[0] != "" {
+		if n, err := strconv.Atoi(parts[0]); err != nil || n < 0 || n > 32 {
+			return fmt.Sprintf("/%s is not an IPv4 prefix length", parts[0])
+		}
+	}
+	if len(parts) == 2 {
+		if n, err := strconv.Atoi(parts[1]); err != nil || n < 0 || n > 128 {
+			return fmt.Sprintf("//%s is not an IPv6 prefix length", parts[1])
+		}
+	}
+	return ""
+}
+
+// lintSPFAddress checks the address[/prefix] of ip4 and ip6.
+func lintSPFAddress(mech, addr string) string {
+	ip, prefix := addr, ""
+	if i := strings.Index(addr, "/"); i != -1 {
+		ip, prefix = addr[:i], addr[i+1:]
+	}
+	parsed := net.ParseIP(ip)
+	max := 32
+	if mech == "ip6" {
+		max = 128
+	}
+	if parsed == nil || (mech == "ip4") != (parsed.To4() != nil && !strings.Contains(ip, ":")) {
+		return fmt.Sprintf("%s is not an %s address", ip, map[string]string{"ip4": "IPv4", "ip6": "IPv6"}[mech])
+	}
+	if prefix != "" {
+		if n, err := strconv.Atoi(prefix); err != nil || n < 0 || n > max {
+			return fmt.Sprintf("/%s is not a prefix length", prefix)
+		}
+	}
+	return ""
+}
+
+// dmarcTags are the tags of RFC 7489 section 6.3, with the values they
+// accept, or nil if they're checked otherwise.
+var dmarcTags = map[string][]string{
+	"v":     nil,
+	"p":     {"none", "quarantine", "reject"},
+	"sp":    {"none", "quarantine", "reject"},
+	"adkim": {"r", "s"},
+	"aspf":  {"r", "s"},
+	"rf":    {"afrf"},
+	"fo":    nil,
+	"pct":   nil,
+	"ri":    nil,
+	"rua":   nil,
+	"ruf":   nil,
+}
+
+// lintDMARC returns the problems of a DMARC record (RFC 7489 section 6.4).
+func lintDMARC(txt string) (problems []string) {
+	seen := map[string]bool{}
+	for i, tag := range strings.Split(txt, ";") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		kv := strings.SplitN(tag, "=", 2)
+		if len(kv) != 2 {
+			problems = append(problems, fmt.Sprintf("%q is not a tag=value", tag))
+			continue
+		}
+		name, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		allowed, known := dmarcTags[name]
+		switch {
+		case !known:
+			problems = append(problems, fmt.Sprintf("%q is not a DMARC tag", name))
+			continue
+		case seen[name]:
+			problems = append(problems, fmt.Sprintf("%s is given more than once", name))
+		case name == "v" && (i != 0 || value != "DMARC1"):
+			problems = append(problems, "v=DMARC1 must be the first tag")
+		case name == "p" && i != 1:
+			problems = append(problems, "p must follow v=DMARC1")
+		}
+		seen[name] = true
+		if allowed != nil && !contains(allowed, value) {
+			problems = append(problems, fmt.Sprintf("%s=%s must be one of %s", name, value, strings.Join(allowed, ", ")))
+		}
+		switch name {
+		case "pct":
+			if n, err := strconv.Atoi(value); err != nil || n < 0 || n > 100 {
+				problems = append(problems, fmt.Sprintf("pct=%s must be a number from 0 to 100", value))
+			}
+		case "ri":
+			if n, err := strconv.ParseUint(value, 10, 32); err != nil || n == 0 {
+				problems = append(problems, fmt.Sprintf("ri=%s must be a number of seconds", value))
+			}
+		case "fo":
+			for _, o := range strings.Split(value, ":") {
+				if !contains([]string{"0", "1", "d", "s"}, o) {
+					problems = append(problems, fmt.Sprintf("fo=%s must be options 0, 1, d or s, separated by colons", value))
+					break
+				}
+			}
+		case "rua", "ruf":
+			for _, uri := range strings.Split(value, ",") {
+				if uri = strings.TrimSpace(uri); !strings.Contains(uri, ":") {
+					problems = append(problems, fmt.Sprintf("%s: %q is not a URI, such as mailto:dmarc@example.com", name, uri))
+				}
+			}
+		}
+	}
+	if !seen["p"] {
+		problems = append(problems, "the policy (p) is missing")
+	}
+	return problems
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
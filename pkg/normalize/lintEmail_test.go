@@ -0,0 +1,105 @@
+package normalize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+func TestLintSPF(t *testing.T) {
+	for _, tst := range []struct {
+		txt      string
+		problems []string
+	}{
+		{"v=spf1 -all", nil},
+		{"v=spf1 a mx:mail.example.com/24 ip4:192.0.2.0/24 ip6:2001:db8::/32 include:_spf.example.net ~all", nil},
+		{"v=spf1 a/24//64 exists:%{i}.spf.example.com redirect=_spf.example.com", nil},
+		{"v=spf1 ip4:192.0.2.1 exp=explain.example.com -all", nil},
+		{"v=spf1 ipv4:192.0.2.1 -all", []string{`"ipv4:192.0.2.1" is not an SPF mechanism`}},
+		{"v=spf1 ip4:192.0.2.300 ip4:2001:db8::1 ip6:192.0.2.1 ip4:192.0.2.0/33 -all", []string{
+			"192.0.2.300 is not an IPv4 address", "2001:db8::1 is not an IPv4 address",
+			"192.0.2.1 is not an IPv6 address", "/33 is not a prefix length"}},
+		{"v=spf1 include: a/40 -all", []string{"include needs a domain", "/40 is not an IPv4 prefix length"}},
+		{"v=spf1 -all include:_spf.example.net", []string{`"include:_spf.example.net" is never used, because it comes after "-all"`}},
+		{"v=spf1 redirect=a.example.com ~all redirect=b.example.com", []string{
+			"redirect is given more than once", `redirect is never used, because of "~all"`}},
+		{"v=spf1 include:a include:b include:c include:d include:e include:f a mx ptr exists:g redirect=h",
+			[]string{"it needs at least 11 DNS lookups, more than the 10 allowed"}},
+	} {
+		problems := lintSPF(tst.txt)
+		if len(problems) != len(tst.problems) {
+			t.Errorf("%q: expected %q, got %q", tst.txt, tst.problems, problems)
+			continue
+		}
+		for i, want := range tst.problems {
+			if !strings.Contains(problems[i], want) {
+				t.Errorf("%q: expected %q, got %q", tst.txt, want, problems[i])
+			}
+		}
+	}
+}
+
+func TestLintDMARC(t *testing.T) {
+	for _, tst := range []struct {
+		txt      string
+		problems []string
+	}{
+		{"v=DMARC1; p=none", nil},
+		{"v=DMARC1; p=reject; sp=quarantine; pct=50; adkim=s; aspf=r; fo=0:d; rf=afrf; ri=86400; rua=mailto:a@example.com,mailto:b@example.com!10m; ruf=mailto:f@example.com;", nil},
+		{"v=DMARC1; sp=none", []string{"the policy (p) is missing"}},
+		{"v=DMARC1; rua=mailto:a@example.com; p=none", []string{"p must follow v=DMARC1"}},
+		{"v=DMARC1; p=drop; pct=150; adkim=loose; fo=2", []string{
+			"p=drop must be one of none, quarantine, reject", "pct=150 must be a number",
+			"adkim=loose must be one of r, s", "fo=2 must be options"}},
+		{"v=DMARC1; p=none; rua=dmarc@example.com; policy=none; p=none; oops", []string{
+			`"dmarc@example.com" is not a URI`, `"policy" is not a DMARC tag`, "p is given more than once", `"oops" is not a tag=value`}},
+	} {
+		problems := lintDMARC(tst.txt)
+		if len(problems) != len(tst.problems) {
+			t.Errorf("%q: expected %q, got %q", tst.txt, tst.problems, problems)
+			continue
+		}
+		for i, want := range tst.problems {
+			if !strings.Contains(problems[i], want) {
+				t.Errorf("%q: expected %q, got %q", tst.txt, want, problems[i])
+			}
+		}
+	}
+}
+
+func TestLintEmail(t *testing.T) {
+	txt := func(label, target string) *models.RecordConfig {
+		return makeRC(label, "example.com", target, models.RecordConfig{Type: "TXT", TxtStrings: []string{target}})
+	}
+	ignored := txt("other", "v=spf1 bogus -all")
+	ignored.Metadata = map[string]string{"ignore_warnings": "spf-syntax"}
+	cfg := &models.DNSConfig{Domains: []*models.DomainConfig{{
+		Name: "example.com",
+		Records: []*models.RecordConfig{
+			txt("@", "v=spf1 include:_spf.example.net -all"),
+			txt("@", "v=spf1 -all"),
+			txt("mail", "v=spf1 ipv4:192.0.2.1 -all"),
+			txt("_dmarc", "v=DMARC1; p=drop"),
+			// Not at _dmarc, or not starting with the version: left alone.
+			txt("dmarc", "v=DMARC1; p=drop"),
+			txt("@", "google-site-verification=abc"),
+			ignored,
+		},
+	}}}
+	res := Result{}
+	res.Add(LintEmail(cfg)...)
+	want := []string{
+		"SPF record example.com: there is more than one",
+		"SPF record mail.example.com:",
+		"DMARC record _dmarc.example.com: p=drop",
+	}
+	if len(res.Warnings) != len(want) || len(res.Errors) != 0 {
+		t.Fatalf("expected %d warnings, got %v", len(want), res)
+	}
+	for i, w := range want {
+		if !strings.Contains(res.Warnings[i].Error(), w) {
+			t.Errorf("expected %q, got %q", w, res.Warnings[i])
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package normalize
+
+import (
+	"bytes"
+	"net"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/miekg/dns/dnsutil"
+	"github.com/pkg/errors"
+)
+
+// CheckPTRForward warns about PTR records in reverse zones whose target has
+// no A or AAAA record of the address back, as many mail servers require.
+// Targets in a domain of cfg must have the record there. Other targets are
+// looked up in the DNS, and failed lookups other than "no such host" are
+// skipped. So are targets that are CNAMEs, or that a domain with NO_PURGE or
+// an IGNOREd label may have records for that cfg doesn't know about.
+// It must run after NormalizeAndValidateConfig.
+func CheckPTRForward(cfg *models.DNSConfig) (errs []error) {
+	for _, domain := range cfg.Domains {
+		for _, rec := range domain.Records {
+			if rec.Type != "PTR" {
+				continue
+			}
+			ip := reverseIP(rec.GetLabelFQDN())
+			if ip == nil {
+				continue
+			}
+			target := strings.ToLower(strings.TrimSuffix(rec.GetTargetField(), "."))
+			addrs, ok := forwardAddresses(cfg, target)
+			if !ok {
+				continue
+			}
+			if len(addrs) == 0 {
+				errs = append(errs, Warning{errors.Errorf("PTR %s points to %s, which has no A or AAAA record", rec.GetLabelFQDN(), target), "ptr-forward", rec})
+				continue
+			}
+			found := false
+			for _, a := range addrs {
+				found = found || ip.Equal(net.ParseIP(a))
+			}
+			if !found {
+				errs = append(errs, Warning{errors.Errorf("PTR %s points to %s, whose addresses (%s) don't include %s", rec.GetLabelFQDN(), target, strings.Join(addrs, ", "), ip), "ptr-forward", rec})
+			}
+		}
+	}
+	return errs
+}
+
+// reverseIP returns the address of a name in in-addr.arpa or ip6.arpa, or
+// nil if it isn't the name of a single address. The labels of RFC 2317
+// classless zones, such as "0/26", are skipped.
+func reverseIP(name string) net.IP {
+	var labels []string
+	var sep string
+	switch {
+	case strings.HasSuffix(name, ".in-addr.arpa"):
+		for _, l := range strings.Split(strings.TrimSuffix(name, ".in-addr.arpa"), ".") {
+			if !strings.Contains(l, "/") {
+				labels = append(labels, l)
+			}
+		}
+		if len(labels) != 4 {
+			return nil
+		}
+		sep = "."
+	case strings.HasSuffix(name, ".ip6.arpa"):
+		labels = strings.Split(strings.TrimSuffix(name, ".ip6.arpa"), ".")
+		if len(labels) != 32 {
+			return nil
+		}
+	default:
+		return nil
+	}
+	var b bytes.Buffer
+	for i := len(labels) - 1; i >= 0; i-- {
+		b.WriteString(labels[i])
+		if sep == "" && i%4 == 0 && i > 0 {
+			b.WriteString(":")
+		} else if i > 0 {
+			b.WriteString(sep)
+		}
+	}
+	return net.ParseIP(b.String())
+}
+
+// forwardAddresses returns the A and AAAA addresses of name, and false if
+// they can't be known.
+func forwardAddresses(cfg *models.DNSConfig, name string) ([]string, bool) {
+	var zone *models.DomainConfig
+	for _, d := range cfg.Domains {
+		if (name == d.Name || strings.HasSuffix(name, "."+d.Name)) && (zone == nil || len(d.Name) > len(zone.Name)) {
+			zone = d
+		}
+	}
+	if zone == nil {
+		addrs, err := lookupHost(name)
+		if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.Err == "no such host" {
+			return nil, true
+		}
+		return addrs, err == nil
+	}
+	var addrs []string
+	for _, r := range zone.Records {
+		if r.GetLabelFQDN() != name {
+			continue
+		}
+		switch r.Type {
+		case "A", "AAAA":
+			addrs = append(addrs, r.GetTargetField())
+		case "CNAME":
+			return nil, false
+		}
+	}
+	if len(addrs) > 0 {
+		return addrs, true
+	}
+	if zone.KeepUnknown {
+		return nil, false
+	}
+	return nil, !zone.IgnoresLabel(dnsutil.TrimDomainName(name, zone.Name))
+}
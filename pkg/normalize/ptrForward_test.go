@@ -0,0 +1,95 @@
+package normalize
+
+import (
+	"net"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+func TestReverseIP(t *testing.T) {
+	for name, want := range map[string]string{
+		"4.3.2.1.in-addr.arpa":              "1.2.3.4",
+		"129.128/27.18.20.172.in-addr.arpa": "172.20.18.129",
+		"2.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.2.0.3.0.8.b.d.0.1.0.0.2.ip6.arpa": "2001:db8:302::2",
+		"3.2.1.in-addr.arpa":   "",
+		"0.0.2.ip6.arpa":       "",
+		"x.3.2.1.in-addr.arpa": "",
+		"www.example.com":      "",
+	} {
+		got := reverseIP(name)
+		if want == "" && got != nil || want != "" && !got.Equal(net.ParseIP(want)) {
+			t.Errorf("%s: expected %q, got %v", name, want, got)
+		}
+	}
+}
+
+func TestCheckPTRForward(t *testing.T) {
+	defer func() { lookupHost = net.LookupHost }()
+	lookupHost = func(name string) ([]string, error) {
+		switch name {
+		case "mail.example.org":
+			return []string{"192.0.2.10", "2001:db8::10"}, nil
+		case "broken.example.org":
+			return nil, &net.DNSError{Err: "i/o timeout", Name: name}
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: name}
+	}
+	rec := func(label, rType, target string) *models.RecordConfig {
+		return makeRC(label, "example.com", target, models.RecordConfig{Type: rType})
+	}
+	for _, tst := range []struct {
+		name, target string
+		warnings     int
+	}{
+		// Matching.
+		{"10", "mail.example.com.", 0},
+		{"10", "mail.example.org.", 0},
+		{"10", "dual.example.com.", 0},
+		// Missing forward.
+		{"10", "missing.example.com.", 1},
+		{"10", "missing.example.org.", 1},
+		{"10", "text.example.com.", 1},
+		// Forward mismatch.
+		{"11", "mail.example.com.", 1},
+		{"11", "mail.example.org.", 1},
+		// Can't tell.
+		{"10", "alias.example.com.", 0},
+		{"10", "ignored.example.com.", 0},
+		{"10", "broken.example.org.", 0},
+	} {
+		cfg := &models.DNSConfig{Domains: []*models.DomainConfig{
+			{Name: "example.com", IgnoredLabels: []string{"ignored"}, Records: []*models.RecordConfig{
+				rec("mail", "A", "192.0.2.10"),
+				rec("dual", "AAAA", "2001:db8::10"),
+				rec("dual", "A", "192.0.2.10"),
+				rec("text", "TXT", "192.0.2.10"),
+				rec("alias", "CNAME", "mail.example.org."),
+			}},
+			{Name: "2.0.192.in-addr.arpa", Records: []*models.RecordConfig{
+				makeRC(tst.name, "2.0.192.in-addr.arpa", tst.target, models.RecordConfig{Type: "PTR"}),
+			}},
+		}}
+		errs := CheckPTRForward(cfg)
+		if len(errs) != tst.warnings {
+			t.Errorf("%s %s: expected %d warnings, got %v", tst.name, tst.target, tst.warnings, errs)
+		}
+		for _, err := range errs {
+			if w, ok := err.(Warning); !ok || w.ID != "ptr-forward" || w.Record.Type != "PTR" {
+				t.Errorf("%s %s: expected a ptr-forward warning about the PTR, got %v", tst.name, tst.target, err)
+			}
+		}
+	}
+}
+
+func TestCheckPTRForwardNoPurge(t *testing.T) {
+	cfg := &models.DNSConfig{Domains: []*models.DomainConfig{
+		{Name: "example.com", KeepUnknown: true},
+		{Name: "2.0.192.in-addr.arpa", Records: []*models.RecordConfig{
+			makeRC("10", "2.0.192.in-addr.arpa", "mail.example.com.", models.RecordConfig{Type: "PTR"}),
+		}},
+	}}
+	if errs := CheckPTRForward(cfg); len(errs) != 0 {
+		t.Errorf("Expected no warnings for a domain with NO_PURGE, got %v", errs)
+	}
+}
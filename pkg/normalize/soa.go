@@ -0,0 +1,70 @@
+package normalize
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns/dnsutil"
+	"github.com/pkg/errors"
+)
+
+// checkSOA validates the target of an SOA() record: "ns mbox serial refresh
+// retry expire minttl", where the serial is left to the provider. The timers
+// must be in the ranges of RFC 1912 section 2.2, loosely: a secondary must
+// retry before it would refresh again, and keep the zone for longer than a
+// day; negative answers may be cached for a day at most (RFC 2308).
+func checkSOA(label, target string) error {
+	if label != "@" {
+		return errors.Errorf("SOA records can only be at the apex (@)")
+	}
+	fields := strings.Fields(target)
+	if len(fields) != 7 {
+		return errors.Errorf("SOA target %q must be ns, mbox, serial, refresh, retry, expire and minttl", target)
+	}
+	if err := checkTarget(fields[0]); err != nil {
+		return errors.Errorf("SOA ns: %s", err)
+	}
+	if !strings.Contains(fields[1], "@") {
+		if err := checkTarget(fields[1]); err != nil {
+			return errors.Errorf("SOA mbox: %s", err)
+		}
+	}
+	var timers [4]uint64
+	for i, name := range []string{"refresh", "retry", "expire", "minttl"} {
+		n, err := strconv.ParseUint(fields[3+i], 10, 32)
+		if err != nil {
+			return errors.Errorf("SOA %s %q is not a number of seconds", name, fields[3+i])
+		}
+		timers[i] = n
+	}
+	refresh, retry, expire, minttl := timers[0], timers[1], timers[2], timers[3]
+	switch {
+	case refresh < 60:
+		return errors.Errorf("SOA refresh %d must be at least 60", refresh)
+	case retry < 60 || retry >= refresh:
+		return errors.Errorf("SOA retry %d must be at least 60 and less than refresh (%d)", retry, refresh)
+	case expire < 86400 || expire <= refresh+retry:
+		return errors.Errorf("SOA expire %d must be at least 86400 and more than refresh + retry (%d)", expire, refresh+retry)
+	case minttl > 86400:
+		return errors.Errorf("SOA minttl %d must be at most 86400", minttl)
+	}
+	return nil
+}
+
+// canonicalSOA makes the ns and mbox of an SOA target fully qualified.
+// An mbox may be given as an email address, hostmaster@example.com, which is
+// written hostmaster.example.com.
+func canonicalSOA(target, domain string) string {
+	fields := strings.Fields(target)
+	if len(fields) != 7 {
+		return target
+	}
+	fields[0] = dnsutil.AddOrigin(fields[0], domain+".")
+	if at := strings.LastIndex(fields[1], "@"); at != -1 {
+		local := strings.Replace(fields[1][:at], ".", `\.`, -1)
+		fields[1] = local + "." + strings.TrimSuffix(fields[1][at+1:], ".") + "."
+	} else {
+		fields[1] = dnsutil.AddOrigin(fields[1], domain+".")
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,68 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers"
+)
+
+func TestCheckSOA(t *testing.T) {
+	for _, tst := range []struct {
+		label, target, err string
+	}{
+		{"@", "ns1.example.com. hostmaster.example.com. 0 3600 600 604800 1440", ""},
+		{"@", "ns1 hostmaster@example.com 0 43200 3600 1209600 3600", ""},
+		{"www", "ns1 hostmaster 0 3600 600 604800 1440", "SOA records can only be at the apex (@)"},
+		{"@", "ns1 hostmaster 0 3600 600", `SOA target "ns1 hostmaster 0 3600 600" must be ns, mbox, serial, refresh, retry, expire and minttl`},
+		{"@", "ns1.example.com hostmaster 0 3600 600 604800 1440", "SOA ns: target (ns1.example.com) must end with a (.) [https://stackexchange.github.io/dnscontrol/why-the-dot]"},
+		{"@", "ns1 hostmaster 0 1h 600 604800 1440", `SOA refresh "1h" is not a number of seconds`},
+		{"@", "ns1 hostmaster 0 30 10 604800 1440", "SOA refresh 30 must be at least 60"},
+		{"@", "ns1 hostmaster 0 3600 3600 604800 1440", "SOA retry 3600 must be at least 60 and less than refresh (3600)"},
+		{"@", "ns1 hostmaster 0 3600 600 3600 1440", "SOA expire 3600 must be at least 86400 and more than refresh + retry (4200)"},
+		{"@", "ns1 hostmaster 0 3600 600 604800 604800", "SOA minttl 604800 must be at most 86400"},
+	} {
+		msg := ""
+		if err := checkSOA(tst.label, tst.target); err != nil {
+			msg = err.Error()
+		}
+		if msg != tst.err {
+			t.Errorf("%s %s: expected %q, got %q", tst.label, tst.target, tst.err, msg)
+		}
+	}
+
+	for target, want := range map[string]string{
+		"ns1 hostmaster 0 3600 600 604800 1440":                          "ns1.example.com. hostmaster.example.com. 0 3600 600 604800 1440",
+		"ns1.example.net. first.last@example.net 0 3600 600 604800 1440": `ns1.example.net. first\.last.example.net. 0 3600 600 604800 1440`,
+	} {
+		if got := canonicalSOA(target, "example.com"); got != want {
+			t.Errorf("%s: expected %q, got %q", target, want, got)
+		}
+	}
+}
+
+func TestSOAProviders(t *testing.T) {
+	providers.RegisterDomainServiceProviderType("FAKE-SOA", nil, providers.CanUseSOA)
+	providers.RegisterDomainServiceProviderType("FAKE-NOSOA", nil)
+	soa := func() *models.RecordConfig {
+		return makeRC("@", "example.com", "ns1 hostmaster 0 3600 600 604800 1440", models.RecordConfig{Type: "SOA", Metadata: map[string]string{}})
+	}
+	// A provider with CanUseSOA uses SOA().
+	dc := &models.DomainConfig{Name: "example.com", RegistrarName: "BIND", Records: []*models.RecordConfig{soa()},
+		DNSProviderInstances: []*models.DNSProviderInstance{{ProviderBase: models.ProviderBase{Name: "soa", ProviderType: "FAKE-SOA"}}}}
+	res := NormalizeAndValidateConfig(&models.DNSConfig{Domains: []*models.DomainConfig{dc}})
+	if len(res.Errors) != 0 || len(res.Warnings) != 0 {
+		t.Errorf("Expected FAKE-SOA to accept SOA(), got %v %v", res.Errors, res.Warnings)
+	}
+	if got := dc.Records[0].GetTargetField(); got != "ns1.example.com. hostmaster.example.com. 0 3600 600 604800 1440" {
+		t.Errorf("Expected the names of the SOA to be fully qualified, got %s", got)
+	}
+
+	// Others manage the SOA, and warn.
+	dc = &models.DomainConfig{Name: "example.com", RegistrarName: "BIND", Records: []*models.RecordConfig{soa()},
+		DNSProviderInstances: []*models.DNSProviderInstance{{ProviderBase: models.ProviderBase{Name: "nosoa", ProviderType: "FAKE-NOSOA"}}}}
+	res = NormalizeAndValidateConfig(&models.DNSConfig{Domains: []*models.DomainConfig{dc}})
+	if len(res.Errors) != 0 || len(res.Warnings) != 1 || res.Warnings[0].Error() != "Domain example.com uses SOA(), but DNS provider type FAKE-NOSOA manages the SOA itself and ignores it" {
+		t.Errorf("Expected a warning about the ignored SOA(), got %v %v", res.Errors, res.Warnings)
+	}
+}
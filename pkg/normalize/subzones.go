@@ -0,0 +1,70 @@
+package normalize
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
+)
+
+// checkSubzones warns about domains of the configuration that are inside
+// another one, such as sub.example.com and example.com, when the parent
+// doesn't delegate to the child: there are no NS records for the child's
+// label in the parent, so resolvers never reach the child's zone. If the
+// child has NAMESERVER()s, the parent's NS records must name one of them;
+// the nameservers its DNS providers add aren't known until they are asked.
+// Only the nearest parent is checked, and not one that has NO_PURGE or
+// IGNOREs the label, as the delegation may be managed elsewhere.
+func checkSubzones(cfg *models.DNSConfig) (errs []error) {
+	for _, child := range cfg.Domains {
+		var parent *models.DomainConfig
+		for _, d := range cfg.Domains {
+			if strings.HasSuffix(child.Name, "."+d.Name) && (parent == nil || len(d.Name) > len(parent.Name)) {
+				parent = d
+			}
+		}
+		if parent == nil || parent.KeepUnknown {
+			continue
+		}
+		label := strings.TrimSuffix(child.Name, "."+parent.Name)
+		if parent.IgnoresLabel(label) {
+			continue
+		}
+		delegated := map[string]bool{}
+		for _, rec := range parent.Records {
+			if rec.Type == "NS" && rec.GetLabel() == label {
+				delegated[nameserverName(rec.GetTargetField())] = true
+			}
+		}
+		if len(delegated) == 0 {
+			errs = append(errs, Warning{errors.Errorf("%s is a domain of its own, but %s has no NS records for %s to delegate it",
+				child.Name, parent.Name, label), "missing-delegation", nil})
+			continue
+		}
+		if len(child.Nameservers) == 0 {
+			continue
+		}
+		var want []string
+		found := false
+		for _, ns := range child.Nameservers {
+			name := nameserverName(ns.Name)
+			found = found || delegated[name]
+			want = append(want, name)
+		}
+		if !found {
+			var got []string
+			for name := range delegated {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			errs = append(errs, Warning{errors.Errorf("%s delegates %s to %s, but its nameservers are %s",
+				parent.Name, child.Name, strings.Join(got, ", "), strings.Join(want, ", ")), "missing-delegation", nil})
+		}
+	}
+	return errs
+}
+
+func nameserverName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
@@ -0,0 +1,76 @@
+package normalize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+func TestCheckSubzones(t *testing.T) {
+	ns := func(label, target string) *models.RecordConfig {
+		return makeRC(label, "example.com", target, models.RecordConfig{Type: "NS", Metadata: map[string]string{}})
+	}
+	for _, tst := range []struct {
+		desc        string
+		records     []*models.RecordConfig
+		nameservers []string
+		want        []string
+	}{
+		{"delegated", []*models.RecordConfig{
+			ns("sub", "ns1.example.net."),
+			ns("sub", "ns2.example.net."),
+		}, nil, nil},
+		{"delegated to its nameservers", []*models.RecordConfig{
+			ns("sub", "NS1.example.net."),
+		}, []string{"ns1.example.net.", "ns2.example.net."}, nil},
+		{"missing delegation", []*models.RecordConfig{
+			ns("other", "ns1.example.net."),
+		}, nil, []string{"sub.example.com is a domain of its own, but example.com has no NS records for sub to delegate it"}},
+		{"delegated elsewhere", []*models.RecordConfig{
+			ns("sub", "ns2.example.org."),
+			ns("sub", "ns1.example.org."),
+		}, []string{"ns1.example.net."}, []string{"example.com delegates sub.example.com to ns1.example.org, ns2.example.org, but its nameservers are ns1.example.net"}},
+	} {
+		cfg := &models.DNSConfig{Domains: []*models.DomainConfig{
+			{Name: "example.com", Records: tst.records},
+			{Name: "sub.example.com", Nameservers: models.StringsToNameservers(tst.nameservers)},
+			{Name: "notexample.com"},
+		}}
+		errs := checkSubzones(cfg)
+		if len(errs) != len(tst.want) {
+			t.Errorf("%s: expected %d problems, got %v", tst.desc, len(tst.want), errs)
+			continue
+		}
+		for i, err := range errs {
+			if err.Error() != tst.want[i] {
+				t.Errorf("%s: expected %q, got %q", tst.desc, tst.want[i], err)
+			}
+		}
+	}
+
+	// Only the nearest parent delegates, and NO_PURGE or IGNORE() may leave
+	// the delegation to someone else.
+	cfg := &models.DNSConfig{Domains: []*models.DomainConfig{
+		{Name: "example.com"},
+		{Name: "b.example.com", Records: []*models.RecordConfig{makeRC("a", "b.example.com", "ns1.example.net.", models.RecordConfig{Type: "NS"})}},
+		{Name: "a.b.example.com"},
+		{Name: "ignored.example.com"},
+		{Name: "kept.example.net"},
+		{Name: "example.net", KeepUnknown: true},
+	}}
+	cfg.Domains[0].IgnoredLabels = []string{"ignored"}
+	errs := checkSubzones(cfg)
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "b.example.com is a domain of its own, but example.com has no NS records") {
+		t.Errorf("Expected a warning about b.example.com only, got %v", errs)
+	}
+
+	// The check is part of the validation.
+	cfg = &models.DNSConfig{Domains: []*models.DomainConfig{
+		{Name: "example.com", RegistrarName: "BIND"},
+		{Name: "sub.example.com", RegistrarName: "BIND"},
+	}}
+	if res := NormalizeAndValidateConfig(cfg); len(res.Errors) != 0 || len(res.Warnings) != 1 {
+		t.Errorf("Expected a warning about sub.example.com, got %v %v", res.Errors, res.Warnings)
+	}
+}
@@ -0,0 +1,42 @@
+package normalize
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
+)
+
+// maxTTLJitter is the largest jitter ttl_jitter accepts, in percent.
+const maxTTLJitter = 50
+
+// parseTTLJitter returns the percentage of the ttl_jitter of a domain, or 0
+// if it has none.
+func parseTTLJitter(domain *models.DomainConfig) (int, error) {
+	v, ok := domain.Metadata[models.MetaTTLJitter]
+	if !ok {
+		return 0, nil
+	}
+	pct, err := strconv.Atoi(strings.TrimSuffix(v, "%"))
+	if err != nil || pct < 0 || pct > maxTTLJitter {
+		return 0, errors.Errorf("%s: %s must be a percentage from 0 to %d, not %q", domain.Name, models.MetaTTLJitter, maxTTLJitter, v)
+	}
+	return pct, nil
+}
+
+// jitterTTL moves ttl by up to pct percent, up or down. The amount is
+// derived from name (the name and type of the record set), so that every
+// run gives the same TTL, and all the records of a set keep the same TTL.
+func jitterTTL(ttl uint32, pct int, name string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(name)))
+	// A factor from -1000 to 1000 thousandths.
+	factor := int64(h.Sum32()%2001) - 1000
+	t := int64(ttl) + int64(ttl)*int64(pct)*factor/100000
+	if t < 1 {
+		t = 1
+	}
+	return uint32(t)
+}
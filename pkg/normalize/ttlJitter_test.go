@@ -0,0 +1,83 @@
+package normalize
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+func TestJitterTTL(t *testing.T) {
+	seen := map[uint32]bool{}
+	for i := 0; i < 1000; i++ {
+		name := fmt.Sprintf("host%d.example.com A", i)
+		ttl := jitterTTL(3600, 10, name)
+		if ttl < 3240 || ttl > 3960 {
+			t.Fatalf("%s: %d is more than 10%% away from 3600", name, ttl)
+		}
+		if again := jitterTTL(3600, 10, name); again != ttl {
+			t.Fatalf("%s: expected the same TTL on every run, got %d then %d", name, ttl, again)
+		}
+		seen[ttl] = true
+	}
+	if len(seen) < 100 {
+		t.Errorf("Expected the TTLs to be spread out, got only %d different ones", len(seen))
+	}
+	if got := jitterTTL(3600, 10, "WWW.example.com A"); got != jitterTTL(3600, 10, "www.example.com A") {
+		t.Errorf("Expected names to be compared without case")
+	}
+	if got := jitterTTL(1, 50, "www.example.com A"); got != 1 {
+		t.Errorf("Expected a TTL of at least 1, got %d", got)
+	}
+	if got := jitterTTL(3600, 0, "www.example.com A"); got != 3600 {
+		t.Errorf("Expected no jitter at 0%%, got %d", got)
+	}
+}
+
+func TestTTLJitterMetadata(t *testing.T) {
+	records := func() []*models.RecordConfig {
+		return []*models.RecordConfig{
+			makeRC("www", "example.com", "10.0.0.1", models.RecordConfig{Type: "A", TTL: 3600}),
+			makeRC("www", "example.com", "10.0.0.2", models.RecordConfig{Type: "A", TTL: 3600}),
+			makeRC("mail", "example.com", "10.0.0.3", models.RecordConfig{Type: "A"}),
+			makeRC("auto", "example.com", "10.0.0.4", models.RecordConfig{Type: "A", Metadata: map[string]string{models.MetaAutoTTL: "true"}}),
+		}
+	}
+	validate := func(jitter string) (*models.DomainConfig, Result) {
+		dc := &models.DomainConfig{
+			Name:          "example.com",
+			RegistrarName: "BIND",
+			Records:       records(),
+			Metadata:      map[string]string{models.MetaTTLJitter: jitter},
+		}
+		return dc, NormalizeAndValidateConfig(&models.DNSConfig{Domains: []*models.DomainConfig{dc}})
+	}
+
+	dc, res := validate("20%")
+	if len(res.Errors) != 0 {
+		t.Fatal(res.Errors)
+	}
+	www, mail, auto := dc.Records[0].TTL, dc.Records[2].TTL, dc.Records[3].TTL
+	if www == 3600 || www < 2880 || www > 4320 {
+		t.Errorf("Expected www to be jittered within 20%% of 3600, got %d", www)
+	}
+	if dc.Records[1].TTL != www {
+		t.Errorf("Expected the records of a set to keep the same TTL, got %d and %d", www, dc.Records[1].TTL)
+	}
+	if mail == models.DefaultTTL || mail < 240 || mail > 360 {
+		t.Errorf("Expected the default TTL to be jittered, got %d", mail)
+	}
+	if auto != models.DefaultTTL {
+		t.Errorf("Expected TTL('auto') to be left alone, got %d", auto)
+	}
+	// The domain compiles to the same TTLs every time.
+	if again, _ := validate("20%"); again.Records[0].TTL != www || again.Records[2].TTL != mail {
+		t.Errorf("Expected the same TTLs on every run")
+	}
+
+	for _, bad := range []string{"51", "-5", "ten", "10.5"} {
+		if _, res := validate(bad); len(res.Errors) != 1 {
+			t.Errorf("%q: expected an error, got %v", bad, res.Errors)
+		}
+	}
+}
@@ -1,7 +1,12 @@
 package normalize
 
 import (
+	"encoding/hex"
 	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/StackExchange/dnscontrol/models"
@@ -14,7 +19,7 @@ import (
 
 // Returns false if target does not validate.
 func checkIPv4(label string) error {
-	if net.ParseIP(label).To4() == nil {
+	if net.ParseIP(label).To4() == nil || strings.Contains(label, ":") {
 		return errors.Errorf("WARNING: target (%v) is not an IPv4 address", label)
 	}
 	return nil
@@ -22,7 +27,7 @@ func checkIPv4(label string) error {
 
 // Returns false if target does not validate.
 func checkIPv6(label string) error {
-	if net.ParseIP(label).To16() == nil {
+	if net.ParseIP(label) == nil || !strings.Contains(label, ":") {
 		return errors.Errorf("WARNING: target (%v) is not an IPv6 address", label)
 	}
 	return nil
@@ -43,6 +48,57 @@ func checkTarget(target string) error {
 	if strings.ContainsRune(target, '.') && target[len(target)-1] != '.' {
 		return errors.Errorf("target (%v) must end with a (.) [https://stackexchange.github.io/dnscontrol/why-the-dot]", target)
 	}
+	// "." alone is the root, the target of a null MX or SRV record.
+	if target == "." {
+		return nil
+	}
+	if len(target) > 254 {
+		return errors.Errorf("target (%v) is longer than 253 characters", target)
+	}
+	for _, l := range strings.Split(strings.TrimSuffix(target, "."), ".") {
+		if l == "" {
+			return errors.Errorf("target (%v) has an empty label", target)
+		}
+		if len(l) > 63 {
+			return errors.Errorf("target (%v) has a label longer than 63 characters", target)
+		}
+	}
+	return nil
+}
+
+// checkCAA validates the value of a CAA record (RFC 8659): a domain name,
+// optionally followed by parameters, or ";" alone for issue and issuewild,
+// a mailto:, http:// or https:// URL for iodef.
+func checkCAA(tag, value string) error {
+	switch tag {
+	case "issue", "issuewild":
+		name := strings.TrimSpace(strings.SplitN(value, ";", 2)[0])
+		if name == "" {
+			return nil
+		}
+		if strings.HasSuffix(name, ".") || checkTarget(name+".") != nil {
+			return errors.Errorf("CAA %s value %q must start with the domain name of a CA", tag, value)
+		}
+	case "iodef":
+		u, err := url.Parse(value)
+		mail := u != nil && u.Scheme == "mailto" && u.Opaque != ""
+		web := u != nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+		if err != nil || !(mail || web) {
+			return errors.Errorf("CAA iodef value %q must be a mailto:, http:// or https:// URL", value)
+		}
+	}
+	return nil
+}
+
+// checkTLSA validates the certificate association data of a TLSA record: hex,
+// of the length of a SHA-256 or SHA-512 hash for matching types 1 and 2.
+func checkTLSA(matchingType uint8, data string) error {
+	if _, err := hex.DecodeString(data); err != nil || data == "" {
+		return errors.Errorf("TLSA data %q is not hexadecimal", data)
+	}
+	if want := map[uint8]int{1: 64, 2: 128}[matchingType]; want != 0 && len(data) != want {
+		return errors.Errorf("TLSA data of matching type %d must be %d hex digits, not %d", matchingType, want, len(data))
+	}
 	return nil
 }
 
@@ -61,6 +117,10 @@ func validateRecordTypes(rec *models.RecordConfig, domain string, pTypes []strin
 		"NS":               true,
 		"PTR":              true,
 		"ALIAS":            false,
+		"URL":              true,
+		"URL301":           true,
+		"FRAME":            true,
+		"SOA":              true,
 	}
 	_, ok := validTypes[rec.Type]
 	if !ok {
@@ -117,12 +177,54 @@ func checkLabel(label string, rType string, domain string, meta map[string]strin
 	}
 	// underscores are warnings
 	if strings.ContainsRune(label, '_') {
-		return Warning{errors.Errorf("label %s.%s contains an underscore", label, domain)}
+		return Warning{errors.Errorf("label %s.%s contains an underscore", label, domain), "underscore", nil}
 	}
 
 	return nil
 }
 
+// srvProtos are the protocols an SRV name may use.
+var srvProtos = map[string]bool{"_tcp": true, "_udp": true, "_sctp": true, "_dccp": true, "_tls": true}
+
+// srvService matches an RFC 6335 service name, with its underscore.
+var srvService = regexp.MustCompile(`^_[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// checkSRVName warns if the label of an SRV record is not
+// _service._proto[.name] with a known protocol, which is usually an SRV
+// record declared at the wrong name.
+func checkSRVName(label, domain string) error {
+	parts := strings.SplitN(strings.ToLower(label), ".", 3)
+	if len(parts) < 2 || !srvService.MatchString(parts[0]) || len(parts[0]) > 16 || strings.Contains(parts[0], "--") {
+		return Warning{errors.Errorf("SRV %s.%s: the name should be _service._proto, as in _sip._tcp", label, domain), "srv-name", nil}
+	}
+	if !srvProtos[parts[1]] {
+		return Warning{errors.Errorf("SRV %s.%s: %s is not a known protocol (_tcp, _udp, _sctp, _dccp or _tls)", label, domain, parts[1]), "srv-name", nil}
+	}
+	return nil
+}
+
+// checkWildcard returns an error if label has a wildcard anywhere but as the
+// whole leftmost label.
+func checkWildcard(label, domain string) error {
+	if !strings.Contains(label, "*") {
+		return nil
+	}
+	if (label != "*" && !strings.HasPrefix(label, "*.")) || strings.Contains(label[1:], "*") {
+		return errors.Errorf("label %s.%s: a wildcard (*) is only allowed as the whole leftmost label", label, domain)
+	}
+	return nil
+}
+
+// checkURLTarget checks the target of a web forwarding record, which is the
+// URL the web site is sent to.
+func checkURLTarget(target string) error {
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("target (%v) is not an http:// or https:// URL", target)
+	}
+	return nil
+}
+
 // checkTargets returns true if rec.Target is valid for the rec.Type.
 func checkTargets(rec *models.RecordConfig, domain string) (errs []error) {
 	label := rec.GetLabel()
@@ -130,8 +232,8 @@ func checkTargets(rec *models.RecordConfig, domain string) (errs []error) {
 	check := func(e error) {
 		if e != nil {
 			err := errors.Errorf("In %s %s.%s: %s", rec.Type, rec.GetLabel(), domain, e.Error())
-			if _, ok := e.(Warning); ok {
-				err = Warning{err}
+			if w, ok := e.(Warning); ok {
+				err = Warning{err, w.ID, rec}
 			}
 			errs = append(errs, err)
 		}
@@ -159,7 +261,15 @@ func checkTargets(rec *models.RecordConfig, domain string) (errs []error) {
 		check(checkTarget(target))
 	case "SRV":
 		check(checkTarget(target))
-	case "TXT", "IMPORT_TRANSFORM", "CAA", "TLSA":
+	case "URL", "URL301", "FRAME":
+		check(checkURLTarget(target))
+	case "SOA":
+		check(checkSOA(label, target))
+	case "CAA":
+		check(checkCAA(rec.CaaTag, target))
+	case "TLSA":
+		check(checkTLSA(rec.TlsaMatchingType, target))
+	case "TXT", "IMPORT_TRANSFORM":
 	default:
 		if rec.Metadata["orig_custom_type"] != "" {
 			// it is a valid custom type. We perform no validation on target
@@ -216,7 +326,7 @@ func importTransform(srcDomain, dstDomain *models.DomainConfig, transforms []tra
 			r := newRec()
 			r.SetTarget(transformCNAME(r.GetTargetField(), srcDomain.Name, dstDomain.Name))
 			dstDomain.Records = append(dstDomain.Records, r)
-		case "MX", "NS", "SRV", "TXT", "CAA", "TLSA":
+		case "MX", "NS", "SRV", "TXT", "CAA", "TLSA", "SOA":
 			// Not imported.
 			continue
 		default:
@@ -238,28 +348,164 @@ func deleteImportTransformRecords(domain *models.DomainConfig) {
 }
 
 // Warning is a wrapper around error that can be used to indicate it should not
-// stop execution, but is still likely a problem.
+// stop execution, but is still likely a problem. ID names the kind of
+// warning. If Record is set, the warning is about that record and is dropped
+// if the record has IGNORE_WARNING(ID).
 type Warning struct {
 	error
+	ID     string
+	Record *models.RecordConfig
+}
+
+// WarningIDs are the IDs of the warnings about a single record, that
+// IGNORE_WARNING() accepts.
+var WarningIDs = []string{
+	"auto-ttl",
+	"cname-target",
+	"defaults-override",
+	"dkim-key",
+	"dkim-key-size",
+	"dkim-rotation",
+	"dmarc-report",
+	"dmarc-syntax",
+	"duplicate",
+	"min-ttl",
+	"mx-cname",
+	"ptr-forward",
+	"soa-ignored",
+	"spf-length",
+	"spf-lookups",
+	"spf-syntax",
+	"srv-name",
+	"underscore",
+}
+
+// ignoredWarnings returns the warning IDs of the record's IGNORE_WARNING().
+func ignoredWarnings(rec *models.RecordConfig) []string {
+	if rec.Metadata["ignore_warnings"] == "" {
+		return nil
+	}
+	return strings.Split(rec.Metadata["ignore_warnings"], ",")
+}
+
+// checkIgnoredWarnings returns an error if the record ignores an unknown
+// warning, most likely a typo that would leave the warning in place.
+func checkIgnoredWarnings(rec *models.RecordConfig) error {
+	for _, id := range ignoredWarnings(rec) {
+		known := false
+		for _, k := range WarningIDs {
+			known = known || id == k
+		}
+		if !known {
+			return errors.Errorf("%s %s: IGNORE_WARNING(%q) is not a warning ID (must be one of %s)",
+				rec.Type, rec.GetLabelFQDN(), id, strings.Join(WarningIDs, ", "))
+		}
+	}
+	return nil
+}
+
+// ignored returns true if the warning is about a record that ignores it.
+func (w Warning) ignored() bool {
+	if w.Record == nil {
+		return false
+	}
+	for _, id := range ignoredWarnings(w.Record) {
+		if id == w.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// Result is the outcome of NormalizeAndValidateConfig. Errors mean that the
+// configuration can't be used. Warnings point at likely problems, but don't
+// stop anything unless validation is strict.
+type Result struct {
+	Errors   []error
+	Warnings []error
+}
+
+// Failed returns true if there are errors or, if strict is set, warnings.
+func (r Result) Failed(strict bool) bool {
+	return len(r.Errors) > 0 || (strict && len(r.Warnings) > 0)
 }
 
 // NormalizeAndValidateConfig performs and normalization and/or validation of the IR.
-func NormalizeAndValidateConfig(config *models.DNSConfig) (errs []error) {
+func NormalizeAndValidateConfig(config *models.DNSConfig) Result {
+	var r Result
+	r.Add(normalizeAndValidate(config)...)
+	return r
+}
+
+// Add sorts errs into the errors and warnings of r.
+func (r *Result) Add(errs ...error) {
+	for _, err := range errs {
+		if w, ok := err.(Warning); ok {
+			if !w.ignored() {
+				r.Warnings = append(r.Warnings, err)
+			}
+		} else {
+			r.Errors = append(r.Errors, err)
+		}
+	}
+}
+
+func normalizeAndValidate(config *models.DNSConfig) (errs []error) {
 	for _, domain := range config.Domains {
 		pTypes := []string{}
-		txtMultiDissenters := []string{}
+		autoTTLDissenters := []string{}
+		knownSettings := map[string]bool{}
 		for _, provider := range domain.DNSProviderInstances {
 			pType := provider.ProviderType
+			for _, s := range providers.ProviderZoneSettings(pType) {
+				knownSettings[s] = true
+			}
+			// The provider's own TTL for apex NS records replaces ns_ttl.
+			if t := providers.ProviderApexTTL(pType); t != 0 {
+				if nsTTL := domain.Metadata["ns_ttl"]; nsTTL != "" && nsTTL != strconv.FormatUint(uint64(t), 10) {
+					errs = append(errs, Warning{errors.Errorf("ns_ttl %s of %s is not used by %s, which always uses %d for the apex NS records",
+						nsTTL, domain.Name, provider.Name, t), "ns-ttl", nil})
+				}
+			}
 			// If NO_PURGE is in use, make sure this *isn't* a provider that *doesn't* support NO_PURGE.
 			if domain.KeepUnknown && providers.ProviderHasCabability(pType, providers.CantUseNOPURGE) {
 				errs = append(errs, errors.Errorf("%s uses NO_PURGE which is not supported by %s(%s)", domain.Name, provider.Name, pType))
 			}
 
-			// Record if any providers do not support TXTMulti:
-			if !providers.ProviderHasCabability(pType, providers.CanUseTXTMulti) {
-				txtMultiDissenters = append(txtMultiDissenters, provider.Name)
+			// Record if any providers do not have an automatic TTL:
+			if !providers.ProviderHasCabability(pType, providers.CanUseAutoTTL) {
+				autoTTLDissenters = append(autoTTLDissenters, provider.Name)
+			}
+		}
+
+		switch order := domain.Metadata[models.MetaCorrectionOrder]; order {
+		case "", models.CreatesFirst, models.DeletesFirst:
+		default:
+			errs = append(errs, errors.Errorf("%s: %s must be %s or %s, not %q", domain.Name, models.MetaCorrectionOrder, models.CreatesFirst, models.DeletesFirst, order))
+		}
+
+		jitter, err := parseTTLJitter(domain)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		// Every zone setting must be managed by at least one of the providers.
+		var unknownSettings []string
+		for name := range domain.ProviderMeta {
+			if !knownSettings[name] {
+				unknownSettings = append(unknownSettings, name)
 			}
 		}
+		sort.Strings(unknownSettings)
+		for _, name := range unknownSettings {
+			errs = append(errs, errors.Errorf("%s: providerMeta %q is not a zone setting of any of its DNS providers", domain.Name, name))
+		}
+
+		// REV() names networks smaller than a /24 the RFC 2317 way.
+		if strings.Contains(domain.Name, "/") && strings.HasSuffix(domain.Name, ".in-addr.arpa") {
+			errs = append(errs, Warning{errors.Errorf("%s is a classless (RFC 2317) reverse zone. It only works if %s delegates it, with a CNAME for each address",
+				domain.Name, domain.Name[strings.Index(domain.Name, ".")+1:]), "rfc2317", nil})
+		}
 
 		// Normalize Nameservers.
 		for _, ns := range domain.Nameservers {
@@ -272,11 +518,38 @@ func NormalizeAndValidateConfig(config *models.DNSConfig) (errs []error) {
 			if rec.TTL == 0 {
 				rec.TTL = models.DefaultTTL
 			}
+			if jitter != 0 && rec.Metadata[models.MetaAutoTTL] != "true" {
+				rec.TTL = jitterTTL(rec.TTL, jitter, rec.GetLabel()+"."+domain.Name+" "+rec.Type)
+			}
+			if rec.Metadata[models.MetaAutoTTL] == "true" && len(autoTTLDissenters) != 0 {
+				errs = append(errs, Warning{errors.Errorf("TTL auto for %s %s.%s is not supported by %s. Using %d",
+					rec.Type, rec.GetLabel(), domain.Name, strings.Join(autoTTLDissenters, ","), rec.TTL), "auto-ttl", rec})
+			}
 			// Validate the unmodified inputs:
 			if err := validateRecordTypes(rec, domain.Name, pTypes); err != nil {
 				errs = append(errs, err)
 			}
 			if err := checkLabel(rec.GetLabel(), rec.Type, domain.Name, rec.Metadata); err != nil {
+				if w, ok := err.(Warning); ok {
+					w.Record = rec
+					err = w
+				}
+				errs = append(errs, err)
+			}
+			if rec.Type == "SRV" {
+				if err := checkSRVName(rec.GetLabel(), domain.Name); err != nil {
+					w := err.(Warning)
+					w.Record = rec
+					errs = append(errs, w)
+				}
+			}
+			if err := checkIgnoredWarnings(rec); err != nil {
+				errs = append(errs, err)
+			}
+			if n := rec.Metadata[models.MetaReplacedDefaults]; n != "" {
+				errs = append(errs, Warning{errors.Errorf("%s %s.%s replaces %s DEFAULTS record(s) of its name and type", rec.Type, rec.GetLabel(), domain.Name, n), "defaults-override", rec})
+			}
+			if err := checkWildcard(rec.GetLabel(), domain.Name); err != nil {
 				errs = append(errs, err)
 			}
 			if errs2 := checkTargets(rec, domain.Name); errs2 != nil {
@@ -288,6 +561,8 @@ func NormalizeAndValidateConfig(config *models.DNSConfig) (errs []error) {
 				rec.SetTarget(dnsutil.AddOrigin(rec.GetTargetField(), domain.Name+"."))
 			} else if rec.Type == "A" || rec.Type == "AAAA" {
 				rec.SetTarget(net.ParseIP(rec.GetTargetField()).String())
+			} else if rec.Type == "SOA" {
+				rec.SetTarget(canonicalSOA(rec.GetTargetField(), domain.Name))
 			} else if rec.Type == "PTR" {
 				var err error
 				var name string
@@ -312,12 +587,6 @@ func NormalizeAndValidateConfig(config *models.DNSConfig) (errs []error) {
 					errs = append(errs, errors.Errorf("TLSA MatchingType %d is invalid in record %s (domain %s)",
 						rec.TlsaMatchingType, rec.GetLabel(), domain.Name))
 				}
-			} else if rec.Type == "TXT" && len(txtMultiDissenters) != 0 && len(rec.TxtStrings) > 1 {
-				// There are providers that  don't support TXTMulti yet there is
-				// a TXT record with multiple strings:
-				errs = append(errs,
-					errors.Errorf("TXT records with multiple strings (label %v domain: %v) not supported by %s",
-						rec.GetLabel(), domain.Name, strings.Join(txtMultiDissenters, ",")))
 			}
 
 			// Populate FQDN:
@@ -330,6 +599,9 @@ func NormalizeAndValidateConfig(config *models.DNSConfig) (errs []error) {
 		errs = append(errs, ers...)
 	}
 
+	// Subdomains that are domains of their own
+	errs = append(errs, checkSubzones(config)...)
+
 	// Process IMPORT_TRANSFORM
 	for _, domain := range config.Domains {
 		for _, rec := range domain.Records {
@@ -359,15 +631,16 @@ func NormalizeAndValidateConfig(config *models.DNSConfig) (errs []error) {
 
 	// Check that CNAMES don't have to co-exist with any other records
 	for _, d := range config.Domains {
+		errs = append(errs, checkDuplicates(d)...)
 		errs = append(errs, checkCNAMEs(d)...)
+		errs = append(errs, checkGeo(d)...)
+		errs = append(errs, checkDKIM(d)...)
+		errs = append(errs, checkGlue(d)...)
 	}
 
-	// Check that if any aliases / ptr / etc.. are used in a domain, every provider for that domain supports them
+	// Check the records against the capabilities and limits of every provider of the domain
 	for _, d := range config.Domains {
-		err := checkProviderCapabilities(d)
-		if err != nil {
-			errs = append(errs, err)
-		}
+		errs = append(errs, checkProviderLimits(d)...)
 	}
 
 	return errs
@@ -388,38 +661,56 @@ func checkCNAMEs(dc *models.DomainConfig) (errs []error) {
 			errs = append(errs, errors.Errorf("Cannot have CNAME and %s record with same name: %s", r.Type, r.GetLabelFQDN()))
 		}
 	}
-	return
+	return append(errs, MXCNAMEs(dc)...)
 }
 
-func checkProviderCapabilities(dc *models.DomainConfig) error {
-	types := []struct {
-		rType string
-		cap   providers.Capability
-	}{
-		{"ALIAS", providers.CanUseAlias},
-		{"PTR", providers.CanUsePTR},
-		{"SRV", providers.CanUseSRV},
-		{"CAA", providers.CanUseCAA},
-		{"TLSA", providers.CanUseTLSA},
-	}
-	for _, ty := range types {
-		hasAny := false
-		for _, r := range dc.Records {
-			if r.Type == ty.rType {
-				hasAny = true
-				break
+// MXCNAMEs returns the mx-cname warnings of dc: RFC 2181 section 10.3 says
+// the target of an MX must not be an alias. The lint rule of the same name
+// uses it too.
+func MXCNAMEs(dc *models.DomainConfig) (errs []error) {
+	cnames := map[string]bool{}
+	for _, r := range dc.Records {
+		if r.Type == "CNAME" {
+			cnames[r.GetLabel()] = true
+		}
+	}
+	for _, r := range dc.Records {
+		if r.Type == "MX" {
+			target := strings.TrimSuffix(r.GetTargetField(), ".")
+			if label := strings.TrimSuffix(target, "."+dc.Name); label != target && cnames[label] {
+				errs = append(errs, Warning{errors.Errorf("MX %s points to %s, which is a CNAME", r.GetLabelFQDN(), target), "mx-cname", r})
 			}
 		}
-		if !hasAny {
+	}
+	return errs
+}
+
+// checkDuplicates warns about records that are declared more than once with
+// the same name, type and value, which is easy to do in generated
+// configurations. Providers either reject them or keep only one, which shows
+// as a change on every run, so only the first is kept. GEO() records for
+// other locations are not duplicates. Records with the same name and type
+// but other values are fine.
+func checkDuplicates(dc *models.DomainConfig) (errs []error) {
+	seen := map[string]bool{}
+	records := dc.Records[:0]
+	for _, r := range dc.Records {
+		// R53_ALIAS keeps part of its value outside of the target.
+		if r.Type == "R53_ALIAS" {
+			records = append(records, r)
 			continue
 		}
-		for _, provider := range dc.DNSProviderInstances {
-			if !providers.ProviderHasCabability(provider.ProviderType, ty.cap) {
-				return errors.Errorf("Domain %s uses %s records, but DNS provider type %s does not support them", dc.Name, ty.rType, provider.ProviderType)
-			}
+		k := r.GetLabelFQDN() + " " + r.Type + " " + r.GetTargetCombined()
+		geo := r.Metadata[models.MetaGeo]
+		if seen[k+" "+geo] {
+			errs = append(errs, Warning{errors.Errorf("%s is declared more than once, only the first is kept", k), "duplicate", r})
+			continue
 		}
+		seen[k+" "+geo] = true
+		records = append(records, r)
 	}
-	return nil
+	dc.Records = records
+	return errs
 }
 
 func applyRecordTransforms(domain *models.DomainConfig) error {
@@ -4,8 +4,11 @@ import (
 	"testing"
 
 	"fmt"
+	"reflect"
+	"strings"
 
 	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers"
 )
 
 func TestCheckLabel(t *testing.T) {
@@ -43,6 +46,38 @@ func TestCheckLabel(t *testing.T) {
 	}
 }
 
+func TestCheckSRVName(t *testing.T) {
+	for _, tst := range []struct {
+		label   string
+		isError bool
+	}{
+		{"_sip._tcp", false},
+		{"_sip._udp", false},
+		{"_xmpp-client._tcp", false},
+		{"_sips._tls.eu", false},
+		{"_LDAP._TCP.dc", false},
+		{"_sip._tcp.a.b", false},
+		{"@", true},
+		{"sip", true},
+		{"sip._tcp", true},
+		{"_sip", true},
+		{"_sip.tcp", true},
+		{"_sip._tpc", true},
+		{"_tcp._sip", true},
+		{"_-sip._tcp", true},
+		{"_si--p._tcp", true},
+		{"_averyveryverylongname._tcp", true},
+	} {
+		t.Run(tst.label, func(t *testing.T) {
+			err := checkSRVName(tst.label, "example.com")
+			checkError(t, err, tst.isError, tst.label)
+			if _, ok := err.(Warning); err != nil && !ok {
+				t.Errorf("expected a warning, got %v", err)
+			}
+		})
+	}
+}
+
 func checkError(t *testing.T, err error, shouldError bool, experiment string) {
 	if err != nil && !shouldError {
 		t.Errorf("%v: Error (%v)\n", experiment, err)
@@ -91,6 +126,70 @@ func Test_assert_valid_target(t *testing.T) {
 	}
 }
 
+func TestCheckTargets(t *testing.T) {
+	sha256 := strings.Repeat("0f", 32)
+	long := strings.Repeat("a", 64)
+	for _, tst := range []struct {
+		rType, target string
+		rc            models.RecordConfig
+		valid         bool
+	}{
+		{"A", "1.2.3.4", models.RecordConfig{}, true},
+		{"A", "1.2.3.256", models.RecordConfig{}, false},
+		{"A", "2001:db8::1", models.RecordConfig{}, false},
+		{"A", "::ffff:1.2.3.4", models.RecordConfig{}, false},
+		{"AAAA", "2001:db8::1", models.RecordConfig{}, true},
+		{"AAAA", "::ffff:1.2.3.4", models.RecordConfig{}, true},
+		{"AAAA", "1.2.3.4", models.RecordConfig{}, false},
+		{"AAAA", "2001:db8::g", models.RecordConfig{}, false},
+		{"CNAME", "www.example.net.", models.RecordConfig{}, true},
+		{"CNAME", "www", models.RecordConfig{}, true},
+		{"CNAME", "www..example.net.", models.RecordConfig{}, false},
+		{"CNAME", long + ".example.net.", models.RecordConfig{}, false},
+		{"CNAME", strings.Repeat("abcdefgh.", 29) + "net.", models.RecordConfig{}, false},
+		{"MX", "mx.example.net.", models.RecordConfig{MxPreference: 10}, true},
+		{"MX", ".", models.RecordConfig{}, true},
+		{"MX", "mx.example.net", models.RecordConfig{MxPreference: 10}, false},
+		{"NS", "ns1.example.net.", models.RecordConfig{}, true},
+		{"NS", "ns1 .example.net.", models.RecordConfig{}, false},
+		{"SRV", "sip.example.net.", models.RecordConfig{SrvPort: 5060}, true},
+		{"SRV", ".", models.RecordConfig{}, true},
+		{"SRV", ".example.net.", models.RecordConfig{SrvPort: 5060}, false},
+		{"PTR", "host.example.net.", models.RecordConfig{}, true},
+		{"PTR", "host.example.net/", models.RecordConfig{}, false},
+		{"CAA", "letsencrypt.org", models.RecordConfig{CaaTag: "issue"}, true},
+		{"CAA", "letsencrypt.org; validationmethods=dns-01", models.RecordConfig{CaaTag: "issue"}, true},
+		{"CAA", ";", models.RecordConfig{CaaTag: "issuewild"}, true},
+		{"CAA", "lets encrypt", models.RecordConfig{CaaTag: "issue"}, false},
+		{"CAA", "letsencrypt.org.", models.RecordConfig{CaaTag: "issue"}, false},
+		{"CAA", "mailto:security@example.com", models.RecordConfig{CaaTag: "iodef"}, true},
+		{"CAA", "https://example.com/caa", models.RecordConfig{CaaTag: "iodef"}, true},
+		{"CAA", "security@example.com", models.RecordConfig{CaaTag: "iodef"}, false},
+		{"CAA", "ftp://example.com/", models.RecordConfig{CaaTag: "iodef"}, false},
+		{"TLSA", sha256, models.RecordConfig{TlsaUsage: 3, TlsaSelector: 1, TlsaMatchingType: 1}, true},
+		{"TLSA", sha256 + sha256, models.RecordConfig{TlsaUsage: 3, TlsaSelector: 1, TlsaMatchingType: 2}, true},
+		{"TLSA", "3082", models.RecordConfig{TlsaUsage: 3, TlsaSelector: 1, TlsaMatchingType: 0}, true},
+		{"TLSA", sha256[:62], models.RecordConfig{TlsaUsage: 3, TlsaSelector: 1, TlsaMatchingType: 1}, false},
+		{"TLSA", "not hex", models.RecordConfig{TlsaUsage: 3, TlsaSelector: 1, TlsaMatchingType: 0}, false},
+		{"TXT", "anything goes", models.RecordConfig{}, true},
+	} {
+		tst.rc.Type = tst.rType
+		rec := makeRC("test", "example.com", tst.target, tst.rc)
+		errs := checkTargets(rec, "example.com")
+		if tst.valid && len(errs) != 0 {
+			t.Errorf("%s %q: expected no error, got %v", tst.rType, tst.target, errs)
+		}
+		if !tst.valid && len(errs) == 0 {
+			t.Errorf("%s %q: expected an error", tst.rType, tst.target)
+		}
+		for _, err := range errs {
+			if !strings.Contains(err.Error(), "In "+tst.rType+" test.example.com") {
+				t.Errorf("%s %q: expected the error to name the record, got %q", tst.rType, tst.target, err)
+			}
+		}
+	}
+}
+
 func Test_transform_cname(t *testing.T) {
 	var tests = []struct {
 		experiment string
@@ -195,6 +294,139 @@ func TestCNAMEMutex(t *testing.T) {
 	}
 }
 
+func TestCheckDuplicates(t *testing.T) {
+	mx := func(label string, prio uint16, target string) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: "MX"}
+		rc.SetLabel(label, "example.com")
+		rc.SetTargetMX(prio, target)
+		return rc
+	}
+	srv := func(prio, weight, port uint16) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: "SRV"}
+		rc.SetLabel("_sip._tcp", "example.com")
+		rc.SetTargetSRV(prio, weight, port, "sip.example.com.")
+		return rc
+	}
+	a := func(label, ip, geo string) *models.RecordConfig {
+		rc := makeRC(label, "example.com", ip, models.RecordConfig{Type: "A", Metadata: map[string]string{}})
+		if geo != "" {
+			rc.Metadata[models.MetaGeo] = geo
+		}
+		return rc
+	}
+	txt := func(txts ...string) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: "TXT"}
+		rc.SetLabel("@", "example.com")
+		rc.SetTargetTXTs(txts)
+		return rc
+	}
+	tests := []struct {
+		desc     string
+		records  []*models.RecordConfig
+		warnings int
+	}{
+		{"same priority, other targets", []*models.RecordConfig{mx("@", 5, "a.example.com."), mx("@", 5, "b.example.com.")}, 0},
+		{"other priority, same target", []*models.RecordConfig{mx("@", 5, "a.example.com."), mx("@", 10, "a.example.com.")}, 0},
+		{"other label", []*models.RecordConfig{mx("@", 5, "a.example.com."), mx("www", 5, "a.example.com.")}, 0},
+		{"duplicate MX", []*models.RecordConfig{mx("@", 5, "a.example.com."), mx("@", 5, "a.example.com."), mx("@", 5, "a.example.com.")}, 2},
+		{"SRV other port", []*models.RecordConfig{srv(10, 5, 5060), srv(10, 5, 5061)}, 0},
+		{"duplicate SRV", []*models.RecordConfig{srv(10, 5, 5060), srv(10, 5, 5060)}, 1},
+		{"A other addresses", []*models.RecordConfig{a("www", "192.0.2.1", ""), a("www", "192.0.2.2", "")}, 0},
+		{"duplicate A", []*models.RecordConfig{a("www", "192.0.2.1", ""), a("www", "192.0.2.2", ""), a("www", "192.0.2.1", "")}, 1},
+		{"A for other locations", []*models.RecordConfig{a("www", "192.0.2.1", "EU"), a("www", "192.0.2.1", "NA")}, 0},
+		{"TXT other strings", []*models.RecordConfig{txt("a", "b"), txt("ab"), txt("a")}, 0},
+		{"duplicate TXT", []*models.RecordConfig{txt("a", "b"), txt("a", "b")}, 1},
+	}
+	for _, tst := range tests {
+		t.Run(tst.desc, func(t *testing.T) {
+			dc := &models.DomainConfig{Name: "example.com", Records: append([]*models.RecordConfig{}, tst.records...)}
+			errs := checkDuplicates(dc)
+			if len(errs) != tst.warnings {
+				t.Fatalf("expected %d warnings, got %v", tst.warnings, errs)
+			}
+			for _, err := range errs {
+				if _, ok := err.(Warning); !ok {
+					t.Errorf("expected a warning, got %v", err)
+				}
+			}
+			// Only the first of the duplicates is kept, in order.
+			if len(dc.Records) != len(tst.records)-tst.warnings || dc.Records[0] != tst.records[0] {
+				t.Errorf("expected %d records, got %v", len(tst.records)-tst.warnings, dc.Records)
+			}
+		})
+	}
+
+	// A CNAME declared twice is a duplicate, not two CNAMEs.
+	cname := func() *models.RecordConfig {
+		return makeRC("www", "example.com", "host.example.net.", models.RecordConfig{Type: "CNAME", Metadata: map[string]string{}})
+	}
+	dc := &models.DomainConfig{Name: "example.com", RegistrarName: "BIND", Records: []*models.RecordConfig{cname(), cname()}}
+	res := NormalizeAndValidateConfig(&models.DNSConfig{Domains: []*models.DomainConfig{dc}})
+	if len(res.Errors) != 0 || len(res.Warnings) != 1 || len(dc.Records) != 1 {
+		t.Errorf("Expected one CNAME and a warning, got %v %v %v", dc.Records, res.Errors, res.Warnings)
+	}
+}
+
+func TestIgnoreWarning(t *testing.T) {
+	rec := func(label, rType, target, ignore string) *models.RecordConfig {
+		rc := makeRC(label, "example.com", target, models.RecordConfig{Type: rType, Metadata: map[string]string{}})
+		if ignore != "" {
+			rc.Metadata["ignore_warnings"] = ignore
+		}
+		return rc
+	}
+	replaced := func(rc *models.RecordConfig) *models.RecordConfig {
+		rc.Metadata[models.MetaReplacedDefaults] = "1"
+		return rc
+	}
+	for _, tst := range []struct {
+		desc     string
+		records  []*models.RecordConfig
+		warnings []string
+		errors   int
+	}{
+		{"no suppression", []*models.RecordConfig{
+			rec("_a", "A", "1.2.3.4", ""),
+			rec("@", "MX", "mail", ""),
+			rec("mail", "CNAME", "mail.example.net.", ""),
+		}, []string{"underscore", "mx-cname"}, 0},
+		{"suppressed", []*models.RecordConfig{
+			rec("_a", "A", "1.2.3.4", "underscore"),
+			rec("@", "MX", "mail", "duplicate,mx-cname"),
+			rec("mail", "CNAME", "mail.example.net.", ""),
+		}, nil, 0},
+		// Only the record with IGNORE_WARNING() loses the warning.
+		{"other record", []*models.RecordConfig{
+			rec("_a", "A", "1.2.3.4", "underscore"),
+			rec("_b", "A", "1.2.3.4", ""),
+		}, []string{"underscore"}, 0},
+		// Only the warning named is suppressed.
+		{"other warning", []*models.RecordConfig{
+			rec("_a", "A", "1.2.3.4", "mx-cname"),
+		}, []string{"underscore"}, 0},
+		{"unknown ID", []*models.RecordConfig{
+			rec("_a", "A", "1.2.3.4", "underscores"),
+		}, []string{"underscore"}, 1},
+		{"replaced defaults", []*models.RecordConfig{
+			replaced(rec("@", "A", "1.2.3.4", "")),
+			replaced(rec("www", "A", "1.2.3.4", "defaults-override")),
+		}, []string{"defaults-override"}, 0},
+	} {
+		t.Run(tst.desc, func(t *testing.T) {
+			res := NormalizeAndValidateConfig(&models.DNSConfig{Domains: []*models.DomainConfig{
+				{Name: "example.com", RegistrarName: "BIND", Records: tst.records},
+			}})
+			var ids []string
+			for _, w := range res.Warnings {
+				ids = append(ids, w.(Warning).ID)
+			}
+			if !reflect.DeepEqual(ids, tst.warnings) || len(res.Errors) != tst.errors {
+				t.Errorf("expected warnings %v and %d errors, got %v", tst.warnings, tst.errors, res)
+			}
+		})
+	}
+}
+
 func TestCAAValidation(t *testing.T) {
 	config := &models.DNSConfig{
 		Domains: []*models.DomainConfig{
@@ -207,8 +439,8 @@ func TestCAAValidation(t *testing.T) {
 			},
 		},
 	}
-	errs := NormalizeAndValidateConfig(config)
-	if len(errs) != 1 {
+	res := NormalizeAndValidateConfig(config)
+	if len(res.Errors) != 1 {
 		t.Error("Expect error on invalid CAA but got none")
 	}
 }
@@ -220,14 +452,307 @@ func TestTLSAValidation(t *testing.T) {
 				Name:          "_443._tcp.example.com",
 				RegistrarName: "BIND",
 				Records: []*models.RecordConfig{
-					makeRC("_443._tcp", "_443._tcp.example.com", "abcdef0", models.RecordConfig{
+					makeRC("_443._tcp", "_443._tcp.example.com", strings.Repeat("ab", 32), models.RecordConfig{
 						Type: "TLSA", TlsaUsage: 4, TlsaSelector: 1, TlsaMatchingType: 1}),
 				},
 			},
 		},
 	}
-	errs := NormalizeAndValidateConfig(config)
-	if len(errs) != 1 {
+	res := NormalizeAndValidateConfig(config)
+	if len(res.Errors) != 1 {
 		t.Error("Expect error on invalid TLSA but got none")
 	}
 }
+
+func TestTXTOrderPreserved(t *testing.T) {
+	spf := []string{"v=spf1 ip4:198.252.206.0/24 include:mailgun.org", "include:_spf.google.com ~all"}
+	rec := makeRC("@", "example.com", "", models.RecordConfig{Type: "TXT", Metadata: map[string]string{}})
+	rec.SetTargetTXTs(spf)
+	config := &models.DNSConfig{
+		Domains: []*models.DomainConfig{
+			{
+				Name:          "example.com",
+				RegistrarName: "BIND",
+				Records:       []*models.RecordConfig{rec},
+			},
+		},
+	}
+	if res := NormalizeAndValidateConfig(config); res.Failed(true) {
+		t.Fatalf("Unexpected errors: %v", res)
+	}
+	got := config.Domains[0].Records[0]
+	if got.GetTargetField() != spf[0] {
+		t.Errorf("Target changed: got %q, expected %q", got.GetTargetField(), spf[0])
+	}
+	if len(got.TxtStrings) != len(spf) {
+		t.Fatalf("Expected %d strings, got %d", len(spf), len(got.TxtStrings))
+	}
+	for i := range spf {
+		if got.TxtStrings[i] != spf[i] {
+			t.Errorf("String %d changed: got %q, expected %q", i, got.TxtStrings[i], spf[i])
+		}
+	}
+}
+
+func TestMinTTL(t *testing.T) {
+	providers.RegisterDomainServiceProviderType("FAKE-MINTTL", nil, providers.MinTTL(300))
+	config := &models.DNSConfig{
+		Domains: []*models.DomainConfig{
+			{
+				Name:          "example.com",
+				RegistrarName: "BIND",
+				Records: []*models.RecordConfig{
+					makeRC("low", "example.com", "1.2.3.4", models.RecordConfig{Type: "A", TTL: 60}),
+					makeRC("high", "example.com", "1.2.3.5", models.RecordConfig{Type: "A", TTL: 3600}),
+				},
+				DNSProviderInstances: []*models.DNSProviderInstance{
+					{ProviderBase: models.ProviderBase{Name: "fake", ProviderType: "FAKE-MINTTL"}},
+				},
+			},
+		},
+	}
+	res := NormalizeAndValidateConfig(config)
+	if len(res.Warnings) != 1 || len(res.Errors) != 0 {
+		t.Fatalf("Expected 1 warning, got %v", res)
+	}
+	recs := config.Domains[0].Records
+	if recs[0].TTL != 300 {
+		t.Errorf("Expected TTL to be raised to 300, got %d", recs[0].TTL)
+	}
+	if recs[1].TTL != 3600 {
+		t.Errorf("Expected TTL to stay 3600, got %d", recs[1].TTL)
+	}
+}
+
+func TestApexTTL(t *testing.T) {
+	providers.RegisterDomainServiceProviderType("FAKE-APEXTTL", nil, providers.ApexTTL(86400))
+	for _, tst := range []struct {
+		nsTTL    string
+		warnings int
+	}{
+		{"", 0},
+		{"86400", 0},
+		{"300", 1},
+	} {
+		domain := &models.DomainConfig{
+			Name:          "example.com",
+			RegistrarName: "BIND",
+			Metadata:      map[string]string{},
+			DNSProviderInstances: []*models.DNSProviderInstance{
+				{ProviderBase: models.ProviderBase{Name: "fake", ProviderType: "FAKE-APEXTTL"}},
+			},
+		}
+		if tst.nsTTL != "" {
+			domain.Metadata["ns_ttl"] = tst.nsTTL
+		}
+		res := NormalizeAndValidateConfig(&models.DNSConfig{Domains: []*models.DomainConfig{domain}})
+		if len(res.Warnings) != tst.warnings || len(res.Errors) != 0 {
+			t.Errorf("ns_ttl %q: expected %d warnings, got %v", tst.nsTTL, tst.warnings, res)
+		}
+	}
+}
+
+func TestAutoTTL(t *testing.T) {
+	providers.RegisterDomainServiceProviderType("FAKE-AUTOTTL", nil, providers.CanUseAutoTTL)
+	providers.RegisterDomainServiceProviderType("FAKE-NOAUTOTTL", nil)
+	for _, tst := range []struct {
+		pType    string
+		warnings int
+	}{
+		{"FAKE-AUTOTTL", 0},
+		{"FAKE-NOAUTOTTL", 1},
+	} {
+		t.Run(tst.pType, func(t *testing.T) {
+			config := &models.DNSConfig{
+				Domains: []*models.DomainConfig{
+					{
+						Name:          "example.com",
+						RegistrarName: "BIND",
+						Records: []*models.RecordConfig{
+							makeRC("@", "example.com", "1.2.3.4", models.RecordConfig{Type: "A", Metadata: map[string]string{models.MetaAutoTTL: "true"}}),
+						},
+						DNSProviderInstances: []*models.DNSProviderInstance{
+							{ProviderBase: models.ProviderBase{Name: "fake", ProviderType: tst.pType}},
+						},
+					},
+				},
+			}
+			res := NormalizeAndValidateConfig(config)
+			if len(res.Warnings) != tst.warnings || len(res.Errors) != 0 {
+				t.Fatalf("Expected %d warnings, got %v", tst.warnings, res)
+			}
+			if rec := config.Domains[0].Records[0]; rec.TTL != models.DefaultTTL {
+				t.Errorf("Expected TTL %d, got %d", models.DefaultTTL, rec.TTL)
+			}
+		})
+	}
+}
+
+func TestURLForward(t *testing.T) {
+	providers.RegisterDomainServiceProviderType("FAKE-URLFORWARD", nil, providers.CanUseURLForward)
+	providers.RegisterDomainServiceProviderType("FAKE-NOURLFORWARD", nil)
+	for _, tst := range []struct {
+		pType, rType, target string
+		err                  string
+	}{
+		{"FAKE-URLFORWARD", "URL", "https://www.example.net/", ""},
+		{"FAKE-URLFORWARD", "URL301", "http://www.example.net/path?q=1", ""},
+		{"FAKE-URLFORWARD", "FRAME", "https://www.example.net", ""},
+		{"FAKE-URLFORWARD", "URL", "www.example.net", "In URL www.example.com: target (www.example.net) is not an http:// or https:// URL"},
+		{"FAKE-URLFORWARD", "URL301", "ftp://www.example.net/", "In URL301 www.example.com: target (ftp://www.example.net/) is not an http:// or https:// URL"},
+		{"FAKE-NOURLFORWARD", "URL", "https://www.example.net/", "Domain example.com uses URL records, but DNS provider type FAKE-NOURLFORWARD does not support them"},
+		{"FAKE-NOURLFORWARD", "FRAME", "https://www.example.net/", "Domain example.com uses FRAME records, but DNS provider type FAKE-NOURLFORWARD does not support them"},
+	} {
+		t.Run(tst.pType+" "+tst.rType+" "+tst.target, func(t *testing.T) {
+			config := &models.DNSConfig{
+				Domains: []*models.DomainConfig{
+					{
+						Name:          "example.com",
+						RegistrarName: "BIND",
+						Records: []*models.RecordConfig{
+							makeRC("www", "example.com", tst.target, models.RecordConfig{Type: tst.rType, Metadata: map[string]string{}}),
+						},
+						DNSProviderInstances: []*models.DNSProviderInstance{
+							{ProviderBase: models.ProviderBase{Name: "fake", ProviderType: tst.pType}},
+						},
+					},
+				},
+			}
+			res := NormalizeAndValidateConfig(config)
+			if tst.err == "" {
+				if len(res.Errors) != 0 {
+					t.Errorf("Expected no errors, got %v", res.Errors)
+				}
+				return
+			}
+			if len(res.Errors) != 1 || res.Errors[0].Error() != tst.err {
+				t.Errorf("Expected %q, got %v", tst.err, res.Errors)
+			}
+		})
+	}
+}
+
+func TestZoneSettings(t *testing.T) {
+	providers.RegisterDomainServiceProviderType("FAKE-SETTINGS", nil, providers.ZoneSettings{"ssl"})
+	providers.RegisterDomainServiceProviderType("FAKE-NOSETTINGS", nil)
+	for _, tst := range []struct {
+		settings map[string]string
+		pTypes   []string
+		errors   int
+	}{
+		{map[string]string{"ssl": "full"}, []string{"FAKE-SETTINGS"}, 0},
+		// One provider managing the setting is enough.
+		{map[string]string{"ssl": "full"}, []string{"FAKE-NOSETTINGS", "FAKE-SETTINGS"}, 0},
+		{map[string]string{"ssl": "full", "bogus": "on"}, []string{"FAKE-SETTINGS"}, 1},
+		{map[string]string{"ssl": "full"}, []string{"FAKE-NOSETTINGS"}, 1},
+	} {
+		dc := &models.DomainConfig{
+			Name:          "example.com",
+			RegistrarName: "BIND",
+			ProviderMeta:  tst.settings,
+		}
+		for _, p := range tst.pTypes {
+			dc.DNSProviderInstances = append(dc.DNSProviderInstances, &models.DNSProviderInstance{ProviderBase: models.ProviderBase{Name: p, ProviderType: p}})
+		}
+		res := NormalizeAndValidateConfig(&models.DNSConfig{Domains: []*models.DomainConfig{dc}})
+		if len(res.Errors) != tst.errors {
+			t.Errorf("%v with %v: expected %d errors, got %v", tst.settings, tst.pTypes, tst.errors, res.Errors)
+		}
+	}
+}
+
+func TestCheckWildcard(t *testing.T) {
+	var tests = []struct {
+		label   string
+		isError bool
+	}{
+		{"*", false},
+		{"*.foo", false},
+		{"foo", false},
+		{"foo.*.bar", true},
+		{"foo.*", true},
+		{"*foo", true},
+		{"f*o", true},
+		{"*.*", true},
+	}
+	for _, test := range tests {
+		t.Run(test.label, func(t *testing.T) {
+			err := checkWildcard(test.label, "foo.tld")
+			checkError(t, err, test.isError, test.label)
+		})
+	}
+}
+
+func TestResult(t *testing.T) {
+	config := &models.DNSConfig{
+		Domains: []*models.DomainConfig{
+			{
+				Name:          "example.com",
+				RegistrarName: "BIND",
+				Records: []*models.RecordConfig{
+					makeRC("_foo", "example.com", "1.2.3.4", models.RecordConfig{Type: "A"}),
+					makeRC("@", "example.com", "example.com", models.RecordConfig{Type: "CAA", CaaTag: "invalid"}),
+				},
+			},
+		},
+	}
+	res := NormalizeAndValidateConfig(config)
+	if len(res.Errors) != 1 || len(res.Warnings) != 1 {
+		t.Fatalf("Expected 1 error and 1 warning, got %v", res)
+	}
+	if _, ok := res.Warnings[0].(Warning); !ok {
+		t.Errorf("Expected the underscore to be a warning, got %s", res.Warnings[0])
+	}
+	for _, tst := range []struct {
+		res            Result
+		failed, strict bool
+	}{
+		{Result{}, false, false},
+		{Result{Warnings: res.Warnings}, false, true},
+		{Result{Errors: res.Errors}, true, true},
+		{res, true, true},
+	} {
+		if tst.res.Failed(false) != tst.failed || tst.res.Failed(true) != tst.strict {
+			t.Errorf("%v: expected Failed %v, strict %v", tst.res, tst.failed, tst.strict)
+		}
+	}
+}
+
+func TestClasslessReverseWarning(t *testing.T) {
+	for _, tst := range []struct {
+		name     string
+		warnings int
+	}{
+		{"2.0.192.in-addr.arpa", 0},
+		{"128/26.2.0.192.in-addr.arpa", 1},
+		{"8.b.d.0.1.0.0.2.ip6.arpa", 0},
+	} {
+		config := &models.DNSConfig{Domains: []*models.DomainConfig{{Name: tst.name, RegistrarName: "BIND"}}}
+		res := NormalizeAndValidateConfig(config)
+		if len(res.Warnings) != tst.warnings || len(res.Errors) != 0 {
+			t.Errorf("%s: expected %d warnings, got %v", tst.name, tst.warnings, res)
+		}
+	}
+}
+
+func TestCorrectionOrder(t *testing.T) {
+	for _, tst := range []struct {
+		order  string
+		errors int
+	}{
+		{"", 0},
+		{models.CreatesFirst, 0},
+		{models.DeletesFirst, 0},
+		{"deletes-last", 1},
+	} {
+		domain := &models.DomainConfig{
+			Name:          "example.com",
+			RegistrarName: "BIND",
+			Metadata:      map[string]string{models.MetaCorrectionOrder: tst.order},
+		}
+		res := NormalizeAndValidateConfig(&models.DNSConfig{Domains: []*models.DomainConfig{domain}})
+		if len(res.Errors) != tst.errors {
+			t.Errorf("%q: expected %d errors, got %v", tst.order, tst.errors, res)
+		}
+	}
+}
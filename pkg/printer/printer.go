@@ -3,10 +3,12 @@ package printer
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
 )
 
 // CLI is an abstraction around the CLI.
@@ -31,21 +33,85 @@ type Printer interface {
 var reader = bufio.NewReader(os.Stdin)
 
 // ConsolePrinter is a handle for the console printer.
-type ConsolePrinter struct{}
+type ConsolePrinter struct {
+	// Color enables ANSI colors. Without it the output is plain text.
+	Color bool
+	// W is where the output goes, os.Stdout if it is nil.
+	W io.Writer
+}
+
+func (c ConsolePrinter) out() io.Writer {
+	if c.W == nil {
+		return os.Stdout
+	}
+	return c.W
+}
+
+const (
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorReset  = "\x1b[0m"
+)
+
+// UseColor decides if output to f should be colored, given the value of the
+// -color flag: "always", "never" or "auto". "auto" (or "") colors terminals,
+// unless NO_COLOR is set (https://no-color.org) or TERM is "dumb".
+func UseColor(mode string, f *os.File) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "", "auto":
+		if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+			return false, nil
+		}
+		fi, err := f.Stat()
+		return err == nil && fi.Mode()&os.ModeCharDevice != 0, nil
+	}
+	return false, errors.Errorf("Invalid -color value %q (must be auto, always or never)", mode)
+}
+
+func (c ConsolePrinter) colorize(color, s string) string {
+	if !c.Color || s == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// colorizeCorrection colors each line of a correction message by the kind of change it describes.
+func (c ConsolePrinter) colorizeCorrection(msg string) string {
+	if !c.Color {
+		return msg
+	}
+	lines := strings.Split(msg, "\n")
+	for i, l := range lines {
+		switch t := strings.TrimSpace(l); {
+		case strings.HasPrefix(t, "CREATE"):
+			lines[i] = c.colorize(colorGreen, l)
+		case strings.HasPrefix(t, "DELETE"):
+			lines[i] = c.colorize(colorRed, l)
+		case strings.HasPrefix(t, "MODIFY"):
+			lines[i] = c.colorize(colorYellow, l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
 
 // StartDomain is called at the start of each domain.
 func (c ConsolePrinter) StartDomain(domain string) {
-	fmt.Printf("******************** Domain: %s\n", domain)
+	fmt.Fprintf(c.out(), "******************** Domain: %s\n", domain)
 }
 
 // PrintCorrection is called to print/format each correction.
 func (c ConsolePrinter) PrintCorrection(i int, correction *models.Correction) {
-	fmt.Printf("#%d: %s\n", i+1, correction.Msg)
+	fmt.Fprintf(c.out(), "#%d: %s\n", i+1, c.colorizeCorrection(correction.Msg))
 }
 
 // PromptToRun prompts the user to see if they want to execute a correction.
 func (c ConsolePrinter) PromptToRun() bool {
-	fmt.Print("Run? (Y/n): ")
+	fmt.Fprint(c.out(), "Run? (Y/n): ")
 	txt, err := reader.ReadString('\n')
 	run := true
 	if err != nil {
@@ -56,7 +122,7 @@ func (c ConsolePrinter) PromptToRun() bool {
 		run = false
 	}
 	if !run {
-		fmt.Println("Skipping")
+		fmt.Fprintln(c.out(), "Skipping")
 	}
 	return run
 }
@@ -64,9 +130,9 @@ func (c ConsolePrinter) PromptToRun() bool {
 // EndCorrection is called at the end of each correction.
 func (c ConsolePrinter) EndCorrection(err error) {
 	if err != nil {
-		fmt.Println("FAILURE!", err)
+		fmt.Fprintln(c.out(), c.colorize(colorRed, "FAILURE!"), err)
 	} else {
-		fmt.Println("SUCCESS!")
+		fmt.Fprintln(c.out(), c.colorize(colorGreen, "SUCCESS!"))
 	}
 }
 
@@ -76,7 +142,7 @@ func (c ConsolePrinter) StartDNSProvider(provider string, skip bool) {
 	if skip {
 		lbl = " (skipping)\n"
 	}
-	fmt.Printf("----- DNS Provider: %s...%s", provider, lbl)
+	fmt.Fprintf(c.out(), "----- DNS Provider: %s...%s", provider, lbl)
 }
 
 // StartRegistrar is called at the start of each new registrar.
@@ -85,29 +151,30 @@ func (c ConsolePrinter) StartRegistrar(provider string, skip bool) {
 	if skip {
 		lbl = " (skipping)\n"
 	}
-	fmt.Printf("----- Registrar: %s...%s", provider, lbl)
+	fmt.Fprintf(c.out(), "----- Registrar: %s...%s", provider, lbl)
 }
 
 // EndProvider is called at the end of each provider.
 func (c ConsolePrinter) EndProvider(numCorrections int, err error) {
 	if err != nil {
-		fmt.Println("ERROR")
-		fmt.Printf("Error getting corrections: %s\n", err)
+		fmt.Fprintln(c.out(), c.colorize(colorRed, "ERROR"))
+		fmt.Fprintf(c.out(), "Error getting corrections: %s\n", err)
 	} else {
 		plural := "s"
 		if numCorrections == 1 {
 			plural = ""
 		}
-		fmt.Printf("%d correction%s\n", numCorrections, plural)
+		fmt.Fprintf(c.out(), "%d correction%s\n", numCorrections, plural)
 	}
 }
 
 // Debugf is called to print/format debug information.
 func (c ConsolePrinter) Debugf(format string, args ...interface{}) {
-	fmt.Printf(format, args...)
+	fmt.Fprintf(c.out(), format, args...)
 }
 
 // Warnf is called to print/format a warning.
 func (c ConsolePrinter) Warnf(format string, args ...interface{}) {
-	fmt.Printf("WARNING: "+format, args...)
+	fmt.Fprint(c.out(), c.colorize(colorYellow, "WARNING:"), " ")
+	fmt.Fprintf(c.out(), format, args...)
 }
@@ -0,0 +1,98 @@
+package printer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
+)
+
+func TestUseColor(t *testing.T) {
+	f, err := ioutil.TempFile("", "printer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if v, ok := os.LookupEnv("NO_COLOR"); ok {
+		defer os.Setenv("NO_COLOR", v)
+	} else {
+		defer os.Unsetenv("NO_COLOR")
+	}
+	os.Unsetenv("NO_COLOR")
+	tests := []struct {
+		mode  string
+		color bool
+		err   bool
+	}{
+		{"always", true, false},
+		{"never", false, false},
+		// A file is not a terminal.
+		{"auto", false, false},
+		{"", false, false},
+		{"sometimes", false, true},
+	}
+	for _, tst := range tests {
+		color, err := UseColor(tst.mode, f)
+		if color != tst.color || (err != nil) != tst.err {
+			t.Errorf("%q: expected %v (error %v), got %v (%v)", tst.mode, tst.color, tst.err, color, err)
+		}
+	}
+	os.Setenv("NO_COLOR", "")
+	if color, _ := UseColor("always", f); !color {
+		t.Errorf("Expected -color=always to color with NO_COLOR")
+	}
+	if color, _ := UseColor("auto", os.Stdout); color {
+		t.Errorf("Expected -color=auto not to color with NO_COLOR")
+	}
+}
+
+func TestColorizeCorrection(t *testing.T) {
+	msg := "MODIFY A www: (1.1.1.1 ttl=300) -> (2.2.2.2 ttl=300)\n  DELETE A old\n  CREATE A new\nrefreshing"
+	if got := (ConsolePrinter{}).colorizeCorrection(msg); got != msg {
+		t.Errorf("Expected no colors without Color, got %q", got)
+	}
+	want := colorYellow + "MODIFY A www: (1.1.1.1 ttl=300) -> (2.2.2.2 ttl=300)" + colorReset + "\n" +
+		colorRed + "  DELETE A old" + colorReset + "\n" +
+		colorGreen + "  CREATE A new" + colorReset + "\n" +
+		"refreshing"
+	if got := (ConsolePrinter{Color: true}).colorizeCorrection(msg); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestConsolePrinterWriter(t *testing.T) {
+	var buf bytes.Buffer
+	c := ConsolePrinter{W: &buf}
+	c.StartDomain("example.com")
+	c.StartDNSProvider("bind", false)
+	c.EndProvider(1, nil)
+	c.PrintCorrection(0, &models.Correction{Msg: "CREATE A www"})
+	c.EndCorrection(errors.Errorf("refused"))
+	c.StartRegistrar("none", true)
+	c.Debugf("%d done\n", 1)
+	c.Warnf("%s\n", "careful")
+	want := "******************** Domain: example.com\n" +
+		"----- DNS Provider: bind...1 correction\n" +
+		"#1: CREATE A www\n" +
+		"FAILURE! refused\n" +
+		"----- Registrar: none... (skipping)\n" +
+		"1 done\n" +
+		"WARNING: careful\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+	c.Color = true
+	c.EndCorrection(nil)
+	c.Warnf("careful\n")
+	want = colorGreen + "SUCCESS!" + colorReset + "\n" +
+		colorYellow + "WARNING:" + colorReset + " careful\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+}
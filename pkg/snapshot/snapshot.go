@@ -0,0 +1,101 @@
+// Package snapshot reads and writes the state of a zone as read from a provider.
+// push -backup writes one before changing a zone, so that there is something to roll back to.
+package snapshot
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
+)
+
+// timeFormat is used in file names. It sorts chronologically and has no colons.
+const timeFormat = "20060102T150405Z"
+
+// Snapshot is the set of records a provider had for a domain at a point in time.
+// Records use the same JSON format as `dnscontrol print-ir`.
+type Snapshot struct {
+	Domain       string                 `json:"domain"`
+	Provider     string                 `json:"provider"`
+	ProviderType string                 `json:"provider_type"`
+	Time         time.Time              `json:"time"`
+	Records      []*models.RecordConfig `json:"records"`
+}
+
+// New returns a snapshot of records taken now.
+func New(domain, provider, providerType string, records []*models.RecordConfig) *Snapshot {
+	return &Snapshot{
+		Domain:       domain,
+		Provider:     provider,
+		ProviderType: providerType,
+		Time:         time.Now().UTC().Truncate(time.Second),
+		Records:      records,
+	}
+}
+
+// FileName returns the name the snapshot is written to: DOMAIN_PROVIDER_TIME.json.
+func (s *Snapshot) FileName() string {
+	name := strings.Replace(s.Provider, string(filepath.Separator), "_", -1)
+	return s.Domain + "_" + name + "_" + s.Time.UTC().Format(timeFormat) + ".json"
+}
+
+// Write writes the snapshot into dir, creating dir if needed, and returns the file name.
+func (s *Snapshot) Write(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", err
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	filename := filepath.Join(dir, s.FileName())
+	if err := ioutil.WriteFile(filename, append(b, '\n'), 0640); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// Read reads a snapshot written by Write.
+func Read(filename string) (*Snapshot, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, errors.Wrapf(err, "parsing snapshot %s", filename)
+	}
+	if s.Domain == "" {
+		return nil, errors.Errorf("snapshot %s has no domain", filename)
+	}
+	for _, r := range s.Records {
+		r.SetLabel(r.GetLabel(), s.Domain)
+	}
+	return s, nil
+}
+
+// Added returns the records in current that are not in the snapshot. Restoring
+// the snapshot deletes them. Records that only differ in TTL or metadata are
+// changed by a restore, not deleted, and are not returned.
+func (s *Snapshot) Added(current []*models.RecordConfig) []*models.RecordConfig {
+	type key struct {
+		models.RecordKey
+		target string
+	}
+	had := map[key]bool{}
+	for _, r := range s.Records {
+		had[key{r.Key(), r.GetTargetCombined()}] = true
+	}
+	var added []*models.RecordConfig
+	for _, r := range current {
+		if !had[key{r.Key(), r.GetTargetCombined()}] {
+			added = append(added, r)
+		}
+	}
+	return added
+}
@@ -0,0 +1,108 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+)
+
+func TestFileName(t *testing.T) {
+	s := &Snapshot{Domain: "example.com", Provider: "r53/main", Time: time.Date(2018, 3, 4, 5, 6, 7, 0, time.UTC)}
+	if name := s.FileName(); name != "example.com_r53_main_20180304T050607Z.json" {
+		t.Errorf("unexpected file name %q", name)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mx := &models.RecordConfig{Type: "MX", TTL: 300}
+	mx.SetLabel("@", "example.com")
+	mx.SetTargetMX(10, "mx.example.com.")
+	txt := &models.RecordConfig{Type: "TXT", TTL: 300, Metadata: map[string]string{"cloudflare_proxy": "off"}}
+	txt.SetLabel("www", "example.com")
+	txt.SetTargetTXTs([]string{"a", "b"})
+
+	s := New("example.com", "r53", "ROUTE53", []*models.RecordConfig{mx, txt})
+	filename, err := s.Write(filepath.Join(dir, "backups"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(filename) != s.FileName() {
+		t.Errorf("written to %s, expected %s", filename, s.FileName())
+	}
+
+	// The format is the print-ir record format plus a header.
+	b, _ := ioutil.ReadFile(filename)
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"domain", "provider", "provider_type", "time", "records"} {
+		if _, ok := raw[k]; !ok {
+			t.Errorf("snapshot has no %q field: %s", k, b)
+		}
+	}
+
+	got, err := Read(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Time.Equal(s.Time) || got.ProviderType != "ROUTE53" || len(got.Records) != 2 {
+		t.Fatalf("unexpected snapshot %+v", got)
+	}
+	if r := got.Records[0]; r.GetLabelFQDN() != "example.com" || r.MxPreference != 10 || r.GetTargetField() != "mx.example.com." {
+		t.Errorf("MX not restored: %+v", r)
+	}
+	if r := got.Records[1]; r.GetLabelFQDN() != "www.example.com" || len(r.TxtStrings) != 2 || r.Metadata["cloudflare_proxy"] != "off" {
+		t.Errorf("TXT not restored: %+v", r)
+	}
+}
+
+func rec(label, rtype, target string, ttl uint32) *models.RecordConfig {
+	r := &models.RecordConfig{Type: rtype, TTL: ttl}
+	r.SetLabel(label, "example.com")
+	r.SetTarget(target)
+	return r
+}
+
+func TestRestoreDiff(t *testing.T) {
+	s := New("example.com", "r53", "ROUTE53", []*models.RecordConfig{
+		rec("@", "A", "1.1.1.1", 300),
+		rec("www", "A", "1.1.1.1", 300),
+		rec("gone", "A", "1.1.1.1", 300),
+	})
+	// The zone as it is now: www has a new TTL, gone was deleted and new was added.
+	current := []*models.RecordConfig{
+		rec("@", "A", "1.1.1.1", 300),
+		rec("www", "A", "1.1.1.1", 600),
+		rec("new", "A", "2.2.2.2", 300),
+	}
+
+	added := s.Added(current)
+	if len(added) != 1 || added[0].GetLabelFQDN() != "new.example.com" {
+		t.Errorf("expected only new.example.com to be reported as added, got %v", added)
+	}
+
+	dc := &models.DomainConfig{Name: "example.com", Records: s.Records}
+	_, create, del, modify := diff.New(dc).IncrementalDiff(current)
+	if len(create) != 1 || create[0].Desired.GetLabel() != "gone" {
+		t.Errorf("restore should re-create gone, got %v", create)
+	}
+	if len(del) != 1 || del[0].Existing.GetLabel() != "new" {
+		t.Errorf("restore should delete new, got %v", del)
+	}
+	if len(modify) != 1 || modify[0].Desired.TTL != 300 {
+		t.Errorf("restore should put back the TTL of www, got %v", modify)
+	}
+}
@@ -16,6 +16,13 @@ func (s *SPFRecord) TXT() string {
 
 const maxLen = 255
 
+// MaxLookups is the maximum number of DNS lookups permitted by RFC 7208
+// when evaluating an SPF record.
+const MaxLookups = 10
+
+// MaxLen is the maximum length of a single TXT string.
+const MaxLen = maxLen
+
 // TXTSplit returns a set of txt records to use for SPF.
 // pattern given is used to name all chained spf records.
 // patern should include %d, which will be replaced by a counter.
@@ -91,6 +98,20 @@ func (s *SPFRecord) Flatten(spec string) *SPFRecord {
 	return newRec
 }
 
+// FlattenedIncludes returns the include domains that Flatten(spec) would
+// inline, in the order they are encountered (depth first).
+func (s *SPFRecord) FlattenedIncludes(spec string) []string {
+	var found []string
+	for _, p := range s.Parts {
+		if p.IncludeRecord == nil || !matchesFlatSpec(spec, p.IncludeDomain) {
+			continue
+		}
+		found = append(found, p.IncludeDomain)
+		found = append(found, p.IncludeRecord.FlattenedIncludes(spec)...)
+	}
+	return found
+}
+
 func matchesFlatSpec(spec, fqdn string) bool {
 	if spec == "*" {
 		return true
@@ -104,3 +104,47 @@ func TestSplit(t *testing.T) {
 		})
 	}
 }
+
+type mapResolver map[string]string
+
+func (m mapResolver) GetSPF(name string) (string, error) {
+	return m[name], nil
+}
+
+func TestFlattenNested(t *testing.T) {
+	res := mapResolver{
+		"outer.example.com": "v=spf1 ip4:10.0.0.0/8 include:inner.example.com -all",
+		"inner.example.com": "v=spf1 ip4:192.168.0.0/16 include:leaf.example.com -all",
+		"leaf.example.com":  "v=spf1 ip6:2001:db8::/32 -all",
+		"other.example.com": "v=spf1 a mx -all",
+	}
+	tests := []struct {
+		spec      string
+		txt       string
+		flattened string
+		lookups   int
+	}{
+		{"", "v=spf1 include:outer.example.com include:other.example.com ~all", "", 6},
+		{"outer.example.com", "v=spf1 ip4:10.0.0.0/8 include:inner.example.com include:other.example.com ~all", "outer.example.com", 5},
+		{"outer.example.com,inner.example.com", "v=spf1 ip4:10.0.0.0/8 ip4:192.168.0.0/16 include:leaf.example.com include:other.example.com ~all", "outer.example.com,inner.example.com", 4},
+		{"*", "v=spf1 ip4:10.0.0.0/8 ip4:192.168.0.0/16 ip6:2001:db8::/32 a mx ~all", "outer.example.com,inner.example.com,leaf.example.com,other.example.com", 2},
+	}
+	for _, tst := range tests {
+		t.Run(tst.spec, func(t *testing.T) {
+			rec, err := Parse("v=spf1 include:outer.example.com include:other.example.com ~all", res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(rec.FlattenedIncludes(tst.spec), ","); got != tst.flattened {
+				t.Errorf("Flattened includes: exp %q, got %q", tst.flattened, got)
+			}
+			rec = rec.Flatten(tst.spec)
+			if rec.TXT() != tst.txt {
+				t.Errorf("Exp %s\ngot %s", tst.txt, rec.TXT())
+			}
+			if rec.Lookups() != tst.lookups {
+				t.Errorf("Exp %d lookups, got %d", tst.lookups, rec.Lookups())
+			}
+		})
+	}
+}
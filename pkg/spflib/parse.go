@@ -79,6 +79,12 @@ func Parse(text string, dnsres Resolver) (*SPFRecord, error) {
 					return nil, errors.Errorf("In included spf: %s", err)
 				}
 			}
+		} else if strings.HasPrefix(part, "exists:") || part == "ptr" || strings.HasPrefix(part, "ptr:") || strings.HasPrefix(part, "redirect=") {
+			p.IsLookup = true
+		} else if strings.HasPrefix(part, "exp=") {
+			// the explanation is only looked up when a check fails,
+			// and doesn't count (RFC 7208 section 4.6.4)
+			continue
 		} else {
 			return nil, errors.Errorf("Unsupported spf part %s", part)
 		}
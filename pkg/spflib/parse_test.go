@@ -26,3 +26,34 @@ func TestParse(t *testing.T) {
 	}
 	t.Log(rec.Print())
 }
+
+func TestParseKeepsOrder(t *testing.T) {
+	text := "v=spf1 include:b.example.com ip4:10.0.0.0/8 include:a.example.com a mx ~all"
+	rec, err := Parse(text, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.TXT() != text {
+		t.Fatalf("Mechanism order changed.\nExp %s\ngot %s", text, rec.TXT())
+	}
+	if rec.Parts[0].IncludeDomain != "b.example.com" || rec.Parts[2].IncludeDomain != "a.example.com" {
+		t.Fatalf("Includes reordered: %s", rec.TXT())
+	}
+}
+
+func TestParseLookups(t *testing.T) {
+	for text, want := range map[string]int{
+		"v=spf1 ip4:10.0.0.0/8 ip6:2001:db8::/32 -all":                                  0,
+		"v=spf1 a mx:mail.example.com include:a.example.com ~all":                       3,
+		"v=spf1 ptr exists:%{i}.example.com exp=why.example.com redirect=b.example.com": 3,
+	} {
+		rec, err := Parse(text, nil)
+		if err != nil {
+			t.Errorf("%q: %s", text, err)
+			continue
+		}
+		if got := rec.Lookups(); got != want {
+			t.Errorf("%q: expected %d lookups, got %d", text, want, got)
+		}
+	}
+}
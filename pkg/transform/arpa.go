@@ -9,7 +9,15 @@ import (
 )
 
 // ReverseDomainName turns a CIDR block into a reversed (in-addr) name.
+// An address without a mask is a /32 (IPv4) or /128 (IPv6).
 func ReverseDomainName(cidr string) (string, error) {
+	if !strings.Contains(cidr, "/") {
+		if strings.Contains(cidr, ":") {
+			cidr += "/128"
+		} else {
+			cidr += "/32"
+		}
+	}
 	a, c, err := net.ParseCIDR(cidr)
 	if err != nil {
 		return "", err
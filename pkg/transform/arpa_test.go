@@ -26,6 +26,16 @@ func TestReverse(t *testing.T) {
 		{"174.136.107.14/32", false, "14.107.136.174.in-addr.arpa"},
 		{"2001:0db8:0123:4567:89ab:cdef:1234:5678/128", false, "8.7.6.5.4.3.2.1.f.e.d.c.b.a.9.8.7.6.5.4.3.2.1.0.8.b.d.0.1.0.0.2.ip6.arpa"},
 
+		// Without a mask:
+		{"174.136.107.14", false, "14.107.136.174.in-addr.arpa"},
+		{"2001:db8::1", false, "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa"},
+
+		// IPv6 nibble boundaries:
+		{"2001:db8::/32", false, "8.b.d.0.1.0.0.2.ip6.arpa"},
+		{"2001:db8:302::/48", false, "2.0.3.0.8.b.d.0.1.0.0.2.ip6.arpa"},
+		{"2001:db8:3020::/44", false, "2.0.3.8.b.d.0.1.0.0.2.ip6.arpa"},
+		{"2001:db8:302:7::/64", false, "7.0.0.0.2.0.3.0.8.b.d.0.1.0.0.2.ip6.arpa"},
+
 		// IPv4 "Classless in-addr.arpa delegation" RFC2317.
 		// From examples in the RFC:
 		{"192.0.2.0/25", false, "0/25.2.0.192.in-addr.arpa"},
@@ -72,6 +82,8 @@ func TestReverse(t *testing.T) {
 		{"174.1.0.2/31", false, "2/31.0.1.174.in-addr.arpa"},
 
 		// Error Cases:
+		{"174.136.0.0/20", true, ""},
+		{"2001:db8::/30", true, ""},
 		{"0.0.0.0/0", true, ""},
 		{"2001::/0", true, ""},
 		{"4.5/16", true, ""},
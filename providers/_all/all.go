@@ -16,7 +16,9 @@ import (
 	_ "github.com/StackExchange/dnscontrol/providers/ns1"
 	_ "github.com/StackExchange/dnscontrol/providers/octodns"
 	_ "github.com/StackExchange/dnscontrol/providers/opensrs"
+	_ "github.com/StackExchange/dnscontrol/providers/oracle"
 	_ "github.com/StackExchange/dnscontrol/providers/ovh"
+	_ "github.com/StackExchange/dnscontrol/providers/rfc2136"
 	_ "github.com/StackExchange/dnscontrol/providers/route53"
 	_ "github.com/StackExchange/dnscontrol/providers/softlayer"
 	_ "github.com/StackExchange/dnscontrol/providers/vultr"
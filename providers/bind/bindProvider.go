@@ -5,7 +5,8 @@ package bind
 bind -
   Generate zonefiles suitiable for BIND.
 
-	The zonefiles are read and written to the directory -bind_dir
+	The zonefiles are read and written to the directory of the
+	"directory" setting, one file per zone, named by "filenameformat".
 
 	If the old zonefiles are readable, we read them to determine
 	if an update is actually needed. The old zonefile is also used
@@ -20,6 +21,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/miekg/dns"
@@ -36,6 +38,7 @@ var features = providers.DocumentationNotes{
 	providers.CanUseSRV:              providers.Can(),
 	providers.CanUseTLSA:             providers.Can(),
 	providers.CanUseTXTMulti:         providers.Can(),
+	providers.CanUseSOA:              providers.Can("default_soa sets the SOA of the zones without SOA()."),
 	providers.CantUseNOPURGE:         providers.Cannot(),
 	providers.DocCreateDomains:       providers.Can("Driver just maintains list of zone files. It should automatically add missing ones."),
 	providers.DocDualHost:            providers.Can(),
@@ -46,11 +49,24 @@ func initBind(config map[string]string, providermeta json.RawMessage) (providers
 	// config -- the key/values from creds.json
 	// meta -- the json blob from NewReq('name', 'TYPE', meta)
 	api := &Bind{
-		directory: config["directory"],
+		directory:      config["directory"],
+		filenameFormat: config["filenameformat"],
 	}
 	if api.directory == "" {
 		api.directory = "zones"
 	}
+	if api.filenameFormat == "" {
+		api.filenameFormat = "%D.zone"
+	}
+	if _, err := zoneFileName(api.filenameFormat, "example.com"); err != nil {
+		return nil, err
+	}
+	if by := config["generated_by"]; by != "" {
+		if strings.ContainsAny(by, "\r\n") {
+			return nil, errors.Errorf("generated_by %q must be on one line", by)
+		}
+		api.comment = "; generated by dnscontrol from " + by
+	}
 	if len(providermeta) != 0 {
 		err := json.Unmarshal(providermeta, api)
 		if err != nil {
@@ -86,6 +102,41 @@ type Bind struct {
 	DefaultSoa  SoaInfo  `json:"default_soa"`
 	nameservers []*models.Nameserver
 	directory   string
+	// filenameFormat names the zonefile of each zone, see zoneFileName.
+	filenameFormat string
+	// comment ends the line of each record in the zonefiles, if not empty.
+	comment string
+}
+
+// zoneFileName returns the name of the zonefile of zone, relative to the
+// directory. In format, %D is the zone name in lowercase with "/" (of
+// RFC 2317 reverse zones) replaced by "_", and %% is a %. The file may be
+// in a subdirectory: "master/db.%D".
+func zoneFileName(format, zone string) (string, error) {
+	var b bytes.Buffer
+	hasZone := false
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			b.WriteByte(format[i])
+			continue
+		}
+		if i++; i == len(format) {
+			return "", errors.Errorf("filenameformat %q ends with %%", format)
+		}
+		switch format[i] {
+		case 'D':
+			hasZone = true
+			b.WriteString(strings.Replace(strings.ToLower(zone), "/", "_", -1))
+		case '%':
+			b.WriteByte('%')
+		default:
+			return "", errors.Errorf("filenameformat %q: unknown %%%c. Use %%D for the zone name", format, format[i])
+		}
+	}
+	if !hasZone {
+		return "", errors.Errorf("filenameformat %q must contain %%D, so that each zone has its own file", format)
+	}
+	return b.String(), nil
 }
 
 // var bindSkeletin = flag.String("bind_skeletin", "skeletin/master/var/named/chroot/var/named/master", "")
@@ -140,7 +191,11 @@ func rrToRecord(rr dns.RR, origin string, replaceSerial uint32) (models.RecordCo
 	case *dns.TLSA:
 		panicInvalid(rc.SetTargetTLSA(v.Usage, v.Selector, v.MatchingType, v.Certificate))
 	case *dns.TXT:
-		panicInvalid(rc.SetTargetTXTs(v.Txt))
+		txts := make([]string, len(v.Txt))
+		for i, t := range v.Txt {
+			txts[i] = models.UnescapeTxt(t)
+		}
+		panicInvalid(rc.SetTargetTXTs(txts))
 	default:
 		log.Fatalf("rrToRecord: Unimplemented zone record type=%s (%v)\n", rc.Type, rr)
 	}
@@ -185,6 +240,36 @@ func makeDefaultSOA(info SoaInfo, origin string) *models.RecordConfig {
 	return &soaRec
 }
 
+// ExportSOA returns an SOA record for a zonefile of dc, which has no SOA
+// record of its own. The master is the first apex NS record of dc.
+func ExportSOA(dc *models.DomainConfig, serial uint32) *models.RecordConfig {
+	info := SoaInfo{Mbox: "hostmaster." + dc.Name + ".", Serial: serial}
+	for _, r := range dc.Records {
+		if r.Type == "NS" && r.GetLabel() == "@" {
+			info.Ns = r.GetTargetField()
+			break
+		}
+	}
+	return makeDefaultSOA(info, dc.Name)
+}
+
+// SetSOASerial sets the serial of the SOA record of dc, which SOA() leaves
+// to the provider.
+func SetSOASerial(dc *models.DomainConfig, serial uint32) {
+	for _, r := range dc.Records {
+		if r.Type == "SOA" && r.GetLabel() == "@" {
+			fields := strings.Fields(r.GetTargetField())
+			fields[2] = strconv.FormatUint(uint64(serial), 10)
+			r.SetTarget(strings.Join(fields, " "))
+		}
+	}
+}
+
+func soaSerial(rec *models.RecordConfig) uint32 {
+	n, _ := strconv.ParseUint(strings.Fields(rec.GetTargetField())[2], 10, 32)
+	return uint32(n)
+}
+
 // GetNameservers returns the nameservers for a domain.
 func (c *Bind) GetNameservers(string) ([]*models.Nameserver, error) {
 	return c.nameservers, nil
@@ -212,7 +297,11 @@ func (c *Bind) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correcti
 	// Read foundRecords:
 	foundRecords := make([]*models.RecordConfig, 0)
 	var oldSerial, newSerial uint32
-	zonefile := filepath.Join(c.directory, strings.Replace(strings.ToLower(dc.Name), "/", "_", -1)+".zone")
+	name, err := zoneFileName(c.filenameFormat, dc.Name)
+	if err != nil {
+		return nil, err
+	}
+	zonefile := filepath.Join(c.directory, name)
 	foundFH, err := os.Open(zonefile)
 	zoneFileFound := err == nil
 	if err != nil && !os.IsNotExist(os.ErrNotExist) {
@@ -241,9 +330,11 @@ func (c *Bind) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correcti
 		}
 	}
 
-	// Add SOA record to expected set:
+	// Add SOA record to expected set, or number the one of SOA():
 	if !dc.HasRecordTypeName("SOA", "@") {
 		dc.Records = append(dc.Records, soaRec)
+	} else {
+		SetSOASerial(dc, soaSerial(soaRec))
 	}
 
 	// Normalize
@@ -285,6 +376,9 @@ func (c *Bind) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correcti
 				Msg: msg,
 				F: func() error {
 					fmt.Printf("CREATING ZONEFILE: %v\n", zonefile)
+					if err := os.MkdirAll(filepath.Dir(zonefile), 0755); err != nil {
+						log.Fatalf("Could not create the directory of the zonefile: %v", err)
+					}
 					zf, err := os.Create(zonefile)
 					if err != nil {
 						log.Fatalf("Could not create zonefile: %v", err)
@@ -293,7 +387,7 @@ func (c *Bind) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correcti
 					for _, r := range dc.Records {
 						zonefilerecords = append(zonefilerecords, r.ToRR())
 					}
-					err = WriteZoneFile(zf, zonefilerecords, dc.Name)
+					err = writeZoneFile(zf, zonefilerecords, dc.Name, c.comment)
 
 					if err != nil {
 						log.Fatalf("WriteZoneFile error: %v\n", err)
@@ -0,0 +1,254 @@
+package bind
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/miekg/dns"
+)
+
+func TestTXTRoundTrip(t *testing.T) {
+	for _, txts := range [][]string{
+		{"simple"},
+		{`say "hello"`},
+		{`back\slash`, `trailing\`},
+		{`\065 is not an escape`},
+		{"semi; colon", "tab\there"},
+	} {
+		rc := &models.RecordConfig{Type: "TXT", TTL: 300}
+		rc.SetLabel("@", "example.com")
+		rc.SetTargetTXTs(txts)
+
+		buf := &bytes.Buffer{}
+		if err := WriteZoneFile(buf, []dns.RR{rc.ToRR()}, "example.com"); err != nil {
+			t.Fatal(err)
+		}
+		var found []models.RecordConfig
+		for x := range dns.ParseZone(buf, "example.com.", "example.com.zone") {
+			if x.Error != nil {
+				t.Fatalf("%q: %s", txts, x.Error)
+			}
+			rec, _ := rrToRecord(x.RR, "example.com", 0)
+			found = append(found, rec)
+		}
+		if len(found) != 1 || !reflect.DeepEqual(found[0].TxtStrings, txts) {
+			t.Errorf("%q: read back %+v", txts, found)
+		}
+	}
+}
+
+func TestZoneFileName(t *testing.T) {
+	for _, tst := range []struct {
+		format, zone, want string
+	}{
+		{"%D.zone", "Example.com", "example.com.zone"},
+		{"db.%D", "example.com", "db.example.com"},
+		{"master/%D", "example.com", "master/example.com"},
+		{"%D.zone", "0/26.2.0.192.in-addr.arpa", "0_26.2.0.192.in-addr.arpa.zone"},
+		{"100%%/%D", "example.com", "100%/example.com"},
+	} {
+		got, err := zoneFileName(tst.format, tst.zone)
+		if err != nil {
+			t.Errorf("%q: %s", tst.format, err)
+		} else if got != tst.want {
+			t.Errorf("%q %s: expected %q, got %q", tst.format, tst.zone, tst.want, got)
+		}
+	}
+	for _, format := range []string{"zones.db", "%%D", "%D%", "%X.%D"} {
+		if _, err := zoneFileName(format, "example.com"); err == nil {
+			t.Errorf("%q: expected an error", format)
+		}
+	}
+}
+
+func TestPerZoneFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bind")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p, err := initBind(map[string]string{"directory": dir, "filenameformat": "master/db.%D"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	domain := func(name string) *models.DomainConfig {
+		rc := &models.RecordConfig{Type: "A", TTL: 300}
+		rc.SetLabel("www", name)
+		rc.SetTarget("10.0.0.1")
+		return &models.DomainConfig{Name: name, Records: []*models.RecordConfig{rc}}
+	}
+	for _, name := range []string{"example.com", "example.net"} {
+		corrections, err := p.GetDomainCorrections(domain(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(corrections) != 1 {
+			t.Fatalf("%s: expected 1 correction, got %d", name, len(corrections))
+		}
+		if err := corrections[0].F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"example.com", "example.net"} {
+		zonefile := filepath.Join(dir, "master", "db."+name)
+		data, err := ioutil.ReadFile(zonefile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Contains(data, []byte("www")) || !bytes.Contains(data, []byte("10.0.0.1")) {
+			t.Errorf("%s: the record is missing:\n%s", zonefile, data)
+		}
+		if other := map[string]string{"example.com": "example.net", "example.net": "example.com"}[name]; bytes.Contains(data, []byte(other)) {
+			t.Errorf("%s: has records of %s:\n%s", zonefile, other, data)
+		}
+		// The zone is read back from its own file.
+		corrections, err := p.GetDomainCorrections(domain(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(corrections) != 0 {
+			t.Errorf("%s: expected no corrections once written, got %s", name, corrections[0].Msg)
+		}
+	}
+	if _, err := initBind(map[string]string{"filenameformat": "zones.db"}, nil); err == nil {
+		t.Error("expected an error for a filenameformat without %D")
+	}
+}
+
+func TestGeneratedBy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bind")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p, err := initBind(map[string]string{"directory": dir, "generated_by": "dnsconfig.js"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	domain := func() *models.DomainConfig {
+		a := &models.RecordConfig{Type: "A", TTL: 300}
+		a.SetLabel("www", "example.com")
+		a.SetTarget("10.0.0.1")
+		txt := &models.RecordConfig{Type: "TXT", TTL: 300}
+		txt.SetLabel("@", "example.com")
+		txt.SetTargetTXTs([]string{"semi; colon"})
+		return &models.DomainConfig{Name: "example.com", Records: []*models.RecordConfig{a, txt}}
+	}
+	corrections, err := p.GetDomainCorrections(domain())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 1 {
+		t.Fatalf("Expected 1 correction, got %d", len(corrections))
+	}
+	if err := corrections[0].F(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "example.com.zone"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	// $TTL, the SOA and the two records.
+	if len(lines) != 4 {
+		t.Fatalf("Unexpected zonefile:\n%s", data)
+	}
+	for _, line := range lines[1:] {
+		if !strings.HasSuffix(line, " ; generated by dnscontrol from dnsconfig.js") {
+			t.Errorf("Expected the record to say where it comes from: %s", line)
+		}
+	}
+
+	// The comments are not part of the records.
+	corrections, err = p.GetDomainCorrections(domain())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(corrections) != 0 {
+		t.Errorf("Expected no corrections once written, got %s", corrections[0].Msg)
+	}
+	for x := range dns.ParseZone(bytes.NewReader(data), "example.com.", "example.com.zone") {
+		if x.Error != nil {
+			t.Fatal(x.Error)
+		}
+		if rec, _ := rrToRecord(x.RR, "example.com", 0); rec.Type == "TXT" && !reflect.DeepEqual(rec.TxtStrings, []string{"semi; colon"}) {
+			t.Errorf("Expected the TXT record to be read back as it was, got %q", rec.TxtStrings)
+		}
+	}
+
+	if _, err := initBind(map[string]string{"generated_by": "two\nlines"}, nil); err == nil {
+		t.Error("expected an error for a generated_by of two lines")
+	}
+}
+
+func TestSOARoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bind")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p, err := initBind(map[string]string{"directory": dir}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// As SOA() makes it, once normalized.
+	domain := func(refresh string) *models.DomainConfig {
+		soa := &models.RecordConfig{Type: "SOA", TTL: 3600}
+		soa.SetLabel("@", "example.com")
+		soa.SetTarget("ns1.example.com. hostmaster.example.com. 0 " + refresh + " 600 604800 1440")
+		return &models.DomainConfig{Name: "example.com", Records: []*models.RecordConfig{soa}}
+	}
+	zonefile := filepath.Join(dir, "example.com.zone")
+	readSOA := func() *dns.SOA {
+		data, err := ioutil.ReadFile(zonefile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for x := range dns.ParseZone(bytes.NewReader(data), "example.com.", zonefile) {
+			if x.Error != nil {
+				t.Fatal(x.Error)
+			}
+			if soa, ok := x.RR.(*dns.SOA); ok {
+				return soa
+			}
+		}
+		t.Fatalf("No SOA in:\n%s", data)
+		return nil
+	}
+	push := func(dc *models.DomainConfig) int {
+		corrections, err := p.GetDomainCorrections(dc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range corrections {
+			if err := c.F(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return len(corrections)
+	}
+
+	if n := push(domain("3600")); n != 1 {
+		t.Fatalf("Expected 1 correction, got %d", n)
+	}
+	soa := readSOA()
+	if soa.Ns != "ns1.example.com." || soa.Mbox != "hostmaster.example.com." || soa.Serial != 1 || soa.Refresh != 3600 || soa.Retry != 600 || soa.Expire != 604800 || soa.Minttl != 1440 || soa.Hdr.Ttl != 3600 {
+		t.Errorf("Expected the SOA of SOA(), numbered 1, got %s", soa)
+	}
+	// The serial is the provider's, so the SOA is unchanged.
+	if n := push(domain("3600")); n != 0 {
+		t.Errorf("Expected no corrections once written, got %d", n)
+	}
+	if n := push(domain("7200")); n != 1 {
+		t.Fatalf("Expected 1 correction for a new refresh, got %d", n)
+	}
+	if soa := readSOA(); soa.Refresh != 7200 || soa.Serial <= 1 {
+		t.Errorf("Expected the new refresh and a new serial, got %s", soa)
+	}
+}
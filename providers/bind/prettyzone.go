@@ -20,6 +20,8 @@ type zoneGenData struct {
 	Origin     string
 	DefaultTTL uint32
 	Records    []dns.RR
+	// Comment, if not empty, ends the line of every record.
+	Comment string
 }
 
 func (z *zoneGenData) Len() int      { return len(z.Records) }
@@ -131,6 +133,12 @@ func mostCommonTTL(records []dns.RR) uint32 {
 
 // WriteZoneFile writes a beautifully formatted zone file.
 func WriteZoneFile(w io.Writer, records []dns.RR, origin string) error {
+	return writeZoneFile(w, records, origin, "")
+}
+
+// writeZoneFile is WriteZoneFile, with comment added to each record, such as
+// "; generated by dnscontrol". Parsers ignore it.
+func writeZoneFile(w io.Writer, records []dns.RR, origin, comment string) error {
 	// This function prioritizes beauty over efficiency.
 	// * The zone records are sorted by label, grouped by subzones to
 	//   be easy to read and pleasant to the eye.
@@ -147,6 +155,7 @@ func WriteZoneFile(w io.Writer, records []dns.RR, origin string) error {
 	z := &zoneGenData{
 		Origin:     dnsutil.AddOrigin(origin, "."),
 		DefaultTTL: defaultTTL,
+		Comment:    comment,
 	}
 	z.Records = nil
 	for _, r := range records {
@@ -202,7 +211,11 @@ func (z *zoneGenData) generateZoneFileHelper(w io.Writer) error {
 		// items[4]: the remaining line
 		target := items[4]
 
-		fmt.Fprintln(w, formatLine([]int{10, 5, 2, 5, 0}, []string{name, ttl, "IN", typeStr, target}))
+		line = formatLine([]int{10, 5, 2, 5, 0}, []string{name, ttl, "IN", typeStr, target})
+		if z.Comment != "" {
+			line += " " + z.Comment
+		}
+		fmt.Fprintln(w, line)
 	}
 	return nil
 }
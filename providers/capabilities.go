@@ -2,6 +2,9 @@ package providers
 
 import (
 	"log"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
 )
 
 // Capability is a bitmasked set of "features" that a provider supports. Only use constants from this package.
@@ -43,6 +46,18 @@ const (
 
 	// CanUseRoute53Alias indicates the provider support the specific R53_ALIAS records that only the Route53 provider supports
 	CanUseRoute53Alias
+
+	// CanUseAutoTTL indicates the provider has an "automatic" TTL that TTL("auto") can be mapped to
+	CanUseAutoTTL
+
+	// CanUseGeoRecords indicates the provider can serve different records to clients in different locations (GEO())
+	CanUseGeoRecords
+
+	// CanUseURLForward indicates the provider can handle the web forwarding records URL, URL301 and FRAME
+	CanUseURLForward
+
+	// CanUseSOA indicates the provider takes the SOA record of a zone from SOA(), rather than managing it itself
+	CanUseSOA
 )
 
 var providerCapabilities = map[string]map[Capability]bool{}
@@ -55,6 +70,19 @@ func ProviderHasCabability(pType string, cap Capability) bool {
 	return providerCapabilities[pType][cap]
 }
 
+// ProviderWritesWholeZone returns true if the provider writes every record of
+// a zone from the desired records at once, so it can't leave any of them
+// alone (NO_PURGE, -only, PROTECTED()...). Providers declare this with
+// CantUseNOPURGE, either as a bare capability or, like BIND, as a note that
+// NO_PURGE is not supported. That note's HasFeature is false, so
+// ProviderHasCabability can't be used for it.
+func ProviderWritesWholeZone(pType string) bool {
+	if n := Notes[pType][CantUseNOPURGE]; n != nil {
+		return !n.HasFeature
+	}
+	return ProviderHasCabability(pType, CantUseNOPURGE)
+}
+
 // DocumentationNote is a way for providers to give more detail about what features they support.
 type DocumentationNote struct {
 	HasFeature    bool
@@ -72,6 +100,143 @@ type ProviderMetadata interface{}
 // Notes is a collection of all documentation notes, keyed by provider type
 var Notes = map[string]DocumentationNotes{}
 
+// MinTTL is the smallest TTL a provider accepts. Providers that silently clamp
+// lower TTLs should pass one to RegisterDomainServiceProviderType so that
+// normalization can raise TTLs to the floor instead of reporting a change on
+// every run.
+type MinTTL uint32
+
+var providerMinTTLs = map[string]uint32{}
+
+// ProviderMinTTL returns the smallest TTL a provider accepts, or 0 if it has no floor.
+func ProviderMinTTL(pType string) uint32 {
+	return providerMinTTLs[pType]
+}
+
+// ApexTTL is the TTL a provider always uses for the apex NS and SOA records
+// of its zones, whatever TTL is asked for. Providers that pass one to
+// RegisterDomainServiceProviderType get the desired apex NS and SOA records
+// with that TTL, so that no TTL change is reported for them on every run.
+type ApexTTL uint32
+
+var providerApexTTLs = map[string]uint32{}
+
+// ProviderApexTTL returns the TTL a provider enforces on apex NS and SOA records, or 0 if it has none.
+func ProviderApexTTL(pType string) uint32 {
+	return providerApexTTLs[pType]
+}
+
+// ApplyApexTTL sets the TTL of the apex NS and SOA records of dc to the one
+// the provider enforces, if any. dc should be the provider's own copy of the domain.
+func ApplyApexTTL(pType string, dc *models.DomainConfig) {
+	ttl := providerApexTTLs[pType]
+	if ttl == 0 {
+		return
+	}
+	for _, r := range dc.Records {
+		if (r.Type == "NS" || r.Type == "SOA") && r.GetLabel() == "@" {
+			r.TTL = ttl
+		}
+	}
+}
+
+// RemoveSOA drops the SOA() record of dc, unless the provider can use it
+// (CanUseSOA). Other providers manage the SOA themselves. dc should be the
+// provider's own copy of the domain.
+func RemoveSOA(pType string, dc *models.DomainConfig) {
+	if ProviderHasCabability(pType, CanUseSOA) {
+		return
+	}
+	records := dc.Records[:0]
+	for _, r := range dc.Records {
+		if r.Type != "SOA" {
+			records = append(records, r)
+		}
+	}
+	dc.Records = records
+}
+
+// RequiredRecord is a record a provider puts in every zone and won't let be
+// removed, such as a TXT record that proves who owns the domain. Content is
+// the target in zone file format, as PopulateFromString takes it.
+type RequiredRecord struct {
+	Label   string
+	Type    string
+	Content string
+	TTL     uint32
+}
+
+// RequiredRecords lists the records a provider requires. Providers that pass
+// them to RegisterDomainServiceProviderType get them added to the desired
+// records of each domain, so that the diff neither creates nor purges them.
+type RequiredRecords []RequiredRecord
+
+var providerRequiredRecords = map[string]RequiredRecords{}
+
+// AddRequiredRecords adds the records the provider requires to dc, unless
+// dc already has them. dc should be the provider's own copy of the domain.
+func AddRequiredRecords(pType string, dc *models.DomainConfig) error {
+	for _, req := range providerRequiredRecords[pType] {
+		rc := &models.RecordConfig{TTL: req.TTL, Metadata: map[string]string{}}
+		if rc.TTL == 0 {
+			rc.TTL = models.DefaultTTL
+		}
+		rc.SetLabel(req.Label, dc.Name)
+		if err := rc.PopulateFromString(req.Type, req.Content, dc.Name); err != nil {
+			return errors.Errorf("record %s %s required by %s: %s", req.Label, req.Type, pType, err)
+		}
+		found := false
+		for _, r := range dc.Records {
+			if r.Key() == rc.Key() && r.GetTargetCombined() == rc.GetTargetCombined() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dc.Records = append(dc.Records, rc)
+		}
+	}
+	return nil
+}
+
+// PrepareDomain readies dc, the provider's own copy of a domain, for the
+// provider of type pType to diff: ApplyApexTTL, RemoveSOA, then
+// AddRequiredRecords.
+func PrepareDomain(pType string, dc *models.DomainConfig) error {
+	ApplyApexTTL(pType, dc)
+	RemoveSOA(pType, dc)
+	return AddRequiredRecords(pType, dc)
+}
+
+// ZoneSettings lists the zone-level settings a provider manages alongside
+// records. Users set them with D(..., {providerMeta: {name: value}}) and they
+// appear as DomainConfig.ProviderMeta. Providers that pass ZoneSettings to
+// RegisterDomainServiceProviderType must reconcile them in GetDomainCorrections.
+type ZoneSettings []string
+
+var providerZoneSettings = map[string]ZoneSettings{}
+
+// ProviderZoneSettings returns the zone settings a provider manages.
+func ProviderZoneSettings(pType string) ZoneSettings {
+	return providerZoneSettings[pType]
+}
+
+// NoWildcards lists the record types that a provider rejects at a wildcard
+// label (*.example.com). Validation reports them instead of failing at push time.
+type NoWildcards []string
+
+var providerNoWildcards = map[string]NoWildcards{}
+
+// ProviderAllowsWildcard returns false if the provider rejects rType records at a wildcard label.
+func ProviderAllowsWildcard(pType, rType string) bool {
+	for _, t := range providerNoWildcards[pType] {
+		if t == rType {
+			return false
+		}
+	}
+	return true
+}
+
 func unwrapProviderCapabilities(pName string, meta []ProviderMetadata) {
 	if providerCapabilities[pName] == nil {
 		providerCapabilities[pName] = map[Capability]bool{}
@@ -80,6 +245,16 @@ func unwrapProviderCapabilities(pName string, meta []ProviderMetadata) {
 		switch x := pm.(type) {
 		case Capability:
 			providerCapabilities[pName][x] = true
+		case MinTTL:
+			providerMinTTLs[pName] = uint32(x)
+		case ApexTTL:
+			providerApexTTLs[pName] = uint32(x)
+		case RequiredRecords:
+			providerRequiredRecords[pName] = x
+		case ZoneSettings:
+			providerZoneSettings[pName] = x
+		case NoWildcards:
+			providerNoWildcards[pName] = x
 		case DocumentationNotes:
 			if Notes[pName] == nil {
 				Notes[pName] = DocumentationNotes{}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -30,12 +32,16 @@ Record level metadata available:
 Domain level metadata available:
    - cloudflare_proxy_default ("on", "off", or "full")
 
+Zone settings (providerMeta) available:
+   - see zoneSettings below
+
  Provider level metadata available:
    - ip_conversions
 */
 
 var features = providers.DocumentationNotes{
 	providers.CanUseAlias:            providers.Can("CF automatically flattens CNAME records into A records dynamically"),
+	providers.CanUseAutoTTL:          providers.Can("TTL 1 means automatic"),
 	providers.CanUseCAA:              providers.Can(),
 	providers.CanUseSRV:              providers.Can(),
 	providers.DocCreateDomains:       providers.Can(),
@@ -43,8 +49,28 @@ var features = providers.DocumentationNotes{
 	providers.DocOfficiallySupported: providers.Can(),
 }
 
+// zoneSettings are the zone settings with string values that can be set with providerMeta.
+// https://api.cloudflare.com/#zone-settings-properties
+var zoneSettings = providers.ZoneSettings{
+	"always_online",
+	"always_use_https",
+	"automatic_https_rewrites",
+	"brotli",
+	"email_obfuscation",
+	"hotlink_protection",
+	"ip_geolocation",
+	"ipv6",
+	"min_tls_version",
+	"opportunistic_encryption",
+	"rocket_loader",
+	"security_level",
+	"ssl",
+	"tls_1_3",
+	"websockets",
+}
+
 func init() {
-	providers.RegisterDomainServiceProviderType("CLOUDFLAREAPI", newCloudflare, features)
+	providers.RegisterDomainServiceProviderType("CLOUDFLAREAPI", newCloudflare, features, zoneSettings, providers.NoWildcards{"NS"})
 	providers.RegisterCustomRecordType("CF_REDIRECT", "CLOUDFLAREAPI", "")
 	providers.RegisterCustomRecordType("CF_TEMP_REDIRECT", "CLOUDFLAREAPI", "")
 }
@@ -58,6 +84,7 @@ type CloudflareApi struct {
 	ipConversions   []transform.IpConversion
 	ignoredLabels   []string
 	manageRedirects bool
+	client          *http.Client
 }
 
 func labelMatches(label string, matches []string) bool {
@@ -169,15 +196,47 @@ func (c *CloudflareApi) GetDomainCorrections(dc *models.DomainConfig) ([]*models
 		} else {
 			e := ex.Original.(*cfRecord)
 			proxy := e.Proxiable && rec.Metadata[metaProxy] != "off"
+			mod := &models.Correction{Msg: d.String()}
+			mod.F = func() error {
+				err := c.modifyRecord(id, e.ID, proxy, rec)
+				if err == nil {
+					mod.ID = e.ID
+				}
+				return err
+			}
+			corrections = append(corrections, mod)
+		}
+	}
+
+	if len(dc.ProviderMeta) > 0 {
+		existing, err := c.getSettings(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range changedSettings(existing, dc.ProviderMeta) {
+			name, value := name, dc.ProviderMeta[name]
 			corrections = append(corrections, &models.Correction{
-				Msg: d.String(),
-				F:   func() error { return c.modifyRecord(id, e.ID, proxy, rec) },
+				Msg: fmt.Sprintf("MODIFY zone setting %s: (%s) -> (%s)", name, existing[name], value),
+				F:   func() error { return c.changeSetting(id, name, value) },
 			})
 		}
 	}
 	return corrections, nil
 }
 
+// changedSettings returns the names of the desired zone settings that differ
+// from the existing ones, in order. Settings that are not mentioned are left alone.
+func changedSettings(existing, desired map[string]string) []string {
+	var changed []string
+	for name, v := range desired {
+		if existing[name] != v {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
 func checkNSModifications(dc *models.DomainConfig) {
 	newList := make([]*models.RecordConfig, 0, len(dc.Records))
 	for _, rec := range dc.Records {
@@ -232,7 +291,8 @@ func (c *CloudflareApi) preprocessConfig(dc *models.DomainConfig) error {
 		if rec.Metadata == nil {
 			rec.Metadata = map[string]string{}
 		}
-		if rec.TTL == 0 || rec.TTL == 300 {
+		if rec.TTL == 0 || rec.TTL == 300 || rec.Metadata[models.MetaAutoTTL] == "true" {
+			// TTL 1 is Cloudflare's "automatic" TTL.
 			rec.TTL = 1
 		}
 		if rec.TTL != 1 && rec.TTL < 120 {
@@ -305,8 +365,12 @@ func newCloudflare(m map[string]string, metadata json.RawMessage) (providers.DNS
 	if api.ApiKey == "" || api.ApiUser == "" {
 		return nil, errors.Errorf("cloudflare apikey and apiuser must be provided")
 	}
+	var err error
+	if api.client, err = providers.HTTPClient(m); err != nil {
+		return nil, err
+	}
 
-	err := api.fetchDomainList()
+	err = api.fetchDomainList()
 	if err != nil {
 		return nil, err
 	}
@@ -417,6 +481,51 @@ func getProxyMetadata(r *models.RecordConfig) map[string]string {
 	}
 }
 
+// ProxiedRecords returns the keys (see ProxyKey) of the records of domain
+// that are proxied by Cloudflare. Zone exports don't include the proxy
+// state, so convertzone uses this to add CF_PROXY_ON to imported records.
+func (c *CloudflareApi) ProxiedRecords(domain string) (map[string]bool, error) {
+	id, ok := c.domainIndex[domain]
+	if !ok {
+		return nil, errors.Errorf("%s not listed in zones for cloudflare account", domain)
+	}
+	records, err := c.getRecordsForDomain(id, domain)
+	if err != nil {
+		return nil, err
+	}
+	return proxiedKeys(records), nil
+}
+
+func proxiedKeys(records []*models.RecordConfig) map[string]bool {
+	keys := map[string]bool{}
+	for _, r := range records {
+		if r.Original.(*cfRecord).Proxied {
+			keys[ProxyKey(r.GetLabelFQDN(), r.Type, r.GetTargetField())] = true
+		}
+	}
+	return keys
+}
+
+// ProxyKey identifies a record for ProxiedRecords. Case and trailing dots
+// don't matter.
+func ProxyKey(fqdn, rType, target string) string {
+	norm := func(s string) string { return strings.ToLower(strings.TrimSuffix(s, ".")) }
+	return norm(fqdn) + " " + rType + " " + norm(target)
+}
+
+// ListZones returns the names of the zones of the account.
+func (c *CloudflareApi) ListZones() ([]string, error) {
+	if err := c.fetchDomainList(); err != nil {
+		return nil, err
+	}
+	var zones []string
+	for domain := range c.domainIndex {
+		zones = append(zones, domain)
+	}
+	sort.Strings(zones)
+	return zones, nil
+}
+
 // EnsureDomainExists returns an error of domain does not exist.
 func (c *CloudflareApi) EnsureDomainExists(domain string) error {
 	if _, ok := c.domainIndex[domain]; ok {
@@ -126,3 +126,24 @@ func TestIpRewriting(t *testing.T) {
 		}
 	}
 }
+
+func TestPreprocess_AutoTTL(t *testing.T) {
+	cf := &CloudflareApi{}
+	domain := newDomainConfig()
+	auto := makeRCmeta(map[string]string{models.MetaAutoTTL: "true"})
+	auto.TTL = 3600
+	low := makeRCmeta(map[string]string{})
+	low.TTL = 60
+	def := makeRCmeta(map[string]string{})
+	def.TTL = 300
+	domain.Records = append(domain.Records, auto, low, def)
+	if err := cf.preprocessConfig(domain); err != nil {
+		t.Fatal(err)
+	}
+	expected := []uint32{1, 120, 1}
+	for i, rec := range domain.Records {
+		if rec.TTL != expected[i] {
+			t.Errorf("At index %d: expected TTL %d but found %d", i, expected[i], rec.TTL)
+		}
+	}
+}
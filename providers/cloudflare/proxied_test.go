@@ -0,0 +1,36 @@
+package cloudflare
+
+import (
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+func TestProxiedKeys(t *testing.T) {
+	var records []*models.RecordConfig
+	for _, n := range []*cfRecord{
+		{Type: "A", Name: "www.example.com", Content: "1.2.3.4", Proxied: true},
+		{Type: "A", Name: "mail.example.com", Content: "1.2.3.5"},
+		{Type: "CNAME", Name: "blog.example.com", Content: "blog.example.net", Proxied: true},
+		{Type: "CNAME", Name: "docs.example.com", Content: "docs.example.net"},
+		{Type: "MX", Name: "example.com", Content: "mail.example.com", Priority: 10},
+	} {
+		records = append(records, n.nativeToRecord("example.com"))
+	}
+	keys := proxiedKeys(records)
+	for key, expected := range map[string]bool{
+		ProxyKey("www.example.com.", "A", "1.2.3.4"):               true,
+		ProxyKey("WWW.example.com", "A", "1.2.3.4"):                true,
+		ProxyKey("mail.example.com", "A", "1.2.3.5"):               false,
+		ProxyKey("blog.example.com", "CNAME", "Blog.Example.NET."): true,
+		ProxyKey("docs.example.com", "CNAME", "docs.example.net."): false,
+		ProxyKey("www.example.com", "A", "1.2.3.5"):                false,
+	} {
+		if keys[key] != expected {
+			t.Errorf("%q: expected proxied=%v", key, expected)
+		}
+	}
+	if len(keys) != 2 {
+		t.Errorf("expected 2 proxied records, got %v", keys)
+	}
+}
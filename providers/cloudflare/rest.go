@@ -20,6 +20,8 @@ const (
 	pageRulesURL      = zonesURL + "%s/pagerules/"
 	singlePageRuleURL = pageRulesURL + "%s"
 	singleRecordURL   = recordsURL + "%s"
+	settingsURL       = zonesURL + "%s/settings/"
+	singleSettingURL  = settingsURL + "%s"
 )
 
 // get list of domains for account. Cache so the ids can be looked up from domain name
@@ -81,19 +83,22 @@ func (c *CloudflareApi) getRecordsForDomain(id string, domain string) ([]*models
 
 // create a correction to delete a record
 func (c *CloudflareApi) deleteRec(rec *cfRecord, domainID string) *models.Correction {
-	return &models.Correction{
+	del := &models.Correction{
 		Msg: fmt.Sprintf("DELETE record: %s %s %d %s (id=%s)", rec.Name, rec.Type, rec.TTL, rec.Content, rec.ID),
-		F: func() error {
-			endpoint := fmt.Sprintf(singleRecordURL, domainID, rec.ID)
-			req, err := http.NewRequest("DELETE", endpoint, nil)
-			if err != nil {
-				return err
-			}
-			c.setHeaders(req)
-			_, err = handleActionResponse(http.DefaultClient.Do(req))
+	}
+	del.F = func() error {
+		endpoint := fmt.Sprintf(singleRecordURL, domainID, rec.ID)
+		req, err := http.NewRequest("DELETE", endpoint, nil)
+		if err != nil {
 			return err
-		},
+		}
+		c.setHeaders(req)
+		if _, err = handleActionResponse(c.client.Do(req)); err == nil {
+			del.ID = rec.ID
+		}
+		return err
 	}
+	return del
 }
 
 func (c *CloudflareApi) createZone(domainName string) (string, error) {
@@ -113,7 +118,7 @@ func (c *CloudflareApi) createZone(domainName string) (string, error) {
 		return "", err
 	}
 	c.setHeaders(req)
-	id, err = handleActionResponse(http.DefaultClient.Do(req))
+	id, err = handleActionResponse(c.client.Do(req))
 	return id, err
 }
 
@@ -156,40 +161,42 @@ func (c *CloudflareApi) createRec(rec *models.RecordConfig, domainID string) []*
 	if rec.Type == "MX" {
 		prio = fmt.Sprintf(" %d ", rec.MxPreference)
 	}
-	arr := []*models.Correction{{
+	create := &models.Correction{
 		Msg: fmt.Sprintf("CREATE record: %s %s %d%s %s", rec.GetLabel(), rec.Type, rec.TTL, prio, content),
-		F: func() error {
-
-			cf := &createRecord{
-				Name:     rec.GetLabel(),
-				Type:     rec.Type,
-				TTL:      rec.TTL,
-				Content:  content,
-				Priority: rec.MxPreference,
-			}
-			if rec.Type == "SRV" {
-				cf.Data = cfSrvData(rec)
-				cf.Name = rec.GetLabelFQDN()
-			} else if rec.Type == "CAA" {
-				cf.Data = cfCaaData(rec)
-				cf.Name = rec.GetLabelFQDN()
-				cf.Content = ""
-			}
-			endpoint := fmt.Sprintf(recordsURL, domainID)
-			buf := &bytes.Buffer{}
-			encoder := json.NewEncoder(buf)
-			if err := encoder.Encode(cf); err != nil {
-				return err
-			}
-			req, err := http.NewRequest("POST", endpoint, buf)
-			if err != nil {
-				return err
-			}
-			c.setHeaders(req)
-			id, err = handleActionResponse(http.DefaultClient.Do(req))
+	}
+	create.F = func() error {
+
+		cf := &createRecord{
+			Name:     rec.GetLabel(),
+			Type:     rec.Type,
+			TTL:      rec.TTL,
+			Content:  content,
+			Priority: rec.MxPreference,
+		}
+		if rec.Type == "SRV" {
+			cf.Data = cfSrvData(rec)
+			cf.Name = rec.GetLabelFQDN()
+		} else if rec.Type == "CAA" {
+			cf.Data = cfCaaData(rec)
+			cf.Name = rec.GetLabelFQDN()
+			cf.Content = ""
+		}
+		endpoint := fmt.Sprintf(recordsURL, domainID)
+		buf := &bytes.Buffer{}
+		encoder := json.NewEncoder(buf)
+		if err := encoder.Encode(cf); err != nil {
 			return err
-		},
-	}}
+		}
+		req, err := http.NewRequest("POST", endpoint, buf)
+		if err != nil {
+			return err
+		}
+		c.setHeaders(req)
+		id, err = handleActionResponse(c.client.Do(req))
+		create.ID = id
+		return err
+	}
+	arr := []*models.Correction{create}
 	if rec.Metadata[metaProxy] != "off" {
 		arr = append(arr, &models.Correction{
 			Msg: fmt.Sprintf("ACTIVATE PROXY for new record %s %s %d %s", rec.GetLabel(), rec.Type, rec.TTL, rec.GetTargetField()),
@@ -242,7 +249,7 @@ func (c *CloudflareApi) modifyRecord(domainID, recID string, proxied bool, rec *
 		return err
 	}
 	c.setHeaders(req)
-	_, err = handleActionResponse(http.DefaultClient.Do(req))
+	_, err = handleActionResponse(c.client.Do(req))
 	return err
 }
 
@@ -275,7 +282,7 @@ func (c *CloudflareApi) get(endpoint string, target interface{}) error {
 		return err
 	}
 	c.setHeaders(req)
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.client.Do(req)
 	if err != nil {
 		return err
 	}
@@ -333,7 +340,7 @@ func (c *CloudflareApi) deletePageRule(recordID, domainID string) error {
 		return err
 	}
 	c.setHeaders(req)
-	_, err = handleActionResponse(http.DefaultClient.Do(req))
+	_, err = handleActionResponse(c.client.Do(req))
 	return err
 }
 
@@ -379,7 +386,34 @@ func (c *CloudflareApi) sendPageRule(endpoint, method string, data string) error
 		return err
 	}
 	c.setHeaders(req)
-	_, err = handleActionResponse(http.DefaultClient.Do(req))
+	_, err = handleActionResponse(c.client.Do(req))
+	return err
+}
+
+// getSettings returns the zone settings that have string values.
+func (c *CloudflareApi) getSettings(domainID string) (map[string]string, error) {
+	data := settingsResponse{}
+	if err := c.get(fmt.Sprintf(settingsURL, domainID), &data); err != nil {
+		return nil, errors.Errorf("Error fetching zone settings from cloudflare: %s", err)
+	}
+	if !data.Success {
+		return nil, errors.Errorf("Error fetching zone settings from cloudflare: %s", stringifyErrors(data.Errors))
+	}
+	return data.stringValues(), nil
+}
+
+func (c *CloudflareApi) changeSetting(domainID, name, value string) error {
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(map[string]string{"value": value}); err != nil {
+		return err
+	}
+	req, err := http.NewRequest("PATCH", fmt.Sprintf(singleSettingURL, domainID, name), buf)
+	if err != nil {
+		return err
+	}
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+	_, err = handleActionResponse(c.client.Do(req))
 	return err
 }
 
@@ -459,3 +493,24 @@ type pagingInfo struct {
 	Count      int `json:"count"`
 	TotalCount int `json:"total_count"`
 }
+
+type settingsResponse struct {
+	basicResponse
+	Result []struct {
+		ID    string          `json:"id"`
+		Value json.RawMessage `json:"value"`
+	} `json:"result"`
+}
+
+// stringValues returns the settings that have string values. Others (numbers
+// and objects) can't be set with providerMeta.
+func (r *settingsResponse) stringValues() map[string]string {
+	settings := map[string]string{}
+	for _, s := range r.Result {
+		var v string
+		if json.Unmarshal(s.Value, &v) == nil {
+			settings[s.ID] = v
+		}
+	}
+	return settings
+}
@@ -0,0 +1,41 @@
+package cloudflare
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSettingsStringValues(t *testing.T) {
+	data := `{"success":true,"errors":[],"result":[
+		{"id":"ssl","value":"flexible","editable":true},
+		{"id":"always_use_https","value":"off","editable":true},
+		{"id":"browser_cache_ttl","value":14400,"editable":true},
+		{"id":"minify","value":{"css":"off","html":"off","js":"off"},"editable":true}
+	]}`
+	r := settingsResponse{}
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"ssl": "flexible", "always_use_https": "off"}
+	if got := r.stringValues(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestChangedSettings(t *testing.T) {
+	existing := map[string]string{"ssl": "flexible", "always_use_https": "off", "ipv6": "on"}
+	for _, tst := range []struct {
+		desired  map[string]string
+		expected []string
+	}{
+		{map[string]string{"ssl": "flexible"}, nil},
+		{map[string]string{"ssl": "full", "always_use_https": "on", "ipv6": "on"}, []string{"always_use_https", "ssl"}},
+		// Settings that aren't read back (e.g. not yet set) are always changed.
+		{map[string]string{"tls_1_3": "on"}, []string{"tls_1_3"}},
+	} {
+		if got := changedSettings(existing, tst.desired); !reflect.DeepEqual(got, tst.expected) {
+			t.Errorf("%v: expected %v, got %v", tst.desired, tst.expected, got)
+		}
+	}
+}
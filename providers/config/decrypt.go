@@ -0,0 +1,95 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/DisposaBoy/JsonConfigReader"
+	"github.com/pkg/errors"
+)
+
+// decryptCommand runs a decryption tool and returns what it writes to its
+// standard output. Tests replace it.
+var decryptCommand = func(name string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.Errorf("%s: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// decrypt returns the plaintext of a credentials file, whose content is dat.
+// Files encrypted with sops (https://github.com/mozilla/sops) are decrypted by
+// running sops, which finds its keys itself, as in $SOPS_AGE_KEY_FILE or
+// $SOPS_AGE_KEY. Files encrypted with age (https://age-encryption.org) are
+// decrypted by running age with the identity in $SOPS_AGE_KEY_FILE or
+// $SOPS_AGE_KEY. Other files are returned as they are. Nothing decrypted is
+// written to disk.
+func decrypt(fname string, dat []byte) ([]byte, error) {
+	switch {
+	case isAge(dat):
+		identity := os.Getenv("SOPS_AGE_KEY_FILE")
+		if identity == "" {
+			key := os.Getenv("SOPS_AGE_KEY")
+			if key == "" {
+				return nil, errors.Errorf("%s is encrypted with age, but there is no key to decrypt it (set -creds-key, $SOPS_AGE_KEY_FILE or $SOPS_AGE_KEY)", fname)
+			}
+			// age only reads identities from files.
+			f, err := ioutil.TempFile("", "dnscontrol-age")
+			if err != nil {
+				return nil, err
+			}
+			defer os.Remove(f.Name())
+			_, err = f.WriteString(key + "\n")
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return nil, err
+			}
+			identity = f.Name()
+		}
+		out, err := decryptCommand("age", "--decrypt", "--identity", identity, fname)
+		if err != nil {
+			return nil, errors.Errorf("decrypting %s with age: %s", fname, err)
+		}
+		return out, nil
+	case isSops(dat):
+		out, err := decryptCommand("sops", "--decrypt", "--input-type", "json", "--output-type", "json", fname)
+		if err != nil {
+			return nil, errors.Errorf("decrypting %s with sops: %s", fname, err)
+		}
+		return out, nil
+	}
+	return dat, nil
+}
+
+// isAge returns true if dat is encrypted with age, in its binary or its
+// armored (-a) format.
+func isAge(dat []byte) bool {
+	return bytes.HasPrefix(dat, []byte("age-encryption.org/v1\n")) ||
+		bytes.HasPrefix(bytes.TrimSpace(dat), []byte("-----BEGIN AGE ENCRYPTED FILE-----"))
+}
+
+// isSops returns true if dat is a JSON file encrypted with sops, which adds
+// a "sops" object with its metadata, and a MAC of the plaintext.
+func isSops(dat []byte) bool {
+	var top map[string]json.RawMessage
+	if json.NewDecoder(JsonConfigReader.New(bytes.NewReader(dat))).Decode(&top) != nil {
+		return false
+	}
+	var meta struct {
+		MAC string `json:"mac"`
+	}
+	return top["sops"] != nil && json.Unmarshal(top["sops"], &meta) == nil && meta.MAC != ""
+}
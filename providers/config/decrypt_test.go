@@ -0,0 +1,99 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// The fixtures in testdata have the format of files encrypted with sops and
+// age, but can't be decrypted: the tools are replaced by fakeDecrypt.
+const plainCreds = `{"r53": {"KeyId": "AKIA", "SecretKey": "s3cret"}}`
+
+// fakeDecrypt replaces decryptCommand until the func it returns is called.
+// It records the command lines in *ran, and decrypts to plainCreds.
+func fakeDecrypt(ran *[]string, fail bool) func() {
+	cmd := decryptCommand
+	decryptCommand = func(name string, args ...string) ([]byte, error) {
+		*ran = append(*ran, name+" "+strings.Join(args, " "))
+		if fail {
+			return nil, errors.Errorf("exit status 128: no identity matched any of the recipients")
+		}
+		return []byte(plainCreds), nil
+	}
+	return func() { decryptCommand = cmd }
+}
+
+func TestLoadEncryptedCreds(t *testing.T) {
+	for _, v := range []string{"SOPS_AGE_KEY_FILE", "SOPS_AGE_KEY"} {
+		old, ok := os.LookupEnv(v)
+		os.Unsetenv(v)
+		if ok {
+			defer os.Setenv(v, old)
+		}
+	}
+	want := map[string]map[string]string{"r53": {"KeyId": "AKIA", "SecretKey": "s3cret"}}
+	sopsFile := filepath.Join("testdata", "creds.sops.json")
+	ageFile := filepath.Join("testdata", "creds.json.age")
+
+	var ran []string
+	defer fakeDecrypt(&ran, false)()
+	configs, err := LoadProviderConfigs(sopsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(configs, want) || len(ran) != 1 || ran[0] != "sops --decrypt --input-type json --output-type json "+sopsFile {
+		t.Errorf("Expected sops to decrypt %s to %v, ran %q and got %v", sopsFile, want, ran, configs)
+	}
+
+	// age needs a key.
+	ran = nil
+	if _, err := LoadProviderConfigs(ageFile); err == nil || !strings.Contains(err.Error(), "no key to decrypt it") || ran != nil {
+		t.Errorf("Expected an error about the missing key, got %v, ran %q", err, ran)
+	}
+	os.Setenv("SOPS_AGE_KEY_FILE", "key.txt")
+	configs, err = LoadProviderConfigs(ageFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(configs, want) || len(ran) != 1 || ran[0] != "age --decrypt --identity key.txt "+ageFile {
+		t.Errorf("Expected age to decrypt %s to %v, ran %q and got %v", ageFile, want, ran, configs)
+	}
+	// The key itself is written to a file that is removed afterwards.
+	os.Unsetenv("SOPS_AGE_KEY_FILE")
+	os.Setenv("SOPS_AGE_KEY", "AGE-SECRET-KEY-1FAKE")
+	ran = nil
+	if _, err := LoadProviderConfigs(ageFile); err != nil {
+		t.Fatal(err)
+	}
+	identity := strings.Fields(ran[0])[3]
+	if _, err := os.Stat(identity); !os.IsNotExist(err) {
+		t.Errorf("Expected the identity file %s to be removed, got %v", identity, err)
+	}
+	os.Unsetenv("SOPS_AGE_KEY")
+
+	// Plaintext files are read as they are, even with a "sops" provider.
+	dir, err := ioutil.TempDir("", "creds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	plain := filepath.Join(dir, "creds.json")
+	if err := ioutil.WriteFile(plain, []byte(`{"sops": {"apikey": "x"}, "r53": {"KeyId": "AKIA"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ran = nil
+	if _, err := LoadProviderConfigs(plain); err != nil || ran != nil {
+		t.Errorf("Expected the plaintext file to be read, got %v, ran %q", err, ran)
+	}
+
+	defer fakeDecrypt(&ran, true)()
+	if _, err := LoadProviderConfigs(sopsFile); err == nil || !strings.Contains(err.Error(), "decrypting "+sopsFile+" with sops: exit status 128") {
+		t.Errorf("Expected the error of sops, got %v", err)
+	}
+}
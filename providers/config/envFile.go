@@ -0,0 +1,59 @@
+package config
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LoadEnvFile sets the environment variables listed in a .env file, so that
+// $VAR references in the credentials file can be resolved without exporting
+// them first. Variables that are already set are not changed. A missing file
+// is not an error.
+//
+// Each line is NAME=VALUE, optionally starting with "export ". Empty lines
+// and lines starting with # are skipped. The value may be in single quotes,
+// which are taken literally, or in double quotes, where \n, \" and \\ are
+// unescaped.
+func LoadEnvFile(fname string) error {
+	f, err := os.Open(fname)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.Errorf("While reading env file %v: %v", fname, err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		i := strings.Index(line, "=")
+		if i < 1 {
+			return errors.Errorf("While reading env file %v: line %d is not NAME=VALUE", fname, n)
+		}
+		name, value := strings.TrimSpace(line[:i]), envValue(strings.TrimSpace(line[i+1:]))
+		if _, ok := os.LookupEnv(name); ok {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return errors.Errorf("While reading env file %v: line %d: %v", fname, n, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func envValue(v string) string {
+	if len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'' {
+		return v[1 : len(v)-1]
+	}
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		return strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(v[1 : len(v)-1])
+	}
+	return v
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadEnvFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "envfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	envFile := filepath.Join(dir, ".env")
+	err = ioutil.WriteFile(envFile, []byte(`# credentials for local runs
+DNSC_TEST_USER=fromfile
+export DNSC_TEST_KEY = "s3cr\"et"
+DNSC_TEST_LITERAL='a\nb'
+DNSC_TEST_SET=fromfile
+
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	credsFile := filepath.Join(dir, "creds.json")
+	err = ioutil.WriteFile(credsFile, []byte(`{
+  "p": {
+    "user": "$DNSC_TEST_USER",
+    "key": "$DNSC_TEST_KEY",
+    "literal": "$DNSC_TEST_LITERAL",
+    "set": "$DNSC_TEST_SET"
+  }
+}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("DNSC_TEST_SET", "fromenv")
+	defer func() {
+		for _, v := range []string{"DNSC_TEST_USER", "DNSC_TEST_KEY", "DNSC_TEST_LITERAL", "DNSC_TEST_SET"} {
+			os.Unsetenv(v)
+		}
+	}()
+
+	if err := LoadEnvFile(envFile); err != nil {
+		t.Fatal(err)
+	}
+	configs, err := LoadProviderConfigs(credsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, expected := range map[string]string{
+		"user":    "fromfile",
+		"key":     `s3cr"et`,
+		"literal": `a\nb`,
+		// Already set variables win.
+		"set": "fromenv",
+	} {
+		if configs["p"][k] != expected {
+			t.Errorf("%s: expected %q, got %q", k, expected, configs["p"][k])
+		}
+	}
+}
+
+func TestLoadEnvFileErrors(t *testing.T) {
+	if err := LoadEnvFile(filepath.Join(os.TempDir(), "no-such-dnscontrol.env")); err != nil {
+		t.Errorf("a missing file should be ignored, got %s", err)
+	}
+	f, err := ioutil.TempFile("", "envfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("DNSC_TEST_OK=1\nnot a variable\n")
+	f.Close()
+	defer os.Unsetenv("DNSC_TEST_OK")
+	if err := LoadEnvFile(f.Name()); err == nil {
+		t.Errorf("expected an error for a line without =")
+	}
+}
@@ -7,6 +7,7 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/DisposaBoy/JsonConfigReader"
@@ -15,6 +16,14 @@ import (
 )
 
 // LoadProviderConfigs will open the specified file name, and parse its contents. It will replace environment variables it finds if any value matches $[A-Za-z_-0-9]+
+//
+// The credentials of a provider may be kept in a file of their own, so that
+// access to them can be granted separately. Its entry is then the path of
+// that file, relative to fname:
+//    "r53": "secrets/r53.json"
+// and the file holds what the entry would, such as {"KeyId": "...", "SecretKey": "..."}.
+//
+// Either file may be encrypted with sops or age, see decrypt.
 func LoadProviderConfigs(fname string) (map[string]map[string]string, error) {
 	var results = map[string]map[string]string{}
 	dat, err := utfutil.ReadFile(fname, utfutil.POSIX)
@@ -25,18 +34,56 @@ func LoadProviderConfigs(fname string) (map[string]map[string]string, error) {
 		}
 		return nil, errors.Errorf("While reading provider credentials file %v: %v", fname, err)
 	}
+	if dat, err = decrypt(fname, dat); err != nil {
+		return nil, err
+	}
 	s := string(dat)
 	r := JsonConfigReader.New(strings.NewReader(s))
-	err = json.NewDecoder(r).Decode(&results)
+	var entries map[string]json.RawMessage
+	err = json.NewDecoder(r).Decode(&entries)
 	if err != nil {
 		return nil, errors.Errorf("While parsing provider credentials file %v: %v", fname, err)
 	}
+	for name, raw := range entries {
+		var path string
+		if json.Unmarshal(raw, &path) == nil {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(filepath.Dir(fname), path)
+			}
+			if results[name], err = loadCredsFile(path); err != nil {
+				return nil, errors.Errorf("While reading the credentials of %s: %v", name, err)
+			}
+			continue
+		}
+		var creds map[string]string
+		if err := json.Unmarshal(raw, &creds); err != nil {
+			return nil, errors.Errorf("While parsing provider credentials file %v: %s: %v", fname, name, err)
+		}
+		results[name] = creds
+	}
 	if err = replaceEnvVars(results); err != nil {
 		return nil, err
 	}
 	return results, nil
 }
 
+// loadCredsFile reads the credentials of one provider from their own file.
+func loadCredsFile(fname string) (map[string]string, error) {
+	dat, err := utfutil.ReadFile(fname, utfutil.POSIX)
+	if err != nil {
+		return nil, err
+	}
+	if dat, err = decrypt(fname, dat); err != nil {
+		return nil, err
+	}
+	var creds map[string]string
+	r := JsonConfigReader.New(strings.NewReader(string(dat)))
+	if err := json.NewDecoder(r).Decode(&creds); err != nil {
+		return nil, errors.Errorf("parsing %v: %v", fname, err)
+	}
+	return creds, nil
+}
+
 func replaceEnvVars(m map[string]map[string]string) error {
 	for _, keys := range m {
 		for k, v := range keys {
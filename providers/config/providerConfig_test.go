@@ -0,0 +1,53 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadProviderConfigsFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "creds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, "secrets"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"creds.json": `{
+  "bind": {"directory": "zones"},
+  // Only CI can read these.
+  "r53": "secrets/r53.json",
+}`,
+		"secrets/r53.json": `{"KeyId": "AKIA", "SecretKey": "$DNSC_TEST_SECRET",}`,
+		"missing.json":     `{"bind": {}, "r53": "secrets/none.json"}`,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.Setenv("DNSC_TEST_SECRET", "s3cret")
+	defer os.Unsetenv("DNSC_TEST_SECRET")
+
+	configs, err := LoadProviderConfigs(filepath.Join(dir, "creds.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]map[string]string{
+		"bind": {"directory": "zones"},
+		"r53":  {"KeyId": "AKIA", "SecretKey": "s3cret"},
+	}
+	if !reflect.DeepEqual(configs, expected) {
+		t.Errorf("expected %v, got %v", expected, configs)
+	}
+
+	_, err = LoadProviderConfigs(filepath.Join(dir, "missing.json"))
+	if err == nil || !strings.Contains(err.Error(), "credentials of r53") || !strings.Contains(err.Error(), "none.json") {
+		t.Errorf("expected an error naming r53 and its file, got %v", err)
+	}
+}
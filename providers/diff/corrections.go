@@ -0,0 +1,95 @@
+package diff
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/pkg/errors"
+)
+
+// RecordEditor is implemented by providers whose API has an object for each
+// record: each value of a record set is created, changed and deleted on its
+// own.
+type RecordEditor interface {
+	CreateRecord(domain string, rc *models.RecordConfig) error
+	ModifyRecord(domain string, existing, desired *models.RecordConfig) error
+	DeleteRecord(domain string, rc *models.RecordConfig) error
+}
+
+// RecordSetEditor is implemented by providers whose API has an object for
+// each record set, the records of a name and type, which is written with all
+// its values at once.
+type RecordSetEditor interface {
+	// ReplaceRecordSet makes desired the records of the set of key. existing
+	// is empty for a new set, and desired is empty for a set to delete.
+	ReplaceRecordSet(domain string, key models.RecordKey, existing, desired models.Records) error
+}
+
+// Corrections returns the corrections that turn the existing records into
+// the desired ones with editor, which must be a RecordEditor or a
+// RecordSetEditor. A RecordSetEditor gets one call for each changed record
+// set. A RecordEditor gets one call for each changed record, ordered so that
+// a record set that keeps existing is never empty in between: its new values
+// are created before its old ones are deleted. Only record sets that go away
+// entirely are deleted first, so that a name can change type.
+func (d *differ) Corrections(existing []*models.RecordConfig, editor interface{}) ([]*models.Correction, error) {
+	domain := d.dc.Name
+	switch e := editor.(type) {
+	case RecordSetEditor:
+		changed := d.ChangedGroups(existing)
+		var keys []models.RecordKey
+		for k := range changed {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].Name < keys[j].Name || (keys[i].Name == keys[j].Name && keys[i].Type < keys[j].Type)
+		})
+		had, want := models.Records(existing).Grouped(), models.Records(d.dc.Records).Grouped()
+		var corrections []*models.Correction
+		for _, k := range keys {
+			k := k
+			corrections = append(corrections, &models.Correction{
+				Msg: strings.Join(changed[k], "\n"),
+				F:   func() error { return e.ReplaceRecordSet(domain, k, had[k], want[k]) },
+			})
+		}
+		return corrections, nil
+	case RecordEditor:
+		_, create, toDelete, modify := d.IncrementalDiff(existing)
+		for _, cs := range []Changeset{create, toDelete, modify} {
+			sort.Slice(cs, func(i, j int) bool { return cs[i].String() < cs[j].String() })
+		}
+		declared := map[models.RecordKey]bool{}
+		for _, r := range d.dc.Records {
+			declared[r.Key()] = true
+		}
+		var gone, rest Changeset
+		for _, c := range toDelete {
+			if declared[c.Existing.Key()] {
+				rest = append(rest, c)
+			} else {
+				gone = append(gone, c)
+			}
+		}
+		var corrections []*models.Correction
+		for _, c := range gone {
+			c := c
+			corrections = append(corrections, &models.Correction{Msg: c.String(), F: func() error { return e.DeleteRecord(domain, c.Existing) }})
+		}
+		for _, c := range create {
+			c := c
+			corrections = append(corrections, &models.Correction{Msg: c.String(), F: func() error { return e.CreateRecord(domain, c.Desired) }})
+		}
+		for _, c := range modify {
+			c := c
+			corrections = append(corrections, &models.Correction{Msg: c.String(), F: func() error { return e.ModifyRecord(domain, c.Existing, c.Desired) }})
+		}
+		for _, c := range rest {
+			c := c
+			corrections = append(corrections, &models.Correction{Msg: c.String(), F: func() error { return e.DeleteRecord(domain, c.Existing) }})
+		}
+		return corrections, nil
+	}
+	return nil, errors.Errorf("%T is neither a diff.RecordEditor nor a diff.RecordSetEditor", editor)
+}
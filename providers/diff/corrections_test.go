@@ -0,0 +1,108 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+// recordAPI is a provider API with an object for each record.
+type recordAPI struct{ calls *[]string }
+
+func (a recordAPI) CreateRecord(domain string, rc *models.RecordConfig) error {
+	*a.calls = append(*a.calls, fmt.Sprintf("create %s %s %s", rc.GetLabelFQDN(), rc.Type, rc.GetTargetField()))
+	return nil
+}
+
+func (a recordAPI) ModifyRecord(domain string, existing, desired *models.RecordConfig) error {
+	*a.calls = append(*a.calls, fmt.Sprintf("modify %s %s %s -> %s", desired.GetLabelFQDN(), desired.Type, existing.GetTargetField(), desired.GetTargetField()))
+	return nil
+}
+
+func (a recordAPI) DeleteRecord(domain string, rc *models.RecordConfig) error {
+	*a.calls = append(*a.calls, fmt.Sprintf("delete %s %s %s", rc.GetLabelFQDN(), rc.Type, rc.GetTargetField()))
+	return nil
+}
+
+// recordSetAPI is a provider API with an object for each record set.
+type recordSetAPI struct{ calls *[]string }
+
+func (a recordSetAPI) ReplaceRecordSet(domain string, key models.RecordKey, existing, desired models.Records) error {
+	targets := func(records models.Records) string {
+		var s []string
+		for _, r := range records {
+			s = append(s, r.GetTargetField())
+		}
+		return "[" + strings.Join(s, " ") + "]"
+	}
+	*a.calls = append(*a.calls, fmt.Sprintf("replace %s %s %s -> %s", key.Name, key.Type, targets(existing), targets(desired)))
+	return nil
+}
+
+func TestCorrections(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("www A 300 1.1.1.1"),
+		myRecord("mail CNAME 300 host.example.net."),
+		myRecord("api A 300 2.2.2.1"),
+		myRecord("api A 300 2.2.2.2"),
+		myRecord("ftp A 300 4.4.4.4"),
+	}
+	desired := []*models.RecordConfig{
+		myRecord("www A 300 1.1.1.1"),
+		myRecord("www A 300 1.1.1.2"),
+		myRecord("mail A 300 3.3.3.3"),
+		myRecord("api A 300 2.2.2.1"),
+		myRecord("ftp A 600 4.4.4.4"),
+	}
+	for _, tst := range []struct {
+		name   string
+		editor func(*[]string) interface{}
+		want   []string
+	}{
+		{"records", func(calls *[]string) interface{} { return recordAPI{calls} }, []string{
+			// The CNAME goes away entirely, before the A record replaces it.
+			"delete mail.example.com CNAME host.example.net.",
+			"create mail.example.com A 3.3.3.3",
+			"create www.example.com A 1.1.1.2",
+			"modify ftp.example.com A 4.4.4.4 -> 4.4.4.4",
+			// api keeps a record, so it is never empty.
+			"delete api.example.com A 2.2.2.2",
+		}},
+		{"record sets", func(calls *[]string) interface{} { return recordSetAPI{calls} }, []string{
+			"replace api A [2.2.2.1 2.2.2.2] -> [2.2.2.1]",
+			"replace ftp A [4.4.4.4] -> [4.4.4.4]",
+			"replace mail A [] -> [3.3.3.3]",
+			"replace mail CNAME [host.example.net.] -> []",
+			"replace www A [1.1.1.1] -> [1.1.1.1 1.1.1.2]",
+		}},
+	} {
+		var calls []string
+		dc := &models.DomainConfig{Name: "example.com", Records: desired}
+		corrections, err := New(dc).Corrections(existing, tst.editor(&calls))
+		if err != nil {
+			t.Fatalf("%s: %s", tst.name, err)
+		}
+		for _, c := range corrections {
+			if err := c.F(); err != nil {
+				t.Fatalf("%s: %s", tst.name, err)
+			}
+		}
+		if !reflect.DeepEqual(calls, tst.want) {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tst.name, strings.Join(tst.want, "\n"), strings.Join(calls, "\n"))
+		}
+	}
+
+	// The description of a record set lists each of its changes.
+	dc := &models.DomainConfig{Name: "example.com", Records: desired}
+	corrections, _ := New(dc).Corrections(existing, recordSetAPI{&[]string{}})
+	if want := "DELETE A api.example.com 2.2.2.2 ttl=300"; corrections[0].Msg != want {
+		t.Errorf("Expected %q, got %q", want, corrections[0].Msg)
+	}
+
+	if _, err := New(dc).Corrections(existing, struct{}{}); err == nil {
+		t.Errorf("Expected an editor of neither model to be an error")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"sort"
+	"sync"
 
 	"github.com/StackExchange/dnscontrol/models"
 )
@@ -25,6 +26,8 @@ type Differ interface {
 	// ChangedGroups performs a diff more appropriate for providers with a "RecordSet" model, where all records with the same name and type are grouped.
 	// Individual record changes are often not useful in such scenarios. Instead we return a map of record keys to a list of change descriptions within that group.
 	ChangedGroups(existing []*models.RecordConfig) map[models.RecordKey][]string
+	// Corrections returns the corrections to make with a RecordEditor or a RecordSetEditor, whichever of the two API models the provider has.
+	Corrections(existing []*models.RecordConfig, editor interface{}) ([]*models.Correction, error)
 }
 
 // New is a constructor for a Differ.
@@ -32,17 +35,142 @@ func New(dc *models.DomainConfig, extraValues ...func(*models.RecordConfig) map[
 	return &differ{
 		dc:          dc,
 		extraValues: extraValues,
+		collector:   collectorOf(dc),
+	}
+}
+
+// Collector collects what the diffs of a domain report, for the command
+// that asks a provider for the corrections of the domain. The differs of the
+// domain, and of the copies providers make of it, report to it until Stop.
+type Collector struct {
+	mu      sync.Mutex
+	stopped bool
+	// Existing are the records the provider reported for the domain, before
+	// IGNORE and -only filtering, or nil if nothing was diffed. push -backup
+	// saves them.
+	Existing []*models.RecordConfig
+	// Blocked are the changes left out because they change PROTECTED()
+	// records.
+	Blocked Changeset
+	// Purged are the deletions of records whose name and type the domain
+	// doesn't declare at all: they are purged because they aren't managed,
+	// rather than deleted because the configuration changed.
+	Purged Changeset
+	// Changes are the changes reported. preview uses their existing records
+	// to estimate how long resolvers may serve the old values.
+	Changes Changeset
+}
+
+var collectors = struct {
+	sync.Mutex
+	m map[*models.DomainConfig]*Collector
+}{m: map[*models.DomainConfig]*Collector{}}
+
+// Collect returns a new Collector of the diffs of dc. Each call to a
+// provider should get its own copy of the domain, and its own Collector.
+func Collect(dc *models.DomainConfig) *Collector {
+	c := &Collector{}
+	collectors.Lock()
+	defer collectors.Unlock()
+	collectors.m[dc] = c
+	return c
+}
+
+// Stop stops collecting. What the diffs report afterwards, as those of a call
+// that was given up on may, is dropped: the fields of c can be read once
+// Stop returns.
+func (c *Collector) Stop() {
+	collectors.Lock()
+	for dc, found := range collectors.m {
+		if found == c {
+			delete(collectors.m, dc)
+		}
+	}
+	collectors.Unlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopped = true
+}
+
+// collectorOf returns the Collector of dc, or of the domain it is a copy of.
+func collectorOf(dc *models.DomainConfig) *Collector {
+	collectors.Lock()
+	defer collectors.Unlock()
+	for ; dc != nil; dc = dc.CopiedFrom() {
+		if c := collectors.m[dc]; c != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// add calls f with c, unless there is no collector or it was stopped.
+func (c *Collector) add(f func(c *Collector)) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.stopped {
+		f(c)
 	}
 }
 
 type differ struct {
 	dc          *models.DomainConfig
 	extraValues []func(*models.RecordConfig) map[string]string
+	collector   *Collector
 }
 
+// TTL formats of Correlation.String.
+const (
+	TTLSeconds = "seconds" // ttl=3600
+	TTLHuman   = "human"   // ttl=1h
+	TTLBoth    = "both"    // ttl=3600 (1h)
+)
+
+// TTLFormat is how Correlation.String shows TTLs. preview -ttl-format sets it.
+var TTLFormat = TTLSeconds
+
 // get normalized content for record. target, ttl, mxprio, and specified metadata
 func (d *differ) content(r *models.RecordConfig) string {
-	content := fmt.Sprintf("%v ttl=%d", r.GetTargetCombined(), r.TTL)
+	return d.format(r, fmt.Sprintf("%d", r.TTL))
+}
+
+// display returns the content of the record with the TTL in TTLFormat.
+func (d *differ) display(r *models.RecordConfig) string {
+	switch TTLFormat {
+	case TTLHuman:
+		return d.format(r, HumanTTL(r.TTL))
+	case TTLBoth:
+		return d.format(r, fmt.Sprintf("%d (%s)", r.TTL, HumanTTL(r.TTL)))
+	}
+	return d.content(r)
+}
+
+// HumanTTL returns ttl in the units the DSL accepts, like 1h or 1d12h.
+func HumanTTL(ttl uint32) string {
+	if ttl == 0 {
+		return "0s"
+	}
+	s := ""
+	for _, u := range []struct {
+		name    string
+		seconds uint32
+	}{{"d", 86400}, {"h", 3600}, {"m", 60}, {"s", 1}} {
+		if ttl >= u.seconds {
+			s += fmt.Sprintf("%d%s", ttl/u.seconds, u.name)
+			ttl %= u.seconds
+		}
+	}
+	return s
+}
+
+func (d *differ) format(r *models.RecordConfig, ttl string) string {
+	content := fmt.Sprintf("%v ttl=%s", r.GetTargetCombined(), ttl)
+	if geo, ok := r.Metadata[models.MetaGeo]; ok {
+		content += " geo=" + geo
+	}
 	for _, f := range d.extraValues {
 		// sort the extra values map keys to perform a deterministic
 		// comparison since Golang maps iteration order is not guaranteed
@@ -61,31 +189,59 @@ func (d *differ) content(r *models.RecordConfig) string {
 }
 
 func (d *differ) IncrementalDiff(existing []*models.RecordConfig) (unchanged, create, toDelete, modify Changeset) {
+	var blocked Changeset
+	unchanged, create, toDelete, modify, blocked = d.diff(existing)
+	d.reportBlocked(blocked)
+	d.reportPurged(toDelete)
+	d.reportChanged(create, toDelete, modify)
+	return unchanged, create, toDelete, modify
+}
+
+// setKey identifies a record set: the records of a name and type, and of a
+// location for GEO() records, which are only served to some clients.
+type setKey struct {
+	name, rType, geo string
+}
+
+func keyOf(r *models.RecordConfig) setKey {
+	return setKey{r.GetLabelFQDN(), r.Type, r.Metadata[models.MetaGeo]}
+}
+
+// diff is IncrementalDiff. The changes to PROTECTED() records are left out,
+// in blocked.
+func (d *differ) diff(existing []*models.RecordConfig) (unchanged, create, toDelete, modify, blocked Changeset) {
 	unchanged = Changeset{}
 	create = Changeset{}
 	toDelete = Changeset{}
 	modify = Changeset{}
 	desired := d.dc.Records
+	d.collector.add(func(c *Collector) {
+		c.Existing = append([]*models.RecordConfig{}, existing...)
+	})
 
-	// sort existing and desired by name
-	type key struct {
-		name, rType string
-	}
-	existingByNameAndType := map[key][]*models.RecordConfig{}
-	desiredByNameAndType := map[key][]*models.RecordConfig{}
+	// sort existing and desired by record set, so that records only served
+	// to different clients are never correlated
+	existingByNameAndType := map[setKey][]*models.RecordConfig{}
+	desiredByNameAndType := map[setKey][]*models.RecordConfig{}
 	for _, e := range existing {
-		if d.matchIgnored(e.GetLabel()) {
+		if !d.dc.MatchesOnly(e) {
+			continue
+		}
+		if d.dc.IgnoresLabel(e.GetLabel()) {
 			log.Printf("Ignoring record %s %s due to IGNORE", e.GetLabel(), e.Type)
 		} else {
-			k := key{e.GetLabelFQDN(), e.Type}
+			k := keyOf(e)
 			existingByNameAndType[k] = append(existingByNameAndType[k], e)
 		}
 	}
 	for _, dr := range desired {
-		if d.matchIgnored(dr.GetLabel()) {
+		if !d.dc.MatchesOnly(dr) {
+			continue
+		}
+		if d.dc.IgnoresLabel(dr.GetLabel()) {
 			panic(fmt.Sprintf("Trying to update/add IGNOREd record: %s %s", dr.GetLabel(), dr.Type))
 		} else {
-			k := key{dr.GetLabelFQDN(), dr.Type}
+			k := keyOf(dr)
 			desiredByNameAndType[k] = append(desiredByNameAndType[k], dr)
 		}
 	}
@@ -177,32 +333,157 @@ func (d *differ) IncrementalDiff(existing []*models.RecordConfig) (unchanged, cr
 			create = append(create, Correlation{d, nil, rec})
 		}
 	}
+	preserve(unchanged)
+	preserve(modify)
+	create, toDelete, modify, blocked = d.blockProtected(create, toDelete, modify)
 	return
 }
 
-func (d *differ) ChangedGroups(existing []*models.RecordConfig) map[models.RecordKey][]string {
-	changedKeys := map[models.RecordKey][]string{}
-	_, create, delete, modify := d.IncrementalDiff(existing)
+// preserve copies the Preserved fields of each existing record to the desired
+// record it correlates with, which comes from the configuration and has none.
+// A provider that writes the whole desired record, or the whole record set,
+// then keeps the fields it doesn't model as they were.
+func preserve(cs Changeset) {
+	for _, c := range cs {
+		if len(c.Existing.Preserved) == 0 || c.Desired.Preserved != nil {
+			continue
+		}
+		c.Desired.Preserved = map[string]string{}
+		for k, v := range c.Existing.Preserved {
+			c.Desired.Preserved[k] = v
+		}
+	}
+}
+
+// blockProtected takes the changes to PROTECTED() records out of create,
+// toDelete and modify, unless the domain allows them. A protected record is
+// changed when it is created or modified in a record set that loses or
+// changes other records: its value was edited, and since the records of a
+// set are paired up by value, any of those changes may be the old value
+// going away. Such record sets are left alone entirely. So are the sets
+// losing or changing a record that was protected when the domain was last
+// pushed (dc.Protected), so that removing a PROTECTED() record from the
+// configuration doesn't delete it.
+func (d *differ) blockProtected(create, toDelete, modify Changeset) (Changeset, Changeset, Changeset, Changeset) {
+	blocked := Changeset{}
+	if d.dc.AllowProtectedChanges {
+		return create, toDelete, modify, blocked
+	}
+	lost, changed := map[setKey]bool{}, map[setKey]bool{}
+	for _, c := range toDelete {
+		lost[keyOf(c.Existing)] = true
+		changed[keyOf(c.Existing)] = changed[keyOf(c.Existing)] || d.dc.WasProtected(c.Existing)
+	}
+	for _, c := range modify {
+		lost[keyOf(c.Existing)] = true
+		changed[keyOf(c.Desired)] = changed[keyOf(c.Desired)] || isProtected(c.Desired) || d.dc.WasProtected(c.Existing)
+	}
 	for _, c := range create {
-		changedKeys[c.Desired.Key()] = append(changedKeys[c.Desired.Key()], c.String())
+		changed[keyOf(c.Desired)] = changed[keyOf(c.Desired)] || isProtected(c.Desired)
+	}
+	keep := func(changes Changeset) Changeset {
+		kept := Changeset{}
+		for _, c := range changes {
+			if k := keyOf(c.record()); lost[k] && changed[k] {
+				blocked = append(blocked, c)
+				continue
+			}
+			kept = append(kept, c)
+		}
+		return kept
+	}
+	create, toDelete, modify = keep(create), keep(toDelete), keep(modify)
+	return create, toDelete, modify, blocked
+}
+
+func isProtected(r *models.RecordConfig) bool {
+	return r.Metadata[models.MetaProtected] == "true"
+}
+
+func (d *differ) reportBlocked(blocked Changeset) {
+	d.collector.add(func(c *Collector) { c.Blocked = append(c.Blocked, blocked...) })
+}
+
+func (d *differ) reportPurged(toDelete Changeset) {
+	declared := map[setKey]bool{}
+	for _, r := range d.dc.Records {
+		declared[keyOf(r)] = true
+	}
+	var purged Changeset
+	for _, c := range toDelete {
+		if !declared[keyOf(c.Existing)] {
+			purged = append(purged, c)
+		}
 	}
-	for _, d := range delete {
-		changedKeys[d.Existing.Key()] = append(changedKeys[d.Existing.Key()], d.String())
+	d.collector.add(func(c *Collector) { c.Purged = append(c.Purged, purged...) })
+}
+
+func (d *differ) reportChanged(changes ...Changeset) {
+	d.collector.add(func(c *Collector) {
+		for _, cs := range changes {
+			c.Changes = append(c.Changes, cs...)
+		}
+	})
+}
+
+// record is the record the change is about.
+func (c Correlation) record() *models.RecordConfig {
+	if c.Desired != nil {
+		return c.Desired
 	}
-	for _, m := range modify {
-		changedKeys[m.Desired.Key()] = append(changedKeys[m.Desired.Key()], m.String())
+	return c.Existing
+}
+
+// ChangedGroups leaves a record set alone if any of its changes is blocked,
+// since providers replace whole record sets: the other changes are blocked
+// too.
+func (d *differ) ChangedGroups(existing []*models.RecordConfig) map[models.RecordKey][]string {
+	changedKeys := map[models.RecordKey][]string{}
+	_, create, delete, modify, blocked := d.diff(existing)
+	blockedKeys := map[models.RecordKey]bool{}
+	for _, c := range blocked {
+		blockedKeys[c.record().Key()] = true
 	}
+	deleted, changed := Changeset{}, Changeset{}
+	for _, changes := range []Changeset{create, delete, modify} {
+		for _, c := range changes {
+			k := c.record().Key()
+			if blockedKeys[k] {
+				blocked = append(blocked, c)
+				continue
+			}
+			if c.Desired == nil {
+				deleted = append(deleted, c)
+			}
+			changed = append(changed, c)
+			changedKeys[k] = append(changedKeys[k], c.String())
+		}
+	}
+	d.reportBlocked(blocked)
+	d.reportPurged(deleted)
+	d.reportChanged(changed)
 	return changedKeys
 }
 
 func (c Correlation) String() string {
+	action, rType, fqdn, value := c.Fields()
+	if action == "MODIFY" {
+		return fmt.Sprintf("MODIFY %s %s: %s", rType, fqdn, value)
+	}
+	return fmt.Sprintf("%s %s %s %s", action, rType, fqdn, value)
+}
+
+// Fields returns the parts of the description of the change: CREATE, MODIFY
+// or DELETE, the type and name of the record, and its target and TTL, or
+// "(old) -> (new)" for MODIFY.
+func (c Correlation) Fields() (action, rType, fqdn, value string) {
 	if c.Existing == nil {
-		return fmt.Sprintf("CREATE %s %s %s", c.Desired.Type, c.Desired.GetLabelFQDN(), c.d.content(c.Desired))
+		return "CREATE", c.Desired.Type, c.Desired.GetLabelFQDN(), c.d.display(c.Desired)
 	}
 	if c.Desired == nil {
-		return fmt.Sprintf("DELETE %s %s %s", c.Existing.Type, c.Existing.GetLabelFQDN(), c.d.content(c.Existing))
+		return "DELETE", c.Existing.Type, c.Existing.GetLabelFQDN(), c.d.display(c.Existing)
 	}
-	return fmt.Sprintf("MODIFY %s %s: (%s) -> (%s)", c.Existing.Type, c.Existing.GetLabelFQDN(), c.d.content(c.Existing), c.d.content(c.Desired))
+	return "MODIFY", c.Existing.Type, c.Existing.GetLabelFQDN(), fmt.Sprintf("(%s) -> (%s)", c.d.display(c.Existing), c.d.display(c.Desired))
 }
 
 func sortedKeys(m map[string]*models.RecordConfig) []string {
@@ -214,11 +495,27 @@ func sortedKeys(m map[string]*models.RecordConfig) []string {
 	return s
 }
 
-func (d *differ) matchIgnored(name string) bool {
-	for _, tst := range d.dc.IgnoredLabels {
-		if name == tst {
+// EmptiesZone returns true if changing the existing records of a zone to
+// those of dc leaves nothing but the SOA and the apex NS records, that is,
+// every other record in the zone is deleted. IGNOREd records survive a push
+// and don't count. A zone with NO_PURGE, -only or -types is never emptied.
+func EmptiesZone(dc *models.DomainConfig, existing []*models.RecordConfig) bool {
+	if dc.KeepUnknown || dc.OnlyLabelFQDN != "" || len(dc.OnlyTypes) != 0 {
+		return false
+	}
+	for _, r := range dc.Records {
+		if !isApexNS(r) && r.Type != "SOA" {
+			return false
+		}
+	}
+	for _, r := range existing {
+		if r.Type != "SOA" && !isApexNS(r) && !dc.IgnoresLabel(r.GetLabel()) {
 			return true
 		}
 	}
 	return false
 }
+
+func isApexNS(r *models.RecordConfig) bool {
+	return r.Type == "NS" && r.GetLabel() == "@"
+}
@@ -2,6 +2,8 @@ package diff
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
@@ -115,6 +117,40 @@ func TestTTLChange(t *testing.T) {
 	checkLengths(t, existing, desired, 0, 0, 0, 1)
 }
 
+func TestHumanTTL(t *testing.T) {
+	for ttl, expected := range map[uint32]string{
+		0:      "0s",
+		45:     "45s",
+		300:    "5m",
+		3600:   "1h",
+		5400:   "1h30m",
+		86400:  "1d",
+		90061:  "1d1h1m1s",
+		604800: "7d",
+	} {
+		if found := HumanTTL(ttl); found != expected {
+			t.Errorf("%d: expected %s, got %s", ttl, expected, found)
+		}
+	}
+}
+
+func TestTTLFormat(t *testing.T) {
+	existing := []*models.RecordConfig{myRecord("www A 300 1.1.1.1")}
+	desired := []*models.RecordConfig{myRecord("www A 3600 1.1.1.1")}
+	defer func() { TTLFormat = TTLSeconds }()
+	for format, expected := range map[string]string{
+		TTLSeconds: "MODIFY A www.example.com: (1.1.1.1 ttl=300) -> (1.1.1.1 ttl=3600)",
+		TTLHuman:   "MODIFY A www.example.com: (1.1.1.1 ttl=5m) -> (1.1.1.1 ttl=1h)",
+		TTLBoth:    "MODIFY A www.example.com: (1.1.1.1 ttl=300 (5m)) -> (1.1.1.1 ttl=3600 (1h))",
+	} {
+		TTLFormat = format
+		_, _, _, mod := checkLengths(t, existing, desired, 0, 0, 0, 1)
+		if found := mod[0].String(); found != expected {
+			t.Errorf("%s: expected %s, got %s", format, expected, found)
+		}
+	}
+}
+
 func TestMetaChange(t *testing.T) {
 	existing := []*models.RecordConfig{
 		myRecord("www MX 1 1.1.1.1"),
@@ -153,6 +189,203 @@ func TestMetaOrdering(t *testing.T) {
 	checkLengths(t, existing, desired, 1, 0, 0, 0, getMeta)
 }
 
+func TestGeo(t *testing.T) {
+	geo := func(s, location string) *models.RecordConfig {
+		r := myRecord(s)
+		r.Metadata[models.MetaGeo] = location
+		return r
+	}
+	existing := []*models.RecordConfig{
+		geo("www A 1 1.1.1.1", "country:DE"),
+		geo("www A 1 2.2.2.2", "country:FR"),
+	}
+	checkLengths(t, existing, []*models.RecordConfig{
+		geo("www A 1 2.2.2.2", "country:FR"),
+		geo("www A 1 1.1.1.1", "country:DE"),
+	}, 2, 0, 0, 0)
+	// Swapping the targets changes each location, it doesn't move records
+	// between them.
+	_, _, _, mod := checkLengths(t, existing, []*models.RecordConfig{
+		geo("www A 1 2.2.2.2", "country:DE"),
+		geo("www A 1 1.1.1.1", "country:FR"),
+	}, 0, 0, 0, 2)
+	for _, m := range mod {
+		if m.Existing.Metadata[models.MetaGeo] != m.Desired.Metadata[models.MetaGeo] {
+			t.Errorf("Expected %s to stay in its location", m)
+		}
+	}
+	if want := "MODIFY A www.example.com: (1.1.1.1 ttl=1 geo=country:DE) -> (2.2.2.2 ttl=1 geo=country:DE)"; mod[0].String() != want && mod[1].String() != want {
+		t.Errorf("Expected %q, got %s and %s", want, mod[0], mod[1])
+	}
+	checkLengths(t, existing, []*models.RecordConfig{
+		geo("www A 1 1.1.1.1", "country:DE"),
+		geo("www A 1 2.2.2.2", "*"),
+	}, 1, 1, 1, 0)
+}
+
+func TestProtected(t *testing.T) {
+	record := func(s string) *models.RecordConfig {
+		r := myRecord(s)
+		if r.Type == "TXT" {
+			r.SetTargetTXT(r.GetTargetField())
+		}
+		return r
+	}
+	protected := func(s string) *models.RecordConfig {
+		r := record(s)
+		r.Metadata[models.MetaProtected] = "true"
+		return r
+	}
+	existing := []*models.RecordConfig{
+		record("@ TXT 1 verify=abc"),
+		record("@ TXT 1 v=spf1"),
+		record("www A 1 1.1.1.1"),
+	}
+	for _, tst := range []struct {
+		desc                                     string
+		desired                                  []*models.RecordConfig
+		unCount, createCount, delCount, modCount int
+		blocked                                  []string
+	}{
+		{"unchanged", []*models.RecordConfig{
+			protected("@ TXT 1 verify=abc"), record("@ TXT 1 v=spf1"), record("www A 1 1.1.1.1"),
+		}, 3, 0, 0, 0, nil},
+		{"TTL change", []*models.RecordConfig{
+			protected("@ TXT 300 verify=abc"), record("@ TXT 1 v=spf1"), record("www A 1 1.1.1.1"),
+		}, 2, 0, 0, 0, []string{`MODIFY TXT example.com: ("verify=abc" ttl=1) -> ("verify=abc" ttl=300)`}},
+		{"value edited", []*models.RecordConfig{
+			protected("@ TXT 1 verify=xyz"), record("@ TXT 1 v=spf1"), record("www A 1 1.1.1.1"),
+		}, 2, 0, 0, 0, []string{`MODIFY TXT example.com: ("verify=abc" ttl=1) -> ("verify=xyz" ttl=1)`}},
+		// The records of a set are paired up by value, so the old value may
+		// be paired with any other change: the whole set is left alone.
+		{"value edited, other record edited", []*models.RecordConfig{
+			protected("@ TXT 1 verify=aaa"), record("@ TXT 1 v=spf1~all"), record("www A 1 1.1.1.1"),
+		}, 1, 0, 0, 0, []string{
+			`MODIFY TXT example.com: ("v=spf1" ttl=1) -> ("v=spf1~all" ttl=1)`,
+			`MODIFY TXT example.com: ("verify=abc" ttl=1) -> ("verify=aaa" ttl=1)`,
+		}},
+		{"value edited, other record deleted", []*models.RecordConfig{
+			protected("@ TXT 1 verify=xyz"), record("www A 1 1.1.1.1"),
+		}, 1, 0, 0, 0, []string{
+			`DELETE TXT example.com "verify=abc" ttl=1`,
+			`MODIFY TXT example.com: ("v=spf1" ttl=1) -> ("verify=xyz" ttl=1)`,
+		}},
+		{"protected record added, other record deleted", []*models.RecordConfig{
+			protected("@ TXT 1 verify=abc"), protected("@ TXT 1 verify=new"), record("www A 1 1.1.1.1"),
+		}, 2, 0, 0, 0, []string{
+			`MODIFY TXT example.com: ("v=spf1" ttl=1) -> ("verify=new" ttl=1)`,
+		}},
+		// The other records of the set can change.
+		{"other record edited", []*models.RecordConfig{
+			protected("@ TXT 1 verify=abc"), record("@ TXT 1 v=spf1~all"), record("www A 1 2.2.2.2"),
+		}, 1, 0, 0, 2, nil},
+		{"new protected record", []*models.RecordConfig{
+			protected("@ TXT 1 verify=abc"), record("@ TXT 1 v=spf1"), record("www A 1 1.1.1.1"), protected("new A 1 3.3.3.3"),
+		}, 3, 1, 0, 0, nil},
+	} {
+		t.Run(tst.desc, func(t *testing.T) {
+			var un, cre, del, mod Changeset
+			c := collect(&models.DomainConfig{Name: "example.com", Records: tst.desired}, func(d Differ) { un, cre, del, mod = d.IncrementalDiff(existing) })
+			if got, want := []int{len(un), len(cre), len(del), len(mod)}, []int{tst.unCount, tst.createCount, tst.delCount, tst.modCount}; !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected %v unchanged, created, deleted and modified records, got %v", want, got)
+			}
+			if blocked := strings.Join(c.Blocked.strings(), "\n"); blocked != strings.Join(tst.blocked, "\n") {
+				t.Errorf("Expected blocked:\n%s\ngot:\n%s", strings.Join(tst.blocked, "\n"), blocked)
+			}
+		})
+	}
+
+	desired := []*models.RecordConfig{protected("@ TXT 1 verify=xyz"), record("@ TXT 1 v=spf1~all"), record("www A 1 1.1.1.1")}
+	// Record set providers leave the whole set alone.
+	var groups map[models.RecordKey][]string
+	c := collect(&models.DomainConfig{Name: "example.com", Records: desired}, func(d Differ) { groups = d.ChangedGroups(existing) })
+	if len(groups) != 0 || len(c.Blocked) != 2 {
+		t.Errorf("Expected the TXT set to be left alone, got %v, blocked %v", groups, c.Blocked)
+	}
+	// -allow-protected-changes
+	var mod Changeset
+	c = collect(&models.DomainConfig{Name: "example.com", Records: desired, AllowProtectedChanges: true}, func(d Differ) { _, _, _, mod = d.IncrementalDiff(existing) })
+	if len(mod) != 2 || c.Blocked != nil {
+		t.Errorf("Expected both TXT records to change, got %v, blocked %v", mod, c.Blocked)
+	}
+	// A record that was PROTECTED() when the domain was last pushed isn't
+	// deleted once it's removed from the configuration.
+	removed := []*models.RecordConfig{record("@ TXT 1 v=spf1"), record("www A 1 1.1.1.1")}
+	was := []string{models.ProtectedID(record("@ TXT 1 verify=abc"))}
+	var del Changeset
+	c = collect(&models.DomainConfig{Name: "example.com", Records: removed, Protected: was}, func(d Differ) { _, _, del, _ = d.IncrementalDiff(existing) })
+	if len(del) != 0 || len(c.Blocked) != 1 {
+		t.Errorf("Expected the deletion to be blocked, got %v, blocked %v", del, c.Blocked)
+	}
+	c = collect(&models.DomainConfig{Name: "example.com", Records: removed, Protected: was, AllowProtectedChanges: true}, func(d Differ) { _, _, del, _ = d.IncrementalDiff(existing) })
+	if len(del) != 1 || c.Blocked != nil {
+		t.Errorf("Expected the TXT record to be deleted, got %v, blocked %v", del, c.Blocked)
+	}
+}
+
+func TestURLForwardLifecycle(t *testing.T) {
+	existing := []*models.RecordConfig{}
+	desired := []*models.RecordConfig{myRecord("www URL 300 https://www.example.net/")}
+	checkLengths(t, existing, desired, 0, 1, 0, 0)
+	existing = desired
+	checkLengths(t, existing, desired, 1, 0, 0, 0)
+	// Another target, or another kind of forwarding, is a change.
+	checkLengths(t, existing, []*models.RecordConfig{myRecord("www URL 300 https://www.example.org/")}, 0, 0, 0, 1)
+	checkLengths(t, existing, []*models.RecordConfig{myRecord("www URL301 300 https://www.example.net/")}, 0, 1, 1, 0)
+	checkLengths(t, existing, []*models.RecordConfig{}, 0, 0, 1, 0)
+}
+
+func TestPurged(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("www A 1 1.1.1.1"),
+		myRecord("www A 1 2.2.2.2"),
+		myRecord("www AAAA 1 2001:db8::1"),
+		myRecord("old A 1 3.3.3.3"),
+		myRecord("keep A 1 4.4.4.4"),
+	}
+	desired := []*models.RecordConfig{
+		myRecord("www A 1 1.1.1.1"),
+		myRecord("keep A 1 4.4.4.4"),
+	}
+	// www A 2.2.2.2 is deleted because the A records of www changed, the
+	// others because www AAAA and old A aren't declared.
+	var del Changeset
+	c := collect(&models.DomainConfig{Name: "example.com", Records: desired}, func(d Differ) { _, _, del, _ = d.IncrementalDiff(existing) })
+	want := []string{"DELETE A old.example.com 3.3.3.3 ttl=1", "DELETE AAAA www.example.com 2001:db8::1 ttl=1"}
+	if purged := c.Purged.strings(); !reflect.DeepEqual(purged, want) || len(del) != 3 {
+		t.Errorf("Expected %q to be purged, got %q", want, purged)
+	}
+
+	var groups map[models.RecordKey][]string
+	c = collect(&models.DomainConfig{Name: "example.com", Records: desired}, func(d Differ) { groups = d.ChangedGroups(existing) })
+	if purged := c.Purged.strings(); !reflect.DeepEqual(purged, want) || len(groups) != 3 {
+		t.Errorf("Expected ChangedGroups to purge %q, got %q", want, purged)
+	}
+
+	c = collect(&models.DomainConfig{Name: "example.com", Records: desired, KeepUnknown: true}, func(d Differ) { _, _, del, _ = d.IncrementalDiff(existing) })
+	if c.Purged != nil || len(del) != 1 {
+		t.Errorf("Expected NO_PURGE to purge nothing, got %v", c.Purged)
+	}
+}
+
+func TestTXTOrdering(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("@ TXT 1 x"),
+	}
+	desired := []*models.RecordConfig{
+		myRecord("@ TXT 1 x"),
+	}
+	existing[0].SetTargetTXTs([]string{"v=spf1 include:a.com include:b.com", "~all"})
+	desired[0].SetTargetTXTs([]string{"v=spf1 include:a.com include:b.com", "~all"})
+	checkLengths(t, existing, desired, 1, 0, 0, 0)
+	// SPF is order-sensitive; swapping mechanisms must be seen as a change.
+	desired[0].SetTargetTXTs([]string{"v=spf1 include:b.com include:a.com", "~all"})
+	checkLengths(t, existing, desired, 0, 0, 0, 1)
+	// The same goes for the order of the strings within a single record.
+	desired[0].SetTargetTXTs([]string{"~all", "v=spf1 include:a.com include:b.com"})
+	checkLengths(t, existing, desired, 0, 0, 0, 1)
+}
+
 func checkLengths(t *testing.T, existing, desired []*models.RecordConfig, unCount, createCount, delCount, modCount int, valFuncs ...func(*models.RecordConfig) map[string]string) (un, cre, del, mod Changeset) {
 	return checkLengthsWithKeepUnknown(t, existing, desired, unCount, createCount, delCount, modCount, false, valFuncs...)
 }
@@ -230,3 +463,253 @@ func TestModifyingIgnoredRecords(t *testing.T) {
 
 	checkLengthsFull(t, existing, desired, 0, 0, 0, 1, false, []string{"www1", "www2"})
 }
+
+func checkOnly(t *testing.T, existing, desired []*models.RecordConfig, fqdn, rType string, unCount, createCount, delCount, modCount int) {
+	dc := &models.DomainConfig{
+		Name:          "example.com",
+		Records:       desired,
+		OnlyLabelFQDN: fqdn,
+		OnlyType:      rType,
+	}
+	un, cre, del, mod := New(dc).IncrementalDiff(existing)
+	if len(un) != unCount || len(cre) != createCount || len(del) != delCount || len(mod) != modCount {
+		t.Errorf("-only %s:%s: got %d/%d/%d/%d unchanged/create/delete/modify, expected %d/%d/%d/%d", fqdn, rType,
+			len(un), len(cre), len(del), len(mod), unCount, createCount, delCount, modCount)
+	}
+}
+
+func TestOnlyRecord(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("www A 1 1.1.1.1"),
+		myRecord("www MX 1 1.1.1.1"),
+		myRecord("old A 1 1.1.1.1"),
+	}
+	desired := []*models.RecordConfig{
+		myRecord("www A 1 2.2.2.2"),
+		myRecord("new A 1 1.1.1.1"),
+	}
+	// Without -only, old and www MX are deleted and new is created.
+	checkOnly(t, existing, desired, "", "", 0, 1, 2, 1)
+	// Only www: the MX is deleted, but old is left alone and new is not created.
+	checkOnly(t, existing, desired, "www.example.com", "", 0, 0, 1, 1)
+	// Only the www A record.
+	checkOnly(t, existing, desired, "www.example.com", "A", 0, 0, 0, 1)
+	// Only new: nothing else is deleted.
+	checkOnly(t, existing, desired, "new.example.com", "A", 0, 1, 0, 0)
+	// A name that is in neither list changes nothing.
+	checkOnly(t, existing, desired, "missing.example.com", "", 0, 0, 0, 0)
+}
+
+func TestOnlyTypes(t *testing.T) {
+	txt := func(s string) *models.RecordConfig {
+		r := myRecord("@ TXT 1 " + s)
+		r.SetTargetTXT(s)
+		return r
+	}
+	existing := []*models.RecordConfig{
+		myRecord("www A 1 1.1.1.1"),
+		myRecord("old A 1 1.1.1.1"),
+		myRecord("@ MX 1 mx.example.com."),
+		txt("old"),
+	}
+	desired := []*models.RecordConfig{
+		myRecord("www A 1 2.2.2.2"),
+		myRecord("new AAAA 1 2001:db8::1"),
+		txt("new"),
+	}
+	for _, tst := range []struct {
+		types                                    []string
+		unCount, createCount, delCount, modCount int
+	}{
+		{nil, 0, 1, 2, 2},
+		{[]string{"A"}, 0, 0, 1, 1},
+		{[]string{"A", "AAAA"}, 0, 1, 1, 1},
+		{[]string{"MX"}, 0, 0, 1, 0},
+		{[]string{"CNAME"}, 0, 0, 0, 0},
+	} {
+		dc := &models.DomainConfig{Name: "example.com", Records: desired, OnlyTypes: tst.types}
+		un, cre, del, mod := New(dc).IncrementalDiff(existing)
+		if len(un) != tst.unCount || len(cre) != tst.createCount || len(del) != tst.delCount || len(mod) != tst.modCount {
+			t.Errorf("-types %v: got %d/%d/%d/%d unchanged/create/delete/modify, expected %d/%d/%d/%d", tst.types,
+				len(un), len(cre), len(del), len(mod), tst.unCount, tst.createCount, tst.delCount, tst.modCount)
+		}
+	}
+}
+
+func TestCollector(t *testing.T) {
+	existing := []*models.RecordConfig{
+		myRecord("www A 1 1.1.1.1"),
+		myRecord("ignored A 1 1.1.1.1"),
+	}
+	dc := &models.DomainConfig{Name: "example.com", IgnoredLabels: []string{"ignored"}}
+	c := Collect(dc)
+	// The providers that copy the domain report to its collector too.
+	copied, err := dc.Copy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	New(copied).IncrementalDiff(existing)
+	// IGNOREd records are part of the zone, so they are reported too.
+	if len(c.Existing) != 2 || len(c.Changes) != 1 {
+		t.Errorf("Expected 2 records and 1 change, got %v and %v", c.Existing, c.Changes)
+	}
+	// The diffs of other domains, and those after Stop, are not collected.
+	New(&models.DomainConfig{Name: "example.com"}).IncrementalDiff(existing)
+	d := New(dc)
+	c.Stop()
+	d.IncrementalDiff(existing)
+	New(dc).IncrementalDiff(existing)
+	if len(c.Changes) != 1 {
+		t.Errorf("Expected only the first diff to be collected, got %v", c.Changes)
+	}
+}
+
+// collect returns what diff, a diff of dc, reports.
+func collect(dc *models.DomainConfig, diff func(Differ)) *Collector {
+	c := Collect(dc)
+	diff(New(dc))
+	c.Stop()
+	return c
+}
+
+// strings returns the changes as text, sorted.
+func (cs Changeset) strings() []string {
+	var s []string
+	for _, c := range cs {
+		s = append(s, c.String())
+	}
+	sort.Strings(s)
+	return s
+}
+
+func TestEmptiesZone(t *testing.T) {
+	zone := []*models.RecordConfig{
+		myRecord("@ SOA 1 ns1.example.net."),
+		myRecord("@ NS 1 ns1.example.net."),
+		myRecord("www A 1 1.1.1.1"),
+	}
+	apexNS := []*models.RecordConfig{myRecord("@ NS 1 ns1.example.net.")}
+	for _, tst := range []struct {
+		desc     string
+		existing []*models.RecordConfig
+		dc       models.DomainConfig
+		expected bool
+	}{
+		{"all records deleted", zone, models.DomainConfig{}, true},
+		{"only apex NS left", zone, models.DomainConfig{Records: apexNS}, true},
+		{"a record is kept", zone, models.DomainConfig{Records: []*models.RecordConfig{myRecord("www A 1 2.2.2.2")}}, false},
+		{"zone already empty", zone[:2], models.DomainConfig{}, false},
+		{"NO_PURGE", zone, models.DomainConfig{KeepUnknown: true}, false},
+		{"-only", zone, models.DomainConfig{OnlyLabelFQDN: "www.example.com"}, false},
+		{"the rest is IGNOREd", zone, models.DomainConfig{IgnoredLabels: []string{"www"}}, false},
+	} {
+		tst.dc.Name = "example.com"
+		if got := EmptiesZone(&tst.dc, tst.existing); got != tst.expected {
+			t.Errorf("%s: got %v, expected %v", tst.desc, got, tst.expected)
+		}
+	}
+}
+
+func TestQuotedTXT(t *testing.T) {
+	txt := func(s ...string) *models.RecordConfig {
+		r := myRecord("@ TXT 1 x")
+		r.SetTargetTXTs(s)
+		return r
+	}
+	// As read from a provider that includes the quotes.
+	existing := []*models.RecordConfig{txt(`"v=spf1 -all"`), txt(`"part one" "part two"`)}
+	models.PostProcessRecords(existing)
+	desired := []*models.RecordConfig{txt(`v=spf1 -all`), txt(`part one`, `part two`)}
+	models.PostProcessRecords(desired)
+	checkLengths(t, existing, desired, 2, 0, 0, 0)
+}
+
+func TestTargetCase(t *testing.T) {
+	for _, tst := range []struct {
+		existing, desired string
+		modified          int
+	}{
+		{"@ TXT 1 Hello", "@ TXT 1 hello", 1},
+		{"@ CNAME 1 Foo.Example.NET.", "@ CNAME 1 foo.example.net.", 0},
+		{"@ MX 1 MAIL.example.com.", "@ MX 1 mail.example.com.", 0},
+		{"_sip._tcp SRV 1 SIP.example.com.", "_sip._tcp SRV 1 sip.example.com.", 0},
+		{"www A 1 1.2.3.4", "WWW A 1 1.2.3.4", 0},
+	} {
+		existing := []*models.RecordConfig{myRecord(tst.existing)}
+		desired := []*models.RecordConfig{myRecord(tst.desired)}
+		if existing[0].Type == "TXT" {
+			existing[0].SetTargetTXT(existing[0].GetTargetField())
+			desired[0].SetTargetTXT(desired[0].GetTargetField())
+		}
+		models.PostProcessRecords(existing)
+		models.PostProcessRecords(desired)
+		if _, _, _, mod := New(&models.DomainConfig{Name: "example.com", Records: desired}).IncrementalDiff(existing); len(mod) != tst.modified {
+			t.Errorf("%s -> %s: expected %d modifications, got %d", tst.existing, tst.desired, tst.modified, len(mod))
+		}
+	}
+}
+
+func TestCAACase(t *testing.T) {
+	caa := func(tag, value string) *models.RecordConfig {
+		r := myRecord("@ CAA 1 x")
+		r.SetTargetCAA(0, tag, value)
+		return r
+	}
+	for _, tst := range []struct {
+		existing, desired *models.RecordConfig
+		modified          int
+	}{
+		{caa("ISSUE", "letsencrypt.org"), caa("issue", "letsencrypt.org"), 0},
+		{caa("iodef", "mailto:Admin@example.com"), caa("iodef", "mailto:admin@example.com"), 1},
+	} {
+		existing := []*models.RecordConfig{tst.existing}
+		desired := []*models.RecordConfig{tst.desired}
+		models.PostProcessRecords(existing)
+		models.PostProcessRecords(desired)
+		if _, _, _, mod := New(&models.DomainConfig{Name: "example.com", Records: desired}).IncrementalDiff(existing); len(mod) != tst.modified {
+			t.Errorf("%s -> %s: expected %d modifications, got %d", tst.existing.GetTargetCombined(), tst.desired.GetTargetCombined(), tst.modified, len(mod))
+		}
+	}
+}
+
+// mockZone is a provider zone whose records have a flag dnscontrol doesn't
+// model. Updates replace the whole record, as many provider APIs do.
+type mockZone map[string]*models.RecordConfig
+
+func (z mockZone) records() []*models.RecordConfig {
+	var recs []*models.RecordConfig
+	for _, r := range z {
+		rec := *r
+		rec.Preserved = map[string]string{"flagged": r.Metadata["mock_flag"]}
+		recs = append(recs, &rec)
+	}
+	return recs
+}
+
+func (z mockZone) apply(mod Changeset) {
+	for _, c := range mod {
+		r := *c.Desired
+		r.Metadata = map[string]string{"mock_flag": c.Desired.Preserved["flagged"]}
+		z[r.GetLabel()] = &r
+	}
+}
+
+func TestPreserved(t *testing.T) {
+	flagged := myRecord("www A 1 1.1.1.1")
+	flagged.Metadata["mock_flag"] = "on"
+	zone := mockZone{"www": flagged, "mail": myRecord("mail A 1 2.2.2.2")}
+	desired := []*models.RecordConfig{
+		myRecord("www A 300 1.1.1.1"),
+		myRecord("mail A 1 2.2.2.2"),
+	}
+	un, _, _, mod := checkLengths(t, zone.records(), desired, 1, 0, 0, 1)
+	zone.apply(mod)
+	if got := zone["www"]; got.TTL != 300 || got.Metadata["mock_flag"] != "on" {
+		t.Errorf("Expected the TTL to change and the flag to be kept, got TTL %d and flag %q", got.TTL, got.Metadata["mock_flag"])
+	}
+	if un[0].Desired.Preserved["flagged"] != "" {
+		t.Errorf("Expected the unflagged record to stay unflagged, got %q", un[0].Desired.Preserved["flagged"])
+	}
+	// The diff doesn't compare preserved fields.
+	checkLengths(t, zone.records(), desired, 2, 0, 0, 0)
+}
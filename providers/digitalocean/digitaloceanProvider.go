@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
+	"strings"
 
 	"github.com/StackExchange/dnscontrol/models"
 	"github.com/StackExchange/dnscontrol/providers"
@@ -42,27 +44,44 @@ func NewDo(m map[string]string, metadata json.RawMessage) (providers.DNSServiceP
 		return nil, errors.Errorf("no Digitalocean token provided")
 	}
 
-	ctx := context.Background()
+	httpClient, err := providers.HTTPClient(m)
+	if err != nil {
+		return nil, err
+	}
+	// oauth2 wraps the transport of the client in the context, not its timeout.
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
 	oauthClient := oauth2.NewClient(
 		ctx,
 		oauth2.StaticTokenSource(&oauth2.Token{AccessToken: m["token"]}),
 	)
+	oauthClient.Timeout = httpClient.Timeout
 	client := godo.NewClient(oauthClient)
+	if client.BaseURL, err = providers.Endpoint(m, client.BaseURL.String()); err != nil {
+		return nil, err
+	}
 
 	api := &DoApi{client: client}
 
 	// Get a domain to validate the token
-	_, resp, err := api.client.Domains.List(ctx, &godo.ListOptions{PerPage: 1})
-	if err != nil {
+	if err := api.CheckCredentials(); err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.Errorf("token for digitalocean is not valid")
-	}
 
 	return api, nil
 }
 
+// CheckCredentials lists one domain to validate the token.
+func (api *DoApi) CheckCredentials() error {
+	_, resp, err := api.client.Domains.List(context.Background(), &godo.ListOptions{PerPage: 1})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("token for digitalocean is not valid")
+	}
+	return nil
+}
+
 var features = providers.DocumentationNotes{
 	providers.DocCreateDomains:       providers.Can(),
 	providers.DocOfficiallySupported: providers.Cannot(),
@@ -102,10 +121,8 @@ func (api *DoApi) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Corre
 		return nil, err
 	}
 
-	existingRecords := make([]*models.RecordConfig, len(records))
-	for i := range records {
-		existingRecords[i] = toRc(dc, &records[i])
-	}
+	existingRecords := toExisting(dc, records)
+	removeApexNS(dc)
 
 	// Normalize
 	models.PostProcessRecords(existingRecords)
@@ -154,6 +171,40 @@ func (api *DoApi) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Corre
 	return corrections, nil
 }
 
+// isManagedByDO returns true for the records that DO creates with every zone
+// and manages itself: the SOA and the apex NS records. They can't be changed
+// or deleted, so they are left out of the diff.
+func isManagedByDO(r *godo.DomainRecord) bool {
+	return r.Type == "SOA" || (r.Type == "NS" && r.Name == "@")
+}
+
+// toExisting converts the records read from DO, except the ones DO manages.
+func toExisting(dc *models.DomainConfig, records []godo.DomainRecord) []*models.RecordConfig {
+	existing := make([]*models.RecordConfig, 0, len(records))
+	for i := range records {
+		if isManagedByDO(&records[i]) {
+			continue
+		}
+		existing = append(existing, toRc(dc, &records[i]))
+	}
+	return existing
+}
+
+// removeApexNS removes the apex NS records from dc, since DO always uses its own.
+func removeApexNS(dc *models.DomainConfig) {
+	recordsToKeep := make([]*models.RecordConfig, 0, len(dc.Records))
+	for _, rec := range dc.Records {
+		if rec.Type == "NS" && rec.GetLabel() == "@" {
+			if !strings.HasSuffix(rec.GetTargetField(), ".digitalocean.com.") {
+				log.Printf("WARNING: Digitalocean does not support changing apex NS records. %s will not be added.", rec.GetTargetField())
+			}
+			continue
+		}
+		recordsToKeep = append(recordsToKeep, rec)
+	}
+	dc.Records = recordsToKeep
+}
+
 func getRecords(api *DoApi, name string) ([]godo.DomainRecord, error) {
 	ctx := context.Background()
 
@@ -0,0 +1,67 @@
+package digitalocean
+
+import (
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/pkg/nameservers"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+	"github.com/digitalocean/godo"
+)
+
+// freshZone is what DO returns for a zone that was just created.
+var freshZone = []godo.DomainRecord{
+	{ID: 1, Type: "SOA", Name: "@", Data: "1800", TTL: 1800},
+	{ID: 2, Type: "NS", Name: "@", Data: "ns1.digitalocean.com", TTL: 1800},
+	{ID: 3, Type: "NS", Name: "@", Data: "ns2.digitalocean.com", TTL: 1800},
+	{ID: 4, Type: "NS", Name: "@", Data: "ns3.digitalocean.com", TTL: 1800},
+}
+
+func newDomain(records ...*models.RecordConfig) *models.DomainConfig {
+	dc := &models.DomainConfig{
+		Name:        "example.com",
+		Records:     records,
+		Nameservers: models.StringsToNameservers(defaultNameServerNames),
+	}
+	nameservers.AddNSRecords(dc)
+	return dc
+}
+
+func corrections(dc *models.DomainConfig, records []godo.DomainRecord) (create, del, modify diff.Changeset) {
+	existing := toExisting(dc, records)
+	removeApexNS(dc)
+	models.PostProcessRecords(existing)
+	_, create, del, modify = diff.New(dc).IncrementalDiff(existing)
+	return
+}
+
+func TestFreshZone(t *testing.T) {
+	create, del, modify := corrections(newDomain(), freshZone)
+	if len(create)+len(del)+len(modify) != 0 {
+		t.Errorf("expected no changes to a fresh zone, got %v %v %v", create, del, modify)
+	}
+}
+
+func TestFreshZoneWithRecords(t *testing.T) {
+	www := &models.RecordConfig{Type: "A", TTL: 300}
+	www.SetLabel("www", "example.com")
+	www.SetTarget("1.2.3.4")
+	sub := &models.RecordConfig{Type: "NS", TTL: 300}
+	sub.SetLabel("sub", "example.com")
+	sub.SetTarget("ns1.example.net.")
+
+	create, del, modify := corrections(newDomain(www, sub), freshZone)
+	if len(create) != 2 || len(del) != 0 || len(modify) != 0 {
+		t.Errorf("expected only the 2 new records to be created, got %v %v %v", create, del, modify)
+	}
+
+	// Once created, NS records below the apex are managed like any other record.
+	zone := append(freshZone,
+		godo.DomainRecord{ID: 5, Type: "A", Name: "www", Data: "1.2.3.4", TTL: 300},
+		godo.DomainRecord{ID: 6, Type: "NS", Name: "sub", Data: "ns1.example.net", TTL: 1800},
+	)
+	create, del, modify = corrections(newDomain(www, sub), zone)
+	if len(create) != 0 || len(del) != 0 || len(modify) != 1 {
+		t.Errorf("expected only the TTL of sub NS to change, got %v %v %v", create, del, modify)
+	}
+}
@@ -0,0 +1,29 @@
+package providers
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Endpoint returns the base URL of the API a provider sends its requests to:
+// the endpoint field of its credentials if it is set, to use a regional API
+// or a mock server, or else def. The endpoint must be an http:// or https://
+// URL. Its path gets a trailing slash, so that the paths of requests resolve
+// under it. Providers whose API has a single base URL should call it in their
+// initializer rather than use theirs as is.
+func Endpoint(creds map[string]string, def string) (*url.URL, error) {
+	s := creds["endpoint"]
+	if s == "" {
+		return url.Parse(def)
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.Errorf("endpoint %q is not an http:// or https:// URL", s)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return u, nil
+}
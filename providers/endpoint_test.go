@@ -0,0 +1,24 @@
+package providers
+
+import "testing"
+
+func TestEndpoint(t *testing.T) {
+	for creds, want := range map[string]string{
+		"":                           "https://api.example.com/v2/",
+		"https://api.example.cn/v2/": "https://api.example.cn/v2/",
+		"http://localhost:8080/v2":   "http://localhost:8080/v2/",
+		"http://127.0.0.1:8080":      "http://127.0.0.1:8080/",
+	} {
+		u, err := Endpoint(map[string]string{"endpoint": creds}, "https://api.example.com/v2/")
+		if err != nil {
+			t.Errorf("%q: %s", creds, err)
+		} else if u.String() != want {
+			t.Errorf("%q: expected %s, got %s", creds, want, u)
+		}
+	}
+	for _, bad := range []string{"api.example.com/v2/", "ftp://api.example.com/", "https://", "http://[::1"} {
+		if _, err := Endpoint(map[string]string{"endpoint": bad}, "https://api.example.com/v2/"); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
@@ -36,7 +36,7 @@ var features = providers.DocumentationNotes{
 }
 
 func init() {
-	providers.RegisterDomainServiceProviderType("GANDI", newDsp, features)
+	providers.RegisterDomainServiceProviderType("GANDI", newDsp, features, providers.MinTTL(300))
 	providers.RegisterRegistrarType("GANDI", newReg)
 }
 
@@ -31,7 +31,7 @@ var liveFeatures = providers.DocumentationNotes{
 }
 
 func init() {
-	providers.RegisterDomainServiceProviderType("GANDI-LIVEDNS", newLiveDsp, liveFeatures)
+	providers.RegisterDomainServiceProviderType("GANDI-LIVEDNS", newLiveDsp, liveFeatures, providers.MinTTL(300))
 }
 
 func newLiveDsp(m map[string]string, metadata json.RawMessage) (providers.DNSServiceProvider, error) {
@@ -0,0 +1,51 @@
+package providers
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// HTTPClient returns the HTTP client a provider should send its API requests
+// with, set up by two optional fields of its credentials. http_timeout is
+// how long a request may take, as a duration ("90s") or a number of seconds;
+// the default is no limit. https_proxy is the URL of the proxy to send the
+// requests through, instead of the one the HTTPS_PROXY environment variable
+// names. Providers that make their own client should call it in their
+// initializer rather than use http.DefaultClient.
+func HTTPClient(creds map[string]string) (*http.Client, error) {
+	client := &http.Client{}
+	if s := creds["http_timeout"]; s != "" {
+		timeout, err := time.ParseDuration(s)
+		if n, nerr := strconv.Atoi(s); nerr == nil {
+			timeout, err = time.Duration(n)*time.Second, nil
+		}
+		if err != nil || timeout <= 0 {
+			return nil, errors.Errorf("http_timeout %q is not a duration, such as 90s", s)
+		}
+		client.Timeout = timeout
+	}
+	if s := creds["https_proxy"]; s != "" {
+		proxy, err := url.Parse(s)
+		if err != nil || (proxy.Scheme != "http" && proxy.Scheme != "https") || proxy.Host == "" {
+			return nil, errors.Errorf("https_proxy %q is not an http:// or https:// URL", s)
+		}
+		// The settings of http.DefaultTransport, but for the proxy.
+		client.Transport = &http.Transport{
+			Proxy: http.ProxyURL(proxy),
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		}
+	}
+	return client, nil
+}
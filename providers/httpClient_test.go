@@ -0,0 +1,81 @@
+package providers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPClient(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer slow.Close()
+	// The proxy answers requests for any host itself.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("proxied " + r.URL.String()))
+	}))
+	defer proxy.Close()
+
+	client, err := HTTPClient(map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Timeout != 0 || client.Transport != nil || client == http.DefaultClient {
+		t.Errorf("Expected a client like http.DefaultClient, but of its own, got %+v", client)
+	}
+	if _, err := client.Get(slow.URL); err != nil {
+		t.Errorf("Expected no timeout by default, got %s", err)
+	}
+
+	for _, timeout := range []string{"50ms", "1"} {
+		client, err := HTTPClient(map[string]string{"http_timeout": timeout})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]time.Duration{"50ms": 50 * time.Millisecond, "1": time.Second}[timeout]
+		if client.Timeout != want {
+			t.Errorf("http_timeout %s: expected %s, got %s", timeout, want, client.Timeout)
+		}
+	}
+	client, err = HTTPClient(map[string]string{"http_timeout": "50ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(slow.URL); err == nil || !strings.Contains(err.Error(), "Timeout") {
+		t.Errorf("Expected the request to time out, got %v", err)
+	}
+
+	client, err = HTTPClient(map[string]string{"https_proxy": proxy.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get("http://api.example.invalid/zones")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if string(body) != "proxied http://api.example.invalid/zones" {
+		t.Errorf("Expected the request to go through the proxy, got %q", body)
+	}
+	if http.DefaultTransport.(*http.Transport).Proxy == nil {
+		t.Errorf("Expected http.DefaultTransport to keep its proxy settings")
+	}
+
+	for _, bad := range []map[string]string{
+		{"http_timeout": "soon"},
+		{"http_timeout": "-5s"},
+		{"http_timeout": "0"},
+		{"https_proxy": "proxy.example.com:3128"},
+		{"https_proxy": "socks5://proxy.example.com"},
+		{"https_proxy": "http://"},
+	} {
+		if _, err := HTTPClient(bad); err == nil {
+			t.Errorf("%v: expected an error", bad)
+		}
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"net/url"
 
 	"regexp"
+	"sort"
 	"strings"
 
 	"golang.org/x/oauth2"
@@ -53,6 +54,9 @@ type LinodeApi struct {
 	domainIndex map[string]int
 }
 
+// apexTTL is the TTL of the read-only apex NS records, Linode's default TTL.
+const apexTTL = 86400
+
 var defaultNameServerNames = []string{
 	"ns1.linode.com",
 	"ns2.linode.com",
@@ -67,15 +71,21 @@ func NewLinode(m map[string]string, metadata json.RawMessage) (providers.DNSServ
 		return nil, errors.Errorf("Missing Linode token")
 	}
 
-	ctx := context.Background()
+	httpClient, err := providers.HTTPClient(m)
+	if err != nil {
+		return nil, err
+	}
+	// oauth2 wraps the transport of the client in the context, not its timeout.
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
 	client := oauth2.NewClient(
 		ctx,
 		oauth2.StaticTokenSource(&oauth2.Token{AccessToken: m["token"]}),
 	)
+	client.Timeout = httpClient.Timeout
 
-	baseURL, err := url.Parse(defaultBaseURL)
+	baseURL, err := providers.Endpoint(m, defaultBaseURL)
 	if err != nil {
-		return nil, errors.Errorf("Linode base URL not valid")
+		return nil, err
 	}
 
 	api := &LinodeApi{client: client, baseURL: baseURL}
@@ -95,7 +105,25 @@ var features = providers.DocumentationNotes{
 
 func init() {
 	// SRV support is in this provider, but Linode doesn't seem to support it properly
-	providers.RegisterDomainServiceProviderType("LINODE", NewLinode, features)
+	providers.RegisterDomainServiceProviderType("LINODE", NewLinode, features, providers.ApexTTL(apexTTL))
+}
+
+// CheckCredentials lists the domains to validate the token.
+func (api *LinodeApi) CheckCredentials() error {
+	return api.fetchDomainList()
+}
+
+// ListZones returns the names of the domains of the account.
+func (api *LinodeApi) ListZones() ([]string, error) {
+	if err := api.fetchDomainList(); err != nil {
+		return nil, err
+	}
+	var zones []string
+	for domain := range api.domainIndex {
+		zones = append(zones, domain)
+	}
+	sort.Strings(zones)
+	return zones, nil
 }
 
 // GetNameservers returns the nameservers for a domain.
@@ -137,6 +165,7 @@ func (api *LinodeApi) GetDomainCorrections(dc *models.DomainConfig) ([]*models.C
 	for _, name := range defaultNameServerNames {
 		rc := &models.RecordConfig{
 			Type:     "NS",
+			TTL:      apexTTL,
 			Original: &domainRecord{},
 		}
 		rc.SetLabelFromFQDN(dc.Name, dc.Name)
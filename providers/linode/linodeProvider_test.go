@@ -1,6 +1,8 @@
 package linode
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -21,3 +23,23 @@ func TestFixTTL(t *testing.T) {
 		}
 	}
 }
+
+func TestEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/mock/v4/domains" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		w.Write([]byte(`{"page": 1, "pages": 1, "data": [{"id": 7, "domain": "example.com"}]}`))
+	}))
+	defer srv.Close()
+	p, err := NewLinode(map[string]string{"token": "secret", "endpoint": srv.URL + "/mock/v4"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := p.(*LinodeApi).domainIndex["example.com"]; id != 7 {
+		t.Errorf("Expected the domains of the endpoint, got %v", p.(*LinodeApi).domainIndex)
+	}
+	if _, err := NewLinode(map[string]string{"token": "secret", "endpoint": "api.linode.com"}, nil); err == nil {
+		t.Errorf("Expected an endpoint that is not a URL to be an error")
+	}
+}
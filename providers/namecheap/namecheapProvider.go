@@ -33,6 +33,7 @@ var features = providers.DocumentationNotes{
 	providers.CanUsePTR:              providers.Cannot(),
 	providers.CanUseSRV:              providers.Cannot("The namecheap web console allows you to make SRV records, but their api does not let you read or set them"),
 	providers.CanUseTLSA:             providers.Cannot(),
+	providers.CanUseURLForward:       providers.Can(),
 	providers.CantUseNOPURGE:         providers.Cannot(),
 	providers.DocCreateDomains:       providers.Cannot("Requires domain registered through their service"),
 	providers.DocDualHost:            providers.Cannot("Doesn't allow control of apex NS records"),
@@ -42,9 +43,6 @@ var features = providers.DocumentationNotes{
 func init() {
 	providers.RegisterRegistrarType("NAMECHEAP", newReg)
 	providers.RegisterDomainServiceProviderType("NAMECHEAP", newDsp, features)
-	providers.RegisterCustomRecordType("URL", "NAMECHEAP", "")
-	providers.RegisterCustomRecordType("URL301", "NAMECHEAP", "")
-	providers.RegisterCustomRecordType("FRAME", "NAMECHEAP", "")
 }
 
 func newDsp(conf map[string]string, metadata json.RawMessage) (providers.DNSServiceProvider, error) {
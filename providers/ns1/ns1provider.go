@@ -9,8 +9,6 @@ import (
 	"github.com/StackExchange/dnscontrol/providers"
 	"github.com/pkg/errors"
 
-	"net/http"
-
 	"strings"
 
 	"github.com/StackExchange/dnscontrol/providers/diff"
@@ -37,7 +35,11 @@ func newProvider(creds map[string]string, meta json.RawMessage) (providers.DNSSe
 	if creds["api_token"] == "" {
 		return nil, errors.Errorf("api_token required for ns1")
 	}
-	return &nsone{rest.NewClient(http.DefaultClient, rest.SetAPIKey(creds["api_token"]))}, nil
+	client, err := providers.HTTPClient(creds)
+	if err != nil {
+		return nil, err
+	}
+	return &nsone{rest.NewClient(client, rest.SetAPIKey(creds["api_token"]))}, nil
 }
 
 func (n *nsone) GetNameservers(domain string) ([]*models.Nameserver, error) {
@@ -64,42 +66,23 @@ func (n *nsone) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correct
 		}
 		found = append(found, zrs...)
 	}
-	foundGrouped := found.Grouped()
-	desiredGrouped := dc.Records.Grouped()
 
 	//  Normalize
 	models.PostProcessRecords(found)
 
-	differ := diff.New(dc)
-	changedGroups := differ.ChangedGroups(found)
-	corrections := []*models.Correction{}
 	// each name/type is given to the api as a unit.
-	for k, descs := range changedGroups {
-		key := k
-		desc := strings.Join(descs, "\n")
-		_, current := foundGrouped[k]
-		recs, wanted := desiredGrouped[k]
-		if wanted && !current {
-			// pure addition
-			corrections = append(corrections, &models.Correction{
-				Msg: desc,
-				F:   func() error { return n.add(recs, dc.Name) },
-			})
-		} else if current && !wanted {
-			// pure deletion
-			corrections = append(corrections, &models.Correction{
-				Msg: desc,
-				F:   func() error { return n.remove(key, dc.Name) },
-			})
-		} else {
-			// modification
-			corrections = append(corrections, &models.Correction{
-				Msg: desc,
-				F:   func() error { return n.modify(recs, dc.Name) },
-			})
-		}
+	return diff.New(dc).Corrections(found, n)
+}
+
+// ReplaceRecordSet implements diff.RecordSetEditor.
+func (n *nsone) ReplaceRecordSet(domain string, key models.RecordKey, existing, desired models.Records) error {
+	switch {
+	case len(existing) == 0:
+		return n.add(desired, domain)
+	case len(desired) == 0:
+		return n.remove(key, domain)
 	}
-	return corrections, nil
+	return n.modify(desired, domain)
 }
 
 func (n *nsone) add(recs models.Records, domain string) error {
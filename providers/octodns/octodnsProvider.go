@@ -39,6 +39,7 @@ var features = providers.DocumentationNotes{
 	providers.CanUsePTR: providers.Can(),
 	providers.CanUseSRV: providers.Can(),
 	//providers.CanUseTXTMulti:   providers.Can(),
+	providers.CantUseNOPURGE:   providers.Cannot(),
 	providers.DocCreateDomains: providers.Cannot("Driver just maintains list of OctoDNS config files. You must manually create the master config files that refer these."),
 	providers.DocDualHost:      providers.Cannot("Research is needed."),
 }
@@ -0,0 +1,294 @@
+package oracle
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	apiVersion  = "20180115"
+	pageLimit   = "100"
+	maxRetries  = 5
+	mediaType   = "application/json"
+	nextPageHdr = "opc-next-page"
+)
+
+// ociClient talks to the OCI DNS API, signing every request with the
+// user's API signing key as described in
+// https://docs.cloud.oracle.com/iaas/Content/API/Concepts/signingrequests.htm
+type ociClient struct {
+	client  *http.Client
+	baseURL string
+	keyID   string
+	key     *rsa.PrivateKey
+	// sleep is replaced in tests so that backoff does not slow them down.
+	sleep func(time.Duration)
+}
+
+func newClient(tenancy, user, fingerprint, region, privateKey string) (*ociClient, error) {
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &ociClient{
+		client:  &http.Client{Timeout: time.Minute},
+		baseURL: fmt.Sprintf("https://dns.%s.oraclecloud.com/%s", region, apiVersion),
+		keyID:   fmt.Sprintf("%s/%s/%s", tenancy, user, fingerprint),
+		key:     key,
+		sleep:   time.Sleep,
+	}, nil
+}
+
+func parsePrivateKey(s string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, errors.Errorf("OCI private_key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing OCI private_key")
+	}
+	key, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.Errorf("OCI private_key must be an RSA key")
+	}
+	return key, nil
+}
+
+// signedHeaders returns the headers that must be signed for method.
+func signedHeaders(method string) []string {
+	h := []string{"date", "(request-target)", "host"}
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		h = append(h, "content-length", "content-type", "x-content-sha256")
+	}
+	return h
+}
+
+func signingString(req *http.Request, headers []string) string {
+	lines := make([]string, len(headers))
+	for i, h := range headers {
+		switch h {
+		case "(request-target)":
+			lines[i] = fmt.Sprintf("%s: %s %s", h, strings.ToLower(req.Method), req.URL.RequestURI())
+		case "host":
+			lines[i] = fmt.Sprintf("%s: %s", h, req.URL.Host)
+		default:
+			lines[i] = fmt.Sprintf("%s: %s", h, req.Header.Get(h))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *ociClient) sign(req *http.Request, body []byte) error {
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("Accept", mediaType)
+	headers := signedHeaders(req.Method)
+	if len(headers) > 3 {
+		sum := sha256.Sum256(body)
+		req.Header.Set("Content-Type", mediaType)
+		req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		req.Header.Set("X-Content-Sha256", base64.StdEncoding.EncodeToString(sum[:]))
+	}
+	hashed := sha256.Sum256([]byte(signingString(req, headers)))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, c.key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf(`Signature version="1",keyId="%s",algorithm="rsa-sha256",headers="%s",signature="%s"`,
+		c.keyID, strings.Join(headers, " "), base64.StdEncoding.EncodeToString(sig)))
+	return nil
+}
+
+// do sends a signed request and decodes the response into target (if not nil).
+// Requests that are throttled (429) or fail with a server error are retried
+// with exponential backoff. It returns the response headers.
+func (c *ociClient) do(method, path string, query url.Values, body, target interface{}) (http.Header, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	wait := time.Second
+	for retry := 0; ; retry++ {
+		req, err := http.NewRequest(method, u, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if err := c.sign(req, data); err != nil {
+			return nil, err
+		}
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		respBody, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500) && retry < maxRetries {
+			if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+				wait = time.Duration(s) * time.Second
+			}
+			log.Printf("OCI returned %d for %s %s. Waiting %s to retry.", resp.StatusCode, method, path, wait)
+			c.sleep(wait)
+			wait *= 2
+			continue
+		}
+		if resp.StatusCode/100 != 2 {
+			e := &errorResponse{}
+			if json.Unmarshal(respBody, e) != nil || e.Message == "" {
+				return nil, errors.Errorf("OCI %s %s: status %d", method, path, resp.StatusCode)
+			}
+			return nil, errors.Errorf("OCI %s %s: status %d: %s: %s", method, path, resp.StatusCode, e.Code, e.Message)
+		}
+		if target != nil && len(respBody) > 0 {
+			if err := json.Unmarshal(respBody, target); err != nil {
+				return nil, errors.Wrapf(err, "decoding OCI response to %s %s", method, path)
+			}
+		}
+		return resp.Header, nil
+	}
+}
+
+func (c *ociClient) listZones(compartment string) ([]zoneSummary, error) {
+	var zones []zoneSummary
+	q := url.Values{"compartmentId": {compartment}, "limit": {pageLimit}}
+	for {
+		var page []zoneSummary
+		h, err := c.do(http.MethodGet, "/zones", q, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, page...)
+		next := h.Get(nextPageHdr)
+		if next == "" {
+			return zones, nil
+		}
+		q.Set("page", next)
+	}
+}
+
+func (c *ociClient) getZone(compartment, zone string) (*zoneDetails, error) {
+	z := &zoneDetails{}
+	q := url.Values{"compartmentId": {compartment}}
+	if _, err := c.do(http.MethodGet, "/zones/"+zone, q, nil, z); err != nil {
+		return nil, err
+	}
+	return z, nil
+}
+
+func (c *ociClient) createZone(compartment, zone string) error {
+	req := createZoneRequest{Name: zone, ZoneType: "PRIMARY", CompartmentID: compartment}
+	_, err := c.do(http.MethodPost, "/zones", url.Values{"compartmentId": {compartment}}, req, nil)
+	return err
+}
+
+func (c *ociClient) getRecords(compartment, zone string) ([]record, error) {
+	var records []record
+	q := url.Values{"compartmentId": {compartment}, "limit": {pageLimit}}
+	for {
+		page := &recordCollection{}
+		h, err := c.do(http.MethodGet, "/zones/"+zone+"/records", q, nil, page)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, page.Items...)
+		next := h.Get(nextPageHdr)
+		if next == "" {
+			return records, nil
+		}
+		q.Set("page", next)
+	}
+}
+
+// patchRRSet applies ops to the rrset (domain, rtype) of zone.
+func (c *ociClient) patchRRSet(compartment, zone, domain, rtype string, ops []recordOperation) error {
+	path := fmt.Sprintf("/zones/%s/records/%s/%s", zone, domain, rtype)
+	_, err := c.do(http.MethodPatch, path, url.Values{"compartmentId": {compartment}}, patchRequest{Items: ops}, nil)
+	return err
+}
+
+// patchZone applies ops, which may be for any rrset, to zone in one request.
+func (c *ociClient) patchZone(compartment, zone string, ops []recordOperation) error {
+	_, err := c.do(http.MethodPatch, "/zones/"+zone+"/records", url.Values{"compartmentId": {compartment}}, patchRequest{Items: ops}, nil)
+	return err
+}
+
+type zoneSummary struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	ZoneType      string `json:"zoneType"`
+	CompartmentID string `json:"compartmentId"`
+}
+
+type zoneDetails struct {
+	zoneSummary
+	Nameservers []struct {
+		Hostname string `json:"hostname"`
+	} `json:"nameservers"`
+}
+
+type createZoneRequest struct {
+	Name          string `json:"name"`
+	ZoneType      string `json:"zoneType"`
+	CompartmentID string `json:"compartmentId"`
+}
+
+type record struct {
+	Domain      string `json:"domain"`
+	RecordHash  string `json:"recordHash"`
+	IsProtected bool   `json:"isProtected"`
+	Rdata       string `json:"rdata"`
+	Rtype       string `json:"rtype"`
+	TTL         uint32 `json:"ttl"`
+}
+
+type recordCollection struct {
+	Items []record `json:"items"`
+}
+
+type recordOperation struct {
+	Domain     string `json:"domain,omitempty"`
+	RecordHash string `json:"recordHash,omitempty"`
+	Rdata      string `json:"rdata,omitempty"`
+	Rtype      string `json:"rtype,omitempty"`
+	TTL        uint32 `json:"ttl,omitempty"`
+	Operation  string `json:"operation"`
+}
+
+type patchRequest struct {
+	Items []recordOperation `json:"items"`
+}
+
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
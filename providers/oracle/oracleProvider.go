@@ -0,0 +1,232 @@
+package oracle
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+	"github.com/pkg/errors"
+)
+
+/*
+
+Oracle Cloud Infrastructure (OCI) DNS provider:
+
+Info required in `creds.json`:
+   - tenancy_ocid
+   - user_ocid
+   - fingerprint
+   - private_key (PEM, the API signing key)
+   - region
+   - compartment (optional, defaults to the tenancy)
+
+*/
+
+var features = providers.DocumentationNotes{
+	providers.CanUseAlias:            providers.Cannot(),
+	providers.CanUseCAA:              providers.Can(),
+	providers.CanUsePTR:              providers.Can(),
+	providers.CanUseSRV:              providers.Can(),
+	providers.CanUseTLSA:             providers.Can(),
+	providers.CanUseTXTMulti:         providers.Can(),
+	providers.DocCreateDomains:       providers.Can(),
+	providers.DocDualHost:            providers.Cannot("The apex NS records are managed by OCI and cannot be changed"),
+	providers.DocOfficiallySupported: providers.Cannot(),
+}
+
+func init() {
+	providers.RegisterDomainServiceProviderType("ORACLE", newOracle, features)
+}
+
+type oracleProvider struct {
+	client      *ociClient
+	compartment string
+}
+
+func newOracle(m map[string]string, metadata json.RawMessage) (providers.DNSServiceProvider, error) {
+	for _, k := range []string{"tenancy_ocid", "user_ocid", "fingerprint", "private_key", "region"} {
+		if m[k] == "" {
+			return nil, errors.Errorf("OCI %s must be provided", k)
+		}
+	}
+	client, err := newClient(m["tenancy_ocid"], m["user_ocid"], m["fingerprint"], m["region"], m["private_key"])
+	if err != nil {
+		return nil, err
+	}
+	if m["http_timeout"] != "" || m["https_proxy"] != "" {
+		if client.client, err = providers.HTTPClient(m); err != nil {
+			return nil, err
+		}
+	}
+	compartment := m["compartment"]
+	if compartment == "" {
+		// The tenancy is the root compartment.
+		compartment = m["tenancy_ocid"]
+	}
+	return &oracleProvider{client: client, compartment: compartment}, nil
+}
+
+// GetNameservers returns the nameservers OCI assigned to the zone.
+func (o *oracleProvider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	z, err := o.client.getZone(o.compartment, domain)
+	if err != nil {
+		return nil, err
+	}
+	ns := make([]string, len(z.Nameservers))
+	for i, n := range z.Nameservers {
+		ns[i] = n.Hostname
+	}
+	return models.StringsToNameservers(ns), nil
+}
+
+// EnsureDomainExists creates the zone in the compartment if it does not exist.
+func (o *oracleProvider) EnsureDomainExists(domain string) error {
+	zones, err := o.client.listZones(o.compartment)
+	if err != nil {
+		return err
+	}
+	for _, z := range zones {
+		if z.Name == domain {
+			return nil
+		}
+	}
+	return o.client.createZone(o.compartment, domain)
+}
+
+// GetDomainCorrections returns the corrections for a domain.
+func (o *oracleProvider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	dc, err := dc.Copy()
+	if err != nil {
+		return nil, err
+	}
+	dc.Punycode()
+
+	records, err := o.client.getRecords(o.compartment, dc.Name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading zone %s in compartment %s", dc.Name, o.compartment)
+	}
+	existing := make([]*models.RecordConfig, 0, len(records))
+	for i := range records {
+		// The SOA and the apex NS records are owned by OCI.
+		if records[i].IsProtected {
+			continue
+		}
+		rc, err := toRecordConfig(dc.Name, &records[i])
+		if err != nil {
+			return nil, err
+		}
+		existing = append(existing, rc)
+	}
+	models.PostProcessRecords(existing)
+
+	differ := diff.New(dc)
+	_, create, del, modify := differ.IncrementalDiff(existing)
+
+	var corrections []*models.Correction
+	for _, set := range rrsetPatches(create, del, modify) {
+		set := set
+		corrections = append(corrections, &models.Correction{
+			Msg: strings.Join(set.msgs, "\n"),
+			F: func() error {
+				return o.client.patchRRSet(o.compartment, dc.Name, set.domain, set.rtype, set.ops)
+			},
+		})
+	}
+	return corrections, nil
+}
+
+// EmptyZone deletes all records of a zone, except those owned by OCI, in one request.
+func (o *oracleProvider) EmptyZone(domain string) error {
+	records, err := o.client.getRecords(o.compartment, domain)
+	if err != nil {
+		return err
+	}
+	var ops []recordOperation
+	for _, r := range records {
+		if !r.IsProtected {
+			ops = append(ops, recordOperation{Domain: r.Domain, Rtype: r.Rtype, Rdata: r.Rdata, RecordHash: r.RecordHash, Operation: "REMOVE"})
+		}
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	return o.client.patchZone(o.compartment, domain, ops)
+}
+
+// rrsetPatch is the list of operations for one OCI rrset (name and type).
+type rrsetPatch struct {
+	domain, rtype string
+	ops           []recordOperation
+	msgs          []string
+}
+
+// rrsetPatches maps the per-record changes found by the differ onto one
+// PATCH per rrset, in name/type order. A modification is a REMOVE of the old
+// record followed by an ADD of the new one.
+func rrsetPatches(create, del, modify diff.Changeset) []*rrsetPatch {
+	sets := map[models.RecordKey]*rrsetPatch{}
+	get := func(rc *models.RecordConfig) *rrsetPatch {
+		k := rc.Key()
+		if sets[k] == nil {
+			sets[k] = &rrsetPatch{domain: rc.GetLabelFQDN(), rtype: rc.Type}
+		}
+		return sets[k]
+	}
+	remove := func(rc *models.RecordConfig) recordOperation {
+		r := rc.Original.(*record)
+		return recordOperation{Domain: r.Domain, Rtype: r.Rtype, Rdata: r.Rdata, RecordHash: r.RecordHash, Operation: "REMOVE"}
+	}
+	add := func(rc *models.RecordConfig) recordOperation {
+		return recordOperation{Domain: rc.GetLabelFQDN(), Rtype: rc.Type, Rdata: rc.GetTargetCombined(), TTL: rc.TTL, Operation: "ADD"}
+	}
+	for _, m := range del {
+		s := get(m.Existing)
+		s.ops = append(s.ops, remove(m.Existing))
+		s.msgs = append(s.msgs, m.String())
+	}
+	for _, m := range modify {
+		s := get(m.Desired)
+		s.ops = append(s.ops, remove(m.Existing), add(m.Desired))
+		s.msgs = append(s.msgs, m.String())
+	}
+	for _, m := range create {
+		s := get(m.Desired)
+		s.ops = append(s.ops, add(m.Desired))
+		s.msgs = append(s.msgs, m.String())
+	}
+
+	result := make([]*rrsetPatch, 0, len(sets))
+	for _, s := range sets {
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].domain != result[j].domain {
+			return result[i].domain < result[j].domain
+		}
+		return result[i].rtype < result[j].rtype
+	})
+	return result
+}
+
+// toRecordConfig converts an OCI record to a RecordConfig. #rtype_variations
+func toRecordConfig(origin string, r *record) (*models.RecordConfig, error) {
+	rc := &models.RecordConfig{
+		TTL:      r.TTL,
+		Original: r,
+	}
+	rc.SetLabelFromFQDN(r.Domain, origin)
+	if err := rc.PopulateFromString(r.Rtype, r.Rdata, origin); err != nil {
+		return nil, errors.Wrapf(err, "unparsable record received from OCI")
+	}
+	// OCI may return hostnames without the trailing dot.
+	switch rc.Type {
+	case "CNAME", "MX", "NS", "PTR", "SRV":
+		if t := rc.GetTargetField(); !strings.HasSuffix(t, ".") {
+			rc.SetTarget(t + ".")
+		}
+	}
+	return rc, nil
+}
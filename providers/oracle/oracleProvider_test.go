@@ -0,0 +1,150 @@
+package oracle
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+)
+
+func testClient(t *testing.T, baseURL string) *ociClient {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ociClient{
+		client:  http.DefaultClient,
+		baseURL: baseURL,
+		keyID:   "tenancy/user/fp",
+		key:     key,
+		sleep:   func(time.Duration) {},
+	}
+}
+
+func TestSign(t *testing.T) {
+	c := testClient(t, "https://dns.example-region.oraclecloud.com/20180115")
+	body := []byte(`{"items":[]}`)
+	req, _ := http.NewRequest(http.MethodPatch, c.baseURL+"/zones/example.com/records/www.example.com/A?compartmentId=c1", nil)
+	if err := c.sign(req, body); err != nil {
+		t.Fatal(err)
+	}
+
+	m := regexp.MustCompile(`^Signature version="1",keyId="tenancy/user/fp",algorithm="rsa-sha256",headers="([^"]*)",signature="([^"]*)"$`).
+		FindStringSubmatch(req.Header.Get("Authorization"))
+	if m == nil {
+		t.Fatalf("unexpected Authorization header %q", req.Header.Get("Authorization"))
+	}
+	if m[1] != "date (request-target) host content-length content-type x-content-sha256" {
+		t.Errorf("unexpected signed headers %q", m[1])
+	}
+	ss := signingString(req, strings.Split(m[1], " "))
+	if !strings.Contains(ss, "\n(request-target): patch /20180115/zones/example.com/records/www.example.com/A?compartmentId=c1\n") {
+		t.Errorf("unexpected signing string %q", ss)
+	}
+	sig, _ := base64.StdEncoding.DecodeString(m[2])
+	hashed := sha256.Sum256([]byte(ss))
+	if err := rsa.VerifyPKCS1v15(&c.key.PublicKey, crypto.SHA256, hashed[:], sig); err != nil {
+		t.Errorf("signature does not verify: %s", err)
+	}
+	if req.Header.Get("Content-Length") != "12" {
+		t.Errorf("unexpected Content-Length %q", req.Header.Get("Content-Length"))
+	}
+
+	get, _ := http.NewRequest(http.MethodGet, c.baseURL+"/zones", nil)
+	c.sign(get, nil)
+	if !strings.Contains(get.Header.Get("Authorization"), `headers="date (request-target) host"`) {
+		t.Errorf("GET should only sign date, request target and host: %q", get.Header.Get("Authorization"))
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set(nextPageHdr, "")
+		w.Write([]byte(`[{"name":"example.com","id":"z1"}]`))
+	}))
+	defer srv.Close()
+
+	zones, err := testClient(t, srv.URL).listZones("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 || len(zones) != 1 || zones[0].Name != "example.com" {
+		t.Errorf("expected 3 calls and one zone, got %d calls and %v", calls, zones)
+	}
+}
+
+func TestToRecordConfig(t *testing.T) {
+	for _, tst := range []struct {
+		r      record
+		label  string
+		target string
+	}{
+		{record{Domain: "example.com", Rtype: "A", Rdata: "1.2.3.4"}, "@", "1.2.3.4"},
+		{record{Domain: "www.example.com", Rtype: "CNAME", Rdata: "foo.example.net"}, "www", "foo.example.net."},
+		{record{Domain: "example.com", Rtype: "MX", Rdata: "10 mx.example.com."}, "@", "mx.example.com."},
+		{record{Domain: "txt.example.com", Rtype: "TXT", Rdata: `"a b" "c"`}, "txt", "a b"},
+	} {
+		rc, err := toRecordConfig("example.com", &tst.r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rc.GetLabel() != tst.label || rc.GetTargetField() != tst.target {
+			t.Errorf("%v: got %s %s, expected %s %s", tst.r, rc.GetLabel(), rc.GetTargetField(), tst.label, tst.target)
+		}
+	}
+}
+
+func TestRRSetPatches(t *testing.T) {
+	rec := func(label, rtype, target, hash string) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: rtype, TTL: 300}
+		rc.SetLabel(label, "example.com")
+		rc.SetTarget(target)
+		if hash != "" {
+			rc.Original = &record{Domain: rc.GetLabelFQDN(), Rtype: rtype, Rdata: target, RecordHash: hash}
+		}
+		return rc
+	}
+	existing := []*models.RecordConfig{
+		rec("old", "A", "1.1.1.1", "h1"),
+		rec("www", "A", "1.1.1.1", "h2"),
+		rec("www", "MX", "mx.example.com.", "h3"),
+	}
+	existing[2].MxPreference = 10
+	dc := &models.DomainConfig{Name: "example.com", Records: []*models.RecordConfig{
+		rec("www", "A", "1.1.1.2", ""),
+		rec("www", "A", "1.1.1.3", ""),
+		existing[2],
+	}}
+	_, create, del, modify := diff.New(dc).IncrementalDiff(existing)
+
+	sets := rrsetPatches(create, del, modify)
+	if len(sets) != 2 {
+		t.Fatalf("expected 2 rrsets, got %d", len(sets))
+	}
+	if sets[0].domain != "old.example.com" || len(sets[0].ops) != 1 || sets[0].ops[0].Operation != "REMOVE" || sets[0].ops[0].RecordHash != "h1" {
+		t.Errorf("unexpected rrset %+v", sets[0])
+	}
+	var ops []string
+	for _, op := range sets[1].ops {
+		ops = append(ops, op.Operation+" "+op.Rdata)
+	}
+	if strings.Join(ops, ",") != "REMOVE 1.1.1.1,ADD 1.1.1.2,ADD 1.1.1.3" {
+		t.Errorf("unexpected operations for www.example.com: %v", ops)
+	}
+}
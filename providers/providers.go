@@ -3,6 +3,7 @@ package providers
 import (
 	"encoding/json"
 	"log"
+	"sort"
 
 	"github.com/StackExchange/dnscontrol/models"
 	"github.com/pkg/errors"
@@ -24,6 +25,25 @@ type DomainCreator interface {
 	EnsureDomainExists(domain string) error
 }
 
+// ZoneEmptier should be implemented by providers that can delete all records of a zone in one call.
+// push -allow-empty-zone uses it instead of deleting the records one by one. The SOA and the
+// apex NS records must be left alone.
+type ZoneEmptier interface {
+	EmptyZone(domain string) error
+}
+
+// CredentialChecker should be implemented by providers that can verify their credentials by reading
+// account information, without changing anything. check-creds uses it.
+type CredentialChecker interface {
+	CheckCredentials() error
+}
+
+// ZoneLister should be implemented by providers that can list the zones of the account. list-zones
+// uses it to find zones that dnsconfig.js doesn't manage yet.
+type ZoneLister interface {
+	ListZones() ([]string, error)
+}
+
 // RegistrarInitializer is a function to create a registrar. Function will be passed the unprocessed json payload from the configuration file for the given provider.
 type RegistrarInitializer func(map[string]string) (Registrar, error)
 
@@ -117,3 +137,15 @@ func GetCustomRecordType(rType string) *CustomRType {
 }
 
 var customRecordTypes = map[string]*CustomRType{}
+
+// ProviderCustomRecordTypes returns the names of the custom record types of a provider, sorted.
+func ProviderCustomRecordTypes(pType string) []string {
+	var names []string
+	for name, t := range customRecordTypes {
+		if t.Provider == pType {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,251 @@
+package rfc2136
+
+/*
+
+RFC 2136 -
+  Update any authoritative server that accepts dynamic updates
+  (BIND, Knot, PowerDNS...), with TSIG authentication.
+
+	The zone is read with a zone transfer (AXFR) and the changes are
+	sent as one dynamic update (RFC 2136), so that they are applied
+	all together or not at all.
+
+Info required in `creds.json`:
+   - server: host or host:port of the primary server
+Optional:
+   - keyname, keysecret: the TSIG key, keysecret in base64
+   - keyalgorithm: hmac-sha256 (the default), hmac-sha512, hmac-sha1 or hmac-md5
+
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+var features = providers.DocumentationNotes{
+	providers.CanUseCAA:              providers.Can(),
+	providers.CanUsePTR:              providers.Can(),
+	providers.CanUseSRV:              providers.Can(),
+	providers.CanUseTLSA:             providers.Can(),
+	providers.CanUseTXTMulti:         providers.Can(),
+	providers.DocCreateDomains:       providers.Cannot("Zones must be created on the server"),
+	providers.DocDualHost:            providers.Can(),
+	providers.DocOfficiallySupported: providers.Cannot(),
+}
+
+func init() {
+	providers.RegisterDomainServiceProviderType("RFC2136", newProvider, features)
+}
+
+// algorithms are the TSIG algorithms, by their name in creds.json.
+var algorithms = map[string]string{
+	"hmac-md5":    dns.HmacMD5,
+	"hmac-sha1":   dns.HmacSHA1,
+	"hmac-sha256": dns.HmacSHA256,
+	"hmac-sha512": dns.HmacSHA512,
+}
+
+// timeout is how long each query, transfer or update may take.
+const timeout = 30 * time.Second
+
+// rfc2136Provider is the handle for this provider.
+type rfc2136Provider struct {
+	server    string
+	keyName   string
+	algorithm string
+	secret    string
+}
+
+func newProvider(conf map[string]string, metadata json.RawMessage) (providers.DNSServiceProvider, error) {
+	p := &rfc2136Provider{server: conf["server"]}
+	if p.server == "" {
+		return nil, errors.Errorf("RFC2136 server must be provided")
+	}
+	if _, _, err := net.SplitHostPort(p.server); err != nil {
+		p.server = net.JoinHostPort(p.server, "53")
+	}
+	if conf["keyname"] != "" || conf["keysecret"] != "" {
+		if conf["keyname"] == "" || conf["keysecret"] == "" {
+			return nil, errors.Errorf("RFC2136 keyname and keysecret must be provided together")
+		}
+		p.keyName = dns.Fqdn(strings.ToLower(conf["keyname"]))
+		p.secret = conf["keysecret"]
+		alg := conf["keyalgorithm"]
+		if alg == "" {
+			alg = "hmac-sha256"
+		}
+		if p.algorithm = algorithms[strings.ToLower(alg)]; p.algorithm == "" {
+			return nil, errors.Errorf("RFC2136 keyalgorithm %q is not supported (must be hmac-sha256, hmac-sha512, hmac-sha1 or hmac-md5)", alg)
+		}
+	}
+	return p, nil
+}
+
+// sign adds the TSIG of the key to m, if there is a key.
+func (p *rfc2136Provider) sign(m *dns.Msg) map[string]string {
+	if p.keyName == "" {
+		return nil
+	}
+	m.SetTsig(p.keyName, p.algorithm, 300, time.Now().Unix())
+	return map[string]string{p.keyName: p.secret}
+}
+
+// GetNameservers returns the apex NS records of the zone on the server, so
+// that they are kept.
+func (p *rfc2136Provider) GetNameservers(domain string) ([]*models.Nameserver, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(domain), dns.TypeNS)
+	c := &dns.Client{Net: "tcp", Timeout: timeout}
+	c.TsigSecret = p.sign(m)
+	r, _, err := c.Exchange(m, p.server)
+	if err != nil {
+		return nil, errors.Errorf("RFC2136: NS query for %s: %s", domain, err)
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		return nil, errors.Errorf("RFC2136: NS query for %s: %s", domain, dns.RcodeToString[r.Rcode])
+	}
+	var ns []string
+	for _, rr := range r.Answer {
+		if v, ok := rr.(*dns.NS); ok {
+			ns = append(ns, strings.TrimSuffix(v.Ns, "."))
+		}
+	}
+	return models.StringsToNameservers(ns), nil
+}
+
+// getRecords reads the zone with a zone transfer. The SOA, which the server
+// maintains, and the types that aren't managed are left out.
+func (p *rfc2136Provider) getRecords(domain string) ([]*models.RecordConfig, error) {
+	m := new(dns.Msg)
+	m.SetAxfr(dns.Fqdn(domain))
+	t := &dns.Transfer{DialTimeout: timeout, ReadTimeout: timeout, WriteTimeout: timeout}
+	t.TsigSecret = p.sign(m)
+	env, err := t.In(m, p.server)
+	if err != nil {
+		return nil, errors.Errorf("RFC2136: zone transfer of %s: %s", domain, err)
+	}
+	var records []*models.RecordConfig
+	for e := range env {
+		if e.Error != nil {
+			return nil, errors.Errorf("RFC2136: zone transfer of %s: %s", domain, e.Error)
+		}
+		for _, rr := range e.RR {
+			rc, err := rrToRecord(rr, domain)
+			if err != nil {
+				return nil, err
+			}
+			if rc != nil {
+				records = append(records, rc)
+			}
+		}
+	}
+	return records, nil
+}
+
+// rrToRecord converts rr, or returns nil if its type isn't managed.
+func rrToRecord(rr dns.RR, origin string) (*models.RecordConfig, error) {
+	header := rr.Header()
+	rc := &models.RecordConfig{
+		Type:     dns.TypeToString[header.Rrtype],
+		TTL:      header.Ttl,
+		Original: rr,
+	}
+	rc.SetLabelFromFQDN(strings.TrimSuffix(header.Name, "."), origin)
+	var err error
+	switch v := rr.(type) { // #rtype_variations
+	case *dns.A:
+		err = rc.SetTarget(v.A.String())
+	case *dns.AAAA:
+		err = rc.SetTarget(v.AAAA.String())
+	case *dns.CAA:
+		err = rc.SetTargetCAA(v.Flag, v.Tag, v.Value)
+	case *dns.CNAME:
+		err = rc.SetTarget(v.Target)
+	case *dns.MX:
+		err = rc.SetTargetMX(v.Preference, v.Mx)
+	case *dns.NS:
+		err = rc.SetTarget(v.Ns)
+	case *dns.PTR:
+		err = rc.SetTarget(v.Ptr)
+	case *dns.SRV:
+		err = rc.SetTargetSRV(v.Priority, v.Weight, v.Port, v.Target)
+	case *dns.TLSA:
+		err = rc.SetTargetTLSA(v.Usage, v.Selector, v.MatchingType, v.Certificate)
+	case *dns.TXT:
+		txts := make([]string, len(v.Txt))
+		for i, t := range v.Txt {
+			txts[i] = models.UnescapeTxt(t)
+		}
+		err = rc.SetTargetTXTs(txts)
+	default:
+		// SOA, DNSSEC records and the like are the server's.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "RFC2136: unparsable record received: %s", rr)
+	}
+	return rc, nil
+}
+
+// GetDomainCorrections returns a list of corrections to update a domain.
+func (p *rfc2136Provider) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	dc.Punycode()
+	existing, err := p.getRecords(dc.Name)
+	if err != nil {
+		return nil, err
+	}
+	models.PostProcessRecords(existing)
+
+	_, create, del, mod := diff.New(dc).IncrementalDiff(existing)
+	if len(create)+len(del)+len(mod) == 0 {
+		return nil, nil
+	}
+	m := new(dns.Msg)
+	m.SetUpdate(dns.Fqdn(dc.Name))
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "DYNAMIC UPDATE: %s\n", dc.Name)
+	for _, d := range del {
+		m.Remove([]dns.RR{d.Existing.Original.(dns.RR)})
+		fmt.Fprintln(buf, d)
+	}
+	for _, c := range create {
+		m.Insert([]dns.RR{c.Desired.ToRR()})
+		fmt.Fprintln(buf, c)
+	}
+	for _, c := range mod {
+		m.Remove([]dns.RR{c.Existing.Original.(dns.RR)})
+		m.Insert([]dns.RR{c.Desired.ToRR()})
+		fmt.Fprintln(buf, c)
+	}
+	return []*models.Correction{{
+		Msg: buf.String(),
+		F:   func() error { return p.update(dc.Name, m) },
+	}}, nil
+}
+
+// update sends a dynamic update. m is signed as a copy, so that it can be
+// sent again.
+func (p *rfc2136Provider) update(domain string, m *dns.Msg) error {
+	m = m.Copy()
+	c := &dns.Client{Net: "tcp", Timeout: timeout}
+	c.TsigSecret = p.sign(m)
+	r, _, err := c.Exchange(m, p.server)
+	if err != nil {
+		return errors.Errorf("RFC2136: update of %s: %s", domain, err)
+	}
+	if r.Rcode != dns.RcodeSuccess {
+		return errors.Errorf("RFC2136: update of %s refused: %s", domain, dns.RcodeToString[r.Rcode])
+	}
+	return nil
+}
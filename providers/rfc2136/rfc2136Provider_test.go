@@ -0,0 +1,293 @@
+package rfc2136
+
+import (
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers"
+	"github.com/miekg/dns"
+)
+
+const (
+	testKey    = "dnscontrol."
+	testSecret = "c2VjcmV0LXNlY3JldC1zZWNyZXQtc2VjcmV0LQ=="
+)
+
+// fakeServer is a primary that answers NS queries, zone transfers and dynamic
+// updates for one zone, and refuses anything not signed with testKey.
+type fakeServer struct {
+	sync.Mutex
+	zone    string
+	records []dns.RR
+}
+
+func (s *fakeServer) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	s.Lock()
+	defer s.Unlock()
+	r := new(dns.Msg)
+	r.SetReply(req)
+	tsigs := 0
+	for _, rr := range req.Extra {
+		if rr.Header().Rrtype == dns.TypeTSIG {
+			tsigs++
+		}
+	}
+	if tsigs != 1 || req.IsTsig() == nil || w.TsigStatus() != nil {
+		r.SetRcode(req, dns.RcodeRefused)
+		w.WriteMsg(r)
+		return
+	}
+	r.SetTsig(testKey, dns.HmacSHA256, 300, int64(req.IsTsig().TimeSigned))
+	switch {
+	case req.Opcode == dns.OpcodeUpdate:
+		for _, rr := range req.Ns {
+			if rr.Header().Class == dns.ClassNONE {
+				s.remove(rr)
+			} else {
+				s.records = append(s.records, dns.Copy(rr))
+			}
+		}
+	case req.Question[0].Qtype == dns.TypeAXFR:
+		soa, _ := dns.NewRR(s.zone + " 300 IN SOA ns1." + s.zone + " hostmaster." + s.zone + " 1 3600 600 86400 300")
+		ch := make(chan *dns.Envelope, 1)
+		tr := new(dns.Transfer)
+		ch <- &dns.Envelope{RR: append(append([]dns.RR{soa}, s.records...), soa)}
+		close(ch)
+		tr.Out(w, req, ch)
+		return
+	case req.Question[0].Qtype == dns.TypeNS:
+		for _, rr := range s.records {
+			if rr.Header().Rrtype == dns.TypeNS && rr.Header().Name == s.zone {
+				r.Answer = append(r.Answer, rr)
+			}
+		}
+	}
+	w.WriteMsg(r)
+}
+
+// remove deletes the record that a class NONE update RR names.
+func (s *fakeServer) remove(del dns.RR) {
+	want := dns.Copy(del)
+	want.Header().Class = dns.ClassINET
+	for i, rr := range s.records {
+		want.Header().Ttl = rr.Header().Ttl
+		if rr.String() == want.String() {
+			s.records = append(s.records[:i], s.records[i+1:]...)
+			return
+		}
+	}
+}
+
+func startServer(t *testing.T, zone string, records ...string) (*fakeServer, string, func()) {
+	fs := &fakeServer{zone: dns.Fqdn(zone)}
+	for _, r := range records {
+		rr, err := dns.NewRR(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fs.records = append(fs.records, rr)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	srv := &dns.Server{
+		Listener:          l,
+		Handler:           fs,
+		TsigSecret:        map[string]string{testKey: testSecret},
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go srv.ActivateAndServe()
+	<-started
+	return fs, l.Addr().String(), func() { srv.Shutdown() }
+}
+
+func record(label, typ, target, domain string) *models.RecordConfig {
+	rc := &models.RecordConfig{Type: typ, TTL: 300}
+	rc.SetLabel(label, domain)
+	if typ == "TXT" {
+		rc.SetTargetTXTs(strings.Split(target, "|"))
+	} else {
+		rc.SetTarget(target)
+	}
+	return rc
+}
+
+// push runs the corrections for the domain that dc returns, and checks that
+// there are none left after.
+func push(t *testing.T, p providers.DNSServiceProvider, dc func() *models.DomainConfig) {
+	corrections, err := p.GetDomainCorrections(dc())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range corrections {
+		if err := c.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if corrections, err = p.GetDomainCorrections(dc()); err != nil {
+		t.Fatal(err)
+	} else if len(corrections) != 0 {
+		t.Errorf("Expected no corrections after the update, got %s", corrections[0].Msg)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	fs, addr, stop := startServer(t, "example.com",
+		"example.com. 300 IN NS ns1.example.com.",
+		"www.example.com. 300 IN A 10.0.0.1",
+		"old.example.com. 300 IN A 10.0.0.2",
+	)
+	defer stop()
+	p, err := newProvider(map[string]string{"server": addr, "keyname": "dnscontrol", "keysecret": testSecret}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ns, err := p.GetNameservers("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ns) != 1 || ns[0].Name != "ns1.example.com" {
+		t.Errorf("Expected ns1.example.com, got %v", ns)
+	}
+
+	push(t, p, func() *models.DomainConfig {
+		return &models.DomainConfig{
+			Name: "example.com",
+			Records: []*models.RecordConfig{
+				record("@", "NS", "ns1.example.com.", "example.com"),
+				record("www", "A", "10.0.0.3", "example.com"),
+				record("new", "CNAME", "www.example.com.", "example.com"),
+				record("txt", "TXT", `back\slash|say "hi"|caf`+"\xc3\xa9", "example.com"),
+			},
+		}
+	})
+
+	var got []string
+	for _, rr := range fs.records {
+		got = append(got, rr.String())
+	}
+	sort.Strings(got)
+	want := []string{
+		"example.com.\t300\tIN\tNS\tns1.example.com.",
+		"new.example.com.\t300\tIN\tCNAME\twww.example.com.",
+		"txt.example.com.\t300\tIN\tTXT\t\"back\\\\slash\" \"say \\\"hi\\\"\" \"caf\\195\\169\"",
+		"www.example.com.\t300\tIN\tA\t10.0.0.3",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected zone:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestUpdateAgain(t *testing.T) {
+	fs, addr, stop := startServer(t, "example.com")
+	defer stop()
+	p, err := newProvider(map[string]string{"server": addr, "keyname": "dnscontrol", "keysecret": testSecret}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrections, err := p.GetDomainCorrections(&models.DomainConfig{
+		Name:    "example.com",
+		Records: []*models.RecordConfig{record("www", "A", "10.0.0.1", "example.com")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A correction that is retried sends the update again, signed once.
+	for i := 0; i < 2; i++ {
+		if err := corrections[0].F(); err != nil {
+			t.Fatalf("Update %d: %s", i+1, err)
+		}
+	}
+	if len(fs.records) != 2 {
+		t.Errorf("Expected the record to be added twice, got %v", fs.records)
+	}
+}
+
+func TestUpdateRefused(t *testing.T) {
+	_, addr, stop := startServer(t, "example.com")
+	defer stop()
+	for _, conf := range []map[string]string{
+		{"server": addr},
+		{"server": addr, "keyname": "dnscontrol", "keysecret": "d3Jvbmc="},
+	} {
+		p, err := newProvider(conf, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.GetDomainCorrections(&models.DomainConfig{Name: "example.com"}); err == nil {
+			t.Errorf("%v: expected the zone transfer to be refused", conf)
+		}
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	p, err := newProvider(map[string]string{"server": "ns1.example.com", "keyname": "Key", "keysecret": testSecret, "keyalgorithm": "HMAC-SHA512"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := *p.(*rfc2136Provider); got != (rfc2136Provider{"ns1.example.com:53", "key.", dns.HmacSHA512, testSecret}) {
+		t.Errorf("Unexpected provider %+v", got)
+	}
+	for _, conf := range []map[string]string{
+		{},
+		{"server": "ns1.example.com", "keyname": "key"},
+		{"server": "ns1.example.com", "keyname": "key", "keysecret": testSecret, "keyalgorithm": "gss-tsig"},
+	} {
+		if _, err := newProvider(conf, nil); err == nil {
+			t.Errorf("%v: expected an error", conf)
+		}
+	}
+}
+
+// TestServer updates a real server, such as BIND or Knot, when
+// RFC2136_SERVER is set. The zone RFC2136_DOMAIN (default example.com) must
+// allow dynamic updates and transfers with the key in RFC2136_KEYNAME and
+// RFC2136_KEYSECRET. Its records, other than the apex NS, are replaced.
+func TestServer(t *testing.T) {
+	server := os.Getenv("RFC2136_SERVER")
+	if server == "" {
+		t.Skip("RFC2136_SERVER is not set")
+	}
+	domain := os.Getenv("RFC2136_DOMAIN")
+	if domain == "" {
+		domain = "example.com"
+	}
+	p, err := newProvider(map[string]string{
+		"server":       server,
+		"keyname":      os.Getenv("RFC2136_KEYNAME"),
+		"keysecret":    os.Getenv("RFC2136_KEYSECRET"),
+		"keyalgorithm": os.Getenv("RFC2136_KEYALGORITHM"),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns, err := p.GetNameservers(domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, targets := range [][]string{
+		{"A www 10.0.0.1", `TXT txt back\slash|say "hi"`},
+		{"A www 10.0.0.2"},
+		nil,
+	} {
+		push(t, p, func() *models.DomainConfig {
+			dc := &models.DomainConfig{Name: domain}
+			for _, n := range ns {
+				dc.Records = append(dc.Records, record("@", "NS", dns.Fqdn(n.Name), domain))
+			}
+			for _, target := range targets {
+				f := strings.SplitN(target, " ", 3)
+				dc.Records = append(dc.Records, record(f[1], f[0], f[2], domain))
+			}
+			return dc
+		})
+	}
+}
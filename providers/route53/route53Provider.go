@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,9 +20,18 @@ import (
 )
 
 type route53Provider struct {
-	client    *r53.Route53
+	client    route53Client
 	registrar *r53d.Route53Domains
-	zones     map[string]*r53.HostedZone
+	zones     map[string][]*r53.HostedZone // All zones with a given name, public and private.
+	private   route53Private
+}
+
+// route53Private is the provider metadata that marks the zones this provider
+// manages as private hosted zones associated with the given VPCs.
+type route53Private struct {
+	PrivateZone bool     `json:"private_zone"`
+	VPCIDs      []string `json:"vpc_ids"`
+	VPCRegion   string   `json:"vpc_region"`
 }
 
 func newRoute53Reg(conf map[string]string) (providers.Registrar, error) {
@@ -38,8 +48,23 @@ func newRoute53(m map[string]string, metadata json.RawMessage) (*route53Provider
 	// Route53 uses a global endpoint and route53domains
 	// currently only has a single regional endpoint in us-east-1
 	// http://docs.aws.amazon.com/general/latest/gr/rande.html#r53_region
+	client, err := providers.HTTPClient(m)
+	if err != nil {
+		return nil, err
+	}
+	// The endpoint, if any, is only for route53: the registrar keeps using
+	// route53domains.
+	endpoint, err := providers.Endpoint(m, "")
+	if err != nil {
+		return nil, err
+	}
+	r53Config := &aws.Config{}
+	if endpoint.String() != "" {
+		r53Config.Endpoint = aws.String(endpoint.String())
+	}
 	config := &aws.Config{
-		Region: aws.String("us-east-1"),
+		Region:     aws.String("us-east-1"),
+		HTTPClient: client,
 	}
 
 	if keyID != "" || secretKey != "" {
@@ -47,8 +72,16 @@ func newRoute53(m map[string]string, metadata json.RawMessage) (*route53Provider
 	}
 	sess := session.New(config)
 
-	api := &route53Provider{client: r53.New(sess), registrar: r53d.New(sess)}
-	err := api.getZones()
+	api := &route53Provider{client: r53.New(sess, r53Config), registrar: r53d.New(sess)}
+	if len(metadata) != 0 {
+		if err := json.Unmarshal(metadata, &api.private); err != nil {
+			return nil, err
+		}
+	}
+	if api.private.PrivateZone && (len(api.private.VPCIDs) == 0 || api.private.VPCRegion == "") {
+		return nil, errors.New("ROUTE53 private_zone requires vpc_ids and vpc_region")
+	}
+	err = api.getZones()
 	if err != nil {
 		return nil, err
 	}
@@ -65,6 +98,7 @@ var features = providers.DocumentationNotes{
 	providers.CanUseTXTMulti:         providers.Can(),
 	providers.CanUseCAA:              providers.Can(),
 	providers.CanUseRoute53Alias:     providers.Can(),
+	providers.CanUseGeoRecords:       providers.Can("Geolocation routing. Record sets with other routing policies are left alone."),
 }
 
 func init() {
@@ -79,7 +113,7 @@ func sPtr(s string) *string {
 
 func (r *route53Provider) getZones() error {
 	var nextMarker *string
-	r.zones = make(map[string]*r53.HostedZone)
+	r.zones = make(map[string][]*r53.HostedZone)
 	for {
 
 		inp := &r53.ListHostedZonesInput{Marker: nextMarker}
@@ -91,7 +125,7 @@ func (r *route53Provider) getZones() error {
 		}
 		for _, z := range out.HostedZones {
 			domain := strings.TrimSuffix(*z.Name, ".")
-			r.zones[domain] = z
+			r.zones[domain] = append(r.zones[domain], z)
 		}
 		if out.NextMarker != nil {
 			nextMarker = out.NextMarker
@@ -102,13 +136,80 @@ func (r *route53Provider) getZones() error {
 	return nil
 }
 
-// map key for grouping records
+func isPrivate(z *r53.HostedZone) bool {
+	return z.Config != nil && aws.BoolValue(z.Config.PrivateZone)
+}
+
+// selectZone picks the zone this provider manages out of all zones sharing a
+// name. A public and a private zone may have the same name, so only zones
+// of the configured visibility are considered. If several private zones
+// share a name, the one associated with one of our VPCs wins; vpcsOf is
+// used to look up the VPCs of a zone.
+func selectZone(domain string, candidates []*r53.HostedZone, priv route53Private, vpcsOf func(*r53.HostedZone) ([]string, error)) (*r53.HostedZone, error) {
+	matching := []*r53.HostedZone{}
+	for _, z := range candidates {
+		if isPrivate(z) == priv.PrivateZone {
+			matching = append(matching, z)
+		}
+	}
+	if len(matching) == 0 {
+		return nil, errNoExist{domain}
+	}
+	if len(matching) == 1 {
+		return matching[0], nil
+	}
+	if !priv.PrivateZone {
+		return nil, errors.Errorf("Multiple public zones named %s found in your route 53 account", domain)
+	}
+	wanted := map[string]bool{}
+	for _, id := range priv.VPCIDs {
+		wanted[id] = true
+	}
+	var found *r53.HostedZone
+	for _, z := range matching {
+		vpcs, err := vpcsOf(z)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range vpcs {
+			if wanted[id] {
+				if found != nil && found != z {
+					return nil, errors.Errorf("Multiple private zones named %s are associated with VPCs %s", domain, strings.Join(priv.VPCIDs, ","))
+				}
+				found = z
+			}
+		}
+	}
+	if found == nil {
+		return nil, errors.Errorf("No private zone named %s is associated with VPCs %s", domain, strings.Join(priv.VPCIDs, ","))
+	}
+	return found, nil
+}
+
+func (r *route53Provider) vpcsOf(z *r53.HostedZone) ([]string, error) {
+	out, err := r.client.GetHostedZone(&r53.GetHostedZoneInput{Id: z.Id})
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, v := range out.VPCs {
+		ids = append(ids, aws.StringValue(v.VPCId))
+	}
+	return ids, nil
+}
+
+func (r *route53Provider) getZone(domain string) (*r53.HostedZone, error) {
+	return selectZone(domain, r.zones[domain], r.private, r.vpcsOf)
+}
+
+// map key for grouping records. Geo is the location of geolocation record
+// sets (models.MetaGeo), each of which is a record set of its own.
 type key struct {
-	Name, Type string
+	Name, Type, Geo string
 }
 
 func getKey(r *models.RecordConfig) key {
-	return key{r.GetLabelFQDN(), r.Type}
+	return key{r.GetLabelFQDN(), r.Type, r.Metadata[models.MetaGeo]}
 }
 
 type errNoExist struct {
@@ -121,9 +222,9 @@ func (e errNoExist) Error() string {
 
 func (r *route53Provider) GetNameservers(domain string) ([]*models.Nameserver, error) {
 
-	zone, ok := r.zones[domain]
-	if !ok {
-		return nil, errNoExist{domain}
+	zone, err := r.getZone(domain)
+	if err != nil {
+		return nil, err
 	}
 	z, err := r.client.GetHostedZone(&r53.GetHostedZoneInput{Id: zone.Id})
 	if err != nil {
@@ -142,21 +243,27 @@ func (r *route53Provider) GetDomainCorrections(dc *models.DomainConfig) ([]*mode
 	dc.Punycode()
 
 	var corrections = []*models.Correction{}
-	zone, ok := r.zones[dc.Name]
-	// add zone if it doesn't exist
-	if !ok {
-		return nil, errNoExist{dc.Name}
+	zone, err := r.getZone(dc.Name)
+	if err != nil {
+		return nil, err
 	}
 
-	records, err := r.fetchRecordSets(zone.Id)
+	// The converted records of the whole zone are held for the diff, but only
+	// the record sets we may have to delete are kept as they came from r53, so
+	// that huge zones are not held in memory twice.
+	desiredKeys := map[key]bool{}
+	geo := false
+	for _, rc := range dc.Records {
+		desiredKeys[getKey(rc)] = true
+		if _, ok := rc.Metadata[models.MetaGeo]; ok {
+			geo = true
+		}
+	}
+	existingRecords, deletable, err := readRecords(r.client, zone.Id, dc.Name, desiredKeys, geo)
 	if err != nil {
 		return nil, err
 	}
 
-	var existingRecords = []*models.RecordConfig{}
-	for _, set := range records {
-		existingRecords = append(existingRecords, nativeToRecords(set, dc.Name)...)
-	}
 	for _, want := range dc.Records {
 		// update zone_id to current zone.id if not specified by the user
 		if want.Type == "R53_ALIAS" && want.R53Alias["zone_id"] == "" {
@@ -209,34 +316,13 @@ func (r *route53Provider) GetDomainCorrections(dc *models.DomainConfig) ([]*mode
 			chg.Action = sPtr("DELETE")
 			delDesc += strings.Join(namesToUpdate[k], "\n") + "\n"
 			// on delete just submit the original resource set we got from r53.
-			for _, r := range records {
-				if unescape(r.Name) == k.Name && (*r.Type == k.Type || k.Type == "R53_ALIAS") {
-					rrset = r
-					break
-				}
-			}
+			rrset = deletable[k]
 		} else {
 			changes = append(changes, chg)
 			changeDesc += strings.Join(namesToUpdate[k], "\n") + "\n"
 			// on change or create, just build a new record set from our desired state
 			chg.Action = sPtr("UPSERT")
-			rrset = &r53.ResourceRecordSet{
-				Name: sPtr(k.Name),
-				Type: sPtr(k.Type),
-			}
-			for _, r := range recs {
-				val := r.GetTargetCombined()
-				if r.Type != "R53_ALIAS" {
-					rr := &r53.ResourceRecord{
-						Value: &val,
-					}
-					rrset.ResourceRecords = append(rrset.ResourceRecords, rr)
-					i := int64(r.TTL)
-					rrset.TTL = &i // TODO: make sure that ttls are consistent within a set
-				} else {
-					rrset = aliasToRRSet(zone, r)
-				}
-			}
+			rrset = recordsToRRSet(zone, k, recs)
 		}
 		chg.ResourceRecordSet = rrset
 	}
@@ -250,15 +336,16 @@ func (r *route53Provider) GetDomainCorrections(dc *models.DomainConfig) ([]*mode
 	}
 
 	addCorrection := func(msg string, req *r53.ChangeResourceRecordSetsInput) {
-		corrections = append(corrections,
-			&models.Correction{
-				Msg: msg,
-				F: func() error {
-					req.HostedZoneId = zone.Id
-					_, err := r.client.ChangeResourceRecordSets(req)
-					return err
-				},
-			})
+		c := &models.Correction{Msg: msg}
+		c.F = func() error {
+			req.HostedZoneId = zone.Id
+			out, err := r.client.ChangeResourceRecordSets(req)
+			if err == nil && out.ChangeInfo != nil {
+				c.ID = aws.StringValue(out.ChangeInfo.Id)
+			}
+			return err
+		}
+		corrections = append(corrections, c)
 	}
 
 	if len(dels) > 0 {
@@ -273,12 +360,105 @@ func (r *route53Provider) GetDomainCorrections(dc *models.DomainConfig) ([]*mode
 
 }
 
-func nativeToRecords(set *r53.ResourceRecordSet, origin string) []*models.RecordConfig {
+// recordsToRRSet builds the record set of k out of the desired records.
+// Geolocation record sets keep the SetIdentifier they have in r53, which the
+// diff preserved in their records, so that they are updated rather than
+// added; new ones are identified by their location.
+func recordsToRRSet(zone *r53.HostedZone, k key, recs []*models.RecordConfig) *r53.ResourceRecordSet {
+	rrset := &r53.ResourceRecordSet{
+		Name: sPtr(k.Name),
+		Type: sPtr(k.Type),
+	}
+	for _, r := range recs {
+		val := r.GetTargetCombined()
+		if r.Type != "R53_ALIAS" {
+			rr := &r53.ResourceRecord{
+				Value: &val,
+			}
+			rrset.ResourceRecords = append(rrset.ResourceRecords, rr)
+			i := int64(r.TTL)
+			rrset.TTL = &i // TODO: make sure that ttls are consistent within a set
+		} else {
+			rrset = aliasToRRSet(zone, r)
+		}
+	}
+	if k.Geo != "" {
+		setID := ""
+		for _, r := range recs {
+			if id := r.Preserved[preservedSetID]; id != "" {
+				setID = id
+			}
+		}
+		if setID == "" {
+			setID = k.Geo
+		}
+		g, _ := models.ParseGeoLocation(k.Geo) // checked by normalize
+		rrset.SetIdentifier = sPtr(setID)
+		rrset.GeoLocation = geoToNative(g)
+	}
+	return rrset
+}
+
+// readRecords reads the record sets of a zone and converts them, as the diff
+// needs all the records of the zone at once. Only the record sets that are
+// not desired are also kept as they came from r53, so that they can be
+// deleted: the others are dropped page by page. The geolocation record sets
+// are only read with geo.
+func readRecords(client recordSetLister, zoneID *string, origin string, desired map[key]bool, geo bool) ([]*models.RecordConfig, map[key]*r53.ResourceRecordSet, error) {
+	var existing = []*models.RecordConfig{}
+	deletable := map[key]*r53.ResourceRecordSet{}
+	err := forEachRecordSet(client, zoneID, func(set *r53.ResourceRecordSet) error {
+		recs, err := nativeToRecords(set, origin, geo)
+		if err != nil {
+			return err
+		}
+		existing = append(existing, recs...)
+		if len(recs) > 0 {
+			k := getKey(recs[0])
+			if _, ok := deletable[k]; !ok && !desired[k] {
+				deletable[k] = set
+			}
+		}
+		return nil
+	})
+	return existing, deletable, err
+}
+
+// preservedSetID is the RecordConfig.Preserved field of the SetIdentifier of
+// a geolocation record set.
+const preservedSetID = "set_identifier"
+
+// unmanagedTypes are the record types that r53 serves but that are not
+// managed here. Their record sets are left alone.
+var unmanagedTypes = map[string]bool{"SOA": true, "SPF": true, "NAPTR": true, "DS": true}
+
+// nativeToRecords converts a record set. Only plain record sets, aliases and,
+// with geo, geolocation record sets (whose records get models.MetaGeo) are
+// managed: record sets created by traffic policies, and those with another
+// routing policy (weighted, latency, failover or multivalue answer) or a
+// health check, belong to other r53 features and convert to nothing. So do
+// geolocation record sets without geo, that is in domains that don't use
+// GEO(), as they were made outside of dnscontrol.
+func nativeToRecords(set *r53.ResourceRecordSet, origin string, geo bool) ([]*models.RecordConfig, error) {
 	results := []*models.RecordConfig{}
+	if set.TrafficPolicyInstanceId != nil || unmanagedTypes[aws.StringValue(set.Type)] {
+		return results, nil
+	}
+	meta := map[string]string{}
+	var preserved map[string]string
+	if set.SetIdentifier != nil {
+		if !geo || set.GeoLocation == nil || set.Weight != nil || set.Region != nil || set.Failover != nil || set.MultiValueAnswer != nil || set.HealthCheckId != nil {
+			return results, nil
+		}
+		meta[models.MetaGeo] = geoFromNative(set.GeoLocation).String()
+		preserved = map[string]string{preservedSetID: *set.SetIdentifier}
+	}
 	if set.AliasTarget != nil {
 		rc := &models.RecordConfig{
-			Type: "R53_ALIAS",
-			TTL:  300,
+			Type:      "R53_ALIAS",
+			TTL:       300,
+			Metadata:  meta,
+			Preserved: preserved,
 			R53Alias: map[string]string{
 				"type":    *set.Type,
 				"zone_id": *set.AliasTarget.HostedZoneId,
@@ -287,24 +467,43 @@ func nativeToRecords(set *r53.ResourceRecordSet, origin string) []*models.Record
 		rc.SetLabelFromFQDN(unescape(set.Name), origin)
 		rc.SetTarget(aws.StringValue(set.AliasTarget.DNSName))
 		results = append(results, rc)
-	} else if set.TrafficPolicyInstanceId != nil {
-		// skip traffic policy records
 	} else {
 		for _, rec := range set.ResourceRecords {
-			switch rtype := *set.Type; rtype {
-			case "SOA":
-				continue
-			default:
-				rc := &models.RecordConfig{TTL: uint32(*set.TTL)}
-				rc.SetLabelFromFQDN(unescape(set.Name), origin)
-				if err := rc.PopulateFromString(*set.Type, *rec.Value, origin); err != nil {
-					panic(errors.Wrap(err, "unparsable record received from R53"))
-				}
-				results = append(results, rc)
+			rc := &models.RecordConfig{TTL: uint32(aws.Int64Value(set.TTL)), Metadata: meta, Preserved: preserved}
+			rc.SetLabelFromFQDN(unescape(set.Name), origin)
+			if err := rc.PopulateFromString(*set.Type, *rec.Value, origin); err != nil {
+				return nil, errors.Wrapf(err, "unparsable %s record %s received from R53", *set.Type, unescape(set.Name))
 			}
+			results = append(results, rc)
 		}
 	}
-	return results
+	return results, nil
+}
+
+// geoFromNative converts the location of a geolocation record set. r53 names
+// the default location country "*".
+func geoFromNative(g *r53.GeoLocation) models.GeoLocation {
+	country := aws.StringValue(g.CountryCode)
+	if country == "*" {
+		country = ""
+	}
+	return models.GeoLocation{
+		Continent:   aws.StringValue(g.ContinentCode),
+		Country:     country,
+		Subdivision: aws.StringValue(g.SubdivisionCode),
+	}
+}
+
+func geoToNative(g models.GeoLocation) *r53.GeoLocation {
+	switch {
+	case g.Continent != "":
+		return &r53.GeoLocation{ContinentCode: sPtr(g.Continent)}
+	case g.Subdivision != "":
+		return &r53.GeoLocation{CountryCode: sPtr(g.Country), SubdivisionCode: sPtr(g.Subdivision)}
+	case g.Country != "":
+		return &r53.GeoLocation{CountryCode: sPtr(g.Country)}
+	}
+	return &r53.GeoLocation{CountryCode: sPtr("*")}
 }
 
 func getAliasMap(r *models.RecordConfig) map[string]string {
@@ -400,25 +599,51 @@ func (r *route53Provider) updateRegistrarNameservers(domainName string, nameserv
 	return domainUpdate.OperationId, nil
 }
 
-func (r *route53Provider) fetchRecordSets(zoneID *string) ([]*r53.ResourceRecordSet, error) {
+// recordSetLister is the part of the r53 client forEachRecordSet needs.
+type recordSetLister interface {
+	ListResourceRecordSets(*r53.ListResourceRecordSetsInput) (*r53.ListResourceRecordSetsOutput, error)
+}
+
+// route53Client is the part of the r53 client the provider uses. Tests
+// replace it.
+type route53Client interface {
+	recordSetLister
+	ListHostedZones(*r53.ListHostedZonesInput) (*r53.ListHostedZonesOutput, error)
+	GetHostedZone(*r53.GetHostedZoneInput) (*r53.GetHostedZoneOutput, error)
+	CreateHostedZone(*r53.CreateHostedZoneInput) (*r53.CreateHostedZoneOutput, error)
+	AssociateVPCWithHostedZone(*r53.AssociateVPCWithHostedZoneInput) (*r53.AssociateVPCWithHostedZoneOutput, error)
+	ChangeResourceRecordSets(*r53.ChangeResourceRecordSetsInput) (*r53.ChangeResourceRecordSetsOutput, error)
+}
+
+// listPageSize is how many record sets forEachRecordSet asks for at a time,
+// the most ListResourceRecordSets returns.
+const listPageSize = 100
+
+// forEachRecordSet calls fn for every record set of the zone, one page of at
+// most listPageSize at a time. The pages are not kept: fn keeps what it needs
+// of each set.
+func forEachRecordSet(client recordSetLister, zoneID *string, fn func(*r53.ResourceRecordSet) error) error {
 	if zoneID == nil || *zoneID == "" {
-		return nil, nil
+		return nil
 	}
 	var next *string
 	var nextType *string
-	var records []*r53.ResourceRecordSet
 	for {
 		listInput := &r53.ListResourceRecordSetsInput{
 			HostedZoneId:    zoneID,
 			StartRecordName: next,
 			StartRecordType: nextType,
-			MaxItems:        sPtr("100"),
+			MaxItems:        sPtr(strconv.Itoa(listPageSize)),
 		}
-		list, err := r.client.ListResourceRecordSets(listInput)
+		list, err := client.ListResourceRecordSets(listInput)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		for _, set := range list.ResourceRecordSets {
+			if err := fn(set); err != nil {
+				return err
+			}
 		}
-		records = append(records, list.ResourceRecordSets...)
 		if list.NextRecordName != nil {
 			next = list.NextRecordName
 			nextType = list.NextRecordType
@@ -426,7 +651,7 @@ func (r *route53Provider) fetchRecordSets(zoneID *string) ([]*r53.ResourceRecord
 			break
 		}
 	}
-	return records, nil
+	return nil
 }
 
 // we have to process names from route53 to match what we expect and to remove their odd octal encoding
@@ -439,16 +664,55 @@ func unescape(s *string) string {
 	return name
 }
 
+// ListZones returns the names of the zones this provider manages: the
+// public ones, or the private ones with private_zone.
+func (r *route53Provider) ListZones() ([]string, error) {
+	var zones []string
+	for domain, all := range r.zones {
+		for _, z := range all {
+			if isPrivate(z) == r.private.PrivateZone {
+				zones = append(zones, domain)
+				break
+			}
+		}
+	}
+	sort.Strings(zones)
+	return zones, nil
+}
+
 func (r *route53Provider) EnsureDomainExists(domain string) error {
-	if _, ok := r.zones[domain]; ok {
+	_, err := r.getZone(domain)
+	if err == nil {
 		return nil
 	}
-	fmt.Printf("Adding zone for %s to route 53 account\n", domain)
+	if _, ok := err.(errNoExist); !ok {
+		return err
+	}
 	in := &r53.CreateHostedZoneInput{
 		Name:            &domain,
 		CallerReference: sPtr(fmt.Sprint(time.Now().UnixNano())),
 	}
-	_, err := r.client.CreateHostedZone(in)
-	return err
-
+	if !r.private.PrivateZone {
+		fmt.Printf("Adding zone for %s to route 53 account\n", domain)
+		_, err = r.client.CreateHostedZone(in)
+		return err
+	}
+	fmt.Printf("Adding private zone for %s (VPCs %s) to route 53 account\n", domain, strings.Join(r.private.VPCIDs, ","))
+	// Only one VPC can be given at creation time. The rest are associated afterwards.
+	in.HostedZoneConfig = &r53.HostedZoneConfig{PrivateZone: aws.Bool(true)}
+	in.VPC = &r53.VPC{VPCId: sPtr(r.private.VPCIDs[0]), VPCRegion: sPtr(r.private.VPCRegion)}
+	out, err := r.client.CreateHostedZone(in)
+	if err != nil {
+		return err
+	}
+	for _, id := range r.private.VPCIDs[1:] {
+		_, err = r.client.AssociateVPCWithHostedZone(&r53.AssociateVPCWithHostedZoneInput{
+			HostedZoneId: out.HostedZone.Id,
+			VPC:          &r53.VPC{VPCId: sPtr(id), VPCRegion: sPtr(r.private.VPCRegion)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
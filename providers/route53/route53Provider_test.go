@@ -1,6 +1,42 @@
 package route53
 
-import "testing"
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+	"github.com/StackExchange/dnscontrol/providers/diff"
+	"github.com/aws/aws-sdk-go/aws"
+	r53 "github.com/aws/aws-sdk-go/service/route53"
+)
+
+func TestEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/mock/2013-04-01/hostedzone" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListHostedZonesResponse xmlns="https://route53.amazonaws.com/doc/2013-04-01/">
+  <HostedZones><HostedZone><Id>/hostedzone/Z1</Id><Name>example.com.</Name><CallerReference>1</CallerReference></HostedZone></HostedZones>
+  <IsTruncated>false</IsTruncated><MaxItems>100</MaxItems>
+</ListHostedZonesResponse>`))
+	}))
+	defer srv.Close()
+	p, err := newRoute53(map[string]string{"KeyId": "id", "SecretKey": "secret", "endpoint": srv.URL + "/mock"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.zones["example.com"]) != 1 {
+		t.Errorf("Expected the zones of the endpoint, got %v", p.zones)
+	}
+	if _, err := newRoute53(map[string]string{"endpoint": "route53.amazonaws.com"}, nil); err == nil {
+		t.Errorf("Expected an endpoint that is not a URL to be an error")
+	}
+}
 
 func TestUnescape(t *testing.T) {
 	var tests = []struct {
@@ -22,3 +58,323 @@ func TestUnescape(t *testing.T) {
 		}
 	}
 }
+
+func TestSelectZone(t *testing.T) {
+	zone := func(id string, private bool) *r53.HostedZone {
+		return &r53.HostedZone{
+			Id:     aws.String(id),
+			Name:   aws.String("example.com."),
+			Config: &r53.HostedZoneConfig{PrivateZone: aws.Bool(private)},
+		}
+	}
+	public := zone("public", false)
+	privA := zone("privA", true)
+	privB := zone("privB", true)
+	vpcs := map[string][]string{
+		"privA": {"vpc-a"},
+		"privB": {"vpc-b", "vpc-c"},
+	}
+	vpcsOf := func(z *r53.HostedZone) ([]string, error) {
+		return vpcs[*z.Id], nil
+	}
+	pub := route53Private{}
+	priv := func(ids ...string) route53Private {
+		return route53Private{PrivateZone: true, VPCIDs: ids, VPCRegion: "us-east-1"}
+	}
+
+	var tests = []struct {
+		desc       string
+		candidates []*r53.HostedZone
+		cfg        route53Private
+		expected   string // zone id, "" for error
+	}{
+		{"public only", []*r53.HostedZone{public}, pub, "public"},
+		{"public beside private", []*r53.HostedZone{privA, public}, pub, "public"},
+		{"private beside public", []*r53.HostedZone{public, privA}, priv("vpc-a"), "privA"},
+		{"private only, no public", []*r53.HostedZone{privA}, pub, ""},
+		{"public only, want private", []*r53.HostedZone{public}, priv("vpc-a"), ""},
+		{"two private, pick by vpc", []*r53.HostedZone{privA, privB, public}, priv("vpc-c"), "privB"},
+		{"two private, no vpc match", []*r53.HostedZone{privA, privB}, priv("vpc-z"), ""},
+		{"two private, both match", []*r53.HostedZone{privA, privB}, priv("vpc-a", "vpc-b"), ""},
+		{"two public", []*r53.HostedZone{public, zone("public2", false)}, pub, ""},
+		{"none", nil, pub, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			z, err := selectZone("example.com", test.candidates, test.cfg, vpcsOf)
+			if test.expected == "" {
+				if err == nil {
+					t.Fatalf("Expected error, got zone %s", *z.Id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *z.Id != test.expected {
+				t.Errorf("Expected zone %s, got %s", test.expected, *z.Id)
+			}
+		})
+	}
+}
+
+// syntheticZone serves n record sets, generating each page on request. It
+// counts the pages it was asked for.
+type syntheticZone struct {
+	n, served, pages int
+}
+
+func (z *syntheticZone) ListResourceRecordSets(in *r53.ListResourceRecordSetsInput) (*r53.ListResourceRecordSetsOutput, error) {
+	z.pages++
+	if z.served > 0 && aws.StringValue(in.StartRecordName) != fmt.Sprintf("host%06d.example.com.", z.served) {
+		return nil, fmt.Errorf("unexpected start record %s", aws.StringValue(in.StartRecordName))
+	}
+	max, err := strconv.Atoi(aws.StringValue(in.MaxItems))
+	if err != nil {
+		return nil, fmt.Errorf("MaxItems: %s", err)
+	}
+	out := &r53.ListResourceRecordSetsOutput{}
+	for ; z.served < z.n && len(out.ResourceRecordSets) < max; z.served++ {
+		out.ResourceRecordSets = append(out.ResourceRecordSets, &r53.ResourceRecordSet{
+			Name: aws.String(fmt.Sprintf("host%06d.example.com.", z.served)),
+			Type: aws.String("TXT"),
+			TTL:  aws.Int64(300),
+			ResourceRecords: []*r53.ResourceRecord{
+				{Value: aws.String(`"` + strings.Repeat("x", 200) + `"`)},
+			},
+		})
+	}
+	if z.served < z.n {
+		out.NextRecordName = aws.String(fmt.Sprintf("host%06d.example.com.", z.served))
+		out.NextRecordType = aws.String("TXT")
+	}
+	return out, nil
+}
+
+// fakeClient serves the record sets of zone and records the changes sent to
+// it. The other calls are not implemented.
+type fakeClient struct {
+	route53Client
+	zone recordSetLister
+	sent []*r53.ChangeResourceRecordSetsInput
+}
+
+func (c *fakeClient) ListResourceRecordSets(in *r53.ListResourceRecordSetsInput) (*r53.ListResourceRecordSetsOutput, error) {
+	return c.zone.ListResourceRecordSets(in)
+}
+
+// newFakeProvider returns a provider of the zone example.com, served by client.
+func newFakeProvider(client *fakeClient) *route53Provider {
+	return &route53Provider{client: client, zones: map[string][]*r53.HostedZone{
+		"example.com": {{Id: aws.String("Z1"), Name: aws.String("example.com.")}},
+	}}
+}
+
+func (c *fakeClient) ChangeResourceRecordSets(in *r53.ChangeResourceRecordSetsInput) (*r53.ChangeResourceRecordSetsOutput, error) {
+	c.sent = append(c.sent, in)
+	return &r53.ChangeResourceRecordSetsOutput{}, nil
+}
+
+func TestReadRecordsPages(t *testing.T) {
+	zone := &syntheticZone{n: 20 * listPageSize}
+	// All the record sets but the last are desired.
+	desired := map[key]bool{}
+	for i := 0; i < zone.n-1; i++ {
+		desired[key{Name: fmt.Sprintf("host%06d.example.com", i), Type: "TXT"}] = true
+	}
+	existing, deletable, err := readRecords(zone, aws.String("Z1"), "example.com", desired, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zone.pages != 20 {
+		t.Errorf("Expected 20 pages of %d record sets, got %d pages", listPageSize, zone.pages)
+	}
+	if len(existing) != zone.n {
+		t.Errorf("Expected %d records, got %d", zone.n, len(existing))
+	}
+	// Only the record sets a DELETE sends back are kept.
+	if len(deletable) != 1 || deletable[key{Name: "host001999.example.com", Type: "TXT"}] == nil {
+		t.Errorf("Expected to keep only the last record set, got %v", deletable)
+	}
+}
+
+// staticZone serves its record sets in one page.
+type staticZone []*r53.ResourceRecordSet
+
+func (z staticZone) ListResourceRecordSets(in *r53.ListResourceRecordSetsInput) (*r53.ListResourceRecordSetsOutput, error) {
+	return &r53.ListResourceRecordSetsOutput{ResourceRecordSets: z}, nil
+}
+
+func TestReadRecordsSkipsUnmanaged(t *testing.T) {
+	set := func(name, rType, value string) *r53.ResourceRecordSet {
+		return &r53.ResourceRecordSet{
+			Name:            aws.String(name + "example.com."),
+			Type:            aws.String(rType),
+			TTL:             aws.Int64(300),
+			ResourceRecords: []*r53.ResourceRecord{{Value: aws.String(value)}},
+		}
+	}
+	weighted := set("api.", "A", "10.0.0.2")
+	weighted.SetIdentifier = aws.String("blue")
+	weighted.Weight = aws.Int64(10)
+	failover := set("api.", "A", "10.0.0.3")
+	failover.SetIdentifier = aws.String("primary")
+	failover.Failover = aws.String("PRIMARY")
+	failover.HealthCheckId = aws.String("hc-1")
+	policy := &r53.ResourceRecordSet{
+		Name:                    aws.String("tp.example.com."),
+		Type:                    aws.String("A"),
+		TrafficPolicyInstanceId: aws.String("tp-1"),
+	}
+	alias := &r53.ResourceRecordSet{
+		Name:        aws.String("cdn.example.com."),
+		Type:        aws.String("A"),
+		AliasTarget: &r53.AliasTarget{HostedZoneId: aws.String("Z2"), DNSName: aws.String("d1.cloudfront.net.")},
+	}
+	// A zone with query logging, health checks and routing policies set up
+	// outside of dnscontrol.
+	zone := staticZone{
+		set("", "SOA", "ns-1.awsdns-1.com. awsdns-hostmaster.amazon.com. 1 7200 900 1209600 86400"),
+		set("", "NS", "ns-1.awsdns-1.com."),
+		set("www.", "A", "10.0.0.1"),
+		weighted, failover, policy, alias,
+		set("sip.", "NAPTR", `100 10 "S" "SIP+D2U" "" _sip._udp.example.com.`),
+		set("old.", "SPF", `"v=spf1 -all"`),
+	}
+
+	existing, deletable, err := readRecords(zone, aws.String("Z1"), "example.com", map[key]bool{{"www.example.com", "A", ""}: true}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range existing {
+		got = append(got, r.GetLabel()+" "+r.Type)
+	}
+	if want := []string{"@ NS", "www A", "cdn R53_ALIAS"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected records %v, got %v", want, got)
+	}
+	if len(deletable) != 2 || deletable[key{"www.example.com", "A", ""}] != nil || deletable[key{"api.example.com", "A", ""}] != nil {
+		t.Errorf("expected only the NS and alias record sets to be deletable, got %v", deletable)
+	}
+
+	// A record that should parse but doesn't is an error, not a panic.
+	if _, _, err := readRecords(staticZone{set("bad.", "A", "not-an-ip")}, aws.String("Z1"), "example.com", nil, false); err == nil {
+		t.Error("expected an error for an unparsable record")
+	}
+}
+
+func TestGeolocationRecords(t *testing.T) {
+	geo := func(id, value string, loc *r53.GeoLocation) *r53.ResourceRecordSet {
+		return &r53.ResourceRecordSet{
+			Name:            aws.String("www.example.com."),
+			Type:            aws.String("A"),
+			TTL:             aws.Int64(300),
+			SetIdentifier:   aws.String(id),
+			GeoLocation:     loc,
+			ResourceRecords: []*r53.ResourceRecord{{Value: aws.String(value)}},
+		}
+	}
+	checked := geo("checked", "10.0.0.9", &r53.GeoLocation{ContinentCode: aws.String("AS")})
+	checked.HealthCheckId = aws.String("hc-1")
+	zone := staticZone{
+		geo("germany", "10.0.0.1", &r53.GeoLocation{CountryCode: aws.String("DE")}),
+		geo("california", "10.0.0.2", &r53.GeoLocation{CountryCode: aws.String("US"), SubdivisionCode: aws.String("CA")}),
+		geo("default", "10.0.0.3", &r53.GeoLocation{CountryCode: aws.String("*")}),
+		checked,
+	}
+	existing, deletable, err := readRecords(zone, aws.String("Z1"), "example.com", nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range existing {
+		got = append(got, r.GetTargetField()+" "+r.Metadata[models.MetaGeo])
+	}
+	if want := []string{"10.0.0.1 country:DE", "10.0.0.2 country:US-CA", "10.0.0.3 *"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected records %v, got %v", want, got)
+	}
+	if len(deletable) != 3 || existing[1].Preserved[preservedSetID] != "california" {
+		t.Errorf("Expected each location to be a record set of its own, got %v and %v", deletable, existing[1].Preserved)
+	}
+
+	// Swapping two locations modifies both, and nothing else.
+	desired := func(target, loc string) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: "A", TTL: 300, Metadata: map[string]string{models.MetaGeo: loc}}
+		rc.SetLabel("www", "example.com")
+		rc.SetTarget(target)
+		return rc
+	}
+	dc := &models.DomainConfig{Name: "example.com", Records: []*models.RecordConfig{
+		desired("10.0.0.2", "country:DE"),
+		desired("10.0.0.1", "country:US-CA"),
+		desired("10.0.0.3", "*"),
+	}}
+	_, create, del, mod := diff.New(dc, getAliasMap).IncrementalDiff(existing)
+	if len(create) != 0 || len(del) != 0 || len(mod) != 2 {
+		t.Fatalf("Expected 2 modifications, got %v %v %v", create, del, mod)
+	}
+
+	// Changed record sets keep their SetIdentifier, which the diff preserves,
+	// new ones are named after their location.
+	for _, tst := range []struct {
+		geo, setID string
+		want       r53.GeoLocation
+	}{
+		{"country:US-CA", "california", r53.GeoLocation{CountryCode: aws.String("US"), SubdivisionCode: aws.String("CA")}},
+		{"continent:EU", "continent:EU", r53.GeoLocation{ContinentCode: aws.String("EU")}},
+		{"*", "default", r53.GeoLocation{CountryCode: aws.String("*")}},
+	} {
+		dc := &models.DomainConfig{Name: "example.com", Records: []*models.RecordConfig{desired("10.0.0.4", tst.geo)}}
+		diff.New(dc, getAliasMap).IncrementalDiff(existing)
+		rrset := recordsToRRSet(&r53.HostedZone{Id: aws.String("Z1")}, key{"www.example.com", "A", tst.geo}, dc.Records)
+		if aws.StringValue(rrset.SetIdentifier) != tst.setID || rrset.GeoLocation.String() != tst.want.String() {
+			t.Errorf("%s: expected SetIdentifier %s and %s, got %s and %s", tst.geo, tst.setID, tst.want, aws.StringValue(rrset.SetIdentifier), rrset.GeoLocation)
+		}
+	}
+	if rrset := recordsToRRSet(nil, key{"www.example.com", "A", ""}, []*models.RecordConfig{desired("10.0.0.4", "")}); rrset.SetIdentifier != nil || rrset.GeoLocation != nil {
+		t.Errorf("Expected a plain record set, got %s", rrset)
+	}
+}
+
+func TestGeolocationRecordsWithoutGEO(t *testing.T) {
+	set := func(value string, loc *r53.GeoLocation) *r53.ResourceRecordSet {
+		s := &r53.ResourceRecordSet{
+			Name:            aws.String("www.example.com."),
+			Type:            aws.String("A"),
+			TTL:             aws.Int64(300),
+			ResourceRecords: []*r53.ResourceRecord{{Value: aws.String(value)}},
+		}
+		if loc != nil {
+			s.SetIdentifier = aws.String("made-elsewhere")
+			s.GeoLocation = loc
+		}
+		return s
+	}
+	client := &fakeClient{zone: staticZone{
+		set("10.0.0.1", &r53.GeoLocation{CountryCode: aws.String("DE")}),
+		set("10.0.0.2", nil),
+	}}
+	// A domain without GEO() leaves the geolocation record sets alone.
+	rc := &models.RecordConfig{Type: "A", TTL: 300, Metadata: map[string]string{}}
+	rc.SetLabel("mail", "example.com")
+	rc.SetTarget("10.0.0.3")
+	dc := &models.DomainConfig{Name: "example.com", Records: []*models.RecordConfig{rc}}
+	corrections, err := newFakeProvider(client).GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range corrections {
+		if err := c.F(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var changes []string
+	for _, in := range client.sent {
+		for _, chg := range in.ChangeBatch.Changes {
+			changes = append(changes, aws.StringValue(chg.Action)+" "+aws.StringValue(chg.ResourceRecordSet.Name)+" "+aws.StringValue(chg.ResourceRecordSet.SetIdentifier))
+		}
+	}
+	if want := []string{"DELETE www.example.com. ", "UPSERT mail.example.com "}; strings.Join(changes, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %q, got %q", want, changes)
+	}
+}
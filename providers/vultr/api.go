@@ -0,0 +1,174 @@
+package vultr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	mediaType      = "application/json"
+	defaultBaseURL = "https://api.vultr.com/v2/"
+	domainsPath    = "domains"
+	// perPage is the largest page size of the v2 API.
+	perPage = 500
+)
+
+func (c *VultrApi) getAccount() error {
+	return c.get("account", &struct{}{})
+}
+
+func (c *VultrApi) getDomains() ([]string, error) {
+	var domains []string
+	cursor := ""
+	for {
+		dr := &domainsResponse{}
+		endpoint := fmt.Sprintf("%s?per_page=%d&cursor=%s", domainsPath, perPage, url.QueryEscape(cursor))
+		if err := c.get(endpoint, dr); err != nil {
+			return nil, errors.Errorf("Error fetching domain list from Vultr: %s", err)
+		}
+		for _, d := range dr.Domains {
+			domains = append(domains, d.Domain)
+		}
+		if cursor = dr.Meta.Links.Next; cursor == "" {
+			return domains, nil
+		}
+	}
+}
+
+func (c *VultrApi) createDomain(domain string) error {
+	return c.do(http.MethodPost, domainsPath, &struct {
+		Domain string `json:"domain"`
+	}{domain}, nil)
+}
+
+func (c *VultrApi) getRecords(domain string) ([]domainRecord, error) {
+	var records []domainRecord
+	cursor := ""
+	for {
+		rr := &recordsResponse{}
+		endpoint := fmt.Sprintf("%s/%s/records?per_page=%d&cursor=%s", domainsPath, domain, perPage, url.QueryEscape(cursor))
+		if err := c.get(endpoint, rr); err != nil {
+			return nil, errors.Errorf("Error fetching record list from Vultr: %s", err)
+		}
+		records = append(records, rr.Records...)
+		if cursor = rr.Meta.Links.Next; cursor == "" {
+			return records, nil
+		}
+	}
+}
+
+func (c *VultrApi) createRecord(domain string, r *domainRecord) error {
+	return c.do(http.MethodPost, fmt.Sprintf("%s/%s/records", domainsPath, domain), r, nil)
+}
+
+// updateRecord changes the record with r.ID. The type of a record can't be
+// changed.
+func (c *VultrApi) updateRecord(domain string, r *domainRecord) error {
+	req := *r
+	req.Type = ""
+	return c.do(http.MethodPatch, fmt.Sprintf("%s/%s/records/%s", domainsPath, domain, r.ID), &req, nil)
+}
+
+func (c *VultrApi) deleteRecord(domain, id string) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("%s/%s/records/%s", domainsPath, domain, id), nil, nil)
+}
+
+func (c *VultrApi) newRequest(method, endpoint string, body interface{}) (*http.Request, error) {
+	rel, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	u := c.baseURL.ResolveReference(rel)
+
+	buf := new(bytes.Buffer)
+	if body != nil {
+		err = json.NewEncoder(buf).Encode(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest(method, u.String(), buf)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Authorization", "Bearer "+c.token)
+	req.Header.Add("Content-Type", mediaType)
+	req.Header.Add("Accept", mediaType)
+	return req, nil
+}
+
+func (c *VultrApi) get(endpoint string, target interface{}) error {
+	return c.do(http.MethodGet, endpoint, nil, target)
+}
+
+// do sends the request and decodes the response into target, if it isn't nil.
+func (c *VultrApi) do(method, endpoint string, body, target interface{}) error {
+	req, err := c.newRequest(method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return c.handleErrors(resp)
+	}
+	if target == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+func (c *VultrApi) handleErrors(resp *http.Response) error {
+	errResp := &errorResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(errResp); err != nil || errResp.Error == "" {
+		return errors.Errorf("bad status code from Vultr: %d", resp.StatusCode)
+	}
+	return errors.Errorf("bad status code from Vultr: %d: %s", resp.StatusCode, errResp.Error)
+}
+
+type meta struct {
+	Total int `json:"total"`
+	Links struct {
+		Next string `json:"next"`
+		Prev string `json:"prev"`
+	} `json:"links"`
+}
+
+type domainsResponse struct {
+	Domains []struct {
+		Domain string `json:"domain"`
+	} `json:"domains"`
+	Meta meta `json:"meta"`
+}
+
+type recordsResponse struct {
+	Records []domainRecord `json:"records"`
+	Meta    meta           `json:"meta"`
+}
+
+// domainRecord is a record as the API returns it, and the request to create
+// or update one.
+type domainRecord struct {
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Name     string `json:"name"`
+	Data     string `json:"data"`
+	Priority int    `json:"priority"`
+	TTL      int    `json:"ttl"`
+}
+
+type errorResponse struct {
+	Error  string `json:"error"`
+	Status int    `json:"status"`
+}
@@ -3,7 +3,6 @@ package vultr
 import (
 	"testing"
 
-	vultr "github.com/JamesClonk/vultr/lib"
 	"github.com/StackExchange/dnscontrol/models"
 )
 
@@ -12,7 +11,7 @@ func TestConversion(t *testing.T) {
 		Name: "example.com",
 	}
 
-	records := []*vultr.DNSRecord{
+	records := []*domainRecord{
 		{
 			Type: "A",
 			Name: "",
@@ -3,9 +3,10 @@ package vultr
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"strings"
 
-	vultr "github.com/JamesClonk/vultr/lib"
 	"github.com/StackExchange/dnscontrol/models"
 	"github.com/StackExchange/dnscontrol/providers"
 	"github.com/StackExchange/dnscontrol/providers/diff"
@@ -15,7 +16,7 @@ import (
 
 /*
 
-Vultr API DNS provider:
+Vultr API v2 DNS provider:
 
 Info required in `creds.json`:
    - token
@@ -38,8 +39,9 @@ func init() {
 
 // VultrApi represents the Vultr DNSServiceProvider
 type VultrApi struct {
-	client *vultr.Client
-	token  string
+	client  *http.Client
+	baseURL *url.URL
+	token   string
 }
 
 // defaultNS are the default nameservers for Vultr
@@ -50,27 +52,45 @@ var defaultNS = []string{
 
 // NewVultr initializes a Vultr DNSServiceProvider
 func NewVultr(m map[string]string, metadata json.RawMessage) (providers.DNSServiceProvider, error) {
-	api := &VultrApi{
-		token: m["token"],
+	if m["token"] == "" {
+		return nil, errors.Errorf("Vultr API token is required")
 	}
 
-	if api.token == "" {
-		return nil, errors.Errorf("Vultr API token is required")
+	baseURL, err := providers.Endpoint(m, defaultBaseURL)
+	if err != nil {
+		return nil, err
 	}
 
-	api.client = vultr.NewClient(api.token, nil)
+	client, err := providers.HTTPClient(m)
+	if err != nil {
+		return nil, err
+	}
+	api := &VultrApi{
+		client:  client,
+		baseURL: baseURL,
+		token:   m["token"],
+	}
 
 	// Validate token
-	_, err := api.client.GetAccountInfo()
-	if err != nil {
+	if err := api.getAccount(); err != nil {
 		return nil, err
 	}
 
 	return api, nil
 }
 
+// CheckCredentials reads the account info to validate the token.
+func (api *VultrApi) CheckCredentials() error {
+	return api.getAccount()
+}
+
 // GetDomainCorrections gets the corrections for a DomainConfig
 func (api *VultrApi) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Correction, error) {
+	dc, err := dc.Copy()
+	if err != nil {
+		return nil, err
+	}
+
 	dc.Punycode()
 
 	ok, err := api.isDomainInAccount(dc.Name)
@@ -82,20 +102,32 @@ func (api *VultrApi) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Co
 		return nil, errors.Errorf("%s is not a domain in the Vultr account", dc.Name)
 	}
 
-	records, err := api.client.GetDNSRecords(dc.Name)
+	records, err := api.getRecords(dc.Name)
 	if err != nil {
 		return nil, err
 	}
 
-	curRecords := make([]*models.RecordConfig, len(records))
+	curRecords := []*models.RecordConfig{}
 	for i := range records {
 		r, err := toRecordConfig(dc, &records[i])
 		if err != nil {
 			return nil, err
 		}
+		if isDefaultNS(r) {
+			continue
+		}
+		curRecords = append(curRecords, r)
+	}
 
-		curRecords[i] = r
+	// Vultr adds its nameservers to every domain. Leave them to Vultr, or
+	// their TTL would be changed back and forth on every run.
+	desired := dc.Records[:0]
+	for _, r := range dc.Records {
+		if !isDefaultNS(r) {
+			desired = append(desired, r)
+		}
 	}
+	dc.Records = desired
 
 	// Normalize
 	models.PostProcessRecords(curRecords)
@@ -106,11 +138,11 @@ func (api *VultrApi) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Co
 	corrections := []*models.Correction{}
 
 	for _, mod := range delete {
-		id := mod.Existing.Original.(*vultr.DNSRecord).RecordID
+		id := mod.Existing.Original.(*domainRecord).ID
 		corrections = append(corrections, &models.Correction{
 			Msg: fmt.Sprintf("%s; Vultr RecordID: %v", mod.String(), id),
 			F: func() error {
-				return api.client.DeleteDNSRecord(dc.Name, id)
+				return api.deleteRecord(dc.Name, id)
 			},
 		})
 	}
@@ -120,19 +152,19 @@ func (api *VultrApi) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Co
 		corrections = append(corrections, &models.Correction{
 			Msg: mod.String(),
 			F: func() error {
-				return api.client.CreateDNSRecord(dc.Name, r.Name, r.Type, r.Data, r.Priority, r.TTL)
+				return api.createRecord(dc.Name, r)
 			},
 		})
 	}
 
 	for _, mod := range modify {
-		id := mod.Existing.Original.(*vultr.DNSRecord).RecordID
+		id := mod.Existing.Original.(*domainRecord).ID
 		r := toVultrRecord(dc, mod.Desired)
-		r.RecordID = id
+		r.ID = id
 		corrections = append(corrections, &models.Correction{
 			Msg: fmt.Sprintf("%s; Vultr RecordID: %v", mod.String(), id),
 			F: func() error {
-				return api.client.UpdateDNSRecord(dc.Name, *r)
+				return api.updateRecord(dc.Name, r)
 			},
 		})
 	}
@@ -140,6 +172,21 @@ func (api *VultrApi) GetDomainCorrections(dc *models.DomainConfig) ([]*models.Co
 	return corrections, nil
 }
 
+// isDefaultNS returns true if r is one of the NS records Vultr adds to the
+// apex of every domain.
+func isDefaultNS(r *models.RecordConfig) bool {
+	if r.Type != "NS" || r.GetLabel() != "@" {
+		return false
+	}
+	target := strings.TrimSuffix(r.GetTargetField(), ".")
+	for _, ns := range defaultNS {
+		if target == ns {
+			return true
+		}
+	}
+	return false
+}
+
 // GetNameservers gets the Vultr nameservers for a domain
 func (api *VultrApi) GetNameservers(domain string) ([]*models.Nameserver, error) {
 	return models.StringsToNameservers(defaultNS), nil
@@ -153,8 +200,7 @@ func (api *VultrApi) EnsureDomainExists(domain string) error {
 	}
 
 	if !ok {
-		// Vultr requires an initial IP, use a dummy one
-		err := api.client.CreateDNSDomain(domain, "127.0.0.1")
+		err := api.createDomain(domain)
 		if err != nil {
 			return err
 		}
@@ -172,27 +218,22 @@ func (api *VultrApi) EnsureDomainExists(domain string) error {
 }
 
 func (api *VultrApi) isDomainInAccount(domain string) (bool, error) {
-	domains, err := api.client.GetDNSDomains()
+	domains, err := api.getDomains()
 	if err != nil {
 		return false, err
 	}
 
-	var vd *vultr.DNSDomain
 	for _, d := range domains {
-		if d.Domain == domain {
-			vd = &d
+		if d == domain {
+			return true, nil
 		}
 	}
 
-	if vd == nil {
-		return false, nil
-	}
-
-	return true, nil
+	return false, nil
 }
 
 // toRecordConfig converts a Vultr DNSRecord to a RecordConfig #rtype_variations
-func toRecordConfig(dc *models.DomainConfig, r *vultr.DNSRecord) (*models.RecordConfig, error) {
+func toRecordConfig(dc *models.DomainConfig, r *domainRecord) (*models.RecordConfig, error) {
 	origin := dc.Name
 	data := r.Data
 	rc := &models.RecordConfig{
@@ -233,7 +274,7 @@ func toRecordConfig(dc *models.DomainConfig, r *vultr.DNSRecord) (*models.Record
 }
 
 // toVultrRecord converts a RecordConfig converted by toRecordConfig back to a Vultr DNSRecord #rtype_variations
-func toVultrRecord(dc *models.DomainConfig, rc *models.RecordConfig) *vultr.DNSRecord {
+func toVultrRecord(dc *models.DomainConfig, rc *models.RecordConfig) *domainRecord {
 	name := rc.GetLabel()
 	// Vultr uses a blank string to represent the apex domain
 	if name == "@" {
@@ -260,7 +301,7 @@ func toVultrRecord(dc *models.DomainConfig, rc *models.RecordConfig) *vultr.DNSR
 		priority = int(rc.SrvPriority)
 	}
 
-	r := &vultr.DNSRecord{
+	r := &domainRecord{
 		Type:     rc.Type,
 		Name:     name,
 		Data:     data,
@@ -0,0 +1,145 @@
+package vultr
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/StackExchange/dnscontrol/models"
+)
+
+// fakeAPI serves the domains and records of the v2 API, one per page, and
+// records the requests that change records.
+type fakeAPI struct {
+	t       *testing.T
+	records []domainRecord
+	changes []string
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"Invalid API token.","status":401}`))
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/account":
+		w.Write([]byte(`{"account": {}}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/domains":
+		domains := []string{"example.net", "example.com"}
+		i, m := page(r, len(domains))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"domains": []map[string]string{{"domain": domains[i]}},
+			"meta":    m,
+		})
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/domains/example.com/records":
+		i, m := page(r, len(f.records))
+		json.NewEncoder(w).Encode(recordsResponse{Records: f.records[i : i+1], Meta: m})
+	case r.Method == http.MethodPost || r.Method == http.MethodPatch || r.Method == http.MethodDelete:
+		body, _ := ioutil.ReadAll(r.Body)
+		f.changes = append(f.changes, r.Method+" "+r.URL.Path+" "+strings.TrimSpace(string(body)))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// page returns the index of the item at the cursor of r, and the meta with
+// the cursor of the next one.
+func page(r *http.Request, n int) (int, meta) {
+	i, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+	m := meta{Total: n}
+	if i+1 < n {
+		m.Links.Next = strconv.Itoa(i + 1)
+	}
+	return i, m
+}
+
+func newTestAPI(t *testing.T, records []domainRecord) (*VultrApi, *fakeAPI, func()) {
+	f := &fakeAPI{t: t, records: records}
+	srv := httptest.NewServer(f)
+	u, _ := url.Parse(srv.URL + "/v2/")
+	return &VultrApi{client: srv.Client(), baseURL: u, token: "secret"}, f, srv.Close
+}
+
+func TestGetDomainCorrections(t *testing.T) {
+	api, f, done := newTestAPI(t, []domainRecord{
+		// Vultr's own nameservers, with a TTL that differs from ns_ttl.
+		{ID: "ns1", Type: "NS", Name: "", Data: "ns1.vultr.com", TTL: 3600},
+		{ID: "ns2", Type: "NS", Name: "", Data: "ns2.vultr.com", TTL: 3600},
+		{ID: "a", Type: "A", Name: "www", Data: "1.2.3.4", TTL: 300},
+		{ID: "mx", Type: "MX", Name: "", Data: "mail.example.com", Priority: 10, TTL: 300},
+		{ID: "old", Type: "A", Name: "old", Data: "1.2.3.4", TTL: 300},
+	})
+	defer done()
+
+	dc := &models.DomainConfig{Name: "example.com"}
+	rec := func(rType, label, target string) *models.RecordConfig {
+		rc := &models.RecordConfig{Type: rType, TTL: 300, Metadata: map[string]string{}}
+		rc.SetLabel(label, dc.Name)
+		rc.SetTarget(target)
+		return rc
+	}
+	mx := rec("MX", "@", "mail.example.com.")
+	mx.MxPreference = 10
+	dc.Records = []*models.RecordConfig{
+		rec("NS", "@", "ns1.vultr.com."),
+		rec("NS", "@", "ns2.vultr.com."),
+		rec("A", "www", "5.6.7.8"),
+		mx,
+		rec("CNAME", "new", "www.example.com."),
+	}
+
+	corrections, err := api.GetDomainCorrections(dc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range corrections {
+		if err := c.F(); err != nil {
+			t.Fatalf("%s: %s", c.Msg, err)
+		}
+	}
+	expected := []string{
+		"DELETE /v2/domains/example.com/records/old ",
+		`POST /v2/domains/example.com/records {"type":"CNAME","name":"new","data":"www.example.com","priority":0,"ttl":300}`,
+		`PATCH /v2/domains/example.com/records/a {"id":"a","name":"www","data":"5.6.7.8","priority":0,"ttl":300}`,
+	}
+	if strings.Join(f.changes, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected changes\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(f.changes, "\n"))
+	}
+}
+
+func TestBadToken(t *testing.T) {
+	api, _, done := newTestAPI(t, nil)
+	defer done()
+	api.token = "wrong"
+	if err := api.getAccount(); err == nil || !strings.Contains(err.Error(), "Invalid API token") {
+		t.Errorf("expected the API error, got %v", err)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	f := &fakeAPI{t: t}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+	p, err := NewVultr(map[string]string{"token": "secret", "endpoint": srv.URL + "/v2"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	domains, err := p.(*VultrApi).getDomains()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 2 {
+		t.Errorf("Expected the domains of the endpoint, got %v", domains)
+	}
+	if api, err := NewVultr(map[string]string{"token": "secret", "endpoint": "ftp://" + srv.Listener.Addr().String()}, nil); err == nil {
+		t.Errorf("Expected an ftp:// endpoint to be an error, got %v", api)
+	}
+}